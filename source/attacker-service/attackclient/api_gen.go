@@ -0,0 +1,124 @@
+// Code generated by attackclient/gen. DO NOT EDIT.
+
+package attackclient
+
+import (
+	"context"
+
+	"github.com/tsinghua-cel/attacker-service/types"
+)
+
+// AdminSetRoleAttacker calls admin_setRoleAttacker.
+func (c *Client) AdminSetRoleAttacker(ctx context.Context, valIndex int) error {
+	return c.c.CallContext(ctx, nil, "admin_setRoleAttacker", valIndex)
+}
+
+// AdminSetRoleNormal calls admin_setRoleNormal.
+func (c *Client) AdminSetRoleNormal(ctx context.Context, valIndex int) error {
+	return c.c.CallContext(ctx, nil, "admin_setRoleNormal", valIndex)
+}
+
+// AttestAfterBroadCast calls attest_afterBroadCast.
+func (c *Client) AttestAfterBroadCast(ctx context.Context, slot uint64) (types.AttackerResponse, error) {
+	var result types.AttackerResponse
+	err := c.c.CallContext(ctx, &result, "attest_afterBroadCast", slot)
+	return result, err
+}
+
+// AttestAfterPropose calls attest_afterPropose.
+func (c *Client) AttestAfterPropose(ctx context.Context, slot uint64, pubkey string, signedAttestData string, encoding *string) (types.AttackerResponse, error) {
+	var result types.AttackerResponse
+	err := c.c.CallContext(ctx, &result, "attest_afterPropose", slot, pubkey, signedAttestData, encoding)
+	return result, err
+}
+
+// AttestAfterSign calls attest_afterSign.
+func (c *Client) AttestAfterSign(ctx context.Context, slot uint64, pubkey string, signedAttestData string, encoding *string) (types.AttackerResponse, error) {
+	var result types.AttackerResponse
+	err := c.c.CallContext(ctx, &result, "attest_afterSign", slot, pubkey, signedAttestData, encoding)
+	return result, err
+}
+
+// AttestBeforeBroadCast calls attest_beforeBroadCast.
+func (c *Client) AttestBeforeBroadCast(ctx context.Context, slot uint64) (types.AttackerResponse, error) {
+	var result types.AttackerResponse
+	err := c.c.CallContext(ctx, &result, "attest_beforeBroadCast", slot)
+	return result, err
+}
+
+// AttestBeforePropose calls attest_beforePropose.
+func (c *Client) AttestBeforePropose(ctx context.Context, slot uint64, pubkey string, signedAttestData string, encoding *string) (types.AttackerResponse, error) {
+	var result types.AttackerResponse
+	err := c.c.CallContext(ctx, &result, "attest_beforePropose", slot, pubkey, signedAttestData, encoding)
+	return result, err
+}
+
+// AttestBeforeSign calls attest_beforeSign.
+func (c *Client) AttestBeforeSign(ctx context.Context, slot uint64, pubkey string, attestData string, encoding *string) (types.AttackerResponse, error) {
+	var result types.AttackerResponse
+	err := c.c.CallContext(ctx, &result, "attest_beforeSign", slot, pubkey, attestData, encoding)
+	return result, err
+}
+
+// BlockAfterBroadCast calls block_afterBroadCast.
+func (c *Client) BlockAfterBroadCast(ctx context.Context, slot uint64, pubkey *string) (types.AttackerResponse, error) {
+	var result types.AttackerResponse
+	err := c.c.CallContext(ctx, &result, "block_afterBroadCast", slot, pubkey)
+	return result, err
+}
+
+// BlockAfterPropose calls block_afterPropose.
+func (c *Client) BlockAfterPropose(ctx context.Context, slot uint64, pubkey string, signedBlockData string, encoding *string) (types.AttackerResponse, error) {
+	var result types.AttackerResponse
+	err := c.c.CallContext(ctx, &result, "block_afterPropose", slot, pubkey, signedBlockData, encoding)
+	return result, err
+}
+
+// BlockAfterSign calls block_afterSign.
+func (c *Client) BlockAfterSign(ctx context.Context, slot uint64, pubkey string, signedBlockData string, encoding *string) (types.AttackerResponse, error) {
+	var result types.AttackerResponse
+	err := c.c.CallContext(ctx, &result, "block_afterSign", slot, pubkey, signedBlockData, encoding)
+	return result, err
+}
+
+// BlockBeforeBroadCast calls block_beforeBroadCast.
+func (c *Client) BlockBeforeBroadCast(ctx context.Context, slot uint64, pubkey *string) (types.AttackerResponse, error) {
+	var result types.AttackerResponse
+	err := c.c.CallContext(ctx, &result, "block_beforeBroadCast", slot, pubkey)
+	return result, err
+}
+
+// BlockBeforePropose calls block_beforePropose.
+func (c *Client) BlockBeforePropose(ctx context.Context, slot uint64, pubkey string, signedBlockData string, encoding *string) (types.AttackerResponse, error) {
+	var result types.AttackerResponse
+	err := c.c.CallContext(ctx, &result, "block_beforePropose", slot, pubkey, signedBlockData, encoding)
+	return result, err
+}
+
+// BlockBeforeSign calls block_beforeSign.
+func (c *Client) BlockBeforeSign(ctx context.Context, slot uint64, pubkey string, signedBlockData string, encoding *string) (types.AttackerResponse, error) {
+	var result types.AttackerResponse
+	err := c.c.CallContext(ctx, &result, "block_beforeSign", slot, pubkey, signedBlockData, encoding)
+	return result, err
+}
+
+// BlockBroadCastDelay calls block_broadCastDelay.
+func (c *Client) BlockBroadCastDelay(ctx context.Context, slot uint64) (types.AttackerResponse, error) {
+	var result types.AttackerResponse
+	err := c.c.CallContext(ctx, &result, "block_broadCastDelay", slot)
+	return result, err
+}
+
+// BlockDelayForReceiveBlock calls block_delayForReceiveBlock.
+func (c *Client) BlockDelayForReceiveBlock(ctx context.Context, slot uint64, pubkey *string) (types.AttackerResponse, error) {
+	var result types.AttackerResponse
+	err := c.c.CallContext(ctx, &result, "block_delayForReceiveBlock", slot, pubkey)
+	return result, err
+}
+
+// BlockGetNewParentRoot calls block_getNewParentRoot.
+func (c *Client) BlockGetNewParentRoot(ctx context.Context, slot uint64, pubkey string, parentRoot string) (types.AttackerResponse, error) {
+	var result types.AttackerResponse
+	err := c.c.CallContext(ctx, &result, "block_getNewParentRoot", slot, pubkey, parentRoot)
+	return result, err
+}
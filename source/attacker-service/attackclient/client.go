@@ -0,0 +1,96 @@
+// Package attackclient is a typed client of the attacker service rpc. The
+// methods of the rpc namespaces are generated from server/apis, so the client
+// and the service share the request and response types.
+package attackclient
+
+//go:generate go run ./gen -apis ../server/apis -out api_gen.go
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/tsinghua-cel/attacker-service/auth"
+	"github.com/tsinghua-cel/attacker-service/decision"
+	"github.com/tsinghua-cel/attacker-service/rpc"
+	"github.com/tsinghua-cel/attacker-service/types"
+)
+
+// AttackerResponse is the response of the hooks, it and the commands are
+// exported here too so the callers of the hooks only import the client.
+type AttackerResponse = types.AttackerResponse
+
+type AttackerCommand = types.AttackerCommand
+
+const (
+	CMD_NULL             = types.CMD_NULL
+	CMD_CONTINUE         = types.CMD_CONTINUE
+	CMD_RETURN           = types.CMD_RETURN
+	CMD_ABORT            = types.CMD_ABORT
+	CMD_SKIP             = types.CMD_SKIP
+	CMD_ROLE_TO_NORMAL   = types.CMD_ROLE_TO_NORMAL
+	CMD_ROLE_TO_ATTACKER = types.CMD_ROLE_TO_ATTACKER
+	CMD_EXIT             = types.CMD_EXIT
+	CMD_UPDATE_STATE     = types.CMD_UPDATE_STATE
+)
+
+// Client is a client of the attacker service.
+type Client struct {
+	c *rpc.Client
+}
+
+// Dial connects to the attacker service at url, http and websocket urls are
+// supported, the subscriptions need websocket.
+func Dial(url string, options ...rpc.ClientOption) (*Client, error) {
+	return DialContext(context.Background(), url, options...)
+}
+
+func DialContext(ctx context.Context, url string, options ...rpc.ClientOption) (*Client, error) {
+	c, err := rpc.DialOptions(ctx, url, options...)
+	if err != nil {
+		return nil, err
+	}
+	return NewClient(c), nil
+}
+
+// NewClient creates a client that uses the given rpc client.
+func NewClient(c *rpc.Client) *Client {
+	return &Client{c: c}
+}
+
+// WithApiKey sets the api key header of the authenticated namespaces.
+func WithApiKey(key string) rpc.ClientOption {
+	return rpc.WithHeader("X-API-Key", key)
+}
+
+// AuthHeaders returns the headers authenticating a client by the api key, or by
+// a bearer token signed with the jwt secret. The token is issued now and goes
+// stale after a minute, so the headers are made again for every connection.
+func AuthHeaders(apiKey string, jwtSecret []byte) (http.Header, error) {
+	h := make(http.Header)
+	if apiKey != "" {
+		h.Set(auth.ApiKeyHeader, apiKey)
+	}
+	if len(jwtSecret) > 0 {
+		token, err := auth.NewToken(jwtSecret)
+		if err != nil {
+			return nil, err
+		}
+		h.Set("Authorization", "Bearer "+token)
+	}
+	return h, nil
+}
+
+// Close closes the underlying rpc client.
+func (c *Client) Close() {
+	c.c.Close()
+}
+
+// SubscribeDecisions subscribes to the attack decisions of the given
+// validators, or of all validators if pubkeys is empty. With hold the client
+// holds the delayed duties of the validators until their release decision.
+func (c *Client) SubscribeDecisions(ctx context.Context, ch chan<- decision.Decision, pubkeys []string, hold bool) (*rpc.ClientSubscription, error) {
+	if pubkeys == nil {
+		pubkeys = []string{}
+	}
+	return c.c.Subscribe(ctx, "attacker", ch, "decisions", pubkeys, hold)
+}
@@ -0,0 +1,284 @@
+// gen generates the typed methods of the attackclient from the rpc apis
+// registered in server/apis, run it with go generate in the attackclient directory.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const header = `// Code generated by attackclient/gen. DO NOT EDIT.
+
+package attackclient
+
+`
+
+type param struct {
+	name string
+	typ  string
+}
+
+type method struct {
+	namespace string
+	name      string
+	params    []param
+	result    string // empty if the method has no result
+}
+
+func main() {
+	apisDir := flag.String("apis", "../server/apis", "directory of the rpc apis")
+	output := flag.String("out", "api_gen.go", "output file")
+	flag.Parse()
+
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, *apisDir, func(info os.FileInfo) bool {
+		return !strings.HasSuffix(info.Name(), "_test.go")
+	}, 0)
+	if err != nil {
+		log.Fatal(err)
+	}
+	pkg, exist := pkgs["apis"]
+	if !exist {
+		log.Fatalf("package apis not found in %s", *apisDir)
+	}
+
+	// map the service types to the namespaces registered in GetAPIs.
+	constructors := make(map[string]string) // constructor name -> service type
+	namespaces := make(map[string]string)   // constructor name -> namespace
+	for _, file := range pkg.Files {
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Recv != nil {
+				continue
+			}
+			if fn.Type.Results != nil && len(fn.Type.Results.List) == 1 {
+				if star, ok := fn.Type.Results.List[0].Type.(*ast.StarExpr); ok {
+					if ident, ok := star.X.(*ast.Ident); ok {
+						constructors[fn.Name.Name] = ident.Name
+					}
+				}
+			}
+			if fn.Name.Name == "GetAPIs" {
+				collectNamespaces(fn, namespaces)
+			}
+		}
+	}
+	services := make(map[string]string) // service type -> namespace
+	for constructor, namespace := range namespaces {
+		if typ, exist := constructors[constructor]; exist {
+			services[typ] = namespace
+		}
+	}
+
+	methods := make([]method, 0)
+	imports := make(map[string]bool)
+	for _, file := range pkg.Files {
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Recv == nil || !fn.Name.IsExported() {
+				continue
+			}
+			namespace, exist := services[receiverType(fn)]
+			if !exist {
+				continue
+			}
+			m, ok := parseMethod(fset, namespace, fn)
+			if !ok {
+				continue
+			}
+			for _, p := range m.params {
+				collectImports(p.typ, imports)
+			}
+			collectImports(m.result, imports)
+			methods = append(methods, m)
+		}
+	}
+	sort.Slice(methods, func(i, j int) bool {
+		if methods[i].namespace != methods[j].namespace {
+			return methods[i].namespace < methods[j].namespace
+		}
+		return methods[i].name < methods[j].name
+	})
+
+	var buf bytes.Buffer
+	buf.WriteString(header)
+	buf.WriteString("import (\n\t\"context\"\n\n")
+	for _, path := range sortedKeys(imports) {
+		fmt.Fprintf(&buf, "\t%q\n", path)
+	}
+	buf.WriteString(")\n")
+	for _, m := range methods {
+		writeMethod(&buf, m)
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatalf("format generated code failed: %v\n%s", err, buf.String())
+	}
+	if err := os.WriteFile(*output, src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// collectNamespaces reads the rpc.API{Namespace: "...", Service: NewXXX(...)} literals.
+func collectNamespaces(fn *ast.FuncDecl, namespaces map[string]string) {
+	ast.Inspect(fn, func(n ast.Node) bool {
+		lit, ok := n.(*ast.CompositeLit)
+		if !ok {
+			return true
+		}
+		var namespace, constructor string
+		for _, elt := range lit.Elts {
+			kv, ok := elt.(*ast.KeyValueExpr)
+			if !ok {
+				continue
+			}
+			key, ok := kv.Key.(*ast.Ident)
+			if !ok {
+				continue
+			}
+			switch key.Name {
+			case "Namespace":
+				if basic, ok := kv.Value.(*ast.BasicLit); ok {
+					namespace, _ = strconv.Unquote(basic.Value)
+				}
+			case "Service":
+				if call, ok := kv.Value.(*ast.CallExpr); ok {
+					if ident, ok := call.Fun.(*ast.Ident); ok {
+						constructor = ident.Name
+					}
+				}
+			}
+		}
+		if namespace != "" && constructor != "" {
+			namespaces[constructor] = namespace
+		}
+		return true
+	})
+}
+
+func receiverType(fn *ast.FuncDecl) string {
+	typ := fn.Recv.List[0].Type
+	if star, ok := typ.(*ast.StarExpr); ok {
+		typ = star.X
+	}
+	if ident, ok := typ.(*ast.Ident); ok {
+		return ident.Name
+	}
+	return ""
+}
+
+// parseMethod returns false for the methods the client can't call directly,
+// the subscriptions are written by hand.
+func parseMethod(fset *token.FileSet, namespace string, fn *ast.FuncDecl) (method, bool) {
+	m := method{namespace: namespace, name: fn.Name.Name}
+	for i, field := range fn.Type.Params.List {
+		typ := exprString(fset, field.Type)
+		if i == 0 && typ == "context.Context" {
+			continue
+		}
+		for _, name := range field.Names {
+			m.params = append(m.params, param{name: name.Name, typ: typ})
+		}
+	}
+	if fn.Type.Results != nil {
+		results := make([]string, 0)
+		for _, field := range fn.Type.Results.List {
+			n := len(field.Names)
+			if n == 0 {
+				n = 1
+			}
+			for i := 0; i < n; i++ {
+				results = append(results, exprString(fset, field.Type))
+			}
+		}
+		switch {
+		case len(results) == 0:
+		case len(results) == 1 && results[0] == "error":
+		case len(results) == 1:
+			m.result = results[0]
+		case len(results) == 2 && results[1] == "error":
+			m.result = results[0]
+		default:
+			return m, false
+		}
+	}
+	if m.result == "*rpc.Subscription" {
+		return m, false
+	}
+	return m, true
+}
+
+func exprString(fset *token.FileSet, expr ast.Expr) string {
+	var buf bytes.Buffer
+	printer.Fprint(&buf, fset, expr)
+	return buf.String()
+}
+
+var knownImports = map[string]string{
+	"types": "github.com/tsinghua-cel/attacker-service/types",
+	"ethpb": "github.com/prysmaticlabs/prysm/v5/proto/prysm/v1alpha1",
+}
+
+func collectImports(typ string, imports map[string]bool) {
+	for name, path := range knownImports {
+		if strings.Contains(typ, name+".") {
+			imports[path] = true
+		}
+	}
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func exportName(s string) string {
+	r := []rune(s)
+	r[0] = unicode.ToUpper(r[0])
+	return string(r)
+}
+
+func rpcName(s string) string {
+	r := []rune(s)
+	r[0] = unicode.ToLower(r[0])
+	return string(r)
+}
+
+func writeMethod(buf *bytes.Buffer, m method) {
+	name := exportName(m.namespace) + m.name
+	rpcMethod := m.namespace + "_" + rpcName(m.name)
+	params := []string{"ctx context.Context"}
+	args := []string{"ctx", "", fmt.Sprintf("%q", rpcMethod)}
+	for _, p := range m.params {
+		params = append(params, p.name+" "+p.typ)
+		args = append(args, p.name)
+	}
+	fmt.Fprintf(buf, "\n// %s calls %s.\n", name, rpcMethod)
+	if m.result == "" {
+		args[1] = "nil"
+		fmt.Fprintf(buf, "func (c *Client) %s(%s) error {\n", name, strings.Join(params, ", "))
+		fmt.Fprintf(buf, "\treturn c.c.CallContext(%s)\n}\n", strings.Join(args, ", "))
+		return
+	}
+	args[1] = "&result"
+	fmt.Fprintf(buf, "func (c *Client) %s(%s) (%s, error) {\n", name, strings.Join(params, ", "), m.result)
+	fmt.Fprintf(buf, "\tvar result %s\n", m.result)
+	fmt.Fprintf(buf, "\terr := c.c.CallContext(%s)\n", strings.Join(args, ", "))
+	fmt.Fprintf(buf, "\treturn result, err\n}\n")
+}
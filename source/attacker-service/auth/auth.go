@@ -0,0 +1,69 @@
+package auth
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/tsinghua-cel/attacker-service/config"
+)
+
+const ApiKeyHeader = "X-API-Key"
+
+var ErrInvalidApiKey = errors.New("invalid api key")
+
+// Authenticator checks requests against the configured jwt secret and api key.
+// A request is accepted when it carries either a valid bearer token or the
+// api key.
+type Authenticator struct {
+	secret      []byte
+	apiKey      string
+	protectRead bool
+}
+
+func NewAuthenticator(conf config.AuthConfig) (*Authenticator, error) {
+	a := &Authenticator{
+		apiKey:      conf.ApiKey,
+		protectRead: conf.ProtectRead,
+	}
+	if conf.JwtSecret != "" {
+		secret, err := LoadJwtSecret(conf.JwtSecret)
+		if err != nil {
+			return nil, err
+		}
+		a.secret = secret
+	}
+	return a, nil
+}
+
+// Enabled returns true when a jwt secret or an api key is configured.
+func (a *Authenticator) Enabled() bool {
+	return a != nil && (len(a.secret) > 0 || a.apiKey != "")
+}
+
+// ProtectRead returns true when read-only endpoints need authentication too.
+func (a *Authenticator) ProtectRead() bool {
+	return a.Enabled() && a.protectRead
+}
+
+// Check returns nil if the request is authenticated or authentication is disabled.
+func (a *Authenticator) Check(r *http.Request) error {
+	if !a.Enabled() {
+		return nil
+	}
+	if key := r.Header.Get(ApiKeyHeader); key != "" && a.apiKey != "" {
+		if subtle.ConstantTimeCompare([]byte(key), []byte(a.apiKey)) == 1 {
+			return nil
+		}
+		return ErrInvalidApiKey
+	}
+	if len(a.secret) == 0 {
+		return ErrMissingToken
+	}
+	var token string
+	if h := r.Header.Get("Authorization"); strings.HasPrefix(h, "Bearer ") {
+		token = strings.TrimPrefix(h, "Bearer ")
+	}
+	return VerifyToken(a.secret, token)
+}
@@ -0,0 +1,121 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// jwtExpiryTimeout is the allowed drift of the issued-at claim, it is the
+// same value used by the engine api authentication in geth and prysm.
+const jwtExpiryTimeout = 60 * time.Second
+
+var (
+	ErrMissingToken   = errors.New("missing token")
+	ErrInvalidToken   = errors.New("invalid token")
+	ErrInvalidSecret  = errors.New("invalid jwt secret")
+	ErrStaleToken     = errors.New("stale token")
+	ErrFutureToken    = errors.New("future token")
+	ErrMissingIssueAt = errors.New("missing issued-at")
+)
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Typ string `json:"typ,omitempty"`
+}
+
+type jwtClaims struct {
+	IssuedAt *int64 `json:"iat,omitempty"`
+	Expires  *int64 `json:"exp,omitempty"`
+}
+
+// LoadJwtSecret reads a hex encoded 32 bytes secret from file, it accepts the
+// same jwtsecret file that is shared with geth and prysm.
+func LoadJwtSecret(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(string(data)), "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSecret, err)
+	}
+	if len(secret) != 32 {
+		return nil, fmt.Errorf("%w: want 32 bytes, got %d", ErrInvalidSecret, len(secret))
+	}
+	return secret, nil
+}
+
+// NewToken creates a HS256 token with the issued-at claim set to now.
+func NewToken(secret []byte) (string, error) {
+	header, err := json.Marshal(jwtHeader{Alg: "HS256", Typ: "JWT"})
+	if err != nil {
+		return "", err
+	}
+	iat := time.Now().Unix()
+	claims, err := json.Marshal(jwtClaims{IssuedAt: &iat})
+	if err != nil {
+		return "", err
+	}
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(sign(secret, unsigned)), nil
+}
+
+// VerifyToken checks the signature and the issued-at claim of a HS256 token.
+func VerifyToken(secret []byte, token string) error {
+	if len(token) == 0 {
+		return ErrMissingToken
+	}
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return ErrInvalidToken
+	}
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil || header.Alg != "HS256" {
+		return ErrInvalidToken
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return ErrInvalidToken
+	}
+	if !hmac.Equal(sig, sign(secret, parts[0]+"."+parts[1])) {
+		return ErrInvalidToken
+	}
+	var claims jwtClaims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return ErrInvalidToken
+	}
+	now := time.Now()
+	switch {
+	case claims.Expires != nil && now.After(time.Unix(*claims.Expires, 0)):
+		return ErrStaleToken
+	case claims.IssuedAt == nil:
+		return ErrMissingIssueAt
+	case now.Sub(time.Unix(*claims.IssuedAt, 0)) > jwtExpiryTimeout:
+		return ErrStaleToken
+	case time.Unix(*claims.IssuedAt, 0).Sub(now) > jwtExpiryTimeout:
+		return ErrFutureToken
+	}
+	return nil
+}
+
+func sign(secret []byte, unsigned string) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(unsigned))
+	return mac.Sum(nil)
+}
+
+func decodeSegment(seg string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
@@ -0,0 +1,55 @@
+package auth
+
+import (
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/tsinghua-cel/attacker-service/config"
+)
+
+const testSecret = "0x9d7f3c2a0b1e4f5a6c7d8e9f00112233445566778899aabbccddeeff00112233"
+
+func TestAuthenticator(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jwtsecret")
+	if err := os.WriteFile(path, []byte(testSecret+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	a, err := NewAuthenticator(config.AuthConfig{JwtSecret: path, ApiKey: "key"})
+	if err != nil {
+		t.Fatalf("new authenticator failed err:%s", err)
+	}
+	token, err := NewToken(a.secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest("POST", "/", nil)
+	if err := a.Check(req); err != ErrMissingToken {
+		t.Errorf("no credential: got %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	if err := a.Check(req); err != nil {
+		t.Errorf("valid token: got %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token[:len(token)-2]+"AA")
+	if err := a.Check(req); err != ErrInvalidToken {
+		t.Errorf("bad signature: got %v", err)
+	}
+
+	req = httptest.NewRequest("POST", "/", nil)
+	req.Header.Set(ApiKeyHeader, "key")
+	if err := a.Check(req); err != nil {
+		t.Errorf("valid api key: got %v", err)
+	}
+	req.Header.Set(ApiKeyHeader, "wrong")
+	if err := a.Check(req); err != ErrInvalidApiKey {
+		t.Errorf("wrong api key: got %v", err)
+	}
+
+	var disabled *Authenticator
+	if err := disabled.Check(req); err != nil {
+		t.Errorf("disabled: got %v", err)
+	}
+}
@@ -1,18 +1,29 @@
 package beaconapi
 
 import (
+	"bufio"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/astaxie/beego/httplib"
 	eth2client "github.com/attestantio/go-eth2-client"
 	"github.com/attestantio/go-eth2-client/api"
 	apiv1 "github.com/attestantio/go-eth2-client/api/v1"
+	"github.com/attestantio/go-eth2-client/spec"
 	"github.com/attestantio/go-eth2-client/spec/capella"
 	"github.com/attestantio/go-eth2-client/spec/deneb"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/prysmaticlabs/prysm/v5/api/server/structs"
+	ethpb "github.com/prysmaticlabs/prysm/v5/proto/prysm/v1alpha1"
 	log "github.com/sirupsen/logrus"
+	"github.com/tsinghua-cel/attacker-service/common"
 	"github.com/tsinghua-cel/attacker-service/types"
+	"io"
+	"net/http"
 	"strconv"
+	"strings"
+	"time"
 )
 
 const (
@@ -20,65 +31,138 @@ const (
 	SECONDS_PER_SLOT = "SECONDS_PER_SLOT"
 )
 
+const (
+	connectTimeout   = 5 * time.Second
+	readWriteTimeout = 30 * time.Second
+)
+
+// BeaconGwClient talks to the beacon api of one or more beacon nodes, a request
+// fails over to the next endpoint when the current one is unreachable or unhealthy.
 type BeaconGwClient struct {
-	endpoint string
-	config   map[string]string
+	endpoints *common.Endpoints
+	config    map[string]string
 }
 
-func NewBeaconGwClient(endpoint string) *BeaconGwClient {
+func NewBeaconGwClient(endpoints ...string) *BeaconGwClient {
 
 	return &BeaconGwClient{
-		endpoint: endpoint,
-		config:   make(map[string]string),
+		endpoints: common.NewEndpoints(endpoints...),
+		config:    make(map[string]string),
 	}
 }
 
+// Endpoint returns the beacon endpoint currently in use.
+func (b *BeaconGwClient) Endpoint() string {
+	return b.endpoints.Active()
+}
+
 func (b *BeaconGwClient) GetIntConfig(key string) (int, error) {
 	config := b.GetBeaconConfig()
 	if v, exist := config[key]; !exist {
-		return 0, nil
+		return 0, fmt.Errorf("beacon config %s not found", key)
 	} else {
 		return strconv.Atoi(v)
 	}
 }
 
-func (b *BeaconGwClient) doGet(url string) (types.BeaconResponse, error) {
-	resp, err := httplib.Get(url).Response()
-	if err != nil {
-		return types.BeaconResponse{}, err
+func endpointUrl(endpoint string, path string) string {
+	if strings.HasPrefix(endpoint, "http://") || strings.HasPrefix(endpoint, "https://") {
+		return strings.TrimSuffix(endpoint, "/") + path
 	}
-	defer resp.Body.Close()
+	return fmt.Sprintf("http://%s%s", endpoint, path)
+}
 
+func (b *BeaconGwClient) doRequest(method string, path string, data []byte) (types.BeaconResponse, error) {
 	var response types.BeaconResponse
-	err = json.NewDecoder(resp.Body).Decode(&response)
-	if err != nil {
-		log.WithError(err).Error("Error decoding response")
-	}
-	return response, nil
+	err := b.endpoints.Try(func(endpoint string) error {
+		req := httplib.NewBeegoRequest(endpointUrl(endpoint, path), method).SetTimeout(connectTimeout, readWriteTimeout)
+		if data != nil {
+			req.Header("Content-Type", "application/json")
+			req.Body(data)
+		}
+		resp, err := req.Response()
+		if err != nil {
+			log.WithError(err).WithField("endpoint", endpoint).Warn("beacon request failed")
+			return err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode >= http.StatusInternalServerError {
+			log.WithField("endpoint", endpoint).WithField("status", resp.StatusCode).Warn("beacon request failed")
+			return fmt.Errorf("%s %s: status %d", method, path, resp.StatusCode)
+		}
+
+		response = types.BeaconResponse{}
+		err = json.NewDecoder(resp.Body).Decode(&response)
+		if err != nil && err != io.EOF {
+			log.WithError(err).Error("Error decoding response")
+		}
+		if resp.StatusCode == http.StatusNotFound {
+			return common.RequestError(fmt.Errorf("%s %s: %w", method, path, common.ErrNotFound))
+		}
+		if resp.StatusCode >= http.StatusBadRequest {
+			return common.RequestError(fmt.Errorf("%s %s: status %d", method, path, resp.StatusCode))
+		}
+		return nil
+	})
+	return response, err
 }
 
-func (b *BeaconGwClient) doPost(url string, data []byte) (types.BeaconResponse, error) {
-	resp, err := httplib.Post(url).Body(data).Response()
+func (b *BeaconGwClient) doGet(path string) (types.BeaconResponse, error) {
+	return b.doRequest(http.MethodGet, path, nil)
+}
+
+func (b *BeaconGwClient) doPost(path string, data []byte) (types.BeaconResponse, error) {
+	return b.doRequest(http.MethodPost, path, data)
+}
+
+// checkHealth queries /eth/v1/node/health, a syncing node is not healthy since
+// its duties and head are not reliable.
+func checkHealth(endpoint string) error {
+	resp, err := httplib.Get(endpointUrl(endpoint, "/eth/v1/node/health")).SetTimeout(connectTimeout, connectTimeout).Response()
 	if err != nil {
-		return types.BeaconResponse{}, err
+		return err
 	}
-	defer resp.Body.Close()
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("node health status %d", resp.StatusCode)
+	}
+	return nil
+}
 
-	var response types.BeaconResponse
-	err = json.NewDecoder(resp.Body).Decode(&response)
-	if err != nil {
-		log.WithError(err).Error("Error decoding response")
+// CheckHealth updates the health of all endpoints.
+func (b *BeaconGwClient) CheckHealth() {
+	for _, endpoint := range b.endpoints.All() {
+		err := checkHealth(endpoint)
+		if err != nil {
+			log.WithError(err).WithField("endpoint", endpoint).Warn("beacon endpoint unhealthy")
+		}
+		b.endpoints.SetHealth(endpoint, err == nil)
 	}
-	return response, nil
+}
+
+// StartHealthCheck checks the health of the endpoints every interval.
+func (b *BeaconGwClient) StartHealthCheck(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			b.CheckHealth()
+			<-ticker.C
+		}
+	}()
 }
 
 func (b *BeaconGwClient) getBeaconConfig() (map[string]interface{}, error) {
-	response, err := b.doGet(fmt.Sprintf("http://%s/eth/v1/config/spec", b.endpoint))
+	response, err := b.doGet("/eth/v1/config/spec")
+	if err != nil {
+		return nil, err
+	}
 
 	config := make(map[string]interface{})
 	err = json.Unmarshal(response.Data, &config)
 	if err != nil {
 		log.WithError(err).Error("unmarshal config data failed")
+		return nil, err
 	}
 	return config, nil
 }
@@ -87,19 +171,31 @@ func (b *BeaconGwClient) GetBeaconConfig() map[string]string {
 	if len(b.config) == 0 {
 		config, err := b.getBeaconConfig()
 		if err != nil {
-			// todo: add log
+			log.WithError(err).Error("get beacon config failed")
 			return nil
 		}
 		b.config = make(map[string]string)
 		for key, v := range config {
-			b.config[key] = v.(string)
+			if str, ok := v.(string); ok {
+				b.config[key] = str
+			}
 		}
 	}
 	return b.config
 }
 
+func (b *BeaconGwClient) GetGenesis() (types.GenesisInfo, error) {
+	response, err := b.doGet("/eth/v1/beacon/genesis")
+	if err != nil {
+		return types.GenesisInfo{}, err
+	}
+	var genesis types.GenesisInfo
+	err = json.Unmarshal(response.Data, &genesis)
+	return genesis, err
+}
+
 func (b *BeaconGwClient) GetLatestBeaconHeader() (types.BeaconHeaderInfo, error) {
-	response, err := b.doGet(fmt.Sprintf("http://%s/eth/v1/beacon/headers", b.endpoint))
+	response, err := b.doGet("/eth/v1/beacon/headers")
 	var headers = make([]types.BeaconHeaderInfo, 0)
 	err = json.Unmarshal(response.Data, &headers)
 	if err != nil {
@@ -110,9 +206,96 @@ func (b *BeaconGwClient) GetLatestBeaconHeader() (types.BeaconHeaderInfo, error)
 	return headers[0], nil
 }
 
+// GetBeaconHeaderById returns the header of a block, id is a slot, a block root or head.
+func (b *BeaconGwClient) GetBeaconHeaderById(id string) (types.BeaconHeaderInfo, error) {
+	response, err := b.doGet(fmt.Sprintf("/eth/v1/beacon/headers/%s", id))
+	if err != nil {
+		return types.BeaconHeaderInfo{}, err
+	}
+	var header types.BeaconHeaderInfo
+	err = json.Unmarshal(response.Data, &header)
+	return header, err
+}
+
+// GetFork returns the fork of the head state.
+func (b *BeaconGwClient) GetFork() (types.ForkInfo, error) {
+	response, err := b.doGet("/eth/v1/beacon/states/head/fork")
+	if err != nil {
+		return types.ForkInfo{}, err
+	}
+	var fork types.ForkInfo
+	err = json.Unmarshal(response.Data, &fork)
+	return fork, err
+}
+
+// GetFinalityCheckpoints returns the justified and finalized checkpoints of the head state.
+func (b *BeaconGwClient) GetFinalityCheckpoints() (types.FinalityCheckpoints, error) {
+	response, err := b.doGet("/eth/v1/beacon/states/head/finality_checkpoints")
+	if err != nil {
+		return types.FinalityCheckpoints{}, err
+	}
+	var checkpoints types.FinalityCheckpoints
+	err = json.Unmarshal(response.Data, &checkpoints)
+	return checkpoints, err
+}
+
+// GetValidators returns the validators of the state, id is a slot, a state root or head.
+func (b *BeaconGwClient) GetValidators(id string) ([]types.BeaconValidator, error) {
+	response, err := b.doGet(fmt.Sprintf("/eth/v1/beacon/states/%s/validators", id))
+	if err != nil {
+		return nil, err
+	}
+	var validators []types.BeaconValidator
+	err = json.Unmarshal(response.Data, &validators)
+	return validators, err
+}
+
+// ProduceBlock asks the beacon node for a full deneb block of the slot.
+func (b *BeaconGwClient) ProduceBlock(slot uint64, randaoReveal []byte, graffiti []byte) (*ethpb.BeaconBlockContentsDeneb, error) {
+	var g [32]byte
+	copy(g[:], graffiti)
+	path := fmt.Sprintf("/eth/v3/validator/blocks/%d?randao_reveal=%s&graffiti=%s", slot, hexutil.Encode(randaoReveal), hexutil.Encode(g[:]))
+	response, err := b.doGet(path)
+	if err != nil {
+		return nil, err
+	}
+	var contents structs.BeaconBlockContentsDeneb
+	if err := json.Unmarshal(response.Data, &contents); err != nil {
+		return nil, err
+	}
+	if contents.Block == nil {
+		return nil, common.ErrUnsupportedBeaconBlock
+	}
+	return contents.ToConsensus()
+}
+
+// PublishBlock publishes a signed deneb block.
+func (b *BeaconGwClient) PublishBlock(block *ethpb.SignedBeaconBlockContentsDeneb) error {
+	contents, err := structs.SignedBeaconBlockContentsDenebFromConsensus(block)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(contents)
+	if err != nil {
+		return err
+	}
+	_, err = b.doPost("/eth/v1/beacon/blocks", data)
+	return err
+}
+
+// SubmitAttestations submits signed attestations to the attestation pool.
+func (b *BeaconGwClient) SubmitAttestations(atts []*ethpb.Attestation) error {
+	data, err := json.Marshal(structs.AttsFromConsensus(atts))
+	if err != nil {
+		return err
+	}
+	_, err = b.doPost("/eth/v1/beacon/pool/attestations", data)
+	return err
+}
+
 // default grpc-gateway port is 3500
 func (b *BeaconGwClient) GetAllValReward(epoch int) ([]types.TotalReward, error) {
-	url := fmt.Sprintf("http://%s/eth/v1/beacon/rewards/attestations/%d", b.endpoint, epoch)
+	url := fmt.Sprintf("/eth/v1/beacon/rewards/attestations/%d", epoch)
 	response, err := b.doPost(url, []byte("[]"))
 	var rewardInfo types.RewardInfo
 	err = json.Unmarshal(response.Data, &rewardInfo)
@@ -124,7 +307,7 @@ func (b *BeaconGwClient) GetAllValReward(epoch int) ([]types.TotalReward, error)
 }
 
 func (b *BeaconGwClient) GetValReward(epoch int, valIdxs []int) (types.BeaconResponse, error) {
-	url := fmt.Sprintf("http://%s/eth/v1/beacon/rewards/attestations/%d", b.endpoint, epoch)
+	url := fmt.Sprintf("/eth/v1/beacon/rewards/attestations/%d", epoch)
 	vals := make([]string, len(valIdxs))
 	for i := 0; i < len(valIdxs); i++ {
 		vals[i] = strconv.FormatInt(int64(valIdxs[i]), 10)
@@ -140,7 +323,7 @@ func (b *BeaconGwClient) GetValReward(epoch int, valIdxs []int) (types.BeaconRes
 
 // /eth/v1/validator/duties/proposer/:epoch
 func (b *BeaconGwClient) GetProposerDuties(epoch int) ([]types.ProposerDuty, error) {
-	url := fmt.Sprintf("http://%s/eth/v1/validator/duties/proposer/%d", b.endpoint, epoch)
+	url := fmt.Sprintf("/eth/v1/validator/duties/proposer/%d", epoch)
 	var duties = make([]types.ProposerDuty, 0)
 
 	response, err := b.doGet(url)
@@ -154,7 +337,7 @@ func (b *BeaconGwClient) GetProposerDuties(epoch int) ([]types.ProposerDuty, err
 
 // POST /eth/v1/validator/duties/attester/:epoch
 func (b *BeaconGwClient) GetAttesterDuties(epoch int, vals []int) ([]types.AttestDuty, error) {
-	url := fmt.Sprintf("http://%s/eth/v1/validator/duties/attester/%d", b.endpoint, epoch)
+	url := fmt.Sprintf("/eth/v1/validator/duties/attester/%d", epoch)
 	param := make([]string, len(vals))
 	for i := 0; i < len(vals); i++ {
 		param[i] = strconv.FormatInt(int64(vals[i]), 10)
@@ -175,7 +358,10 @@ func (b *BeaconGwClient) GetNextEpochProposerDuties() ([]types.ProposerDuty, err
 	if err != nil {
 		return nil, err
 	}
-	slotPerEpoch, _ := b.GetIntConfig(SLOTS_PER_EPOCH)
+	slotPerEpoch, err := b.GetIntConfig(SLOTS_PER_EPOCH)
+	if err != nil {
+		return nil, err
+	}
 	curSlot, _ := strconv.Atoi(latestHeader.Header.Message.Slot)
 	epoch := curSlot / slotPerEpoch
 	return b.GetProposerDuties(epoch + 1)
@@ -186,7 +372,10 @@ func (b *BeaconGwClient) GetCurrentEpochProposerDuties() ([]types.ProposerDuty,
 	if err != nil {
 		return nil, err
 	}
-	slotPerEpoch, _ := b.GetIntConfig(SLOTS_PER_EPOCH)
+	slotPerEpoch, err := b.GetIntConfig(SLOTS_PER_EPOCH)
+	if err != nil {
+		return nil, err
+	}
 	curSlot, _ := strconv.Atoi(latestHeader.Header.Message.Slot)
 	epoch := curSlot / slotPerEpoch
 	return b.GetProposerDuties(epoch)
@@ -197,7 +386,10 @@ func (b *BeaconGwClient) GetCurrentEpochAttestDuties() ([]types.AttestDuty, erro
 	if err != nil {
 		return nil, err
 	}
-	slotPerEpoch, _ := b.GetIntConfig(SLOTS_PER_EPOCH)
+	slotPerEpoch, err := b.GetIntConfig(SLOTS_PER_EPOCH)
+	if err != nil {
+		return nil, err
+	}
 	curSlot, _ := strconv.Atoi(latestHeader.Header.Message.Slot)
 	epoch := curSlot / slotPerEpoch
 	vals := make([]int, 64)
@@ -212,7 +404,10 @@ func (b *BeaconGwClient) GetNextEpochAttestDuties() ([]types.AttestDuty, error)
 	if err != nil {
 		return nil, err
 	}
-	slotPerEpoch, _ := b.GetIntConfig(SLOTS_PER_EPOCH)
+	slotPerEpoch, err := b.GetIntConfig(SLOTS_PER_EPOCH)
+	if err != nil {
+		return nil, err
+	}
 	curSlot, _ := strconv.Atoi(latestHeader.Header.Message.Slot)
 	epoch := curSlot / slotPerEpoch
 	vals := make([]int, 64)
@@ -223,7 +418,7 @@ func (b *BeaconGwClient) GetNextEpochAttestDuties() ([]types.AttestDuty, error)
 }
 
 func (b *BeaconGwClient) GetSlotRoot(slot int64) (string, error) {
-	response, err := b.doGet(fmt.Sprintf("http://%s/eth/v1/beacon/states/%d/root", b.endpoint, slot))
+	response, err := b.doGet(fmt.Sprintf("/eth/v1/beacon/states/%d/root", slot))
 	var rootInfo = types.SlotStateRoot{}
 	err = json.Unmarshal(response.Data, &rootInfo)
 	if err != nil {
@@ -234,72 +429,124 @@ func (b *BeaconGwClient) GetSlotRoot(slot int64) (string, error) {
 	return rootInfo.Root, nil
 }
 
-func (b *BeaconGwClient) MonitorReorgEvent() chan *apiv1.ChainReorgEvent {
-	service, err := NewClient(context.Background(), b.endpoint)
+// ErrEventStreamClosed is returned when the beacon node closes the event stream.
+var ErrEventStreamClosed = errors.New("event stream closed")
+
+// StreamReorgEvents streams the chain_reorg events of the endpoint to ch until
+// the stream drops or ctx is done. The endpoint is marked unhealthy if the
+// stream can't be opened, so the next subscription fails over.
+func (b *BeaconGwClient) StreamReorgEvents(ctx context.Context, endpoint string, ch chan<- *apiv1.ChainReorgEvent) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpointUrl(endpoint, "/eth/v1/events?topics=chain_reorg"), nil)
 	if err != nil {
-		log.WithError(err).Error("create eth2client failed")
-		return nil
+		return err
 	}
-	ch := make(chan *apiv1.ChainReorgEvent, 100)
-	go func() {
-		service.(eth2client.EventsProvider).Events(context.Background(), []string{"chain_reorg"}, func(event *apiv1.Event) {
-			if ev, ok := event.Data.(*apiv1.ChainReorgEvent); !ok {
-				log.Error("Failed to unmarshal reorg event")
-				return
-			} else {
-				ch <- ev
-			}
-			return
-		})
-	}()
-	return ch
+	req.Header.Set("Accept", "text/event-stream")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		if ctx.Err() == nil {
+			b.endpoints.SetHealth(endpoint, false)
+		}
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		b.endpoints.SetHealth(endpoint, false)
+		return fmt.Errorf("subscribe events: status %d", resp.StatusCode)
+	}
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data:")
+		if !ok {
+			continue
+		}
+		ev := new(apiv1.ChainReorgEvent)
+		if err := json.Unmarshal([]byte(strings.TrimSpace(data)), ev); err != nil {
+			log.WithError(err).Error("Failed to unmarshal reorg event")
+			continue
+		}
+		select {
+		case ch <- ev:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return ErrEventStreamClosed
+}
+
+// withService calls fn with an eth2client of each endpoint until one succeeds.
+func (b *BeaconGwClient) withService(fn func(service eth2client.Service) error) error {
+	return b.endpoints.Try(func(endpoint string) error {
+		service, err := NewClient(context.Background(), endpoint)
+		if err != nil {
+			log.WithError(err).WithField("endpoint", endpoint).Error("create eth2client failed")
+			return err
+		}
+		err = fn(service)
+		var apiErr *api.Error
+		if errors.As(err, &apiErr) && apiErr.StatusCode < http.StatusInternalServerError {
+			return common.RequestError(err)
+		}
+		return err
+	})
 }
 
 func (b *BeaconGwClient) GetBlockHeaderById(id string) (*apiv1.BeaconBlockHeader, error) {
-	service, err := NewClient(context.Background(), b.endpoint)
-	if err != nil {
-		log.WithError(err).Error("create eth2client failed")
-		return nil, err
-	}
 	opts := &api.BeaconBlockHeaderOpts{
 		Block: id,
 	}
-	res, err := service.(eth2client.BeaconBlockHeadersProvider).BeaconBlockHeader(context.Background(), opts)
+	var header *apiv1.BeaconBlockHeader
+	err := b.withService(func(service eth2client.Service) error {
+		res, err := service.(eth2client.BeaconBlockHeadersProvider).BeaconBlockHeader(context.Background(), opts)
+		if err != nil {
+			return err
+		}
+		header = res.Data
+		return nil
+	})
 	if err != nil {
 		log.WithError(err).Error("get block header failed")
 		return &apiv1.BeaconBlockHeader{}, err
 	}
-	return res.Data, nil
+	return header, nil
 }
 
-func (b *BeaconGwClient) GetDenebBlockBySlot(slot uint64) (*deneb.SignedBeaconBlock, error) {
-	service, err := NewClient(context.Background(), b.endpoint)
-	if err != nil {
-		log.WithError(err).Error("create eth2client failed")
-		return nil, err
-	}
-	res, err := service.(eth2client.SignedBeaconBlockProvider).SignedBeaconBlock(context.Background(), &api.SignedBeaconBlockOpts{
-		Block: fmt.Sprintf("%d", slot),
+func (b *BeaconGwClient) getSignedBlockBySlot(slot uint64) (*spec.VersionedSignedBeaconBlock, error) {
+	var block *spec.VersionedSignedBeaconBlock
+	err := b.withService(func(service eth2client.Service) error {
+		res, err := service.(eth2client.SignedBeaconBlockProvider).SignedBeaconBlock(context.Background(), &api.SignedBeaconBlockOpts{
+			Block: fmt.Sprintf("%d", slot),
+		})
+		if err != nil {
+			return err
+		}
+		block = res.Data
+		return nil
 	})
 	if err != nil {
 		log.WithError(err).Error("get block failed")
 		return nil, err
 	}
-	return res.Data.Deneb, nil
+	return block, nil
 }
 
-func (b *BeaconGwClient) GetCapellaBlockBySlot(slot uint64) (*capella.SignedBeaconBlock, error) {
-	service, err := NewClient(context.Background(), b.endpoint)
+func (b *BeaconGwClient) GetDenebBlockBySlot(slot uint64) (*deneb.SignedBeaconBlock, error) {
+	block, err := b.getSignedBlockBySlot(slot)
 	if err != nil {
-		log.WithError(err).Error("create eth2client failed")
 		return nil, err
 	}
-	res, err := service.(eth2client.SignedBeaconBlockProvider).SignedBeaconBlock(context.Background(), &api.SignedBeaconBlockOpts{
-		Block: fmt.Sprintf("%d", slot),
-	})
+	return block.Deneb, nil
+}
+
+func (b *BeaconGwClient) GetCapellaBlockBySlot(slot uint64) (*capella.SignedBeaconBlock, error) {
+	block, err := b.getSignedBlockBySlot(slot)
 	if err != nil {
-		log.WithError(err).Error("get block failed")
 		return nil, err
 	}
-	return res.Data.Capella, nil
+	return block.Capella, nil
 }
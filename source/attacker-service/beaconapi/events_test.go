@@ -0,0 +1,53 @@
+package beaconapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	apiv1 "github.com/attestantio/go-eth2-client/api/v1"
+)
+
+const reorgEventData = `{"slot":"200","depth":"2","old_head_block":"0x0101010101010101010101010101010101010101010101010101010101010101","new_head_block":"0x0202020202020202020202020202020202020202020202020202020202020202","old_head_state":"0x0303030303030303030303030303030303030303030303030303030303030303","new_head_state":"0x0404040404040404040404040404040404040404040404040404040404040404","epoch":"6"}`
+
+func TestStreamReorgEvents(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("topics") != "chain_reorg" {
+			http.Error(w, "unknown topic", http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "event: chain_reorg\ndata: {invalid}\n\n")
+		fmt.Fprintf(w, "event: chain_reorg\ndata: %s\n\n", reorgEventData)
+	}))
+	defer srv.Close()
+
+	b := NewBeaconGwClient(srv.URL)
+	ch := make(chan *apiv1.ChainReorgEvent, 2)
+	if err := b.StreamReorgEvents(context.Background(), srv.URL, ch); err != ErrEventStreamClosed {
+		t.Fatalf("err %v, want %v", err, ErrEventStreamClosed)
+	}
+	if len(ch) != 1 {
+		t.Fatalf("received %d events, want 1", len(ch))
+	}
+	if ev := <-ch; ev.Slot != 200 || ev.Depth != 2 || ev.Epoch != 6 {
+		t.Fatalf("unexpected event %+v", ev)
+	}
+}
+
+func TestStreamReorgEventsFailOver(t *testing.T) {
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer down.Close()
+
+	b := NewBeaconGwClient(down.URL, "http://127.0.0.1:2")
+	if err := b.StreamReorgEvents(context.Background(), down.URL, make(chan *apiv1.ChainReorgEvent)); err == nil {
+		t.Fatal("stream of an unavailable node is opened")
+	}
+	if b.Endpoint() != "http://127.0.0.1:2" {
+		t.Fatalf("endpoint %s is still in use", b.Endpoint())
+	}
+}
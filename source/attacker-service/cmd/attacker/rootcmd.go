@@ -90,7 +90,7 @@ func runNode() {
 	rpcServer := server.NewServer(config.GetConfig(), testcases.NewCaseV1())
 	rpcServer.Start()
 
-	go getRewardBackgroud()
+	go getRewardBackgroud(rpcServer)
 
 	wg := sync.WaitGroup{}
 	wg.Add(1)
@@ -141,21 +141,21 @@ func localFilesystemLogger(logPath string) {
 	log.AddHook(lfHook)
 }
 
-func getRewardBackgroud() {
+func getRewardBackgroud(roles reward.RoleProvider) {
 	ticker := time.NewTicker(time.Minute)
 	defer ticker.Stop()
 	for {
 		select {
 		case <-ticker.C:
 			log.WithFields(log.Fields{
-				"beacon": config.GetConfig().BeaconRpc,
+				"beacon": config.GetConfig().BeaconEndpoints(),
 			}).Debug("goto get reward")
-			err := reward.GetRewardsToMysql(config.GetConfig().BeaconRpc)
+			err := reward.GetRewardsToMysql(config.GetConfig().BeaconEndpoints(), roles)
 			//err := reward.GetRewards(config.GetConfig().BeaconRpc, config.GetConfig().RewardFile)
 			if err != nil {
 				log.WithError(err).Error("collect reward failed")
 			}
-			reward.GetRewards(config.GetConfig().BeaconRpc, config.GetConfig().RewardFile)
+			reward.GetRewards(config.GetConfig().BeaconEndpoints(), config.GetConfig().RewardFile)
 		}
 	}
 }
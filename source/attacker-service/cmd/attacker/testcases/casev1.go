@@ -130,7 +130,11 @@ func (c *CaseV1) BlockDelayForReceiveBlock(ctx plugins.PluginContext, slot uint6
 	}
 	// 当前是最后一个出块的恶意节点，进行延时
 
-	epochSlots := backend.GetSlotsPerEpoch()
+	epochSlots, err := backend.GetSlotsPerEpoch()
+	if err != nil {
+		log.WithError(err).Error("get slots per epoch failed")
+		return ret
+	}
 	seconds := backend.GetIntervalPerSlot()
 	delay := (epochSlots - int(slot%uint64(epochSlots))) * seconds
 	time.Sleep(time.Second * time.Duration(delay))
@@ -207,7 +211,12 @@ func (c *CaseV1) BlockBeforeSign(ctx plugins.PluginContext, slot uint64, pubkey
 		Cmd:    types.CMD_NULL,
 		Result: block,
 	}
-	slotTool := common.SlotTool{backend.SlotsPerEpoch()}
+	slotsPerEpoch, err := backend.SlotsPerEpoch()
+	if err != nil {
+		log.WithError(err).Error("get slots per epoch failed")
+		return ret
+	}
+	slotTool := common.SlotTool{slotsPerEpoch}
 	// 1. 只有每个epoch最后一个出块的恶意节点出块，其他节点不出快
 	valIdx, err := backend.GetValidatorByProposeSlot(slot)
 	if err != nil {
@@ -338,8 +347,13 @@ func (c *CaseV1) BlockAfterSign(ctx plugins.PluginContext, slot uint64, pubkey s
 	if role != types.AttackerRole {
 		return ret
 	}
+	slotsPerEpoch, err := backend.GetSlotsPerEpoch()
+	if err != nil {
+		log.WithError(err).Error("get slots per epoch failed")
+		return ret
+	}
 	slotTool := common.SlotTool{
-		SlotsPerEpoch: backend.GetSlotsPerEpoch(),
+		SlotsPerEpoch: slotsPerEpoch,
 	}
 	epoch := slotTool.SlotToEpoch(int64(slot))
 
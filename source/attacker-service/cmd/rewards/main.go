@@ -4,10 +4,11 @@ import (
 	"flag"
 	log "github.com/sirupsen/logrus"
 	"github.com/tsinghua-cel/attacker-service/reward"
+	"strings"
 )
 
 var (
-	noderpc    = flag.String("node", "127.0.0.1:8545", "execute node rpc addr")
+	noderpc    = flag.String("node", "127.0.0.1:8545", "beacon node rpc addr, separate multiple addrs with comma")
 	rewardfile = flag.String("output", "reward.csv", "output file for reward.")
 )
 
@@ -19,7 +20,7 @@ func main() {
 		"output": *rewardfile,
 	}).Info("start get reward")
 
-	err := reward.GetRewards(*noderpc, *rewardfile)
+	err := reward.GetRewards(strings.Split(*noderpc, ","), *rewardfile)
 	if err != nil {
 		log.WithError(err).Error("get reward failed")
 	} else {
@@ -0,0 +1,216 @@
+package common
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/prysmaticlabs/prysm/v5/api/server/structs"
+	ethpb "github.com/prysmaticlabs/prysm/v5/proto/prysm/v1alpha1"
+)
+
+// Encoding is the payload encoding of the hooks.
+type Encoding string
+
+const (
+	// EncodingProto is base64 of the prysm protobuf, it is the default.
+	EncodingProto Encoding = "proto"
+	// EncodingSSZ is 0x prefixed hex of the spec ssz.
+	EncodingSSZ Encoding = "ssz"
+	// EncodingJSON is the beacon api json.
+	EncodingJSON Encoding = "json"
+)
+
+// Fork is the fork of a block payload, the same name as the
+// Eth-Consensus-Version header of the beacon api.
+type Fork string
+
+const ForkDeneb Fork = "deneb"
+
+var (
+	ErrUnknownEncoding = errors.New("unknown encoding")
+	ErrUnsupportedFork = errors.New("unsupported fork")
+)
+
+// ParseEncoding returns the encoding and the fork of the optional hook
+// parameter, e.g. "ssz:deneb". The fork is deneb if omitted, it is only
+// used by the block payloads.
+func ParseEncoding(encoding *string) (Encoding, Fork, error) {
+	if encoding == nil || *encoding == "" {
+		return EncodingProto, ForkDeneb, nil
+	}
+	name, fork, found := strings.Cut(*encoding, ":")
+	if !found {
+		fork = string(ForkDeneb)
+	}
+	switch e := Encoding(name); e {
+	case EncodingProto, EncodingSSZ, EncodingJSON:
+		return e, Fork(strings.ToLower(fork)), nil
+	default:
+		return "", "", fmt.Errorf("%w %s", ErrUnknownEncoding, *encoding)
+	}
+}
+
+type sszObject interface {
+	MarshalSSZ() ([]byte, error)
+	UnmarshalSSZ([]byte) error
+}
+
+func decodeSSZ(data string, obj sszObject) error {
+	b, err := hexutil.Decode(data)
+	if err != nil {
+		return err
+	}
+	return obj.UnmarshalSSZ(b)
+}
+
+func encodeSSZ(obj sszObject) (string, error) {
+	b, err := obj.MarshalSSZ()
+	if err != nil {
+		return "", err
+	}
+	return hexutil.Encode(b), nil
+}
+
+func encodeJSON(obj interface{}) (string, error) {
+	b, err := json.Marshal(obj)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+func DecodeAttestationData(encoding Encoding, data string) (*ethpb.AttestationData, error) {
+	switch encoding {
+	case EncodingSSZ:
+		attestation := new(ethpb.AttestationData)
+		return attestation, decodeSSZ(data, attestation)
+	case EncodingJSON:
+		var attestation structs.AttestationData
+		if err := json.Unmarshal([]byte(data), &attestation); err != nil {
+			return nil, err
+		}
+		return attestation.ToConsensus()
+	default:
+		return Base64ToAttestationData(data)
+	}
+}
+
+func EncodeAttestationData(encoding Encoding, attestation *ethpb.AttestationData) (string, error) {
+	switch encoding {
+	case EncodingSSZ:
+		return encodeSSZ(attestation)
+	case EncodingJSON:
+		return encodeJSON(structs.AttDataFromConsensus(attestation))
+	default:
+		return AttestationDataToBase64(attestation)
+	}
+}
+
+func DecodeSignedAttestation(encoding Encoding, data string) (*ethpb.Attestation, error) {
+	switch encoding {
+	case EncodingSSZ:
+		attestation := new(ethpb.Attestation)
+		return attestation, decodeSSZ(data, attestation)
+	case EncodingJSON:
+		var attestation structs.Attestation
+		if err := json.Unmarshal([]byte(data), &attestation); err != nil {
+			return nil, err
+		}
+		return attestation.ToConsensus()
+	default:
+		return Base64ToSignedAttestation(data)
+	}
+}
+
+func EncodeSignedAttestation(encoding Encoding, attestation *ethpb.Attestation) (string, error) {
+	switch encoding {
+	case EncodingSSZ:
+		return encodeSSZ(attestation)
+	case EncodingJSON:
+		return encodeJSON(structs.AttFromConsensus(attestation))
+	default:
+		return SignedAttestationToBase64(attestation)
+	}
+}
+
+// blockCodec decodes and encodes the signed blocks of one fork, the block
+// actions get the prysm block of the fork.
+type blockCodec struct {
+	decode func(Encoding, string) (interface{}, error)
+	encode func(Encoding, interface{}) (string, error)
+}
+
+var blockCodecs = map[Fork]blockCodec{
+	ForkDeneb: {
+		decode: func(encoding Encoding, data string) (interface{}, error) {
+			return DecodeSignedDenebBlock(encoding, data)
+		},
+		encode: func(encoding Encoding, block interface{}) (string, error) {
+			b, ok := block.(*ethpb.SignedBeaconBlockDeneb)
+			if !ok {
+				return "", fmt.Errorf("%T is not a deneb block", block)
+			}
+			return EncodeSignedDenebBlock(encoding, b)
+		},
+	},
+}
+
+func getBlockCodec(fork Fork) (blockCodec, error) {
+	codec, ok := blockCodecs[fork]
+	if !ok {
+		return blockCodec{}, fmt.Errorf("%w %s", ErrUnsupportedFork, fork)
+	}
+	return codec, nil
+}
+
+// DecodeSignedBlock decodes the signed block of the given fork.
+func DecodeSignedBlock(encoding Encoding, fork Fork, data string) (interface{}, error) {
+	codec, err := getBlockCodec(fork)
+	if err != nil {
+		return nil, err
+	}
+	return codec.decode(encoding, data)
+}
+
+// EncodeSignedBlock encodes the signed block of the given fork.
+func EncodeSignedBlock(encoding Encoding, fork Fork, block interface{}) (string, error) {
+	codec, err := getBlockCodec(fork)
+	if err != nil {
+		return "", err
+	}
+	return codec.encode(encoding, block)
+}
+
+func DecodeSignedDenebBlock(encoding Encoding, data string) (*ethpb.SignedBeaconBlockDeneb, error) {
+	switch encoding {
+	case EncodingSSZ:
+		block := new(ethpb.SignedBeaconBlockDeneb)
+		return block, decodeSSZ(data, block)
+	case EncodingJSON:
+		var block structs.SignedBeaconBlockDeneb
+		if err := json.Unmarshal([]byte(data), &block); err != nil {
+			return nil, err
+		}
+		return block.ToConsensus()
+	default:
+		return Base64ToSignedDenebBlock(data)
+	}
+}
+
+func EncodeSignedDenebBlock(encoding Encoding, block *ethpb.SignedBeaconBlockDeneb) (string, error) {
+	switch encoding {
+	case EncodingSSZ:
+		return encodeSSZ(block)
+	case EncodingJSON:
+		b, err := structs.SignedBeaconBlockDenebFromConsensus(block)
+		if err != nil {
+			return "", err
+		}
+		return encodeJSON(b)
+	default:
+		return SignedDenebBlockToBase64(block)
+	}
+}
@@ -0,0 +1,183 @@
+package common
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/prysmaticlabs/prysm/v5/consensus-types/primitives"
+	enginev1 "github.com/prysmaticlabs/prysm/v5/proto/engine/v1"
+	ethpb "github.com/prysmaticlabs/prysm/v5/proto/prysm/v1alpha1"
+	"google.golang.org/protobuf/proto"
+)
+
+var encodings = []Encoding{EncodingProto, EncodingSSZ, EncodingJSON}
+
+func testRoot(b byte) []byte {
+	return bytes.Repeat([]byte{b}, 32)
+}
+
+func testAttestationData() *ethpb.AttestationData {
+	return &ethpb.AttestationData{
+		Slot:            10,
+		CommitteeIndex:  1,
+		BeaconBlockRoot: testRoot(9),
+		Source:          &ethpb.Checkpoint{Epoch: 1, Root: testRoot(4)},
+		Target:          &ethpb.Checkpoint{Epoch: 2, Root: testRoot(8)},
+	}
+}
+
+// testDenebBlock returns a deneb block with the minimum marshalable fields.
+func testDenebBlock() *ethpb.SignedBeaconBlockDeneb {
+	return &ethpb.SignedBeaconBlockDeneb{
+		Block: &ethpb.BeaconBlockDeneb{
+			Slot:          primitives.Slot(10),
+			ProposerIndex: primitives.ValidatorIndex(3),
+			ParentRoot:    testRoot(9),
+			StateRoot:     testRoot(1),
+			Body: &ethpb.BeaconBlockBodyDeneb{
+				RandaoReveal: make([]byte, 96),
+				Eth1Data:     &ethpb.Eth1Data{DepositRoot: make([]byte, 32), BlockHash: make([]byte, 32)},
+				Graffiti:     testRoot(7),
+				SyncAggregate: &ethpb.SyncAggregate{
+					SyncCommitteeBits:      make([]byte, 64),
+					SyncCommitteeSignature: make([]byte, 96),
+				},
+				ExecutionPayload: &enginev1.ExecutionPayloadDeneb{
+					ParentHash:    make([]byte, 32),
+					FeeRecipient:  make([]byte, 20),
+					StateRoot:     make([]byte, 32),
+					ReceiptsRoot:  make([]byte, 32),
+					LogsBloom:     make([]byte, 256),
+					PrevRandao:    make([]byte, 32),
+					BaseFeePerGas: make([]byte, 32),
+					BlockHash:     make([]byte, 32),
+				},
+			},
+		},
+		Signature: bytes.Repeat([]byte{0xab}, 96),
+	}
+}
+
+func TestParseEncoding(t *testing.T) {
+	s := func(v string) *string { return &v }
+	tests := []struct {
+		encoding *string
+		want     Encoding
+		wantFork Fork
+		wantErr  error
+	}{
+		{encoding: nil, want: EncodingProto, wantFork: ForkDeneb},
+		{encoding: s(""), want: EncodingProto, wantFork: ForkDeneb},
+		{encoding: s("proto"), want: EncodingProto, wantFork: ForkDeneb},
+		{encoding: s("ssz"), want: EncodingSSZ, wantFork: ForkDeneb},
+		{encoding: s("json:Deneb"), want: EncodingJSON, wantFork: ForkDeneb},
+		{encoding: s("ssz:capella"), want: EncodingSSZ, wantFork: "capella"},
+		{encoding: s("rlp"), wantErr: ErrUnknownEncoding},
+		{encoding: s("SSZ:deneb"), wantErr: ErrUnknownEncoding},
+	}
+	for _, tt := range tests {
+		name := "nil"
+		if tt.encoding != nil {
+			name = *tt.encoding
+		}
+		t.Run(name, func(t *testing.T) {
+			encoding, fork, err := ParseEncoding(tt.encoding)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got err %v, want %v", err, tt.wantErr)
+			}
+			if encoding != tt.want || fork != tt.wantFork {
+				t.Errorf("got %s %s, want %s %s", encoding, fork, tt.want, tt.wantFork)
+			}
+		})
+	}
+}
+
+func TestAttestationCodec(t *testing.T) {
+	for _, encoding := range encodings {
+		t.Run(string(encoding), func(t *testing.T) {
+			data := testAttestationData()
+			encoded, err := EncodeAttestationData(encoding, data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			decoded, err := DecodeAttestationData(encoding, encoded)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !proto.Equal(decoded, data) {
+				t.Errorf("attestation data decoded to %v, want %v", decoded, data)
+			}
+
+			att := &ethpb.Attestation{AggregationBits: []byte{0x03}, Data: data, Signature: bytes.Repeat([]byte{0xab}, 96)}
+			encoded, err = EncodeSignedAttestation(encoding, att)
+			if err != nil {
+				t.Fatal(err)
+			}
+			decodedAtt, err := DecodeSignedAttestation(encoding, encoded)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !proto.Equal(decodedAtt, att) {
+				t.Errorf("attestation decoded to %v, want %v", decodedAtt, att)
+			}
+		})
+	}
+}
+
+func TestBlockCodec(t *testing.T) {
+	for _, encoding := range encodings {
+		t.Run(string(encoding), func(t *testing.T) {
+			block := testDenebBlock()
+			encoded, err := EncodeSignedBlock(encoding, ForkDeneb, block)
+			if err != nil {
+				t.Fatal(err)
+			}
+			decoded, err := DecodeSignedBlock(encoding, ForkDeneb, encoded)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, ok := decoded.(*ethpb.SignedBeaconBlockDeneb)
+			if !ok {
+				t.Fatalf("decoded a %T", decoded)
+			}
+			if !proto.Equal(got, block) {
+				t.Errorf("block decoded to %v, want %v", got, block)
+			}
+		})
+	}
+}
+
+func TestEncodingFormat(t *testing.T) {
+	block := testDenebBlock()
+	ssz, err := EncodeSignedBlock(EncodingSSZ, ForkDeneb, block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(ssz, "0x") {
+		t.Errorf("ssz is not 0x prefixed hex: %.10s", ssz)
+	}
+	json, err := EncodeSignedBlock(EncodingJSON, ForkDeneb, block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(json, `"slot":"10"`) {
+		t.Errorf("json is not the beacon api block: %.60s", json)
+	}
+	if _, err := DecodeSignedBlock(EncodingSSZ, ForkDeneb, "0x01"); err == nil {
+		t.Error("decoded a truncated ssz block")
+	}
+}
+
+func TestUnsupportedFork(t *testing.T) {
+	if _, err := DecodeSignedBlock(EncodingSSZ, "capella", "0x"); !errors.Is(err, ErrUnsupportedFork) {
+		t.Errorf("decode capella block, err %v", err)
+	}
+	if _, err := EncodeSignedBlock(EncodingSSZ, "capella", testDenebBlock()); !errors.Is(err, ErrUnsupportedFork) {
+		t.Errorf("encode capella block, err %v", err)
+	}
+	if _, err := EncodeSignedBlock(EncodingSSZ, ForkDeneb, testAttestationData()); err == nil || errors.Is(err, ErrUnsupportedFork) {
+		t.Errorf("encode an attestation as deneb block, err %v", err)
+	}
+}
@@ -0,0 +1,142 @@
+package common
+
+import (
+	"errors"
+	"strings"
+	"sync"
+)
+
+var (
+	ErrNoEndpoint = errors.New("no endpoint configured")
+	// ErrNotFound is wrapped by the request errors of a missing resource,
+	// e.g. the block of an empty slot.
+	ErrNotFound = errors.New("not found")
+)
+
+// Endpoints is an ordered list of redundant endpoints with their health state.
+// The first healthy endpoint is used for requests, the others are tried in
+// order when it fails.
+type Endpoints struct {
+	mu        sync.RWMutex
+	endpoints []string
+	healthy   []bool
+	active    int
+}
+
+// NewEndpoints creates an endpoint list, empty and duplicate entries are
+// dropped and all endpoints start healthy.
+func NewEndpoints(endpoints ...string) *Endpoints {
+	e := &Endpoints{}
+	seen := make(map[string]bool)
+	for _, ep := range endpoints {
+		ep = strings.TrimSpace(ep)
+		if ep == "" || seen[ep] {
+			continue
+		}
+		seen[ep] = true
+		e.endpoints = append(e.endpoints, ep)
+		e.healthy = append(e.healthy, true)
+	}
+	return e
+}
+
+// All returns all endpoints in configured order.
+func (e *Endpoints) All() []string {
+	return e.endpoints
+}
+
+// Active returns the endpoint that is currently preferred.
+func (e *Endpoints) Active() string {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if len(e.endpoints) == 0 {
+		return ""
+	}
+	return e.endpoints[e.active]
+}
+
+// Order returns the endpoints in the order they should be tried: the active
+// one first, then the other healthy ones, and the unhealthy ones last.
+func (e *Endpoints) Order() []string {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	n := len(e.endpoints)
+	order := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		idx := (e.active + i) % n
+		if e.healthy[idx] {
+			order = append(order, e.endpoints[idx])
+		}
+	}
+	for i := 0; i < n; i++ {
+		idx := (e.active + i) % n
+		if !e.healthy[idx] {
+			order = append(order, e.endpoints[idx])
+		}
+	}
+	return order
+}
+
+// SetHealth records the health of an endpoint. A healthy endpoint becomes the
+// active one if the active endpoint is unhealthy, so requests fail over.
+func (e *Endpoints) SetHealth(endpoint string, healthy bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if len(e.endpoints) == 0 {
+		return
+	}
+	for i, ep := range e.endpoints {
+		if ep != endpoint {
+			continue
+		}
+		e.healthy[i] = healthy
+		if healthy && !e.healthy[e.active] {
+			e.active = i
+		}
+	}
+	if !e.healthy[e.active] {
+		for i := range e.endpoints {
+			if e.healthy[i] {
+				e.active = i
+				break
+			}
+		}
+	}
+}
+
+// requestError is an error returned by a working endpoint, e.g. a bad request,
+// that would be the same on any other endpoint.
+type requestError struct {
+	err error
+}
+
+func (r requestError) Error() string { return r.err.Error() }
+
+func (r requestError) Unwrap() error { return r.err }
+
+// RequestError marks err as a failure of the request rather than of the
+// endpoint, Try returns it without failing over.
+func RequestError(err error) error {
+	return requestError{err: err}
+}
+
+// Try calls fn with the endpoints in Order until one succeeds, and updates
+// their health accordingly. The error of the last endpoint is returned if all
+// of them fail.
+func (e *Endpoints) Try(fn func(endpoint string) error) error {
+	err := ErrNoEndpoint
+	for _, ep := range e.Order() {
+		err = fn(ep)
+		var reqErr requestError
+		if errors.As(err, &reqErr) {
+			e.SetHealth(ep, true)
+			return reqErr.err
+		}
+		if err == nil {
+			e.SetHealth(ep, true)
+			return nil
+		}
+		e.SetHealth(ep, false)
+	}
+	return err
+}
@@ -0,0 +1,90 @@
+package common
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestNewEndpoints(t *testing.T) {
+	e := NewEndpoints(" a ", "", "b", "a", "c")
+	if want := []string{"a", "b", "c"}; !reflect.DeepEqual(e.All(), want) {
+		t.Fatalf("endpoints %v, want %v", e.All(), want)
+	}
+	if e.Active() != "a" {
+		t.Fatalf("active %s, want a", e.Active())
+	}
+	if NewEndpoints().Active() != "" {
+		t.Fatal("empty endpoints have an active one")
+	}
+}
+
+func TestEndpointsSetHealth(t *testing.T) {
+	tests := []struct {
+		name      string
+		updates   []string // endpoint prefixed by + for healthy and - for unhealthy
+		active    string
+		wantOrder []string
+	}{
+		{name: "all healthy", active: "a", wantOrder: []string{"a", "b", "c"}},
+		{name: "active fails over", updates: []string{"-a"}, active: "b", wantOrder: []string{"b", "c", "a"}},
+		{name: "other unhealthy", updates: []string{"-b"}, active: "a", wantOrder: []string{"a", "c", "b"}},
+		{name: "skip unhealthy", updates: []string{"-b", "-a"}, active: "c", wantOrder: []string{"c", "a", "b"}},
+		{name: "stay on recovered", updates: []string{"-a", "+a"}, active: "b", wantOrder: []string{"b", "c", "a"}},
+		{name: "recover from all unhealthy", updates: []string{"-a", "-b", "-c", "+b"}, active: "b", wantOrder: []string{"b", "c", "a"}},
+		{name: "unknown endpoint", updates: []string{"-d"}, active: "a", wantOrder: []string{"a", "b", "c"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := NewEndpoints("a", "b", "c")
+			for _, u := range tt.updates {
+				e.SetHealth(u[1:], u[0] == '+')
+			}
+			if e.Active() != tt.active {
+				t.Errorf("active %s, want %s", e.Active(), tt.active)
+			}
+			if order := e.Order(); !reflect.DeepEqual(order, tt.wantOrder) {
+				t.Errorf("order %v, want %v", order, tt.wantOrder)
+			}
+		})
+	}
+}
+
+func TestEndpointsTry(t *testing.T) {
+	errDown := errors.New("down")
+	errBad := errors.New("bad request")
+	tests := []struct {
+		name    string
+		results map[string]error
+		want    error
+		tried   []string
+		active  string
+	}{
+		{name: "first succeeds", tried: []string{"a"}, active: "a"},
+		{name: "fail over", results: map[string]error{"a": errDown}, tried: []string{"a", "b"}, active: "b"},
+		{name: "request error", results: map[string]error{"a": RequestError(errBad)}, want: errBad, tried: []string{"a"}, active: "a"},
+		{name: "all fail", results: map[string]error{"a": errDown, "b": errDown, "c": errDown}, want: errDown, tried: []string{"a", "b", "c"}, active: "c"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := NewEndpoints("a", "b", "c")
+			var tried []string
+			err := e.Try(func(endpoint string) error {
+				tried = append(tried, endpoint)
+				return tt.results[endpoint]
+			})
+			if err != tt.want {
+				t.Errorf("err %v, want %v", err, tt.want)
+			}
+			if !reflect.DeepEqual(tried, tt.tried) {
+				t.Errorf("tried %v, want %v", tried, tt.tried)
+			}
+			if e.Active() != tt.active {
+				t.Errorf("active %s, want %s", e.Active(), tt.active)
+			}
+		})
+	}
+	if err := NewEndpoints().Try(func(string) error { return nil }); err != ErrNoEndpoint {
+		t.Fatalf("err %v, want %v", err, ErrNoEndpoint)
+	}
+}
@@ -14,16 +14,60 @@ type MysqlConfig struct {
 	DbName string `json:"database" toml:"database"`
 }
 
+// AuthConfig enables authentication on the write endpoints of the openapi
+// server and on the admin rpc namespace.
+type AuthConfig struct {
+	JwtSecret   string `json:"jwt_secret" toml:"jwt_secret"`     // path to the hex encoded jwtsecret file
+	ApiKey      string `json:"api_key" toml:"api_key"`           // static key sent in the X-API-Key header
+	ProtectRead bool   `json:"protect_read" toml:"protect_read"` // also authenticate read-only endpoints
+}
+
+// ProxyConfig enables the beacon api proxy that maps the requests of an
+// unmodified validator client onto the action points.
+type ProxyConfig struct {
+	Port     int    `json:"port" toml:"port"`         // 0 disables the proxy
+	Upstream string `json:"upstream" toml:"upstream"` // beacon api of the upstream node, default is beacon_rpc
+}
+
+// SignerConfig holds the keys of the attacker validators, they are only used
+// by the slashable actions to sign the second block or vote.
+type SignerConfig struct {
+	KeysFile          string `json:"keys_file" toml:"keys_file"`                   // json list of hex encoded secret keys
+	InteropValidators int    `json:"interop_validators" toml:"interop_validators"` // load the first n interop keys
+}
+
+// TraceConfig records the hook invocations to a trace file, or replays the
+// decisions of a recorded trace file.
+type TraceConfig struct {
+	Record string `json:"record" toml:"record"` // trace file to record to
+	Replay string `json:"replay" toml:"replay"` // trace file to replay from
+	Seed   int64  `json:"seed" toml:"seed"`     // seed of the random actions, 0 is not seeded
+}
+
+// EvaluatorConfig labels the evaluation results, so the runs against
+// different clients can be compared in one table.
+type EvaluatorConfig struct {
+	Label string `json:"label" toml:"label"` // e.g. prysm or prysm-reorg-fix
+}
+
 type Config struct {
-	HttpPort    int         `json:"http_port" toml:"http_port"`
-	HttpHost    string      `json:"http_host" toml:"http_host"`
-	ExecuteRpc  string      `json:"execute_rpc" toml:"execute_rpc"`
-	BeaconRpc   string      `json:"beacon_rpc" toml:"beacon_rpc"`
-	MetricsPort int         `json:"metrics_port" toml:"metrics_port"`
-	Strategy    string      `json:"strategy" toml:"strategy"`
-	DbConfig    MysqlConfig `json:"mysql" toml:"mysql"`
-	SwagHost    string      `json:"swag_host" toml:"swag_host"`
-	RewardFile  string      `json:"reward_file" toml:"reward_file"`
+	HttpPort    int             `json:"http_port" toml:"http_port"`
+	HttpHost    string          `json:"http_host" toml:"http_host"`
+	ExecuteRpc  string          `json:"execute_rpc" toml:"execute_rpc"`
+	BeaconRpc   string          `json:"beacon_rpc" toml:"beacon_rpc"`
+	ExecuteRpcs []string        `json:"execute_rpcs" toml:"execute_rpcs"` // backup execute endpoints
+	BeaconRpcs  []string        `json:"beacon_rpcs" toml:"beacon_rpcs"`   // backup beacon endpoints
+	MetricsPort int             `json:"metrics_port" toml:"metrics_port"`
+	Strategy    string          `json:"strategy" toml:"strategy"`
+	DbConfig    MysqlConfig     `json:"mysql" toml:"mysql"`
+	SwagHost    string          `json:"swag_host" toml:"swag_host"`
+	RewardFile  string          `json:"reward_file" toml:"reward_file"`
+	CorsOrigins []string        `json:"cors_origins" toml:"cors_origins"`
+	Auth        AuthConfig      `json:"auth" toml:"auth"`
+	Proxy       ProxyConfig     `json:"proxy" toml:"proxy"`
+	Signer      SignerConfig    `json:"signer" toml:"signer"`
+	Trace       TraceConfig     `json:"trace" toml:"trace"`
+	Evaluator   EvaluatorConfig `json:"evaluator" toml:"evaluator"`
 }
 
 var _cfg *Config = nil
@@ -32,6 +76,25 @@ func (conf *Config) MetricPort() int {
 	return conf.MetricsPort
 }
 
+// BeaconEndpoints returns beacon_rpc followed by the beacon_rpcs list.
+func (conf *Config) BeaconEndpoints() []string {
+	return append([]string{conf.BeaconRpc}, conf.BeaconRpcs...)
+}
+
+// ExecuteEndpoints returns execute_rpc followed by the execute_rpcs list.
+func (conf *Config) ExecuteEndpoints() []string {
+	return append([]string{conf.ExecuteRpc}, conf.ExecuteRpcs...)
+}
+
+// GetCorsOrigins returns the allowed cors origins, or the default ones if
+// nothing is configured.
+func (conf *Config) GetCorsOrigins() []string {
+	if len(conf.CorsOrigins) > 0 {
+		return conf.CorsOrigins
+	}
+	return DefaultCors
+}
+
 func ParseConfig(path string) (*Config, error) {
 	data, err := ioutil.ReadFile(path)
 	if err != nil {
@@ -52,11 +115,12 @@ func GetConfig() *Config {
 }
 
 var (
-	DefaultCors    = []string{"localhost"} // Default cors domain for the apis
-	DefaultVhosts  = []string{"localhost"} // Default virtual hosts for the apis
-	DefaultOrigins = []string{"localhost"} // Default origins for the apis
-	DefaultPrefix  = ""                    // Default prefix for the apis
-	DefaultModules = []string{}            // enable all module.
+	DefaultCors      = []string{"localhost"} // Default cors domain for the apis
+	DefaultVhosts    = []string{"localhost"} // Default virtual hosts for the apis
+	DefaultOrigins   = []string{"localhost"} // Default origins for the apis
+	DefaultPrefix    = ""                    // Default prefix for the apis
+	DefaultModules   = []string{}            // enable all module.
+	ProtectedModules = []string{"admin"}     // modules that need authentication.
 	//DefaultModules = []string{"time", "block", "attest"}
 )
 
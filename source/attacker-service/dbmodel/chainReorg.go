@@ -10,6 +10,7 @@ type ChainReorg struct {
 	Epoch                 int64  `orm:"column(epoch)" db:"epoch" json:"epoch" form:"epoch"`                                                                             // epoch
 	Slot                  int64  `orm:"column(slot)" db:"slot" json:"slot" form:"slot"`                                                                                 // slot
 	Depth                 int    `orm:"column(depth)" db:"depth" json:"depth" form:"depth"`                                                                             // depth
+	OldHeadBlock          string `orm:"column(old_head_block)" db:"old_head_block" json:"old_head_block" form:"old_head_block"`                                         // old_head_block
 	OldBlockSlot          int64  `orm:"column(old_block_slot)" db:"old_block_slot" json:"old_block_slot" form:"old_block_slot"`                                         // old_block_slot
 	NewBlockSlot          int64  `orm:"column(new_block_slot)" db:"new_block_slot" json:"new_block_slot" form:"new_block_slot"`                                         // new_block_slot
 	OldBlockProposerIndex int64  `orm:"column(old_block_proposer_index)" db:"old_block_proposer_index" json:"old_block_proposer_index" form:"old_block_proposer_index"` // old_block_proposer_index
@@ -58,6 +59,7 @@ func InsertNewReorg(ev types.ReorgEvent) {
 		Epoch:                 int64(ev.Epoch),
 		Slot:                  int64(ev.Slot),
 		Depth:                 int(ev.Depth),
+		OldHeadBlock:          ev.OldHeadBlock,
 		OldBlockSlot:          ev.OldBlockSlot,
 		NewBlockSlot:          ev.NewBlockSlot,
 		OldBlockProposerIndex: ev.OldBlockProposerIndex,
@@ -70,3 +72,11 @@ func InsertNewReorg(ev types.ReorgEvent) {
 func GetAllReorgList() []*ChainReorg {
 	return NewChainReorgRepository(orm.NewOrm()).GetListByFilter()
 }
+
+// GetReorgListByOldBlockSlot returns the reorgs whose old head block is in
+// the slots from start to end.
+func GetReorgListByOldBlockSlot(start, end int64) []*ChainReorg {
+	filters := make([]interface{}, 0)
+	filters = append(filters, "old_block_slot__gte", start, "old_block_slot__lte", end)
+	return NewChainReorgRepository(orm.NewOrm()).GetListByFilter(filters...)
+}
@@ -18,5 +18,17 @@ func DbInit(dbconf config.MysqlConfig) {
 	}
 	orm.RegisterModel(new(BlockReward))
 	orm.RegisterModel(new(ChainReorg))
-	orm.RunSyncdb("default", true, true)
+	orm.RegisterModel(new(Evaluation))
+	orm.RegisterModel(new(StrategyRecord))
+	// the rewards and the reorgs are of the current chain and dropped on
+	// start, the evaluations and the strategies are kept to compare runs.
+	o := orm.NewOrm()
+	for _, table := range []string{BlockReward{}.TableName(), ChainReorg{}.TableName()} {
+		if _, err := o.Raw("DROP TABLE IF EXISTS " + table).Exec(); err != nil {
+			log.WithError(err).WithField("table", table).Fatal("failed to drop table")
+		}
+	}
+	if err := orm.RunSyncdb("default", false, true); err != nil {
+		log.WithError(err).Fatal("failed to sync database")
+	}
 }
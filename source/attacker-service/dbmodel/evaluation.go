@@ -0,0 +1,103 @@
+package dbmodel
+
+import (
+	"github.com/astaxie/beego/orm"
+	"time"
+)
+
+type Evaluation struct {
+	ID                  int64   `orm:"column(id)" db:"id" json:"id" form:"id"`
+	Label               string  `orm:"column(label)" db:"label" json:"label" form:"label"`                                                                 // 运行标签, 如 prysm
+	Epoch               int64   `orm:"column(epoch)" db:"epoch" json:"epoch" form:"epoch"`                                                                 // epoch
+	StrategyVersion     string  `orm:"column(strategy_version)" db:"strategy_version" json:"strategy_version" form:"strategy_version"`                     // 策略版本
+	HonestProposals     int     `orm:"column(honest_proposals)" db:"honest_proposals" json:"honest_proposals" form:"honest_proposals"`                     // 诚实节点出块 slot 数
+	HonestOrphaned      int     `orm:"column(honest_orphaned)" db:"honest_orphaned" json:"honest_orphaned" form:"honest_orphaned"`                         // 诚实节点未上链的块数
+	AttackerProposals   int     `orm:"column(attacker_proposals)" db:"attacker_proposals" json:"attacker_proposals" form:"attacker_proposals"`             // 恶意节点出块 slot 数
+	AttackerCanonical   int     `orm:"column(attacker_canonical)" db:"attacker_canonical" json:"attacker_canonical" form:"attacker_canonical"`             // 恶意节点上链的块数
+	AttackerRewardShare float64 `orm:"column(attacker_reward_share)" db:"attacker_reward_share" json:"attacker_reward_share" form:"attacker_reward_share"` // 恶意节点奖励占比
+	AttackerStakeShare  float64 `orm:"column(attacker_stake_share)" db:"attacker_stake_share" json:"attacker_stake_share" form:"attacker_stake_share"`     // 恶意节点质押占比
+	RelativeRewardShare float64 `orm:"column(relative_reward_share)" db:"relative_reward_share" json:"relative_reward_share" form:"relative_reward_share"` // 奖励占比 / 质押占比
+	FinalityDelay       int64   `orm:"column(finality_delay)" db:"finality_delay" json:"finality_delay" form:"finality_delay"`                             // epoch 开始时距最终确定的 epoch 数
+}
+
+func (Evaluation) TableName() string {
+	return "t_evaluation"
+}
+
+type StrategyRecord struct {
+	ID        int64  `orm:"column(id)" db:"id" json:"id" form:"id"`
+	Version   string `orm:"column(version);unique" db:"version" json:"version" form:"version"`      // 策略版本
+	Content   string `orm:"column(content);type(text)" db:"content" json:"content" form:"content"`  // 策略内容
+	CreatedAt int64  `orm:"column(created_at)" db:"created_at" json:"created_at" form:"created_at"` // 首次使用时间
+}
+
+func (StrategyRecord) TableName() string {
+	return "t_strategy"
+}
+
+type EvaluationRepository interface {
+	Create(evaluation *Evaluation) error
+	GetListByFilter(filters ...interface{}) []*Evaluation
+}
+
+type evaluationRepositoryImpl struct {
+	o orm.Ormer
+}
+
+func NewEvaluationRepository(o orm.Ormer) EvaluationRepository {
+	return &evaluationRepositoryImpl{o}
+}
+
+func (repo *evaluationRepositoryImpl) Create(evaluation *Evaluation) error {
+	_, err := repo.o.Insert(evaluation)
+	return err
+}
+
+func (repo *evaluationRepositoryImpl) GetListByFilter(filters ...interface{}) []*Evaluation {
+	list := make([]*Evaluation, 0)
+	query := repo.o.QueryTable(new(Evaluation).TableName())
+	if len(filters) > 0 {
+		l := len(filters)
+		for k := 0; k < l; k += 2 {
+			query = query.Filter(filters[k].(string), filters[k+1])
+		}
+	}
+	query.OrderBy("label", "epoch").All(&list)
+	return list
+}
+
+func InsertEvaluation(evaluation *Evaluation) error {
+	return NewEvaluationRepository(orm.NewOrm()).Create(evaluation)
+}
+
+// GetEvaluationList returns the evaluations filtered by the not empty label and
+// version, from and to are the epoch range, negative values are not limited.
+func GetEvaluationList(label string, version string, from int64, to int64) []*Evaluation {
+	filters := make([]interface{}, 0)
+	if label != "" {
+		filters = append(filters, "label", label)
+	}
+	if version != "" {
+		filters = append(filters, "strategy_version", version)
+	}
+	if from >= 0 {
+		filters = append(filters, "epoch__gte", from)
+	}
+	if to >= 0 {
+		filters = append(filters, "epoch__lte", to)
+	}
+	return NewEvaluationRepository(orm.NewOrm()).GetListByFilter(filters...)
+}
+
+// InsertStrategyRecord records the strategy content of a version the first time it is used.
+func InsertStrategyRecord(version string, content string) error {
+	o := orm.NewOrm()
+	record := &StrategyRecord{Version: version}
+	if err := o.Read(record, "version"); err == nil {
+		return nil
+	}
+	record.Content = content
+	record.CreatedAt = time.Now().Unix()
+	_, err := o.Insert(record)
+	return err
+}
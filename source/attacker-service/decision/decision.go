@@ -0,0 +1,175 @@
+package decision
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type Kind string
+
+const (
+	// KindDelay is sent when an action starts to delay a duty, Until is the
+	// time the duty is released.
+	KindDelay Kind = "delay"
+	// KindRelease is sent when the delay of a duty is finished.
+	KindRelease Kind = "release"
+	// KindWithhold is sent when an action point returns a command that
+	// stops the duty.
+	KindWithhold Kind = "withhold"
+)
+
+// subscriberBuffer is the number of decisions buffered per subscriber, new
+// decisions are dropped when a subscriber falls behind.
+const subscriberBuffer = 256
+
+// Decision is an attack decision pushed to the subscribed validator clients.
+type Decision struct {
+	// ID is set on the delay and release decisions of a held delay, it is
+	// the decision returned by the action point.
+	ID     string `json:"id,omitempty"`
+	Kind   Kind   `json:"kind"`
+	Slot   int64  `json:"slot"`
+	Pubkey string `json:"pubkey,omitempty"`
+	Point  string `json:"point,omitempty"`  // action point of a withhold decision
+	Action string `json:"action,omitempty"` // action of a delay or release decision
+	Cmd    string `json:"cmd,omitempty"`
+	Until  int64  `json:"until,omitempty"` // unix milliseconds the delay ends
+	Time   int64  `json:"time"`            // unix milliseconds the decision is made
+}
+
+// ErrHoldAllValidators is returned when a holding subscriber doesn't list
+// its validators.
+var ErrHoldAllValidators = errors.New("holding subscriber must list its validators")
+
+type subscriber struct {
+	pubkeys map[string]bool // empty means all validators
+	hold    bool            // the subscriber waits for the release of the delays
+	ch      chan Decision
+}
+
+func (s *subscriber) match(pubkey string) bool {
+	// decisions without pubkey are for every validator.
+	return len(s.pubkeys) == 0 || pubkey == "" || s.pubkeys[normalize(pubkey)]
+}
+
+var (
+	mu     sync.RWMutex
+	nextId int
+	subs   = make(map[int]*subscriber)
+
+	// decision ids are unique within a run of the service, the boot time
+	// keeps them apart from the ids of an earlier run.
+	bootId     = strconv.FormatInt(time.Now().UnixMilli(), 36)
+	decisionId atomic.Uint64
+)
+
+func normalize(pubkey string) string {
+	return strings.ToLower(strings.TrimPrefix(pubkey, "0x"))
+}
+
+// Subscribe returns a channel of the decisions of the given validators, all
+// decisions are received if pubkeys is empty. A holding subscriber takes
+// over the delays of its validators, the action points return at once and
+// the subscriber holds the duty until the release decision. The returned
+// function must be called to unsubscribe.
+func Subscribe(pubkeys []string, hold bool) (<-chan Decision, func(), error) {
+	if hold && len(pubkeys) == 0 {
+		return nil, nil, ErrHoldAllValidators
+	}
+	s := &subscriber{
+		pubkeys: make(map[string]bool),
+		hold:    hold,
+		ch:      make(chan Decision, subscriberBuffer),
+	}
+	for _, pubkey := range pubkeys {
+		s.pubkeys[normalize(pubkey)] = true
+	}
+	mu.Lock()
+	id := nextId
+	nextId++
+	subs[id] = s
+	mu.Unlock()
+	return s.ch, func() {
+		mu.Lock()
+		delete(subs, id)
+		mu.Unlock()
+	}, nil
+}
+
+// held tells if a subscriber holds the duties of the validator.
+func held(pubkey string) bool {
+	if pubkey == "" {
+		return false
+	}
+	mu.RLock()
+	defer mu.RUnlock()
+	for _, s := range subs {
+		if s.hold && s.pubkeys[normalize(pubkey)] {
+			return true
+		}
+	}
+	return false
+}
+
+// Publish sends the decision to the matched subscribers without blocking.
+func Publish(d Decision) {
+	if d.Time == 0 {
+		d.Time = time.Now().UnixMilli()
+	}
+	mu.RLock()
+	defer mu.RUnlock()
+	for _, s := range subs {
+		if !s.match(d.Pubkey) {
+			continue
+		}
+		select {
+		case s.ch <- d:
+		default:
+			log.WithField("slot", d.Slot).Warn("decision subscriber is full, drop decision")
+		}
+	}
+}
+
+// Delay delays the duty of a validator for d and calls done once it is
+// released. If a subscriber holds the duties of the validator, Delay returns
+// the id of the decision at once and the release decision with the same id
+// is published after d. Otherwise it blocks for d and returns "".
+func Delay(action string, slot int64, pubkey string, d time.Duration, done func()) string {
+	id := ""
+	if held(pubkey) {
+		id = bootId + "-" + strconv.FormatUint(decisionId.Add(1), 10)
+	}
+	now := time.Now()
+	Publish(Decision{
+		ID:     id,
+		Kind:   KindDelay,
+		Slot:   slot,
+		Pubkey: pubkey,
+		Action: action,
+		Until:  now.Add(d).UnixMilli(),
+		Time:   now.UnixMilli(),
+	})
+	release := func() {
+		done()
+		Publish(Decision{
+			ID:     id,
+			Kind:   KindRelease,
+			Slot:   slot,
+			Pubkey: pubkey,
+			Action: action,
+		})
+	}
+	if id == "" {
+		time.Sleep(d)
+		release()
+		return ""
+	}
+	time.AfterFunc(d, release)
+	return id
+}
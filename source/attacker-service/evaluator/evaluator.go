@@ -0,0 +1,203 @@
+package evaluator
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/tsinghua-cel/attacker-service/common"
+	"github.com/tsinghua-cel/attacker-service/dbmodel"
+	"github.com/tsinghua-cel/attacker-service/types"
+)
+
+// evaluateDelay is the number of epochs an epoch is evaluated after its start,
+// the rewards are collected two epochs later and need some time to be stored.
+const evaluateDelay = 3
+
+// Backend provides the chain data the evaluator needs.
+type Backend interface {
+	GetSlotsPerEpoch() (int, error)
+	GetProposeDuties(epoch int) ([]types.ProposerDuty, error)
+	GetValidatorRole(slot int, valIdx int) types.RoleType
+	GetBeaconHeader(id string) (types.BeaconHeaderInfo, error)
+	GetFinalizedEpoch() (int64, error)
+	GetStrategy() *types.Strategy
+}
+
+// sample is the state observed at an epoch start.
+type sample struct {
+	version       string
+	finalityDelay int64
+}
+
+// Evaluator measures the outcome of the attack for each epoch and the strategy
+// version active in it, the results are stored in t_evaluation.
+type Evaluator struct {
+	backend Backend
+	label   string
+
+	mu      sync.Mutex
+	samples map[int64]sample
+}
+
+func NewEvaluator(backend Backend, label string) *Evaluator {
+	return &Evaluator{
+		backend: backend,
+		label:   label,
+		samples: make(map[int64]sample),
+	}
+}
+
+// OnEpoch is called at every epoch start, it samples the strategy version and
+// the finality delay of the epoch and evaluates the epoch evaluateDelay before it.
+func (e *Evaluator) OnEpoch(epoch int64) {
+	s := sample{finalityDelay: -1}
+	if finalized, err := e.backend.GetFinalizedEpoch(); err == nil {
+		s.finalityDelay = epoch - finalized
+	}
+	if strategy := e.backend.GetStrategy(); strategy != nil {
+		if content, err := json.Marshal(strategy); err == nil {
+			s.version = strategy.Version()
+			if err := dbmodel.InsertStrategyRecord(s.version, string(content)); err != nil {
+				log.WithError(err).WithField("version", s.version).Warn("record strategy failed")
+			}
+		}
+	}
+
+	e.mu.Lock()
+	e.samples[epoch] = s
+	evaluated, exist := e.samples[epoch-evaluateDelay]
+	for k := range e.samples {
+		if k <= epoch-evaluateDelay {
+			delete(e.samples, k)
+		}
+	}
+	e.mu.Unlock()
+
+	if !exist {
+		return
+	}
+	evaluation, err := e.evaluate(epoch-evaluateDelay, evaluated)
+	if err != nil {
+		log.WithError(err).WithField("epoch", epoch-evaluateDelay).Warn("evaluate epoch failed")
+		return
+	}
+	if err := dbmodel.InsertEvaluation(evaluation); err != nil {
+		log.WithError(err).WithField("epoch", epoch-evaluateDelay).Error("insert evaluation failed")
+		return
+	}
+	log.WithFields(log.Fields{
+		"epoch":               evaluation.Epoch,
+		"version":             evaluation.StrategyVersion,
+		"honestOrphaned":      evaluation.HonestOrphaned,
+		"attackerCanonical":   evaluation.AttackerCanonical,
+		"relativeRewardShare": evaluation.RelativeRewardShare,
+		"finalityDelay":       evaluation.FinalityDelay,
+	}).Info("epoch evaluated")
+}
+
+func (e *Evaluator) evaluate(epoch int64, s sample) (*dbmodel.Evaluation, error) {
+	slotsPerEpoch, err := e.backend.GetSlotsPerEpoch()
+	if err != nil {
+		return nil, err
+	}
+	tool := common.SlotTool{SlotsPerEpoch: slotsPerEpoch}
+	// a reorg orphans blocks of the epoch if its old head is in the epoch or
+	// in an epoch after it, up to the current one.
+	reorgs := dbmodel.GetReorgListByOldBlockSlot(tool.EpochStart(epoch), tool.EpochStart(epoch+evaluateDelay)-1)
+	return e.evaluateEpoch(tool, epoch, s, reorgs, dbmodel.GetRewardListByEpoch(epoch))
+}
+
+func (e *Evaluator) evaluateEpoch(tool common.SlotTool, epoch int64, s sample, reorgs []*dbmodel.ChainReorg, rewards []*dbmodel.BlockReward) (*dbmodel.Evaluation, error) {
+	duties, err := e.backend.GetProposeDuties(int(epoch))
+	if err != nil {
+		return nil, err
+	}
+	evaluation := &dbmodel.Evaluation{
+		Label:           e.label,
+		Epoch:           epoch,
+		StrategyVersion: s.version,
+		FinalityDelay:   s.finalityDelay,
+	}
+	// a missed slot is not counted as orphaned.
+	orphaned := e.orphanedBlocks(reorgs, tool.EpochStart(epoch), tool.EpochEnd(epoch))
+	for _, duty := range duties {
+		slot, _ := strconv.Atoi(duty.Slot)
+		valIdx, _ := strconv.Atoi(duty.ValidatorIndex)
+		if e.backend.GetValidatorRole(slot, valIdx) != types.AttackerRole {
+			evaluation.HonestProposals++
+			if proposer, ok := orphaned[int64(slot)]; ok && proposer == int64(valIdx) {
+				evaluation.HonestOrphaned++
+			}
+			continue
+		}
+		// the slot has a canonical block of the duty proposer, an empty slot
+		// has none and the slot is skipped if the lookup fails.
+		header, err := e.backend.GetBeaconHeader(duty.Slot)
+		if err != nil && !errors.Is(err, common.ErrNotFound) {
+			log.WithError(err).WithField("slot", slot).Warn("skip slot of failed header lookup")
+			continue
+		}
+		evaluation.AttackerProposals++
+		if err == nil && header.Header.Message.ProposerIndex == duty.ValidatorIndex {
+			evaluation.AttackerCanonical++
+		}
+	}
+
+	// all validators have the same stake in the test networks, so the stake
+	// share is the share of attacker validators.
+	var attackerReward, totalReward int64
+	var attackerCount, totalCount int
+	for _, reward := range rewards {
+		amount := reward.HeadAmount + reward.TargetAmount
+		if e.backend.GetValidatorRole(int(tool.EpochStart(epoch)), reward.ValidatorIndex) == types.AttackerRole {
+			attackerReward += amount
+			attackerCount++
+		}
+		totalReward += amount
+		totalCount++
+	}
+	if totalReward > 0 {
+		evaluation.AttackerRewardShare = float64(attackerReward) / float64(totalReward)
+	}
+	if totalCount > 0 {
+		evaluation.AttackerStakeShare = float64(attackerCount) / float64(totalCount)
+	}
+	if evaluation.AttackerStakeShare > 0 {
+		evaluation.RelativeRewardShare = evaluation.AttackerRewardShare / evaluation.AttackerStakeShare
+	}
+	return evaluation, nil
+}
+
+// orphanedBlocks returns the proposers of the orphaned blocks by slot from start
+// to end. The old branch of each reorg is walked from its old head back to the
+// common ancestor, the first canonical block.
+func (e *Evaluator) orphanedBlocks(reorgs []*dbmodel.ChainReorg, start, end int64) map[int64]int64 {
+	orphaned := make(map[int64]int64)
+	walked := make(map[string]bool)
+	for _, reorg := range reorgs {
+		for root := reorg.OldHeadBlock; root != "" && !walked[root]; {
+			walked[root] = true
+			header, err := e.backend.GetBeaconHeader(root)
+			if err != nil {
+				log.WithError(err).WithField("root", root).Warn("walk orphaned branch failed")
+				break
+			}
+			if header.Canonical {
+				break
+			}
+			slot, _ := strconv.ParseInt(header.Header.Message.Slot, 10, 64)
+			if slot < start {
+				break
+			}
+			if slot <= end {
+				proposer, _ := strconv.ParseInt(header.Header.Message.ProposerIndex, 10, 64)
+				orphaned[slot] = proposer
+			}
+			root = header.Header.Message.ParentRoot
+		}
+	}
+	return orphaned
+}
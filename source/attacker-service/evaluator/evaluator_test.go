@@ -0,0 +1,150 @@
+package evaluator
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/tsinghua-cel/attacker-service/common"
+	"github.com/tsinghua-cel/attacker-service/dbmodel"
+	"github.com/tsinghua-cel/attacker-service/types"
+)
+
+// testBackend is a chain of 4 slots per epoch where validator i is the
+// proposer of slot i.
+//
+//	canonical: 7 <- 9 (attacker)
+//	orphaned:  7 <- 8 <- 10 <- 12
+type testBackend struct {
+	headers   map[string]types.BeaconHeaderInfo // by root and the canonical ones by slot
+	attackers map[int]bool
+}
+
+func newTestBackend() *testBackend {
+	b := &testBackend{
+		headers:   make(map[string]types.BeaconHeaderInfo),
+		attackers: map[int]bool{9: true, 11: true},
+	}
+	b.add("7", 7, "6", true)
+	b.add("9", 9, "7", true)
+	b.add("8", 8, "7", false)
+	b.add("10", 10, "8", false)
+	b.add("12", 12, "10", false)
+	return b
+}
+
+func (b *testBackend) add(root string, slot int64, parent string, canonical bool) {
+	var header types.BeaconHeaderInfo
+	header.Root = root
+	header.Canonical = canonical
+	header.Header.Message.Slot = strconv.FormatInt(slot, 10)
+	header.Header.Message.ProposerIndex = strconv.FormatInt(slot, 10)
+	header.Header.Message.ParentRoot = parent
+	b.headers[root] = header
+	if canonical {
+		b.headers[header.Header.Message.Slot] = header
+	}
+}
+
+func (b *testBackend) GetSlotsPerEpoch() (int, error) {
+	return 4, nil
+}
+
+func (b *testBackend) GetProposeDuties(epoch int) ([]types.ProposerDuty, error) {
+	duties := make([]types.ProposerDuty, 0, 4)
+	for slot := epoch * 4; slot < epoch*4+4; slot++ {
+		duties = append(duties, types.ProposerDuty{Slot: strconv.Itoa(slot), ValidatorIndex: strconv.Itoa(slot)})
+	}
+	return duties, nil
+}
+
+func (b *testBackend) GetValidatorRole(slot int, valIdx int) types.RoleType {
+	if b.attackers[valIdx] {
+		return types.AttackerRole
+	}
+	return types.NormalRole
+}
+
+func (b *testBackend) GetBeaconHeader(id string) (types.BeaconHeaderInfo, error) {
+	if header, ok := b.headers[id]; ok {
+		return header, nil
+	}
+	return types.BeaconHeaderInfo{}, fmt.Errorf("header %s %w", id, common.ErrNotFound)
+}
+
+func (b *testBackend) GetFinalizedEpoch() (int64, error) {
+	return 0, nil
+}
+
+func (b *testBackend) GetStrategy() *types.Strategy {
+	return nil
+}
+
+func TestOrphanedBlocks(t *testing.T) {
+	tests := []struct {
+		name   string
+		heads  []string // the old heads of the reorgs
+		start  int64
+		end    int64
+		reback bool // block 10 is reorged back to canonical
+		want   map[int64]int64
+	}{
+		{name: "whole branch", heads: []string{"10"}, start: 8, end: 11, want: map[int64]int64{8: 8, 10: 10}},
+		{name: "old head after the slots", heads: []string{"12"}, start: 8, end: 11, want: map[int64]int64{8: 8, 10: 10}},
+		{name: "branch before the slots", heads: []string{"12"}, start: 12, end: 15, want: map[int64]int64{12: 12}},
+		{name: "branches share blocks", heads: []string{"12", "10", "8"}, start: 0, end: 15, want: map[int64]int64{8: 8, 10: 10, 12: 12}},
+		{name: "canonical old head", heads: []string{"9"}, start: 8, end: 11, want: map[int64]int64{}},
+		{name: "reorged back", heads: []string{"12"}, start: 8, end: 15, reback: true, want: map[int64]int64{12: 12}},
+		{name: "unknown old head", heads: []string{"13", ""}, start: 8, end: 15, want: map[int64]int64{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend := newTestBackend()
+			if tt.reback {
+				backend.add("10", 10, "8", true)
+			}
+			reorgs := make([]*dbmodel.ChainReorg, 0, len(tt.heads))
+			for _, head := range tt.heads {
+				reorgs = append(reorgs, &dbmodel.ChainReorg{OldHeadBlock: head})
+			}
+			got := NewEvaluator(backend, "test").orphanedBlocks(reorgs, tt.start, tt.end)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("orphaned %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvaluateEpoch(t *testing.T) {
+	e := NewEvaluator(newTestBackend(), "test")
+	reorgs := []*dbmodel.ChainReorg{{OldHeadBlock: "12", OldBlockSlot: 12, Depth: 3}}
+	rewards := []*dbmodel.BlockReward{
+		{ValidatorIndex: 8, HeadAmount: 100, TargetAmount: 100},
+		{ValidatorIndex: 9, HeadAmount: 150, TargetAmount: 150},
+	}
+	evaluation, err := e.evaluateEpoch(common.SlotTool{SlotsPerEpoch: 4}, 2, sample{version: "v1", finalityDelay: 2}, reorgs, rewards)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := dbmodel.Evaluation{
+		Label:               "test",
+		Epoch:               2,
+		StrategyVersion:     "v1",
+		HonestProposals:     2,
+		HonestOrphaned:      2,
+		AttackerProposals:   2,
+		AttackerCanonical:   1,
+		AttackerRewardShare: 0.6,
+		AttackerStakeShare:  0.5,
+		RelativeRewardShare: 1.2,
+		FinalityDelay:       2,
+	}
+	if math.Abs(evaluation.RelativeRewardShare-want.RelativeRewardShare) < 1e-9 {
+		evaluation.RelativeRewardShare = want.RelativeRewardShare
+	}
+	if *evaluation != want {
+		t.Errorf("evaluation %+v, want %+v", *evaluation, want)
+	}
+}
@@ -0,0 +1,157 @@
+package metrics
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/tsinghua-cel/attacker-service/types"
+)
+
+const namespace = "attacker"
+
+var (
+	actionPointCalls = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "action_point_calls_total",
+		Help:      "Number of hook invocations per action point.",
+	}, []string{"point"})
+
+	actionPointLatency = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "action_point_duration_seconds",
+		Help:      "Time spent inside a hook per action point, including delays.",
+		Buckets:   []float64{0.001, 0.01, 0.1, 0.5, 1, 2, 4, 8, 12, 24, 48, 96, 192, 384},
+	}, []string{"point"})
+
+	actionPointCommands = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "action_point_commands_total",
+		Help:      "Attacker commands returned per action point.",
+	}, []string{"point", "cmd"})
+
+	activeDelays = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "active_delays",
+		Help:      "Number of delay actions currently sleeping.",
+	}, []string{"action"})
+
+	reorgs = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "reorgs_total",
+		Help:      "Chain reorgs observed from the beacon node, by depth.",
+	}, []string{"depth"})
+
+	epochReward = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "epoch_reward_gwei",
+		Help:      "Sum of head, target and source rewards of the last collected epoch, by role.",
+	}, []string{"role"})
+
+	epochRewardDelta = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "epoch_reward_delta_gwei",
+		Help:      "Change of epoch_reward_gwei from the previous epoch, by role.",
+	}, []string{"role"})
+
+	epochRewardValidators = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "epoch_reward_validators",
+		Help:      "Number of validators counted in epoch_reward_gwei, by role.",
+	}, []string{"role"})
+
+	rewardEpoch = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "reward_epoch",
+		Help:      "The last epoch whose rewards were collected.",
+	})
+)
+
+// ObserveActionPoint records one hook invocation that started at start and
+// returned cmd.
+func ObserveActionPoint(point string, start time.Time, cmd types.AttackerCommand) {
+	actionPointCalls.WithLabelValues(point).Inc()
+	actionPointLatency.WithLabelValues(point).Observe(time.Since(start).Seconds())
+	actionPointCommands.WithLabelValues(point, cmd.String()).Inc()
+}
+
+// DelayStart marks a delay action as sleeping, the returned func must be
+// called once the delay is over.
+func DelayStart(action string) func() {
+	g := activeDelays.WithLabelValues(action)
+	g.Inc()
+	return g.Dec
+}
+
+// ReorgObserved counts a reorg event with the given depth.
+func ReorgObserved(depth int64) {
+	reorgs.WithLabelValues(strconv.FormatInt(depth, 10)).Inc()
+}
+
+// lastReward is the reward of the last collected epoch, the delta is only
+// published for consecutive epochs.
+var lastReward struct {
+	sync.Mutex
+	epoch            int64
+	attacker, honest int64
+	valid            bool
+}
+
+// EpochReward publishes the reward totals of one epoch and their change from
+// the previous epoch.
+func EpochReward(epoch int64, attacker, honest int64, attackerCount, honestCount int) {
+	lastReward.Lock()
+	if lastReward.valid && lastReward.epoch == epoch-1 {
+		epochRewardDelta.WithLabelValues("attacker").Set(float64(attacker - lastReward.attacker))
+		epochRewardDelta.WithLabelValues("honest").Set(float64(honest - lastReward.honest))
+	}
+	lastReward.epoch, lastReward.attacker, lastReward.honest, lastReward.valid = epoch, attacker, honest, true
+	lastReward.Unlock()
+
+	rewardEpoch.Set(float64(epoch))
+	epochReward.WithLabelValues("attacker").Set(float64(attacker))
+	epochReward.WithLabelValues("honest").Set(float64(honest))
+	epochRewardValidators.WithLabelValues("attacker").Set(float64(attackerCount))
+	epochRewardValidators.WithLabelValues("honest").Set(float64(honestCount))
+}
+
+// cacheSizes reports the size of the registered caches on every scrape, a
+// cache registered again replaces the previous one instead of panicking on a
+// duplicate registration.
+type cacheSizes struct {
+	desc *prometheus.Desc
+	mu   sync.Mutex
+	fns  map[string]func() int
+}
+
+var caches = &cacheSizes{
+	desc: prometheus.NewDesc(prometheus.BuildFQName(namespace, "", "cache_size"),
+		"Number of entries held by an internal cache.", []string{"cache"}, nil),
+	fns: make(map[string]func() int),
+}
+
+func init() {
+	prometheus.MustRegister(caches)
+}
+
+func (c *cacheSizes) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c *cacheSizes) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for name, fn := range c.fns {
+		ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, float64(fn()), name)
+	}
+}
+
+// RegisterCacheSize exposes the size of a named cache, fn is evaluated on
+// every scrape.
+func RegisterCacheSize(name string, fn func() int) {
+	caches.mu.Lock()
+	defer caches.mu.Unlock()
+	caches.fns[name] = fn
+}
@@ -0,0 +1,35 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestRegisterCacheSizeTwice(t *testing.T) {
+	RegisterCacheSize("test", func() int { return 1 })
+	// a second server in the same process registers the same cache again.
+	RegisterCacheSize("test", func() int { return 2 })
+	if n := testutil.CollectAndCount(caches); n != 1 {
+		t.Fatalf("got %d cache sizes, want 1", n)
+	}
+	if v := testutil.ToFloat64(caches); v != 2 {
+		t.Fatalf("got cache size %v, want 2", v)
+	}
+}
+
+func TestEpochRewardDelta(t *testing.T) {
+	EpochReward(10, 100, 300, 1, 3)
+	EpochReward(11, 80, 330, 1, 3)
+	if v := testutil.ToFloat64(epochRewardDelta.WithLabelValues("attacker")); v != -20 {
+		t.Fatalf("got attacker delta %v, want -20", v)
+	}
+	if v := testutil.ToFloat64(epochRewardDelta.WithLabelValues("honest")); v != 30 {
+		t.Fatalf("got honest delta %v, want 30", v)
+	}
+	// a skipped epoch keeps the last delta.
+	EpochReward(13, 0, 0, 1, 3)
+	if v := testutil.ToFloat64(epochRewardDelta.WithLabelValues("attacker")); v != -20 {
+		t.Fatalf("got attacker delta %v after a gap, want -20", v)
+	}
+}
@@ -0,0 +1,26 @@
+package metrics
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	log "github.com/sirupsen/logrus"
+)
+
+// StartServer serves the prometheus metrics on /metrics at the given port.
+// A zero port disables the metrics server.
+func StartServer(port int) {
+	if port == 0 {
+		return
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	addr := fmt.Sprintf(":%d", port)
+	log.WithField("addr", addr).Info("start metrics server")
+	go func() {
+		if err := http.ListenAndServe(addr, mux); err != nil {
+			log.WithError(err).Error("metrics server stopped")
+		}
+	}()
+}
@@ -1,9 +1,11 @@
 package openapi
 
 import (
+	"encoding/csv"
 	"github.com/gin-gonic/gin"
 	log "github.com/sirupsen/logrus"
 	"github.com/tsinghua-cel/attacker-service/dbmodel"
+	"github.com/tsinghua-cel/attacker-service/strategy/slotstrategy"
 	"github.com/tsinghua-cel/attacker-service/types"
 	"net/http"
 	"strconv"
@@ -44,6 +46,49 @@ func (api apiHandler) GetStrategy(c *gin.Context) {
 	c.JSON(200, strategy)
 }
 
+// @Summary Get chain spec
+// @Description get the slots per epoch, seconds per slot and genesis time of the chain
+// @ID get-chain-spec
+// @Accept  json
+// @Produce  json
+// @Success 200 {object} types.ChainSpec
+// @Router /chainspec [get]
+func (api apiHandler) GetChainSpec(c *gin.Context) {
+	spec, err := api.backend.GetChainSpec()
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(200, spec)
+}
+
+// @Summary Get validators
+// @Description get the validators of the head state with their pubkey and effective balance
+// @ID get-validators
+// @Accept  json
+// @Produce  json
+// @Success 200 {array} types.Validator
+// @Router /validators [get]
+func (api apiHandler) GetValidators(c *gin.Context) {
+	validators, err := api.backend.GetValidators()
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(200, validators)
+}
+
+// @Summary Get catalogue
+// @Description get the action points, actions and function slots supported by the service
+// @ID get-catalogue
+// @Accept  json
+// @Produce  json
+// @Success 200 {object} types.Catalogue
+// @Router /catalogue [get]
+func (api apiHandler) GetCatalogue(c *gin.Context) {
+	c.JSON(200, slotstrategy.GetCatalogue())
+}
+
 // @Summary Get reward by epoch
 // @Description get reward by epoch
 // @ID get-reward-by-epoch
@@ -73,10 +118,12 @@ func (api apiHandler) UpdateStrategy(c *gin.Context) {
 	if err != nil {
 		log.WithError(err).Println("UpdateStrategy ctx.ShouldBindJSON error")
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
 	err = api.backend.UpdateStrategy(&req)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
 	}
 	c.JSON(http.StatusOK, "ok")
 }
@@ -141,9 +188,15 @@ func (api apiHandler) GetEpoch(c *gin.Context) {
 	header, err := api.backend.GetLatestBeaconHeader()
 	if err != nil {
 		c.JSON(500, err)
+		return
+	}
+	slotsPerEpoch, err := api.backend.GetSlotsPerEpoch()
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
 	}
 	slot, _ := strconv.Atoi(header.Header.Message.Slot)
-	epoch := slot / api.backend.GetSlotsPerEpoch()
+	epoch := slot / slotsPerEpoch
 	c.JSON(200, epoch)
 }
 
@@ -162,3 +215,49 @@ func (api apiHandler) GetSlot(c *gin.Context) {
 	slot, _ := strconv.Atoi(header.Header.Message.Slot)
 	c.JSON(200, slot)
 }
+
+// @Summary Get evaluation
+// @Description get the attack outcome evaluated per epoch and strategy version
+// @ID get-evaluation
+// @Accept  json
+// @Produce  json
+// @Param label query string false "Label of the run"
+// @Param version query string false "Strategy version"
+// @Param from query int false "First epoch"
+// @Param to query int false "Last epoch"
+// @Param format query string false "json (default) or csv"
+// @Success 200 {array} dbmodel.Evaluation
+// @Router /evaluation [get]
+func (api apiHandler) GetEvaluation(c *gin.Context) {
+	from, err := strconv.ParseInt(c.DefaultQuery("from", "-1"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	to, err := strconv.ParseInt(c.DefaultQuery("to", "-1"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	list := dbmodel.GetEvaluationList(c.Query("label"), c.Query("version"), from, to)
+	if c.Query("format") != "csv" {
+		c.JSON(200, list)
+		return
+	}
+	c.Header("Content-Type", "text/csv")
+	c.Header("Content-Disposition", "attachment; filename=evaluation.csv")
+	writer := csv.NewWriter(c.Writer)
+	writer.Write([]string{"Label", "Epoch", "Strategy Version", "Honest Proposals", "Honest Orphaned",
+		"Attacker Proposals", "Attacker Canonical", "Attacker Reward Share", "Attacker Stake Share",
+		"Relative Reward Share", "Finality Delay"})
+	for _, e := range list {
+		writer.Write([]string{e.Label, strconv.FormatInt(e.Epoch, 10), e.StrategyVersion,
+			strconv.Itoa(e.HonestProposals), strconv.Itoa(e.HonestOrphaned),
+			strconv.Itoa(e.AttackerProposals), strconv.Itoa(e.AttackerCanonical),
+			strconv.FormatFloat(e.AttackerRewardShare, 'f', 4, 64),
+			strconv.FormatFloat(e.AttackerStakeShare, 'f', 4, 64),
+			strconv.FormatFloat(e.RelativeRewardShare, 'f', 4, 64),
+			strconv.FormatInt(e.FinalityDelay, 10)})
+	}
+	writer.Flush()
+}
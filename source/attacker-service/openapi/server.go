@@ -6,15 +6,18 @@ import (
 	log "github.com/sirupsen/logrus"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
+	"github.com/tsinghua-cel/attacker-service/auth"
 	"github.com/tsinghua-cel/attacker-service/config"
 	"github.com/tsinghua-cel/attacker-service/docs"
 	_ "github.com/tsinghua-cel/attacker-service/docs"
 	"github.com/tsinghua-cel/attacker-service/types"
+	"net/http"
 )
 
 type OpenAPI struct {
 	backend types.ServiceBackend
 	conf    *config.Config
+	auth    *auth.Authenticator
 }
 
 // generate swagger docs for the api in one step
@@ -25,8 +28,8 @@ type OpenAPI struct {
 // @BasePath /v1
 // @accept json
 
-func NewOpenAPI(backend types.ServiceBackend, conf *config.Config) *OpenAPI {
-	return &OpenAPI{backend: backend, conf: conf}
+func NewOpenAPI(backend types.ServiceBackend, conf *config.Config, a *auth.Authenticator) *OpenAPI {
+	return &OpenAPI{backend: backend, conf: conf, auth: a}
 }
 
 func (s *OpenAPI) Start() {
@@ -35,21 +38,30 @@ func (s *OpenAPI) Start() {
 
 func (s *OpenAPI) startHttp(port int) {
 	router := gin.Default()
-	router.Use(cors())
+	router.Use(cors(s.conf.CorsOrigins))
 	router.Use(ginLogrus())
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 	// 创建v1组
 	v1 := router.Group("/v1")
 	{
 		// 在v1这个分组下，注册路由
-		v1.GET("/duties/:epoch", apiHandler{backend: s.backend}.GetDutiesByEpoch)
-		v1.GET("/reward/:epoch", apiHandler{backend: s.backend}.GetRewardByEpoch)
-		v1.GET("/strategy", apiHandler{backend: s.backend}.GetStrategy)
-		v1.POST("/update-strategy", apiHandler{backend: s.backend}.UpdateStrategy)
-		v1.GET("/reorgs", apiHandler{backend: s.backend}.GetReorgs)
-		v1.GET("/block/:slot", apiHandler{backend: s.backend}.GetBlockBySlot)
-		v1.GET("/epoch", apiHandler{backend: s.backend}.GetEpoch)
-		v1.GET("/slot", apiHandler{backend: s.backend}.GetSlot)
+		// read-only routes, authenticated only if protect_read is set.
+		read := v1.Group("", s.authRead())
+		read.GET("/duties/:epoch", apiHandler{backend: s.backend}.GetDutiesByEpoch)
+		read.GET("/reward/:epoch", apiHandler{backend: s.backend}.GetRewardByEpoch)
+		read.GET("/strategy", apiHandler{backend: s.backend}.GetStrategy)
+		read.GET("/reorgs", apiHandler{backend: s.backend}.GetReorgs)
+		read.GET("/block/:slot", apiHandler{backend: s.backend}.GetBlockBySlot)
+		read.GET("/epoch", apiHandler{backend: s.backend}.GetEpoch)
+		read.GET("/slot", apiHandler{backend: s.backend}.GetSlot)
+		read.GET("/evaluation", apiHandler{backend: s.backend}.GetEvaluation)
+		read.GET("/catalogue", apiHandler{backend: s.backend}.GetCatalogue)
+		read.GET("/chainspec", apiHandler{backend: s.backend}.GetChainSpec)
+		read.GET("/validators", apiHandler{backend: s.backend}.GetValidators)
+
+		// write routes, always authenticated if auth is configured.
+		write := v1.Group("", s.authWrite())
+		write.POST("/update-strategy", apiHandler{backend: s.backend}.UpdateStrategy)
 	}
 	log.WithField("swagger", fmt.Sprintf("http://%s/swagger/index.html", docs.SwaggerInfo.Host)).Info("swagger docs url")
 
@@ -69,15 +81,53 @@ func ginLogrus() gin.HandlerFunc {
 	}
 }
 
-// enable cors
-func cors() gin.HandlerFunc {
+// authWrite authenticates the request if auth is enabled.
+func (s *OpenAPI) authWrite() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := s.auth.Check(c.Request); err != nil {
+			log.WithError(err).WithField("path", c.Request.URL.Path).Warn("unauthorized request")
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+		c.Next()
+	}
+}
+
+// authRead authenticates the request if auth is enabled and protect_read is set.
+func (s *OpenAPI) authRead() gin.HandlerFunc {
+	write := s.authWrite()
+	return func(c *gin.Context) {
+		if !s.auth.ProtectRead() {
+			c.Next()
+			return
+		}
+		write(c)
+	}
+}
+
+// enable cors, all origins are allowed if none is configured.
+func cors(origins []string) gin.HandlerFunc {
+	if len(origins) == 0 {
+		origins = []string{"*"}
+	}
+	allowed := make(map[string]bool)
+	for _, origin := range origins {
+		allowed[origin] = true
+	}
 	return func(c *gin.Context) {
-		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
+		origin := c.Request.Header.Get("Origin")
+		switch {
+		case allowed["*"]:
+			c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
+		case origin != "" && allowed[origin]:
+			c.Writer.Header().Set("Access-Control-Allow-Origin", origin)
+			c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
+			c.Writer.Header().Add("Vary", "Origin")
+		}
 		c.Writer.Header().Set("Access-Control-Max-Age", "86400")
 		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, GET, OPTIONS, PUT, DELETE, UPDATE")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Origin, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Origin, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, "+auth.ApiKeyHeader)
 		c.Writer.Header().Set("Access-Control-Expose-Headers", "Content-Length")
-		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(200)
 		} else {
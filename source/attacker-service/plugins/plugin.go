@@ -16,6 +16,8 @@ type PluginContext struct {
 type PluginResponse struct {
 	Cmd    types.AttackerCommand
 	Result interface{}
+	// Decision is the id of a delay held by the validator client.
+	Decision string
 }
 
 type AttackerPlugin interface {
@@ -0,0 +1,282 @@
+package proxy
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/prysmaticlabs/prysm/v5/api/server/structs"
+	ethpb "github.com/prysmaticlabs/prysm/v5/proto/prysm/v1alpha1"
+	log "github.com/sirupsen/logrus"
+	"github.com/tsinghua-cel/attacker-service/common"
+	"github.com/tsinghua-cel/attacker-service/types"
+)
+
+// attestationData maps GET /eth/v1/validator/attestation_data onto AttestBeforeSign.
+// The request only has the slot and the committee index, the hook gets the
+// pubkey of the validator attesting in the committee from the duties of the
+// validator client. If the validator client has none or several validators in
+// the committee, the data is shared by them, so the hook is called without a
+// pubkey and the actions checking the attacker role of the validator don't apply.
+func (p *Proxy) attestationData(w http.ResponseWriter, r *http.Request) {
+	resp, err := p.forward(r, nil)
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err.Error())
+		return
+	}
+	if !resp.ok() {
+		p.writeResponse(w, resp)
+		return
+	}
+	var data structs.GetAttestationDataResponse
+	if err := json.Unmarshal(resp.body, &data); err != nil || data.Data == nil {
+		log.WithError(err).Warn("proxy decode attestation data failed")
+		p.writeResponse(w, resp)
+		return
+	}
+	attestData, err := data.Data.ToConsensus()
+	if err != nil {
+		log.WithError(err).Warn("proxy convert attestation data failed")
+		p.writeResponse(w, resp)
+		return
+	}
+	encoded, err := common.AttestationDataToBase64(attestData)
+	if err != nil {
+		p.writeResponse(w, resp)
+		return
+	}
+	slot := uint64(attestData.Slot)
+	result := p.attest.BeforeSign(slot, p.dutyAttester(slot, uint64(attestData.CommitteeIndex)), encoded, nil)
+	if interrupted(result.Cmd) {
+		log.WithField("slot", slot).Warn("proxy interrupt attestation by attacker")
+		writeError(w, http.StatusServiceUnavailable, "attestation data not available")
+		return
+	}
+	if result.Result != encoded {
+		if modified, err := common.Base64ToAttestationData(result.Result); err == nil {
+			if body, err := json.Marshal(structs.GetAttestationDataResponse{Data: structs.AttDataFromConsensus(modified)}); err == nil {
+				resp.body = body
+			}
+		}
+	}
+	p.writeResponse(w, resp)
+}
+
+// submitAttestations maps POST /eth/v1/beacon/pool/attestations onto
+// AttestAfterSign and AttestBeforePropose for every attestation before it is
+// forwarded, and AttestAfterPropose once it is accepted. Interrupted
+// attestations are dropped from the request.
+func (p *Proxy) submitAttestations(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	var atts []*structs.Attestation
+	if err := json.Unmarshal(body, &atts); err != nil {
+		log.WithError(err).Warn("proxy decode attestations failed")
+		p.forwardRaw(w, r, body)
+		return
+	}
+
+	kept := make([]*ethpb.Attestation, 0, len(atts))
+	for _, a := range atts {
+		att, err := a.ToConsensus()
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if att = p.attestHook(att, p.attest.AfterSign, true); att == nil {
+			continue
+		}
+		if att = p.attestHook(att, p.attest.BeforePropose, false); att == nil {
+			continue
+		}
+		kept = append(kept, att)
+	}
+	if len(kept) == 0 {
+		// all attestations were withheld, pretend they were accepted.
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	body, err = json.Marshal(structs.AttsFromConsensus(kept))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	resp, err := p.forward(r, body)
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err.Error())
+		return
+	}
+	if resp.ok() {
+		for _, att := range kept {
+			p.attestHook(att, p.attest.AfterPropose, false)
+		}
+	}
+	p.writeResponse(w, resp)
+}
+
+// attestHook calls an attest action point with a signed attestation, it
+// returns nil if it is interrupted, or the modified attestation if apply is
+// set, the same as the hooks of the patched prysm validator.
+func (p *Proxy) attestHook(att *ethpb.Attestation, hook func(uint64, string, string, *string) types.AttackerResponse, apply bool) *ethpb.Attestation {
+	encoded, err := common.SignedAttestationToBase64(att)
+	if err != nil {
+		return att
+	}
+	slot := uint64(att.Data.Slot)
+	result := hook(slot, p.attester(att), encoded, nil)
+	if interrupted(result.Cmd) {
+		log.WithField("slot", slot).Warn("proxy interrupt attestation by attacker")
+		return nil
+	}
+	if apply && result.Result != encoded {
+		if modified, err := common.Base64ToSignedAttestation(result.Result); err == nil {
+			return modified
+		}
+	}
+	return att
+}
+
+// attester returns the pubkey of the validator of an unaggregated attestation,
+// the validator at the position of the single aggregation bit in the committee.
+// It returns "" for aggregates or if the committee can't be resolved.
+func (p *Proxy) attester(att *ethpb.Attestation) string {
+	bits := att.AggregationBits.BitIndices()
+	if len(bits) != 1 {
+		return ""
+	}
+	committee, err := p.committee(uint64(att.Data.Slot), uint64(att.Data.CommitteeIndex))
+	if err != nil {
+		log.WithError(err).WithField("slot", att.Data.Slot).Warn("proxy get committee failed")
+		return ""
+	}
+	if bits[0] >= len(committee) {
+		return ""
+	}
+	index, err := strconv.ParseUint(committee[bits[0]], 10, 64)
+	if err != nil {
+		return ""
+	}
+	return p.pubkeyOf(index)
+}
+
+// attesterDuties forwards POST /eth/v1/validator/duties/attester/{epoch} and
+// records the committees of the validators of the validator client.
+func (p *Proxy) attesterDuties(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp, err := p.forward(r, body)
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err.Error())
+		return
+	}
+	var duties structs.GetAttesterDutiesResponse
+	if resp.ok() && json.Unmarshal(resp.body, &duties) == nil {
+		p.recordDuties(duties.Data)
+	}
+	p.writeResponse(w, resp)
+}
+
+func (p *Proxy) recordDuties(duties []*structs.AttesterDuty) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	// the duties of an epoch are requested again on reorg, they replace the old ones.
+	replaced := make(map[committeeKey]bool)
+	var latest uint64
+	for _, duty := range duties {
+		slot, err := strconv.ParseUint(duty.Slot, 10, 64)
+		if err != nil {
+			continue
+		}
+		index, err := strconv.ParseUint(duty.CommitteeIndex, 10, 64)
+		if err != nil {
+			continue
+		}
+		key := committeeKey{slot: slot, index: index}
+		if !replaced[key] {
+			replaced[key] = true
+			p.duties[key] = nil
+		}
+		p.duties[key] = append(p.duties[key], duty.Pubkey)
+		if slot > latest {
+			latest = slot
+		}
+	}
+	for k := range p.duties {
+		if k.slot+committeeCacheSlots < latest {
+			delete(p.duties, k)
+		}
+	}
+}
+
+// dutyAttester returns the pubkey of the validator of the validator client
+// attesting in the committee, or "" if there is none or several of them.
+func (p *Proxy) dutyAttester(slot uint64, index uint64) string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if pubkeys := p.duties[committeeKey{slot: slot, index: index}]; len(pubkeys) == 1 {
+		return pubkeys[0]
+	}
+	return ""
+}
+
+// committee returns the validator indexes of a committee, the committees are
+// cached for committeeCacheSlots slots.
+func (p *Proxy) committee(slot uint64, index uint64) ([]string, error) {
+	key := committeeKey{slot: slot, index: index}
+	p.mu.Lock()
+	committee, ok := p.committees[key]
+	p.mu.Unlock()
+	if ok {
+		return committee, nil
+	}
+
+	target := *p.upstream
+	target.Path = strings.TrimSuffix(p.upstream.Path, "/") + "/eth/v1/beacon/states/head/committees"
+	target.RawQuery = fmt.Sprintf("slot=%d&index=%d", slot, index)
+	res, err := p.client.Get(target.String())
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("get committee failed: %s", res.Status)
+	}
+	var committees structs.GetCommitteesResponse
+	if err := json.NewDecoder(res.Body).Decode(&committees); err != nil {
+		return nil, err
+	}
+	if len(committees.Data) == 0 {
+		return nil, fmt.Errorf("no committee %d at slot %d", index, slot)
+	}
+	committee = committees.Data[0].Validators
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for k := range p.committees {
+		if k.slot+committeeCacheSlots < slot {
+			delete(p.committees, k)
+		}
+	}
+	p.committees[key] = committee
+	return committee, nil
+}
+
+// forwardRaw forwards a request whose body is already consumed.
+func (p *Proxy) forwardRaw(w http.ResponseWriter, r *http.Request, body []byte) {
+	resp, err := p.forward(r, body)
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err.Error())
+		return
+	}
+	p.writeResponse(w, resp)
+}
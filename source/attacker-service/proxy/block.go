@@ -0,0 +1,197 @@
+package proxy
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/prysmaticlabs/prysm/v5/api/server/structs"
+	ethpb "github.com/prysmaticlabs/prysm/v5/proto/prysm/v1alpha1"
+	log "github.com/sirupsen/logrus"
+	"github.com/tsinghua-cel/attacker-service/common"
+)
+
+const (
+	versionDeneb         = "deneb"
+	consensusVersionHead = "Eth-Consensus-Version"
+	octetStream          = "application/octet-stream"
+	blsSignatureLength   = 96
+)
+
+// produceBlock maps GET /eth/v3/validator/blocks/{slot} onto
+// BlockDelayForReceiveBlock before the block is produced and BlockBeforeSign
+// with the produced block. Only full deneb blocks are passed to the hooks.
+func (p *Proxy) produceBlock(w http.ResponseWriter, r *http.Request, slotParam string) {
+	slot, err := strconv.ParseUint(slotParam, 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if result := p.block.DelayForReceiveBlock(slot, nil); interrupted(result.Cmd) {
+		log.WithField("slot", slot).Warn("proxy interrupt block production by attacker")
+		writeError(w, http.StatusServiceUnavailable, "block production interrupted")
+		return
+	}
+	resp, err := p.forward(r, nil)
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err.Error())
+		return
+	}
+	if !resp.ok() {
+		p.writeResponse(w, resp)
+		return
+	}
+	var produced structs.ProduceBlockV3Response
+	if err := json.Unmarshal(resp.body, &produced); err != nil {
+		log.WithError(err).Warn("proxy decode produced block failed")
+		p.writeResponse(w, resp)
+		return
+	}
+	if produced.Version != versionDeneb || produced.ExecutionPayloadBlinded {
+		log.WithField("version", produced.Version).Debug("proxy skip hooks for unsupported block")
+		p.writeResponse(w, resp)
+		return
+	}
+	var contents structs.BeaconBlockContentsDeneb
+	if err := json.Unmarshal(produced.Data, &contents); err != nil {
+		log.WithError(err).Warn("proxy decode produced block failed")
+		p.writeResponse(w, resp)
+		return
+	}
+	block, err := contents.Block.ToConsensus()
+	if err != nil {
+		log.WithError(err).Warn("proxy convert produced block failed")
+		p.writeResponse(w, resp)
+		return
+	}
+	// the hooks take a signed block, the signature is filled by the validator client later.
+	unsigned := &ethpb.SignedBeaconBlockDeneb{Block: block, Signature: make([]byte, blsSignatureLength)}
+	encoded, err := common.SignedDenebBlockToBase64(unsigned)
+	if err != nil {
+		p.writeResponse(w, resp)
+		return
+	}
+	result := p.block.BeforeSign(slot, p.pubkeyOf(uint64(block.ProposerIndex)), encoded, nil)
+	if interrupted(result.Cmd) {
+		log.WithField("slot", slot).Warn("proxy interrupt block production by attacker")
+		writeError(w, http.StatusServiceUnavailable, "block production interrupted")
+		return
+	}
+	if result.Result != encoded {
+		if modified, err := common.Base64ToSignedDenebBlock(result.Result); err == nil {
+			if contents.Block, err = structs.BeaconBlockDenebFromConsensus(modified.Block); err == nil {
+				if produced.Data, err = json.Marshal(contents); err == nil {
+					if body, err := json.Marshal(produced); err == nil {
+						resp.body = body
+					}
+				}
+			}
+		}
+	}
+	p.writeResponse(w, resp)
+}
+
+// publishBlock maps POST /eth/v{1,2}/beacon/blocks onto BlockAfterSign and
+// BlockBeforePropose before the block is forwarded, and BlockAfterPropose once
+// it is accepted. Both json and ssz encoded deneb blocks are supported, other
+// blocks are forwarded without hooks. The block modified by BlockAfterSign is
+// forwarded, BlockBeforePropose and BlockAfterPropose only interrupt or delay
+// the publish, the same as the attest action points.
+func (p *Proxy) publishBlock(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	version := r.Header.Get(consensusVersionHead)
+	if version != "" && version != versionDeneb {
+		p.forwardRaw(w, r, body)
+		return
+	}
+	contents, err := decodeSignedContents(r, body)
+	if err != nil {
+		log.WithError(err).Debug("proxy skip hooks for undecodable block")
+		p.forwardRaw(w, r, body)
+		return
+	}
+	block := contents.Block
+	slot := uint64(block.Block.Slot)
+	pubkey := p.pubkeyOf(uint64(block.Block.ProposerIndex))
+	encoded, err := common.SignedDenebBlockToBase64(block)
+	if err != nil {
+		p.forwardRaw(w, r, body)
+		return
+	}
+	result := p.block.AfterSign(slot, pubkey, encoded, nil)
+	if !interrupted(result.Cmd) && result.Result != encoded {
+		if modified, err := modifiedBlockBody(r, contents, result.Result); err == nil {
+			body, encoded = modified, result.Result
+		} else {
+			log.WithError(err).WithField("slot", slot).Warn("proxy encode modified block failed")
+		}
+	}
+	if !interrupted(result.Cmd) {
+		result = p.block.BeforePropose(slot, pubkey, encoded, nil)
+	}
+	if interrupted(result.Cmd) {
+		// withhold the block, the validator client sees a successful publish.
+		log.WithField("slot", slot).Warn("proxy interrupt block publish by attacker")
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	resp, err := p.forward(r, body)
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err.Error())
+		return
+	}
+	if resp.ok() {
+		p.block.AfterPropose(slot, pubkey, encoded, nil)
+	}
+	p.writeResponse(w, resp)
+}
+
+// modifiedBlockBody returns the request body with the signed block replaced
+// by the modified one, in the encoding of the request.
+func modifiedBlockBody(r *http.Request, contents *ethpb.SignedBeaconBlockContentsDeneb, modifiedBase64 string) ([]byte, error) {
+	modified, err := common.Base64ToSignedDenebBlock(modifiedBase64)
+	if err != nil {
+		return nil, err
+	}
+	replaced := &ethpb.SignedBeaconBlockContentsDeneb{
+		Block:     modified,
+		KzgProofs: contents.KzgProofs,
+		Blobs:     contents.Blobs,
+	}
+	if strings.HasPrefix(r.Header.Get("Content-Type"), octetStream) {
+		return replaced.MarshalSSZ()
+	}
+	body, err := structs.SignedBeaconBlockContentsDenebFromConsensus(replaced)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(body)
+}
+
+func decodeSignedContents(r *http.Request, body []byte) (*ethpb.SignedBeaconBlockContentsDeneb, error) {
+	if strings.HasPrefix(r.Header.Get("Content-Type"), octetStream) {
+		contents := new(ethpb.SignedBeaconBlockContentsDeneb)
+		if err := contents.UnmarshalSSZ(body); err != nil {
+			return nil, err
+		}
+		return contents, nil
+	}
+	var contents structs.SignedBeaconBlockContentsDeneb
+	if err := json.Unmarshal(body, &contents); err != nil {
+		return nil, err
+	}
+	if contents.SignedBlock == nil {
+		return nil, common.ErrUnsupportedBeaconBlock
+	}
+	generic, err := contents.ToGeneric()
+	if err != nil {
+		return nil, err
+	}
+	return generic.GetDeneb(), nil
+}
@@ -0,0 +1,178 @@
+package proxy
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prysmaticlabs/prysm/v5/api/server/structs"
+	"github.com/prysmaticlabs/prysm/v5/consensus-types/primitives"
+	enginev1 "github.com/prysmaticlabs/prysm/v5/proto/engine/v1"
+	ethpb "github.com/prysmaticlabs/prysm/v5/proto/prysm/v1alpha1"
+	"github.com/tsinghua-cel/attacker-service/common"
+	"github.com/tsinghua-cel/attacker-service/types"
+)
+
+// graffitiHooks rewrites the graffiti of the block at AfterSign and records
+// the blocks passed to the other action points.
+type graffitiHooks struct {
+	graffiti byte
+	blocks   map[string]*ethpb.SignedBeaconBlockDeneb
+}
+
+func (h *graffitiHooks) record(point string, data string) types.AttackerResponse {
+	block, _ := common.Base64ToSignedDenebBlock(data)
+	h.blocks[point] = block
+	return types.AttackerResponse{Cmd: types.CMD_NULL, Result: data}
+}
+
+func (h *graffitiHooks) DelayForReceiveBlock(slot uint64, pubkey *string) types.AttackerResponse {
+	return types.AttackerResponse{Cmd: types.CMD_NULL}
+}
+
+func (h *graffitiHooks) BeforeSign(slot uint64, pubkey string, data string, _ *string) types.AttackerResponse {
+	return h.record("BeforeSign", data)
+}
+
+func (h *graffitiHooks) AfterSign(slot uint64, pubkey string, data string, _ *string) types.AttackerResponse {
+	block, err := common.Base64ToSignedDenebBlock(data)
+	if err != nil {
+		return types.AttackerResponse{Cmd: types.CMD_NULL, Result: data}
+	}
+	block.Block.Body.Graffiti[0] = h.graffiti
+	modified, _ := common.SignedDenebBlockToBase64(block)
+	return types.AttackerResponse{Cmd: types.CMD_NULL, Result: modified}
+}
+
+func (h *graffitiHooks) BeforePropose(slot uint64, pubkey string, data string, _ *string) types.AttackerResponse {
+	return h.record("BeforePropose", data)
+}
+
+func (h *graffitiHooks) AfterPropose(slot uint64, pubkey string, data string, _ *string) types.AttackerResponse {
+	return h.record("AfterPropose", data)
+}
+
+// testBlockContents returns deneb block contents with the minimum marshalable fields.
+func testBlockContents(slot uint64) *ethpb.SignedBeaconBlockContentsDeneb {
+	return &ethpb.SignedBeaconBlockContentsDeneb{
+		Block: &ethpb.SignedBeaconBlockDeneb{
+			Block: &ethpb.BeaconBlockDeneb{
+				Slot:       primitives.Slot(slot),
+				ParentRoot: make([]byte, 32),
+				StateRoot:  make([]byte, 32),
+				Body: &ethpb.BeaconBlockBodyDeneb{
+					RandaoReveal: make([]byte, blsSignatureLength),
+					Eth1Data:     &ethpb.Eth1Data{DepositRoot: make([]byte, 32), BlockHash: make([]byte, 32)},
+					Graffiti:     make([]byte, 32),
+					SyncAggregate: &ethpb.SyncAggregate{
+						SyncCommitteeBits:      make([]byte, 64),
+						SyncCommitteeSignature: make([]byte, blsSignatureLength),
+					},
+					ExecutionPayload: &enginev1.ExecutionPayloadDeneb{
+						ParentHash:    make([]byte, 32),
+						FeeRecipient:  make([]byte, 20),
+						StateRoot:     make([]byte, 32),
+						ReceiptsRoot:  make([]byte, 32),
+						LogsBloom:     make([]byte, 256),
+						PrevRandao:    make([]byte, 32),
+						BaseFeePerGas: make([]byte, 32),
+						BlockHash:     make([]byte, 32),
+					},
+				},
+			},
+			Signature: make([]byte, blsSignatureLength),
+		},
+	}
+}
+
+func TestPublishBlockForwardsModifiedBlock(t *testing.T) {
+	contents := testBlockContents(5)
+	jsonContents, err := structs.SignedBeaconBlockContentsDenebFromConsensus(contents)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jsonBody, err := json.Marshal(jsonContents)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sszBody, err := contents.MarshalSSZ()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        []byte
+		decode      func(body []byte) (*ethpb.SignedBeaconBlockContentsDeneb, error)
+	}{
+		{
+			name:        "json",
+			contentType: "application/json",
+			body:        jsonBody,
+			decode: func(body []byte) (*ethpb.SignedBeaconBlockContentsDeneb, error) {
+				var c structs.SignedBeaconBlockContentsDeneb
+				if err := json.Unmarshal(body, &c); err != nil {
+					return nil, err
+				}
+				generic, err := c.ToGeneric()
+				if err != nil {
+					return nil, err
+				}
+				return generic.GetDeneb(), nil
+			},
+		},
+		{
+			name:        "ssz",
+			contentType: octetStream,
+			body:        sszBody,
+			decode: func(body []byte) (*ethpb.SignedBeaconBlockContentsDeneb, error) {
+				c := new(ethpb.SignedBeaconBlockContentsDeneb)
+				return c, c.UnmarshalSSZ(body)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var published []byte
+			upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				published, _ = io.ReadAll(r.Body)
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer upstream.Close()
+			hooks := &graffitiHooks{graffiti: 0xab, blocks: make(map[string]*ethpb.SignedBeaconBlockDeneb)}
+			p, err := NewProxy(upstream.URL, nil, hooks, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/eth/v2/beacon/blocks", bytes.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			req.Header.Set(consensusVersionHead, versionDeneb)
+			rec := httptest.NewRecorder()
+			p.ServeHTTP(rec, req)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("got status %d: %s", rec.Code, rec.Body.String())
+			}
+
+			got, err := tt.decode(published)
+			if err != nil {
+				t.Fatalf("decode published block: %v", err)
+			}
+			if got.Block.Block.Body.Graffiti[0] != 0xab || got.Block.Block.Slot != 5 {
+				t.Fatalf("published block is not the modified one")
+			}
+			if len(got.Blobs) != len(contents.Blobs) || len(got.KzgProofs) != len(contents.KzgProofs) {
+				t.Fatalf("published blobs are changed")
+			}
+			for _, point := range []string{"BeforePropose", "AfterPropose"} {
+				if block := hooks.blocks[point]; block == nil || block.Block.Body.Graffiti[0] != 0xab {
+					t.Fatalf("%s is not called with the modified block", point)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,208 @@
+package proxy
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/tsinghua-cel/attacker-service/types"
+)
+
+// AttestHooks is the attest action points called by the proxy, the data is
+// base64 encoded protobuf, the default encoding of the attest rpc namespace.
+type AttestHooks interface {
+	BeforeSign(slot uint64, pubkey string, attestDataBase64 string, encoding *string) types.AttackerResponse
+	AfterSign(slot uint64, pubkey string, signedAttestDataBase64 string, encoding *string) types.AttackerResponse
+	BeforePropose(slot uint64, pubkey string, signedAttestDataBase64 string, encoding *string) types.AttackerResponse
+	AfterPropose(slot uint64, pubkey string, signedAttestDataBase64 string, encoding *string) types.AttackerResponse
+}
+
+// BlockHooks is the block action points called by the proxy, the data is
+// base64 encoded protobuf, the default encoding of the block rpc namespace.
+type BlockHooks interface {
+	DelayForReceiveBlock(slot uint64, pubkey *string) types.AttackerResponse
+	BeforeSign(slot uint64, pubkey string, signedBlockDataBase64 string, encoding *string) types.AttackerResponse
+	AfterSign(slot uint64, pubkey string, signedBlockDataBase64 string, encoding *string) types.AttackerResponse
+	BeforePropose(slot uint64, pubkey string, signedBlockDataBase64 string, encoding *string) types.AttackerResponse
+	AfterPropose(slot uint64, pubkey string, signedBlockDataBase64 string, encoding *string) types.AttackerResponse
+}
+
+// Validators resolves the pubkey of a proposer index.
+type Validators interface {
+	GetValidatorByIndex(index int) *types.ValidatorInfo
+}
+
+var (
+	produceBlockPath   = regexp.MustCompile(`^/eth/v3/validator/blocks/(\d+)$`)
+	attestDataPath     = regexp.MustCompile(`^/eth/v1/validator/attestation_data$`)
+	publishBlockPath   = regexp.MustCompile(`^/eth/v[12]/beacon/blocks$`)
+	submitAttestPath   = regexp.MustCompile(`^/eth/v1/beacon/pool/attestations$`)
+	attesterDutiesPath = regexp.MustCompile(`^/eth/v1/validator/duties/attester/\d+$`)
+	upstreamClientTime = 60 * time.Second
+	// committeeCacheSlots is how long the committees of a slot are cached,
+	// attestations are included up to an epoch later.
+	committeeCacheSlots uint64 = 64
+)
+
+type committeeKey struct {
+	slot  uint64
+	index uint64
+}
+
+// Proxy is a beacon api reverse proxy placed between an unmodified validator
+// client and its beacon node. Block production, attestation data, block
+// publish and attestation submit requests are mapped onto the block and
+// attest action points, all other requests are forwarded as is.
+type Proxy struct {
+	upstream   *url.URL
+	reverse    *httputil.ReverseProxy
+	client     *http.Client
+	attest     AttestHooks
+	block      BlockHooks
+	validators Validators
+
+	mu         sync.Mutex
+	committees map[committeeKey][]string
+	// duties is the pubkeys of the validators of the validator client by the
+	// committee they attest in, recorded from the attester duty responses.
+	duties map[committeeKey][]string
+}
+
+func NewProxy(upstream string, attest AttestHooks, block BlockHooks, validators Validators) (*Proxy, error) {
+	if !strings.HasPrefix(upstream, "http://") && !strings.HasPrefix(upstream, "https://") {
+		upstream = "http://" + upstream
+	}
+	u, err := url.Parse(upstream)
+	if err != nil {
+		return nil, err
+	}
+	reverse := httputil.NewSingleHostReverseProxy(u)
+	// flush immediately to support the event stream.
+	reverse.FlushInterval = -1
+	return &Proxy{
+		upstream:   u,
+		reverse:    reverse,
+		client:     &http.Client{Timeout: upstreamClientTime},
+		attest:     attest,
+		block:      block,
+		validators: validators,
+		committees: make(map[committeeKey][]string),
+		duties:     make(map[committeeKey][]string),
+	}, nil
+}
+
+// Start serves the proxy at the given port in background.
+func (p *Proxy) Start(port int) {
+	addr := fmt.Sprintf(":%d", port)
+	log.WithFields(log.Fields{
+		"addr":     addr,
+		"upstream": p.upstream.String(),
+	}).Info("start beacon api proxy")
+	go func() {
+		if err := http.ListenAndServe(addr, p); err != nil {
+			log.WithError(err).Error("beacon api proxy stopped")
+		}
+	}()
+}
+
+// ServeHTTP implements http.Handler
+func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := r.URL.Path
+	switch {
+	case r.Method == http.MethodGet && produceBlockPath.MatchString(path):
+		p.produceBlock(w, r, produceBlockPath.FindStringSubmatch(path)[1])
+	case r.Method == http.MethodGet && attestDataPath.MatchString(path):
+		p.attestationData(w, r)
+	case r.Method == http.MethodPost && publishBlockPath.MatchString(path):
+		p.publishBlock(w, r)
+	case r.Method == http.MethodPost && submitAttestPath.MatchString(path):
+		p.submitAttestations(w, r)
+	case r.Method == http.MethodPost && attesterDutiesPath.MatchString(path):
+		p.attesterDuties(w, r)
+	default:
+		p.reverse.ServeHTTP(w, r)
+	}
+}
+
+// upstreamResponse is a fully read response of the upstream beacon node.
+type upstreamResponse struct {
+	status int
+	header http.Header
+	body   []byte
+}
+
+func (u *upstreamResponse) ok() bool {
+	return u.status >= 200 && u.status < 300
+}
+
+// forward sends the request with the given body to the upstream beacon node,
+// the response is always requested as json so it can be decoded.
+func (p *Proxy) forward(r *http.Request, body []byte) (*upstreamResponse, error) {
+	target := *p.upstream
+	target.Path = strings.TrimSuffix(p.upstream.Path, "/") + r.URL.Path
+	target.RawQuery = r.URL.RawQuery
+	req, err := http.NewRequestWithContext(r.Context(), r.Method, target.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header = r.Header.Clone()
+	req.Header.Set("Accept", "application/json")
+	req.Header.Del("Accept-Encoding")
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	return &upstreamResponse{status: resp.StatusCode, header: resp.Header, body: data}, nil
+}
+
+func (p *Proxy) writeResponse(w http.ResponseWriter, resp *upstreamResponse) {
+	for k, vs := range resp.header {
+		if k == "Content-Length" {
+			continue
+		}
+		for _, v := range vs {
+			w.Header().Add(k, v)
+		}
+	}
+	w.WriteHeader(resp.status)
+	w.Write(resp.body)
+}
+
+func writeError(w http.ResponseWriter, code int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	fmt.Fprintf(w, `{"code":%d,"message":%q}`, code, message)
+}
+
+func (p *Proxy) pubkeyOf(index uint64) string {
+	if p.validators == nil {
+		return ""
+	}
+	if v := p.validators.GetValidatorByIndex(int(index)); v != nil {
+		return v.Pubkey
+	}
+	return ""
+}
+
+// interrupted returns true if the command stops the duty, exit and abort are
+// treated like return since the proxy can't stop the validator client.
+func interrupted(cmd types.AttackerCommand) bool {
+	switch cmd {
+	case types.CMD_RETURN, types.CMD_EXIT, types.CMD_ABORT, types.CMD_SKIP:
+		return true
+	}
+	return false
+}
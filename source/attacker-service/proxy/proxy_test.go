@@ -0,0 +1,195 @@
+package proxy
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/prysmaticlabs/prysm/v5/api/server/structs"
+	"github.com/tsinghua-cel/attacker-service/types"
+)
+
+// recordHooks records the pubkey passed to every attest action point and
+// withholds the attestations of the pubkeys in withhold.
+type recordHooks struct {
+	mu       sync.Mutex
+	pubkeys  map[string][]string
+	withhold map[string]bool
+}
+
+func (h *recordHooks) call(point string, pubkey string, data string) types.AttackerResponse {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.pubkeys[point] = append(h.pubkeys[point], pubkey)
+	if h.withhold[pubkey] {
+		return types.AttackerResponse{Cmd: types.CMD_RETURN, Result: data}
+	}
+	return types.AttackerResponse{Cmd: types.CMD_NULL, Result: data}
+}
+
+func (h *recordHooks) BeforeSign(slot uint64, pubkey string, data string, _ *string) types.AttackerResponse {
+	return h.call("BeforeSign", pubkey, data)
+}
+
+func (h *recordHooks) AfterSign(slot uint64, pubkey string, data string, _ *string) types.AttackerResponse {
+	return h.call("AfterSign", pubkey, data)
+}
+
+func (h *recordHooks) BeforePropose(slot uint64, pubkey string, data string, _ *string) types.AttackerResponse {
+	return h.call("BeforePropose", pubkey, data)
+}
+
+func (h *recordHooks) AfterPropose(slot uint64, pubkey string, data string, _ *string) types.AttackerResponse {
+	return h.call("AfterPropose", pubkey, data)
+}
+
+type testValidators map[int]string
+
+func (v testValidators) GetValidatorByIndex(index int) *types.ValidatorInfo {
+	if pubkey, ok := v[index]; ok {
+		return &types.ValidatorInfo{Index: int64(index), Pubkey: pubkey}
+	}
+	return nil
+}
+
+var (
+	testRoot      = "0x" + strings.Repeat("11", 32)
+	testSignature = "0x" + strings.Repeat("22", 96)
+)
+
+func testAttestation(bits string) *structs.Attestation {
+	return &structs.Attestation{
+		AggregationBits: bits,
+		Data: &structs.AttestationData{
+			Slot:            "5",
+			CommitteeIndex:  "0",
+			BeaconBlockRoot: testRoot,
+			Source:          &structs.Checkpoint{Epoch: "0", Root: testRoot},
+			Target:          &structs.Checkpoint{Epoch: "0", Root: testRoot},
+		},
+		Signature: testSignature,
+	}
+}
+
+// startUpstream serves the committee 0 of slot 5 and records the submitted attestations.
+func startUpstream(t *testing.T) (*httptest.Server, *[]*structs.Attestation) {
+	submitted := make([]*structs.Attestation, 0)
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/eth/v1/beacon/states/head/committees":
+			json.NewEncoder(w).Encode(structs.GetCommitteesResponse{Data: []*structs.Committee{
+				{Index: "0", Slot: "5", Validators: []string{"7", "3", "9"}},
+			}})
+		case "/eth/v1/beacon/pool/attestations":
+			var atts []*structs.Attestation
+			body, _ := io.ReadAll(r.Body)
+			if err := json.Unmarshal(body, &atts); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			submitted = append(submitted, atts...)
+			w.WriteHeader(http.StatusOK)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(upstream.Close)
+	return upstream, &submitted
+}
+
+func TestSubmitAttestationsResolvesAttester(t *testing.T) {
+	upstream, submitted := startUpstream(t)
+	hooks := &recordHooks{
+		pubkeys:  make(map[string][]string),
+		withhold: map[string]bool{"0x09": true},
+	}
+	p, err := NewProxy(upstream.URL, hooks, nil, testValidators{7: "0x07", 3: "0x03", 9: "0x09"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the committee has 3 validators, the last bit of the bitlist is the length bit.
+	atts := []*structs.Attestation{testAttestation("0x0a"), testAttestation("0x0c")}
+	body, _ := json.Marshal(atts)
+	req := httptest.NewRequest(http.MethodPost, "/eth/v1/beacon/pool/attestations", bytes.NewReader(body))
+	rec := httptest.NewRecorder()
+	p.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got status %d: %s", rec.Code, rec.Body.String())
+	}
+
+	if got := hooks.pubkeys["AfterSign"]; len(got) != 2 || got[0] != "0x03" || got[1] != "0x09" {
+		t.Fatalf("AfterSign got pubkeys %v, want [0x03 0x09]", got)
+	}
+	if got := hooks.pubkeys["AfterPropose"]; len(got) != 1 || got[0] != "0x03" {
+		t.Fatalf("AfterPropose got pubkeys %v, want [0x03]", got)
+	}
+	// the attestation of validator 9 is withheld by the attacker.
+	if len(*submitted) != 1 || (*submitted)[0].AggregationBits != "0x0a" {
+		t.Fatalf("upstream got %d attestations, want the one of validator 3", len(*submitted))
+	}
+}
+
+func TestAggregateHasNoAttester(t *testing.T) {
+	upstream, _ := startUpstream(t)
+	p, err := NewProxy(upstream.URL, nil, nil, testValidators{7: "0x07", 3: "0x03", 9: "0x09"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	att, err := testAttestation("0x0e").ToConsensus()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pubkey := p.attester(att); pubkey != "" {
+		t.Fatalf("got attester %s of an aggregate", pubkey)
+	}
+}
+
+func TestAttestationDataResolvesAttesterFromDuties(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/eth/v1/validator/duties/attester/0":
+			json.NewEncoder(w).Encode(structs.GetAttesterDutiesResponse{Data: []*structs.AttesterDuty{
+				{Pubkey: "0x03", ValidatorIndex: "3", CommitteeIndex: "0", Slot: "5"},
+				{Pubkey: "0x07", ValidatorIndex: "7", CommitteeIndex: "1", Slot: "5"},
+				{Pubkey: "0x09", ValidatorIndex: "9", CommitteeIndex: "1", Slot: "5"},
+			}})
+		case "/eth/v1/validator/attestation_data":
+			att := testAttestation("0x0a")
+			att.Data.CommitteeIndex = r.URL.Query().Get("committee_index")
+			json.NewEncoder(w).Encode(structs.GetAttestationDataResponse{Data: att.Data})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer upstream.Close()
+	hooks := &recordHooks{pubkeys: make(map[string][]string)}
+	p, err := NewProxy(upstream.URL, hooks, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/eth/v1/validator/duties/attester/0", strings.NewReader(`["3","7","9"]`))
+	rec := httptest.NewRecorder()
+	p.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got status %d: %s", rec.Code, rec.Body.String())
+	}
+	// committee 0 has one validator of the client, committee 1 two and committee 2 none.
+	for _, index := range []string{"0", "1", "2"} {
+		req := httptest.NewRequest(http.MethodGet, "/eth/v1/validator/attestation_data?slot=5&committee_index="+index, nil)
+		rec := httptest.NewRecorder()
+		p.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("got status %d: %s", rec.Code, rec.Body.String())
+		}
+	}
+	if got := hooks.pubkeys["BeforeSign"]; len(got) != 3 || got[0] != "0x03" || got[1] != "" || got[2] != "" {
+		t.Fatalf("BeforeSign got pubkeys %q, want [0x03  ]", got)
+	}
+}
@@ -7,12 +7,20 @@ import (
 	log "github.com/sirupsen/logrus"
 	"github.com/tsinghua-cel/attacker-service/beaconapi"
 	"github.com/tsinghua-cel/attacker-service/dbmodel"
+	"github.com/tsinghua-cel/attacker-service/metrics"
+	"github.com/tsinghua-cel/attacker-service/types"
 	"os"
 	"strconv"
 )
 
-func GetRewardsToMysql(gwEndpoint string) error {
-	client := beaconapi.NewBeaconGwClient(gwEndpoint)
+// RoleProvider tells the role of a validator at a slot, it is used to split
+// the collected rewards into attacker and honest totals.
+type RoleProvider interface {
+	GetValidatorRole(slot int, valIdx int) types.RoleType
+}
+
+func GetRewardsToMysql(gwEndpoints []string, roles RoleProvider) error {
+	client := beaconapi.NewBeaconGwClient(gwEndpoints...)
 	slots_per_epoch, err := client.GetIntConfig(beaconapi.SLOTS_PER_EPOCH)
 	if err != nil {
 		log.WithError(err).Error("GetRewardsToMysql get chain config failed")
@@ -50,10 +58,23 @@ func GetRewardsToMysql(gwEndpoint string) error {
 			return err
 		}
 
+		var attackerReward, honestReward int64
+		var attackerCount, honestCount int
 		for _, totalReward := range totalRewards {
 			valIdx, _ := strconv.ParseInt(totalReward.ValidatorIndex, 10, 64)
 			headAmount, _ := strconv.ParseInt(totalReward.Head, 10, 64)
 			targetAmount, _ := strconv.ParseInt(totalReward.Target, 10, 64)
+			sourceAmount, _ := strconv.ParseInt(totalReward.Source, 10, 64)
+			if roles != nil {
+				slot := int(epochNumber) * slots_per_epoch
+				if roles.GetValidatorRole(slot, int(valIdx)) == types.AttackerRole {
+					attackerReward += headAmount + targetAmount + sourceAmount
+					attackerCount++
+				} else {
+					honestReward += headAmount + targetAmount + sourceAmount
+					honestCount++
+				}
+			}
 			record := &dbmodel.BlockReward{
 				Epoch:          epochNumber,
 				ValidatorIndex: int(valIdx),
@@ -65,6 +86,7 @@ func GetRewardsToMysql(gwEndpoint string) error {
 				return errors.New("insert failed")
 			}
 		}
+		metrics.EpochReward(epochNumber, attackerReward, honestReward, attackerCount, honestCount)
 		epochNumber++
 	}
 	if err = o.Commit(); err != nil {
@@ -73,7 +95,7 @@ func GetRewardsToMysql(gwEndpoint string) error {
 	return nil
 }
 
-func GetRewards(gwEndpoint string, output string) error {
+func GetRewards(gwEndpoints []string, output string) error {
 	bakfile := output + ".bak"
 	file, err := os.Create(bakfile)
 	if err != nil {
@@ -86,7 +108,7 @@ func GetRewards(gwEndpoint string, output string) error {
 			os.Rename(bakfile, output)
 		}
 	}()
-	client := beaconapi.NewBeaconGwClient(gwEndpoint)
+	client := beaconapi.NewBeaconGwClient(gwEndpoints...)
 
 	slots_per_epoch, err := client.GetIntConfig(beaconapi.SLOTS_PER_EPOCH)
 	if err != nil {
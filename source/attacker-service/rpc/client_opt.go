@@ -41,6 +41,7 @@ type clientConfig struct {
 	idgen              func() ID
 	batchItemLimit     int
 	batchResponseLimit int
+	callFilter         CallFilter
 }
 
 func (cfg *clientConfig) initHeaders() {
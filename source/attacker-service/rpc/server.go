@@ -20,6 +20,7 @@ import (
 	"context"
 	log "github.com/sirupsen/logrus"
 	"io"
+	"net/http"
 	"sync"
 	"sync/atomic"
 )
@@ -50,8 +51,12 @@ type Server struct {
 	run                atomic.Bool
 	batchItemLimit     int
 	batchResponseLimit int
+	wsFilter           func(r *http.Request) CallFilter
 }
 
+// CallFilter returns an error for the methods a connection must not call.
+type CallFilter func(method string) error
+
 // NewServer creates a new server instance with no registered handlers.
 func NewServer() *Server {
 	server := &Server{
@@ -77,6 +82,14 @@ func (s *Server) SetBatchLimits(itemLimit, maxResponseSize int) {
 	s.batchResponseLimit = maxResponseSize
 }
 
+// SetWebsocketFilter sets the function returning the call filter of a websocket
+// connection from its upgrade request.
+//
+// This method should be called before serving any websocket connections.
+func (s *Server) SetWebsocketFilter(filter func(r *http.Request) CallFilter) {
+	s.wsFilter = filter
+}
+
 // RegisterName creates a service for the given receiver type under the given name. When no
 // methods on the given receiver match the criteria to be either a RPC method or a
 // subscription an error is returned. Otherwise a new service is created and added to the
@@ -91,6 +104,10 @@ func (s *Server) RegisterName(name string, receiver interface{}) error {
 //
 // Note that codec options are no longer supported.
 func (s *Server) ServeCodec(codec ServerCodec, options CodecOption) {
+	s.serveCodec(codec, nil)
+}
+
+func (s *Server) serveCodec(codec ServerCodec, filter CallFilter) {
 	defer codec.close()
 
 	if !s.trackCodec(codec) {
@@ -102,6 +119,7 @@ func (s *Server) ServeCodec(codec ServerCodec, options CodecOption) {
 		idgen:              s.idgen,
 		batchItemLimit:     s.batchItemLimit,
 		batchResponseLimit: s.batchResponseLimit,
+		callFilter:         filter,
 	}
 	c := initClient(codec, &s.services, cfg)
 	<-codec.closed()
@@ -60,8 +60,12 @@ func (s *Server) WebsocketHandler(allowedOrigins []string) http.Handler {
 			log.WithField("err", err).Debug("WebSocket upgrade failed")
 			return
 		}
+		var filter CallFilter
+		if s.wsFilter != nil {
+			filter = s.wsFilter(r)
+		}
 		codec := newWebsocketCodec(conn, r.Host, r.Header, wsDefaultReadLimit)
-		s.ServeCodec(codec, 0)
+		s.serveCodec(codec, filter)
 	})
 }
 
@@ -0,0 +1,94 @@
+package apis
+
+import (
+	"context"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/tsinghua-cel/attacker-service/decision"
+	"github.com/tsinghua-cel/attacker-service/metrics"
+	"github.com/tsinghua-cel/attacker-service/rpc"
+	"github.com/tsinghua-cel/attacker-service/trace"
+	"github.com/tsinghua-cel/attacker-service/types"
+)
+
+// AttackerAPI offers the subscriptions of the attack decisions.
+type AttackerAPI struct {
+	b Backend
+}
+
+func NewAttackerAPI(b Backend) *AttackerAPI {
+	return &AttackerAPI{b}
+}
+
+// Decisions pushes the delay, release and withhold decisions of the given
+// validators, or of all validators if pubkeys is empty. It is called with
+// attacker_subscribe("decisions", pubkeys, hold) over websocket. With hold
+// the delay actions of these validators return the decision id at once and
+// the client holds the duty until the release decision with the same id.
+func (s *AttackerAPI) Decisions(ctx context.Context, pubkeys []string, hold *bool) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+	// subscribe before returning, so the delays are held as soon as the
+	// client has the subscription.
+	ch, unsubscribe, err := decision.Subscribe(pubkeys, hold != nil && *hold)
+	if err != nil {
+		return &rpc.Subscription{}, err
+	}
+	sub := notifier.CreateSubscription()
+	go func() {
+		defer unsubscribe()
+		for {
+			select {
+			case d := <-ch:
+				notifier.Notify(sub.ID, d)
+			case <-sub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			}
+		}
+	}()
+	return sub, nil
+}
+
+// observe records the metrics and the trace of an action point, and pushes a
+// withhold decision if the duty is stopped.
+func observe(point string, slot uint64, pubkey string, payload string, start time.Time, result types.AttackerResponse) {
+	cmd := result.Cmd
+	metrics.ObserveActionPoint(point, start, cmd)
+	trace.Record(point, slot, pubkey, payload, start, result)
+	switch cmd {
+	case types.CMD_RETURN, types.CMD_SKIP, types.CMD_ABORT, types.CMD_EXIT:
+		decision.Publish(decision.Decision{
+			Kind:   decision.KindWithhold,
+			Slot:   int64(slot),
+			Pubkey: pubkey,
+			Point:  point,
+			Cmd:    cmd.String(),
+		})
+	}
+}
+
+// replayed returns the recorded response of the hook in replay mode, the
+// recorded delay decision is dropped since it is never released again.
+func replayed(point string, slot uint64, pubkey string, payload string, start time.Time) (types.AttackerResponse, bool) {
+	result, ok := trace.Replay(point, slot, pubkey, payload)
+	if ok {
+		result.Decision = ""
+		observe(point, slot, pubkey, payload, start, result)
+	}
+	return result, ok
+}
+
+// decodeFailed returns the response of an undecodable payload, the payload is
+// returned unchanged with the error so it is not taken as no attack.
+func decodeFailed(point string, start time.Time, result types.AttackerResponse, err error) types.AttackerResponse {
+	log.WithError(err).WithField("point", point).Error("decode hook payload failed")
+	metrics.ObserveActionPoint(point, start, types.CMD_NULL)
+	result.Cmd = types.CMD_NULL
+	result.Error = err.Error()
+	return result
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/tsinghua-cel/attacker-service/plugins"
 	"github.com/tsinghua-cel/attacker-service/strategy/slotstrategy"
 	"github.com/tsinghua-cel/attacker-service/types"
+	"time"
 )
 
 // AttestAPI offers and API for attestation operations.
@@ -21,19 +22,23 @@ func NewAttestAPI(b Backend, plugin plugins.AttackerPlugin) *AttestAPI {
 }
 
 func findMaxLevelStrategy(is []slotstrategy.InternalSlotStrategy, slot int64) (slotstrategy.InternalSlotStrategy, bool) {
-	if len(is) == 0 {
-		return slotstrategy.InternalSlotStrategy{}, false
-	}
-	last := is[0]
+	var (
+		last  slotstrategy.InternalSlotStrategy
+		found bool
+	)
 	for _, s := range is {
-		if s.Slot.Compare(slot) == 0 && s.Level > last.Level {
-			last = s
+		if s.Match(slot) && (!found || s.Level > last.Level) {
+			last, found = s, true
 		}
 	}
-	return last, last.Slot.Compare(slot) == 0
+	return last, found
 }
 
 func (s *AttestAPI) BeforeBroadCast(slot uint64) types.AttackerResponse {
+	start := time.Now()
+	if r, ok := replayed("AttestBeforeBroadCast", slot, "", "", start); ok {
+		return r
+	}
 	result := types.AttackerResponse{
 		Cmd: types.CMD_NULL,
 	}
@@ -43,8 +48,10 @@ func (s *AttestAPI) BeforeBroadCast(slot uint64) types.AttackerResponse {
 		if action != nil {
 			r := action.RunAction(s.b, int64(slot), "")
 			result.Cmd = r.Cmd
+			result.Decision = r.Decision
 		}
 	}
+	observe("AttestBeforeBroadCast", slot, "", "", start, result)
 	log.WithFields(log.Fields{
 		"cmd":  result.Cmd,
 		"slot": slot,
@@ -54,6 +61,10 @@ func (s *AttestAPI) BeforeBroadCast(slot uint64) types.AttackerResponse {
 }
 
 func (s *AttestAPI) AfterBroadCast(slot uint64) types.AttackerResponse {
+	start := time.Now()
+	if r, ok := replayed("AttestAfterBroadCast", slot, "", "", start); ok {
+		return r
+	}
 	result := types.AttackerResponse{
 		Cmd: types.CMD_NULL,
 	}
@@ -62,8 +73,10 @@ func (s *AttestAPI) AfterBroadCast(slot uint64) types.AttackerResponse {
 		if action != nil {
 			r := action.RunAction(s.b, int64(slot), "")
 			result.Cmd = r.Cmd
+			result.Decision = r.Decision
 		}
 	}
+	observe("AttestAfterBroadCast", slot, "", "", start, result)
 	log.WithFields(log.Fields{
 		"cmd":  result.Cmd,
 		"slot": slot,
@@ -72,18 +85,24 @@ func (s *AttestAPI) AfterBroadCast(slot uint64) types.AttackerResponse {
 	return result
 }
 
-func (s *AttestAPI) BeforeSign(slot uint64, pubkey string, attestDataBase64 string) types.AttackerResponse {
+// BeforeSign runs the AttestBeforeSign action with the attestation data, the
+// optional encoding is proto (default), ssz or json, the result is encoded the same.
+func (s *AttestAPI) BeforeSign(slot uint64, pubkey string, attestData string, encoding *string) types.AttackerResponse {
+	start := time.Now()
+	if r, ok := replayed("AttestBeforeSign", slot, pubkey, attestData, start); ok {
+		return r
+	}
 	result := types.AttackerResponse{
 		Cmd:    types.CMD_NULL,
-		Result: attestDataBase64,
+		Result: attestData,
 	}
-
-	attestation, err := common.Base64ToAttestationData(attestDataBase64)
+	enc, _, err := common.ParseEncoding(encoding)
 	if err != nil {
-		return types.AttackerResponse{
-			Cmd:    types.CMD_NULL,
-			Result: attestDataBase64,
-		}
+		return decodeFailed("AttestBeforeSign", start, result, err)
+	}
+	attestation, err := common.DecodeAttestationData(enc, attestData)
+	if err != nil {
+		return decodeFailed("AttestBeforeSign", start, result, err)
 	}
 
 	if st, find := findMaxLevelStrategy(s.b.GetInternalSlotStrategy(), int64(slot)); find {
@@ -91,13 +110,18 @@ func (s *AttestAPI) BeforeSign(slot uint64, pubkey string, attestDataBase64 stri
 		if action != nil {
 			r := action.RunAction(s.b, int64(slot), pubkey, attestation)
 			result.Cmd = r.Cmd
+			result.Decision = r.Decision
 			newAttestation, ok := r.Result.(*ethpb.AttestationData)
 			if ok {
-				newData, _ := common.AttestationDataToBase64(newAttestation)
-				result.Result = newData
+				if newData, err := common.EncodeAttestationData(enc, newAttestation); err != nil {
+					result.Error = err.Error()
+				} else {
+					result.Result = newData
+				}
 			}
 		}
 	}
+	observe("AttestBeforeSign", slot, pubkey, attestData, start, result)
 	log.WithFields(log.Fields{
 		"cmd":  result.Cmd,
 		"slot": slot,
@@ -105,100 +129,56 @@ func (s *AttestAPI) BeforeSign(slot uint64, pubkey string, attestDataBase64 stri
 	return result
 }
 
-func (s *AttestAPI) AfterSign(slot uint64, pubkey string, signedAttestDataBase64 string) types.AttackerResponse {
-	signedAttestData, err := common.Base64ToSignedAttestation(signedAttestDataBase64)
-	if err != nil {
-		return types.AttackerResponse{
-			Cmd:    types.CMD_NULL,
-			Result: signedAttestDataBase64,
-		}
-	}
-	result := types.AttackerResponse{
-		Cmd:    types.CMD_NULL,
-		Result: signedAttestDataBase64,
-	}
+func (s *AttestAPI) AfterSign(slot uint64, pubkey string, signedAttestData string, encoding *string) types.AttackerResponse {
+	return s.signedAttestActions("AttestAfterSign", slot, pubkey, signedAttestData, encoding)
+}
 
-	if t, find := findMaxLevelStrategy(s.b.GetInternalSlotStrategy(), int64(slot)); find {
-		action := t.Actions["AttestAfterSign"]
-		if action != nil {
-			r := action.RunAction(s.b, int64(slot), pubkey, signedAttestData)
-			result.Cmd = r.Cmd
-			newAttestation, ok := r.Result.(*ethpb.Attestation)
-			if ok {
-				newData, _ := common.SignedAttestationToBase64(newAttestation)
-				result.Result = newData
-			}
-		}
-	}
-	log.WithFields(log.Fields{
-		"cmd":  result.Cmd,
-		"slot": slot,
-	}).Info("exit AttestAfterSign")
-	return result
+func (s *AttestAPI) BeforePropose(slot uint64, pubkey string, signedAttestData string, encoding *string) types.AttackerResponse {
+	return s.signedAttestActions("AttestBeforePropose", slot, pubkey, signedAttestData, encoding)
 }
 
-func (s *AttestAPI) BeforePropose(slot uint64, pubkey string, signedAttestDataBase64 string) types.AttackerResponse {
-	signedAttest, err := common.Base64ToSignedAttestation(signedAttestDataBase64)
-	if err != nil {
-		return types.AttackerResponse{
-			Cmd:    types.CMD_NULL,
-			Result: signedAttestDataBase64,
-		}
+func (s *AttestAPI) AfterPropose(slot uint64, pubkey string, signedAttestData string, encoding *string) types.AttackerResponse {
+	return s.signedAttestActions("AttestAfterPropose", slot, pubkey, signedAttestData, encoding)
+}
+
+func (s *AttestAPI) signedAttestActions(name string, slot uint64, pubkey string, signedAttestData string, encoding *string) types.AttackerResponse {
+	start := time.Now()
+	if r, ok := replayed(name, slot, pubkey, signedAttestData, start); ok {
+		return r
 	}
 	result := types.AttackerResponse{
 		Cmd:    types.CMD_NULL,
-		Result: signedAttestDataBase64,
-	}
-
-	if t, find := findMaxLevelStrategy(s.b.GetInternalSlotStrategy(), int64(slot)); find {
-		action := t.Actions["AttestBeforePropose"]
-		if action != nil {
-			r := action.RunAction(s.b, int64(slot), pubkey, signedAttest)
-			result.Cmd = r.Cmd
-			newAttestation, ok := r.Result.(*ethpb.Attestation)
-			if ok {
-				newData, _ := common.SignedAttestationToBase64(newAttestation)
-				result.Result = newData
-			}
-		}
+		Result: signedAttestData,
 	}
-	log.WithFields(log.Fields{
-		"cmd":  result.Cmd,
-		"slot": slot,
-	}).Info("exit AttestBeforePropose")
-	return result
-}
-
-func (s *AttestAPI) AfterPropose(slot uint64, pubkey string, signedAttestDataBase64 string) types.AttackerResponse {
-	signedAttest, err := common.Base64ToSignedAttestation(signedAttestDataBase64)
+	enc, _, err := common.ParseEncoding(encoding)
 	if err != nil {
-		return types.AttackerResponse{
-			Cmd:    types.CMD_NULL,
-			Result: signedAttestDataBase64,
-		}
+		return decodeFailed(name, start, result, err)
 	}
-	result := types.AttackerResponse{
-		Cmd:    types.CMD_NULL,
-		Result: signedAttestDataBase64,
+	signedAttest, err := common.DecodeSignedAttestation(enc, signedAttestData)
+	if err != nil {
+		return decodeFailed(name, start, result, err)
 	}
 
 	if t, find := findMaxLevelStrategy(s.b.GetInternalSlotStrategy(), int64(slot)); find {
-		action := t.Actions["AttestAfterPropose"]
+		action := t.Actions[name]
 		if action != nil {
 			r := action.RunAction(s.b, int64(slot), pubkey, signedAttest)
 			result.Cmd = r.Cmd
+			result.Decision = r.Decision
 			newAttestation, ok := r.Result.(*ethpb.Attestation)
 			if ok {
-				newData, _ := common.SignedAttestationToBase64(newAttestation)
-				result.Result = newData
+				if newData, err := common.EncodeSignedAttestation(enc, newAttestation); err != nil {
+					result.Error = err.Error()
+				} else {
+					result.Result = newData
+				}
 			}
 		}
 	}
-
+	observe(name, slot, pubkey, signedAttestData, start, result)
 	log.WithFields(log.Fields{
 		"cmd":  result.Cmd,
 		"slot": slot,
-	}).Info("exit AttestAfterPropose")
-
+	}).Info("exit " + name)
 	return result
 }
@@ -18,6 +18,7 @@ type Backend interface {
 	types.BeaconBackend
 	types.StrategyBackend
 	types.CacheBackend
+	types.SlashableBackend
 }
 
 func GetAPIs(apiBackend Backend, plugin plugins.AttackerPlugin) []rpc.API {
@@ -34,6 +35,10 @@ func GetAPIs(apiBackend Backend, plugin plugins.AttackerPlugin) []rpc.API {
 			Namespace: "attest",
 			Service:   NewAttestAPI(apiBackend, plugin),
 		},
+		{
+			Namespace: "attacker",
+			Service:   NewAttackerAPI(apiBackend),
+		},
 	}
 }
 
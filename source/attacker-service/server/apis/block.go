@@ -1,6 +1,7 @@
 package apis
 
 import (
+	"encoding/hex"
 	"errors"
 	"github.com/prysmaticlabs/prysm/v5/cache/lru"
 	log "github.com/sirupsen/logrus"
@@ -28,20 +29,29 @@ func NewBlockAPI(b Backend, plugin plugins.AttackerPlugin) *BlockAPI {
 }
 
 func (s *BlockAPI) GetNewParentRoot(slot uint64, pubkey string, parentRoot string) types.AttackerResponse {
+	start := time.Now()
+	if r, ok := replayed("BlockGetNewParentRoot", slot, pubkey, parentRoot, start); ok {
+		return r
+	}
 	result := types.AttackerResponse{
 		Cmd:    types.CMD_NULL,
 		Result: parentRoot,
 	}
-	if t, find := findMaxLevelStrategy(s.b.GetInternalSlotStrategy(), int64(slot)); find {
+	if root, ok := s.b.ParentRootOverride(slot); ok {
+		// the block is produced by a slashable action, the parent is chosen by it.
+		result.Result = hex.EncodeToString(root)
+	} else if t, find := findMaxLevelStrategy(s.b.GetInternalSlotStrategy(), int64(slot)); find {
 		action := t.Actions["BlockGetNewParentRoot"]
-		if action != nil {
+		if action != nil && s.attackerProposer("BlockGetNewParentRoot", slot, pubkey) {
 			r := action.RunAction(s.b, int64(slot), pubkey, parentRoot)
 			result.Cmd = r.Cmd
+			result.Decision = r.Decision
 			if r.Result != nil {
 				result.Result = r.Result.(string)
 			}
 		}
 	}
+	observe("BlockGetNewParentRoot", slot, pubkey, parentRoot, start, result)
 	log.WithFields(log.Fields{
 		"cmd":    result.Cmd,
 		"slot":   slot,
@@ -52,50 +62,65 @@ func (s *BlockAPI) GetNewParentRoot(slot uint64, pubkey string, parentRoot strin
 }
 
 func (s *BlockAPI) BroadCastDelay(slot uint64) types.AttackerResponse {
-	return s.todoActionsWithSlot(slot, "BlockDelayForBroadCast")
+	return s.todoActionsWithSlot(slot, "", "BlockDelayForBroadCast")
 }
 
-func (s *BlockAPI) DelayForReceiveBlock(slot uint64) types.AttackerResponse {
+// DelayForReceiveBlock, BeforeBroadCast and AfterBroadCast take the optional
+// pubkey of the block proposer, it is taken from the duties if omitted.
+func (s *BlockAPI) DelayForReceiveBlock(slot uint64, pubkey *string) types.AttackerResponse {
 	s.b.SetSlotStartTime(int(slot), time.Now().Unix())
-	return s.todoActionsWithSlot(slot, "BlockDelayForReceiveBlock")
+	return s.todoActionsWithSlot(slot, optionalPubkey(pubkey), "BlockDelayForReceiveBlock")
 }
 
-func (s *BlockAPI) BeforeBroadCast(slot uint64) types.AttackerResponse {
-	return s.todoActionsWithSlot(slot, "BlockBeforeBroadCast")
+func (s *BlockAPI) BeforeBroadCast(slot uint64, pubkey *string) types.AttackerResponse {
+	return s.todoActionsWithSlot(slot, optionalPubkey(pubkey), "BlockBeforeBroadCast")
 }
 
-func (s *BlockAPI) AfterBroadCast(slot uint64) types.AttackerResponse {
-	return s.todoActionsWithSlot(slot, "BlockAfterBroadCast")
+func (s *BlockAPI) AfterBroadCast(slot uint64, pubkey *string) types.AttackerResponse {
+	return s.todoActionsWithSlot(slot, optionalPubkey(pubkey), "BlockAfterBroadCast")
+}
+
+func optionalPubkey(pubkey *string) string {
+	if pubkey == nil {
+		return ""
+	}
+	return *pubkey
 }
 
-func (s *BlockAPI) BeforeSign(slot uint64, pubkey string, signedBlockDataBase64 string) types.AttackerResponse {
-	return s.todoActionsWithSignedBlock(slot, pubkey, signedBlockDataBase64, "BlockBeforeSign")
+func (s *BlockAPI) BeforeSign(slot uint64, pubkey string, signedBlockData string, encoding *string) types.AttackerResponse {
+	return s.todoActionsWithSignedBlock(slot, pubkey, signedBlockData, encoding, "BlockBeforeSign")
 }
 
-func (s *BlockAPI) AfterSign(slot uint64, pubkey string, signedBlockDataBase64 string) types.AttackerResponse {
-	return s.todoActionsWithSignedBlock(slot, pubkey, signedBlockDataBase64, "BlockAfterSign")
+func (s *BlockAPI) AfterSign(slot uint64, pubkey string, signedBlockData string, encoding *string) types.AttackerResponse {
+	return s.todoActionsWithSignedBlock(slot, pubkey, signedBlockData, encoding, "BlockAfterSign")
 }
 
-func (s *BlockAPI) BeforePropose(slot uint64, pubkey string, signedBlockDataBase64 string) types.AttackerResponse {
-	return s.todoActionsWithSignedBlock(slot, pubkey, signedBlockDataBase64, "BlockBeforePropose")
+func (s *BlockAPI) BeforePropose(slot uint64, pubkey string, signedBlockData string, encoding *string) types.AttackerResponse {
+	return s.todoActionsWithSignedBlock(slot, pubkey, signedBlockData, encoding, "BlockBeforePropose")
 }
 
-func (s *BlockAPI) AfterPropose(slot uint64, pubkey string, signedBlockDataBase64 string) types.AttackerResponse {
-	return s.todoActionsWithSignedBlock(slot, pubkey, signedBlockDataBase64, "BlockAfterPropose")
+func (s *BlockAPI) AfterPropose(slot uint64, pubkey string, signedBlockData string, encoding *string) types.AttackerResponse {
+	return s.todoActionsWithSignedBlock(slot, pubkey, signedBlockData, encoding, "BlockAfterPropose")
 }
 
-func (s *BlockAPI) todoActionsWithSlot(slot uint64, name string) types.AttackerResponse {
+func (s *BlockAPI) todoActionsWithSlot(slot uint64, pubkey string, name string) types.AttackerResponse {
+	start := time.Now()
+	if r, ok := replayed(name, slot, pubkey, "", start); ok {
+		return r
+	}
 	result := types.AttackerResponse{
 		Cmd: types.CMD_NULL,
 	}
 
 	if t, find := findMaxLevelStrategy(s.b.GetInternalSlotStrategy(), int64(slot)); find {
 		action := t.Actions[name]
-		if action != nil {
-			r := action.RunAction(s.b, int64(slot), "")
+		if action != nil && s.attackerProposer(name, slot, pubkey) {
+			r := action.RunAction(s.b, int64(slot), pubkey)
 			result.Cmd = r.Cmd
+			result.Decision = r.Decision
 		}
 	}
+	observe(name, slot, pubkey, "", start, result)
 	log.WithFields(log.Fields{
 		"cmd":    result.Cmd,
 		"slot":   slot,
@@ -105,39 +130,45 @@ func (s *BlockAPI) todoActionsWithSlot(slot uint64, name string) types.AttackerR
 	return result
 }
 
-func (s *BlockAPI) todoActionsWithSignedBlock(slot uint64, pubkey string, signedBlockDataBase64 string, name string) types.AttackerResponse {
-	signedDenebBlock, err := common.Base64ToSignedDenebBlock(signedBlockDataBase64)
-	if err != nil {
-		return types.AttackerResponse{
-			Cmd:    types.CMD_NULL,
-			Result: signedBlockDataBase64,
-		}
+// todoActionsWithSignedBlock runs the action with the signed block, the
+// optional encoding is proto (default), ssz or json with an optional fork,
+// e.g. "ssz:deneb", the result is encoded the same.
+func (s *BlockAPI) todoActionsWithSignedBlock(slot uint64, pubkey string, signedBlockData string, encoding *string, name string) types.AttackerResponse {
+	start := time.Now()
+	if r, ok := replayed(name, slot, pubkey, signedBlockData, start); ok {
+		return r
 	}
 	result := types.AttackerResponse{
 		Cmd:    types.CMD_NULL,
-		Result: signedBlockDataBase64,
+		Result: signedBlockData,
+	}
+	enc, fork, err := common.ParseEncoding(encoding)
+	if err != nil {
+		return decodeFailed(name, start, result, err)
+	}
+	signedBlock, err := common.DecodeSignedBlock(enc, fork, signedBlockData)
+	if err != nil {
+		return decodeFailed(name, start, result, err)
 	}
 
 	if t, find := findMaxLevelStrategy(s.b.GetInternalSlotStrategy(), int64(slot)); find {
 		action := t.Actions[name]
-		if action != nil {
-			//block, err := common.GetDenebBlockFromGenericSignedBlock()
-			//if err != nil {
-			//	log.WithError(err).WithField("slot", slot).Error("get block instance failed")
-			//	return result
-			//}
-			r := action.RunAction(s.b, int64(slot), pubkey, signedDenebBlock)
+		if action != nil && s.attackerProposer(name, slot, pubkey) {
+			r := action.RunAction(s.b, int64(slot), pubkey, signedBlock)
 			result.Cmd = r.Cmd
-			if newBlockBase64, err := common.SignedDenebBlockToBase64(signedDenebBlock); err != nil {
+			result.Decision = r.Decision
+			if newBlock, err := common.EncodeSignedBlock(enc, fork, signedBlock); err != nil {
 				log.WithError(err).WithFields(log.Fields{
 					"slot":   slot,
 					"action": name,
 				}).Error("marshal to block failed")
+				result.Error = err.Error()
 			} else {
-				result.Result = newBlockBase64
+				result.Result = newBlock
 			}
 		}
 	}
+	observe(name, slot, pubkey, signedBlockData, start, result)
 	log.WithFields(log.Fields{
 		"cmd":    result.Cmd,
 		"slot":   slot,
@@ -146,3 +177,35 @@ func (s *BlockAPI) todoActionsWithSignedBlock(slot uint64, pubkey string, signed
 
 	return result
 }
+
+// attackerProposer returns whether the block of the slot is proposed by an
+// attacker validator, the block actions are not applied to honest proposers.
+// The proposer is taken from the duties if the hook has no pubkey.
+func (s *BlockAPI) attackerProposer(name string, slot uint64, pubkey string) bool {
+	valIdx := -1
+	if pubkey != "" {
+		if val := s.b.GetValidatorDataSet().GetValidatorByPubkey(pubkey); val != nil {
+			valIdx = int(val.Index)
+		}
+	}
+	if valIdx < 0 {
+		idx, err := s.b.GetValidatorByProposeSlot(slot)
+		if err != nil {
+			log.WithError(err).WithFields(log.Fields{
+				"slot":   slot,
+				"action": name,
+			}).Warn("get slot proposer failed")
+			return false
+		}
+		valIdx = idx
+	}
+	if s.b.GetValidatorRole(int(slot), valIdx) != types.AttackerRole {
+		log.WithFields(log.Fields{
+			"slot":     slot,
+			"proposer": valIdx,
+			"action":   name,
+		}).Debug("skip action of honest proposer")
+		return false
+	}
+	return true
+}
@@ -0,0 +1,270 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	ethpb "github.com/prysmaticlabs/prysm/v5/proto/prysm/v1alpha1"
+	"github.com/tsinghua-cel/attacker-service/attackclient"
+	"github.com/tsinghua-cel/attacker-service/beaconapi"
+	"github.com/tsinghua-cel/attacker-service/common"
+	"github.com/tsinghua-cel/attacker-service/config"
+	"github.com/tsinghua-cel/attacker-service/decision"
+	"github.com/tsinghua-cel/attacker-service/rpc"
+	"github.com/tsinghua-cel/attacker-service/types"
+)
+
+// startTestServer starts the rpc of an in-process server, no beacon or
+// execute node is needed by the tested methods.
+func startTestServer(t *testing.T) (*Server, string) {
+	return startAuthTestServer(t, config.AuthConfig{})
+}
+
+func startAuthTestServer(t *testing.T, auth config.AuthConfig) (*Server, string) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := l.Addr().(*net.TCPAddr).Port
+	l.Close()
+
+	s := NewServer(&config.Config{
+		HttpHost:   "127.0.0.1",
+		HttpPort:   port,
+		BeaconRpc:  "127.0.0.1:1",
+		ExecuteRpc: "http://127.0.0.1:1",
+		Auth:       auth,
+	}, nil)
+	if err := s.startRPC(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(s.http.stop)
+	return s, fmt.Sprintf("127.0.0.1:%d", port)
+}
+
+// TestClientContract checks every method registered by the server has a
+// client method with the same arguments, run go generate in attackclient if it fails.
+func TestClientContract(t *testing.T) {
+	s, _ := startTestServer(t)
+	client := reflect.TypeOf(&attackclient.Client{})
+	ctxType := reflect.TypeOf((*context.Context)(nil)).Elem()
+	subType := reflect.TypeOf(&rpc.Subscription{})
+	for _, api := range s.rpcAPIs {
+		service := reflect.TypeOf(api.Service)
+		for i := 0; i < service.NumMethod(); i++ {
+			m := service.Method(i)
+			if m.Type.NumOut() > 0 && m.Type.Out(0) == subType {
+				continue
+			}
+			name := string(api.Namespace[0]-'a'+'A') + api.Namespace[1:] + m.Name
+			cm, exist := client.MethodByName(name)
+			if !exist {
+				t.Errorf("client method %s of %s_%s not found", name, api.Namespace, m.Name)
+				continue
+			}
+			var args []reflect.Type
+			for j := 1; j < m.Type.NumIn(); j++ {
+				if j == 1 && m.Type.In(j) == ctxType {
+					continue
+				}
+				args = append(args, m.Type.In(j))
+			}
+			// the client method has the receiver and the context in addition.
+			if cm.Type.NumIn() != len(args)+2 {
+				t.Errorf("client method %s has %d args, want %d", name, cm.Type.NumIn()-2, len(args))
+				continue
+			}
+			for j, arg := range args {
+				if cm.Type.In(j+2) != arg {
+					t.Errorf("client method %s arg %d is %v, want %v", name, j, cm.Type.In(j+2), arg)
+				}
+			}
+		}
+	}
+}
+
+func TestClientCalls(t *testing.T) {
+	_, addr := startTestServer(t)
+	ctx := context.Background()
+
+	c, err := attackclient.Dial("http://" + addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	// an undecodable payload is returned unchanged with the error.
+	res, err := c.AttestBeforeSign(ctx, 1, "", "invalid", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Cmd != types.CMD_NULL || res.Result != "invalid" || res.Error == "" {
+		t.Fatalf("unexpected response %+v", res)
+	}
+	data := &ethpb.AttestationData{
+		Slot:            1,
+		BeaconBlockRoot: make([]byte, 32),
+		Source:          &ethpb.Checkpoint{Root: make([]byte, 32)},
+		Target:          &ethpb.Checkpoint{Root: make([]byte, 32)},
+	}
+	for _, enc := range []common.Encoding{common.EncodingProto, common.EncodingSSZ, common.EncodingJSON} {
+		payload, err := common.EncodeAttestationData(enc, data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		encoding := string(enc)
+		res, err := c.AttestBeforeSign(ctx, 1, "", payload, &encoding)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Error != "" || res.Result != payload {
+			t.Fatalf("unexpected %s response %+v", enc, res)
+		}
+	}
+	// the blocks of other forks than deneb are returned unchanged with the error.
+	encoding := "ssz:capella"
+	if res, err = c.BlockAfterSign(ctx, 1, "", "0x00", &encoding); err != nil {
+		t.Fatal(err)
+	} else if res.Cmd != types.CMD_NULL || res.Result != "0x00" || !strings.Contains(res.Error, common.ErrUnsupportedFork.Error()) {
+		t.Fatalf("unexpected response %+v", res)
+	}
+	if res, err = c.BlockDelayForReceiveBlock(ctx, 1, nil); err != nil {
+		t.Fatal(err)
+	} else if res.Cmd != types.CMD_NULL {
+		t.Fatalf("unexpected response %+v", res)
+	}
+	if err := c.AdminSetRoleNormal(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	ws, err := attackclient.Dial("ws://" + addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ws.Close()
+	ch := make(chan decision.Decision, 1)
+	sub, err := ws.SubscribeDecisions(ctx, ch, []string{"0xaa"}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Unsubscribe()
+	decision.Publish(decision.Decision{Kind: decision.KindWithhold, Slot: 2, Pubkey: "bb"})
+	decision.Publish(decision.Decision{Kind: decision.KindWithhold, Slot: 3, Pubkey: "aa"})
+	select {
+	case d := <-ch:
+		if d.Slot != 3 || d.Kind != decision.KindWithhold {
+			t.Fatalf("unexpected decision %+v", d)
+		}
+	case err := <-sub.Err():
+		t.Fatal(err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("decision not received")
+	}
+}
+
+func TestHeldDelay(t *testing.T) {
+	_, addr := startTestServer(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	ws, err := attackclient.Dial("ws://" + addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ws.Close()
+	if _, err := ws.SubscribeDecisions(ctx, make(chan decision.Decision), nil, true); err == nil {
+		t.Fatal("hold of all validators is subscribed")
+	}
+	ch := make(chan decision.Decision, 2)
+	sub, err := ws.SubscribeDecisions(ctx, ch, []string{"0xcc"}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Unsubscribe()
+
+	released := make(chan struct{})
+	start := time.Now()
+	id := decision.Delay("delayWithSecond", 4, "cc", 200*time.Millisecond, func() { close(released) })
+	if id == "" || time.Since(start) >= 200*time.Millisecond {
+		t.Fatalf("delay of a held validator blocked, id %q", id)
+	}
+	for _, kind := range []decision.Kind{decision.KindDelay, decision.KindRelease} {
+		select {
+		case d := <-ch:
+			if d.Kind != kind || d.ID != id {
+				t.Fatalf("unexpected decision %+v, want %s of %s", d, kind, id)
+			}
+		case err := <-sub.Err():
+			t.Fatal(err)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("%s decision not received", kind)
+		}
+	}
+	<-released
+
+	// the delays of other validators still block the action point.
+	if id := decision.Delay("delayWithSecond", 4, "dd", 0, func() {}); id != "" {
+		t.Fatalf("delay of a not held validator returned id %q", id)
+	}
+}
+
+// TestProduceBlockParentRoot checks the parent root of ProduceBlock is returned
+// to the BlockGetNewParentRoot hook of the beacon node producing the block.
+func TestProduceBlockParentRoot(t *testing.T) {
+	s, addr := startTestServer(t)
+	c, err := attackclient.Dial("http://" + addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	parents := make(chan string, 1)
+	beacon := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, "/eth/v3/validator/blocks/12") {
+			http.NotFound(w, r)
+			return
+		}
+		// the beacon node calls the hook with the hex root of its head.
+		res, err := c.BlockGetNewParentRoot(r.Context(), 12, "", strings.Repeat("09", 32))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		parents <- res.Result
+		http.Error(w, "no block", http.StatusInternalServerError)
+	}))
+	defer beacon.Close()
+	s.beaconClient = beaconapi.NewBeaconGwClient(beacon.URL)
+
+	grandparent := make([]byte, 32)
+	grandparent[31] = 0x08
+	if _, err := s.ProduceBlock(12, make([]byte, 96), nil, grandparent); err == nil {
+		t.Fatal("produced a block from a failing beacon node")
+	}
+	select {
+	case parent := <-parents:
+		if want := strings.Repeat("00", 31) + "08"; parent != want {
+			t.Fatalf("hook returned parent %s, want %s", parent, want)
+		}
+	default:
+		t.Fatal("the beacon node did not call the hook")
+	}
+	if _, ok := s.ParentRootOverride(12); ok {
+		t.Fatal("the parent root is kept after the block is produced")
+	}
+	// without override the hook keeps the parent of the beacon node.
+	res, err := c.BlockGetNewParentRoot(context.Background(), 12, "", strings.Repeat("09", 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Result != strings.Repeat("09", 32) {
+		t.Fatalf("hook returned parent %s without override", res.Result)
+	}
+}
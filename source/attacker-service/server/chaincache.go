@@ -0,0 +1,204 @@
+package server
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/tsinghua-cel/attacker-service/beaconapi"
+	"github.com/tsinghua-cel/attacker-service/types"
+)
+
+const (
+	// keepDutyEpochs is the number of past epochs whose duties are kept.
+	keepDutyEpochs = 4
+	// specRetryInterval is the retry interval of loading the chain spec.
+	specRetryInterval = 5 * time.Second
+)
+
+// chainCache holds the chain spec and the proposer duties, the spec is loaded
+// at startup and the duties of the current and next epoch are refreshed at
+// every epoch start, so the hooks don't need to query the beacon node.
+type chainCache struct {
+	client *beaconapi.BeaconGwClient
+
+	mu     sync.RWMutex
+	spec   *types.ChainSpec
+	duties map[int64][]types.ProposerDuty
+	// reorgs is the max depth of the observed reorgs by epoch.
+	reorgs map[int64]int64
+	// onEpoch is called after the duties are refreshed at every epoch start.
+	onEpoch func(epoch int64)
+}
+
+func newChainCache(client *beaconapi.BeaconGwClient) *chainCache {
+	return &chainCache{
+		client: client,
+		duties: make(map[int64][]types.ProposerDuty),
+		reorgs: make(map[int64]int64),
+	}
+}
+
+func (c *chainCache) loadSpec() error {
+	slotsPerEpoch, err := c.client.GetIntConfig(beaconapi.SLOTS_PER_EPOCH)
+	if err != nil {
+		return err
+	}
+	secondsPerSlot, err := c.client.GetIntConfig(beaconapi.SECONDS_PER_SLOT)
+	if err != nil {
+		return err
+	}
+	if slotsPerEpoch <= 0 || secondsPerSlot <= 0 {
+		return fmt.Errorf("invalid chain spec slots_per_epoch:%d seconds_per_slot:%d", slotsPerEpoch, secondsPerSlot)
+	}
+	genesis, err := c.client.GetGenesis()
+	if err != nil {
+		return err
+	}
+	genesisTime, err := strconv.ParseInt(genesis.GenesisTime, 10, 64)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	c.spec = &types.ChainSpec{
+		SlotsPerEpoch:  slotsPerEpoch,
+		SecondsPerSlot: secondsPerSlot,
+		GenesisTime:    genesisTime,
+	}
+	c.mu.Unlock()
+	log.WithFields(log.Fields{
+		"slotsPerEpoch":  slotsPerEpoch,
+		"secondsPerSlot": secondsPerSlot,
+		"genesisTime":    genesisTime,
+	}).Info("chain spec loaded")
+	return nil
+}
+
+// waitSpec blocks until the chain spec is loaded.
+func (c *chainCache) waitSpec() {
+	for {
+		err := c.loadSpec()
+		if err == nil {
+			return
+		}
+		log.WithError(err).Error("load chain spec failed, retry later")
+		time.Sleep(specRetryInterval)
+	}
+}
+
+// Spec returns the chain spec or ErrChainSpecUnavailable if it is not loaded yet.
+func (c *chainCache) Spec() (types.ChainSpec, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.spec == nil {
+		return types.ChainSpec{}, types.ErrChainSpecUnavailable
+	}
+	return *c.spec, nil
+}
+
+// ProposerDuties returns the proposer duties of the epoch, they are fetched
+// from the beacon node if not cached.
+func (c *chainCache) ProposerDuties(epoch int64) ([]types.ProposerDuty, error) {
+	c.mu.RLock()
+	duties, exist := c.duties[epoch]
+	c.mu.RUnlock()
+	if exist {
+		return duties, nil
+	}
+	return c.fetchDuties(epoch)
+}
+
+func (c *chainCache) fetchDuties(epoch int64) ([]types.ProposerDuty, error) {
+	duties, err := c.client.GetProposerDuties(int(epoch))
+	if err != nil || len(duties) == 0 {
+		log.WithError(err).WithField("epoch", epoch).Warn("fetch proposer duties failed")
+		return nil, fmt.Errorf("%w: epoch %d", types.ErrDutiesUnavailable, epoch)
+	}
+	c.mu.Lock()
+	c.duties[epoch] = duties
+	c.mu.Unlock()
+	return duties, nil
+}
+
+// refresh fetches the duties of the current and the next epoch again, and
+// drops the duties of old epochs.
+func (c *chainCache) refresh() {
+	spec, err := c.Spec()
+	if err != nil {
+		return
+	}
+	epoch := spec.CurrentEpoch()
+	c.mu.Lock()
+	for e := range c.duties {
+		if e < epoch-keepDutyEpochs {
+			delete(c.duties, e)
+		}
+	}
+	for e := range c.reorgs {
+		if e < epoch-keepDutyEpochs {
+			delete(c.reorgs, e)
+		}
+	}
+	c.mu.Unlock()
+	c.fetchDuties(epoch)
+	c.fetchDuties(epoch + 1)
+	if c.onEpoch != nil {
+		c.onEpoch(epoch)
+	}
+}
+
+// reorgObserved records the depth of a reorg in the epoch.
+func (c *chainCache) reorgObserved(epoch int64, depth int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if depth > c.reorgs[epoch] {
+		c.reorgs[epoch] = depth
+	}
+}
+
+// MaxReorgDepth returns the max depth of the reorgs observed from fromEpoch to toEpoch.
+func (c *chainCache) MaxReorgDepth(fromEpoch, toEpoch int64) int64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	depth := int64(0)
+	for e := fromEpoch; e <= toEpoch; e++ {
+		if c.reorgs[e] > depth {
+			depth = c.reorgs[e]
+		}
+	}
+	return depth
+}
+
+// invalidate drops the cached duties of the current and later epochs, it is
+// called on reorg since the duties depend on the head.
+func (c *chainCache) invalidate() {
+	spec, err := c.Spec()
+	if err != nil {
+		return
+	}
+	epoch := spec.CurrentEpoch()
+	c.mu.Lock()
+	for e := range c.duties {
+		if e >= epoch {
+			delete(c.duties, e)
+		}
+	}
+	c.mu.Unlock()
+}
+
+// run refreshes the duties at the start of every epoch.
+func (c *chainCache) run() {
+	c.refresh()
+	for {
+		spec, err := c.Spec()
+		if err != nil {
+			time.Sleep(specRetryInterval)
+			continue
+		}
+		next := spec.SlotStartTime((spec.CurrentEpoch() + 1) * int64(spec.SlotsPerEpoch))
+		time.Sleep(time.Until(time.Unix(next, 0)))
+		c.refresh()
+	}
+}
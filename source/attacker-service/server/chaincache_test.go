@@ -0,0 +1,166 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/tsinghua-cel/attacker-service/beaconapi"
+	"github.com/tsinghua-cel/attacker-service/types"
+)
+
+// fakeBeacon serves the spec, the genesis and the proposer duties, and
+// counts the duty requests by epoch.
+type fakeBeacon struct {
+	slotsPerEpoch string
+	genesisTime   int64
+
+	mu       sync.Mutex
+	requests map[string]int
+}
+
+func (f *fakeBeacon) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.URL.Path == "/eth/v1/config/spec":
+		fmt.Fprintf(w, `{"data":{"SLOTS_PER_EPOCH":"%s","SECONDS_PER_SLOT":"12"}}`, f.slotsPerEpoch)
+	case r.URL.Path == "/eth/v1/beacon/genesis":
+		fmt.Fprintf(w, `{"data":{"genesis_time":"%d"}}`, f.genesisTime)
+	case strings.HasPrefix(r.URL.Path, "/eth/v1/validator/duties/proposer/"):
+		epoch := strings.TrimPrefix(r.URL.Path, "/eth/v1/validator/duties/proposer/")
+		f.mu.Lock()
+		f.requests[epoch]++
+		f.mu.Unlock()
+		if epoch == "99" {
+			fmt.Fprint(w, `{"data":[]}`)
+			return
+		}
+		fmt.Fprintf(w, `{"data":[{"pubkey":"0xaa","validator_index":"1","slot":"%s"}]}`, epoch)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (f *fakeBeacon) dutyRequests(epoch int64) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.requests[fmt.Sprint(epoch)]
+}
+
+func newTestChainCache(t *testing.T, slotsPerEpoch string) (*chainCache, *fakeBeacon) {
+	f := &fakeBeacon{
+		slotsPerEpoch: slotsPerEpoch,
+		// the wall clock is in epoch 10.
+		genesisTime: time.Now().Unix() - 10*32*12 - 6,
+		requests:    make(map[string]int),
+	}
+	srv := httptest.NewServer(f)
+	t.Cleanup(srv.Close)
+	return newChainCache(beaconapi.NewBeaconGwClient(srv.URL)), f
+}
+
+func TestChainCacheSpec(t *testing.T) {
+	c, f := newTestChainCache(t, "32")
+	if _, err := c.Spec(); !errors.Is(err, types.ErrChainSpecUnavailable) {
+		t.Fatalf("spec before loading, err %v", err)
+	}
+	if err := c.loadSpec(); err != nil {
+		t.Fatal(err)
+	}
+	spec, err := c.Spec()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := types.ChainSpec{SlotsPerEpoch: 32, SecondsPerSlot: 12, GenesisTime: f.genesisTime}
+	if spec != want {
+		t.Fatalf("spec %+v, want %+v", spec, want)
+	}
+
+	c, _ = newTestChainCache(t, "0")
+	if err := c.loadSpec(); err == nil {
+		t.Fatal("invalid spec is loaded")
+	}
+}
+
+func TestChainCacheDuties(t *testing.T) {
+	c, f := newTestChainCache(t, "32")
+	for i := 0; i < 2; i++ {
+		duties, err := c.ProposerDuties(3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(duties) != 1 || duties[0].Slot != "3" {
+			t.Fatalf("unexpected duties %+v", duties)
+		}
+	}
+	if n := f.dutyRequests(3); n != 1 {
+		t.Fatalf("duties fetched %d times, want once", n)
+	}
+	if _, err := c.ProposerDuties(99); !errors.Is(err, types.ErrDutiesUnavailable) {
+		t.Fatalf("empty duties, err %v", err)
+	}
+}
+
+func TestChainCacheRefresh(t *testing.T) {
+	c, f := newTestChainCache(t, "32")
+	if err := c.loadSpec(); err != nil {
+		t.Fatal(err)
+	}
+	// epoch 10 is the current one, epochs before 6 are dropped.
+	for _, epoch := range []int64{5, 6, 10, 11} {
+		c.duties[epoch] = nil
+		c.reorgObserved(epoch, 1)
+	}
+	var refreshed int64 = -1
+	c.onEpoch = func(epoch int64) { refreshed = epoch }
+	c.refresh()
+	if refreshed != 10 {
+		t.Fatalf("refreshed epoch %d, want 10", refreshed)
+	}
+	if _, exist := c.duties[5]; exist {
+		t.Fatal("duties of epoch 5 are kept")
+	}
+	if _, exist := c.duties[6]; !exist {
+		t.Fatal("duties of epoch 6 are dropped")
+	}
+	if f.dutyRequests(10) != 1 || f.dutyRequests(11) != 1 || len(c.duties[10]) != 1 || len(c.duties[11]) != 1 {
+		t.Fatal("duties of the current and next epoch are not fetched again")
+	}
+	if depth := c.MaxReorgDepth(0, 5); depth != 0 {
+		t.Fatalf("reorgs of epoch 5 are kept, depth %d", depth)
+	}
+
+	c.invalidate()
+	if _, exist := c.duties[10]; exist {
+		t.Fatal("duties of the current epoch are kept on invalidate")
+	}
+	if _, exist := c.duties[6]; !exist {
+		t.Fatal("duties of a past epoch are dropped on invalidate")
+	}
+}
+
+func TestChainCacheReorgDepth(t *testing.T) {
+	c, _ := newTestChainCache(t, "32")
+	c.reorgObserved(2, 1)
+	c.reorgObserved(2, 3)
+	c.reorgObserved(2, 2)
+	c.reorgObserved(4, 2)
+	tests := []struct {
+		from, to int64
+		want     int64
+	}{
+		{0, 1, 0},
+		{0, 2, 3},
+		{3, 4, 2},
+		{0, 10, 3},
+	}
+	for _, tt := range tests {
+		if depth := c.MaxReorgDepth(tt.from, tt.to); depth != tt.want {
+			t.Errorf("depth of epochs %d-%d is %d, want %d", tt.from, tt.to, depth, tt.want)
+		}
+	}
+}
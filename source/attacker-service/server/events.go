@@ -0,0 +1,81 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	// eventRetryMin and eventRetryMax bound the backoff of resubscribing a
+	// dropped event stream.
+	eventRetryMin = time.Second
+	eventRetryMax = time.Minute
+	// endpointCheckInterval is the interval of checking the beacon endpoint
+	// in use, the event stream follows it when the client fails over.
+	endpointCheckInterval = time.Minute
+)
+
+var errEndpointChanged = errors.New("beacon endpoint changed")
+
+// followEvents keeps the event stream of the beacon endpoint in use subscribed
+// until ctx is done. A dropped stream is resubscribed with an exponential
+// backoff, which is reset once a stream stayed up for eventRetryMax.
+func followEvents(ctx context.Context, endpoint func() string, stream func(ctx context.Context, endpoint string) error) {
+	retry := eventRetryMin
+	for ctx.Err() == nil {
+		subscribed := endpoint()
+		start := time.Now()
+		err := streamEndpoint(ctx, subscribed, endpoint, stream)
+		if ctx.Err() != nil {
+			return
+		}
+		if errors.Is(err, errEndpointChanged) {
+			log.WithField("endpoint", subscribed).Info("beacon endpoint changed, resubscribe event")
+			retry = eventRetryMin
+			continue
+		}
+		if time.Since(start) >= eventRetryMax {
+			retry = eventRetryMin
+		}
+		log.WithError(err).WithFields(log.Fields{
+			"endpoint": subscribed,
+			"retry":    retry,
+		}).Warn("event stream dropped, resubscribe later")
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(retry):
+		}
+		if retry *= 2; retry > eventRetryMax {
+			retry = eventRetryMax
+		}
+	}
+}
+
+// streamEndpoint runs the stream of the endpoint until it drops, or until the
+// endpoint in use changes.
+func streamEndpoint(ctx context.Context, subscribed string, endpoint func() string, stream func(ctx context.Context, endpoint string) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		done <- stream(ctx, subscribed)
+	}()
+	ticker := time.NewTicker(endpointCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case err := <-done:
+			return err
+		case <-ticker.C:
+			if endpoint() != subscribed {
+				cancel()
+				<-done
+				return errEndpointChanged
+			}
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func setEventTimings(t *testing.T, retryMin, retryMax, check time.Duration) {
+	oldMin, oldMax, oldCheck := eventRetryMin, eventRetryMax, endpointCheckInterval
+	eventRetryMin, eventRetryMax, endpointCheckInterval = retryMin, retryMax, check
+	t.Cleanup(func() {
+		eventRetryMin, eventRetryMax, endpointCheckInterval = oldMin, oldMax, oldCheck
+	})
+}
+
+func TestFollowEventsBackoff(t *testing.T) {
+	setEventTimings(t, 20*time.Millisecond, 80*time.Millisecond, time.Minute)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var calls []time.Time
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		followEvents(ctx, func() string { return "a" }, func(ctx context.Context, endpoint string) error {
+			calls = append(calls, time.Now())
+			if len(calls) == 5 {
+				cancel()
+			}
+			return errors.New("stream dropped")
+		})
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("stream is not resubscribed")
+	}
+	if len(calls) != 5 {
+		t.Fatalf("subscribed %d times, want 5", len(calls))
+	}
+	// the retry doubles up to the max.
+	for i, want := range []time.Duration{20, 40, 80, 80} {
+		want *= time.Millisecond
+		if gap := calls[i+1].Sub(calls[i]); gap < want {
+			t.Errorf("retry %d after %v, want at least %v", i, gap, want)
+		}
+	}
+}
+
+func TestFollowEventsEndpointChange(t *testing.T) {
+	setEventTimings(t, time.Minute, time.Minute, 5*time.Millisecond)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var active atomic.Value
+	active.Store("a")
+	var (
+		mu         sync.Mutex
+		subscribed []string
+	)
+	second := make(chan struct{})
+	done := make(chan struct{})
+	defer func() {
+		cancel()
+		<-done
+	}()
+	go func() {
+		defer close(done)
+		followEvents(ctx, func() string { return active.Load().(string) }, func(ctx context.Context, endpoint string) error {
+			mu.Lock()
+			subscribed = append(subscribed, endpoint)
+			if len(subscribed) == 2 {
+				close(second)
+			}
+			mu.Unlock()
+			<-ctx.Done()
+			return ctx.Err()
+		})
+	}()
+	time.Sleep(20 * time.Millisecond)
+	active.Store("b")
+	select {
+	case <-second:
+	case <-time.After(5 * time.Second):
+		t.Fatal("stream does not follow the endpoint in use")
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if subscribed[0] != "a" || subscribed[1] != "b" {
+		t.Fatalf("subscribed %v, want [a b]", subscribed)
+	}
+}
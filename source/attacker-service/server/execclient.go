@@ -0,0 +1,116 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	ethtype "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	log "github.com/sirupsen/logrus"
+	"github.com/tsinghua-cel/attacker-service/common"
+)
+
+const execRequestTimeout = 10 * time.Second
+
+// execClient wraps the ethclients of all execute endpoints, the clients are
+// dialed lazily and requests fail over to the next endpoint on error.
+type execClient struct {
+	endpoints *common.Endpoints
+
+	mu      sync.Mutex
+	clients map[string]*ethclient.Client
+}
+
+func newExecClient(endpoints ...string) *execClient {
+	return &execClient{
+		endpoints: common.NewEndpoints(endpoints...),
+		clients:   make(map[string]*ethclient.Client),
+	}
+}
+
+func (e *execClient) client(endpoint string) (*ethclient.Client, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if c, exist := e.clients[endpoint]; exist {
+		return c, nil
+	}
+	c, err := ethclient.Dial(endpoint)
+	if err != nil {
+		log.WithError(err).WithField("endpoint", endpoint).Warn("dial execute failed")
+		return nil, err
+	}
+	e.clients[endpoint] = c
+	return c, nil
+}
+
+func (e *execClient) try(fn func(ctx context.Context, c *ethclient.Client) error) error {
+	return e.endpoints.Try(func(endpoint string) error {
+		c, err := e.client(endpoint)
+		if err != nil {
+			return err
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), execRequestTimeout)
+		defer cancel()
+		err = fn(ctx, c)
+		if errors.Is(err, ethereum.NotFound) {
+			return common.RequestError(err)
+		}
+		return err
+	})
+}
+
+// CheckHealth updates the health of all endpoints.
+func (e *execClient) CheckHealth() {
+	for _, endpoint := range e.endpoints.All() {
+		c, err := e.client(endpoint)
+		if err == nil {
+			ctx, cancel := context.WithTimeout(context.Background(), execRequestTimeout)
+			_, err = c.BlockNumber(ctx)
+			cancel()
+		}
+		if err != nil {
+			log.WithError(err).WithField("endpoint", endpoint).Warn("execute endpoint unhealthy")
+		}
+		e.endpoints.SetHealth(endpoint, err == nil)
+	}
+}
+
+// StartHealthCheck checks the health of the endpoints every interval.
+func (e *execClient) StartHealthCheck(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			e.CheckHealth()
+			<-ticker.C
+		}
+	}()
+}
+
+func (e *execClient) BlockNumber() (number uint64, err error) {
+	err = e.try(func(ctx context.Context, c *ethclient.Client) error {
+		number, err = c.BlockNumber(ctx)
+		return err
+	})
+	return number, err
+}
+
+func (e *execClient) BlockByNumber(number *big.Int) (block *ethtype.Block, err error) {
+	err = e.try(func(ctx context.Context, c *ethclient.Client) error {
+		block, err = c.BlockByNumber(ctx, number)
+		return err
+	})
+	return block, err
+}
+
+func (e *execClient) HeaderByNumber(number *big.Int) (header *ethtype.Header, err error) {
+	err = e.try(func(ctx context.Context, c *ethclient.Client) error {
+		header, err = c.HeaderByNumber(ctx, number)
+		return err
+	})
+	return header, err
+}
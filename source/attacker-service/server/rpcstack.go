@@ -1,8 +1,10 @@
 package server
 
 import (
+	"bytes"
 	"compress/gzip"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net"
@@ -16,9 +18,14 @@ import (
 
 	"github.com/rs/cors"
 	log "github.com/sirupsen/logrus"
+	"github.com/tsinghua-cel/attacker-service/auth"
 	"github.com/tsinghua-cel/attacker-service/rpc"
 )
 
+// maxRequestContentLength is the limit of the body read by the auth handler,
+// it matches the limit of the rpc package.
+const maxRequestContentLength = 1024 * 1024 * 32
+
 // httpConfig is the JSON-RPC/HTTP configuration.
 type httpConfig struct {
 	Modules            []string
@@ -37,7 +44,8 @@ type wsConfig struct {
 }
 
 type rpcEndpointConfig struct {
-	jwtSecret              []byte // optional JWT secret
+	auth                   *auth.Authenticator // optional, nil disables authentication
+	protectedModules       []string            // modules that need authentication
 	batchItemLimit         int
 	batchResponseSizeLimit int
 }
@@ -293,7 +301,7 @@ func (h *httpServer) enableRPC(apis []rpc.API, config httpConfig) error {
 	}
 	h.httpConfig = config
 	h.httpHandler.Store(&rpcHandler{
-		Handler: NewHTTPHandlerStack(srv, config.CorsAllowedOrigins, config.Vhosts, config.jwtSecret),
+		Handler: NewHTTPHandlerStack(srv, config.CorsAllowedOrigins, config.Vhosts, config.auth, config.protectedModules),
 		server:  srv,
 	})
 	return nil
@@ -323,9 +331,10 @@ func (h *httpServer) enableWS(apis []rpc.API, config wsConfig) error {
 	if err := RegisterApis(apis, config.Modules, srv); err != nil {
 		return err
 	}
+	srv.SetWebsocketFilter(newWSCallFilter(config.auth, config.protectedModules))
 	h.wsConfig = config
 	h.wsHandler.Store(&rpcHandler{
-		Handler: NewWSHandlerStack(srv.WebsocketHandler(config.Origins), config.jwtSecret),
+		Handler: srv.WebsocketHandler(config.Origins),
 		server:  srv,
 	})
 	return nil
@@ -370,16 +379,113 @@ func isWebsocket(r *http.Request) bool {
 }
 
 // NewHTTPHandlerStack returns wrapped http-related handlers
-func NewHTTPHandlerStack(srv http.Handler, cors []string, vhosts []string, jwtSecret []byte) http.Handler {
+func NewHTTPHandlerStack(srv http.Handler, cors []string, vhosts []string, a *auth.Authenticator, protected []string) http.Handler {
 	// Wrap the CORS-handler within a host-handler
-	handler := newCorsHandler(srv, cors)
+	handler := newAuthHandler(a, protected, srv)
+	handler = newCorsHandler(handler, cors)
 	handler = newVHostHandler(vhosts, handler)
 	return newGzipHandler(handler)
 }
 
-// NewWSHandlerStack returns a wrapped ws-related handler.
-func NewWSHandlerStack(srv http.Handler, jwtSecret []byte) http.Handler {
-	return srv
+// authHandler authenticates the requests that call a method of the protected
+// modules, calls to other modules are served without authentication.
+type authHandler struct {
+	auth      *auth.Authenticator
+	protected map[string]struct{}
+	next      http.Handler
+}
+
+func newAuthHandler(a *auth.Authenticator, protected []string, next http.Handler) http.Handler {
+	if !a.Enabled() || len(protected) == 0 {
+		return next
+	}
+	return &authHandler{auth: a, protected: protectedMap(protected), next: next}
+}
+
+func protectedMap(protected []string) map[string]struct{} {
+	m := make(map[string]struct{})
+	for _, module := range protected {
+		m[module] = struct{}{}
+	}
+	return m
+}
+
+// isProtected returns true if the method belongs to a protected module.
+func isProtected(protected map[string]struct{}, method string) bool {
+	module, _, _ := strings.Cut(method, "_")
+	_, exist := protected[module]
+	return exist
+}
+
+// ServeHTTP serves JSON-RPC requests over HTTP, implements http.Handler
+func (h *authHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.next.ServeHTTP(w, r)
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxRequestContentLength))
+	r.Body.Close()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	if h.needAuth(body) {
+		if err := h.auth.Check(r); err != nil {
+			h.unauthorized(w, err)
+			return
+		}
+	}
+	h.next.ServeHTTP(w, r)
+}
+
+// needAuth returns true if any message of the (batch) request calls a protected
+// module, or the body can not be parsed.
+func (h *authHandler) needAuth(body []byte) bool {
+	var msgs []struct {
+		Method string `json:"method"`
+	}
+	body = bytes.TrimSpace(body)
+	if len(body) > 0 && body[0] != '[' {
+		body = append(append([]byte{'['}, body...), ']')
+	}
+	if err := json.Unmarshal(body, &msgs); err != nil {
+		return true
+	}
+	for _, msg := range msgs {
+		if isProtected(h.protected, msg.Method) {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *authHandler) unauthorized(w http.ResponseWriter, err error) {
+	log.WithError(err).Warn("unauthorized rpc request")
+	http.Error(w, err.Error(), http.StatusUnauthorized)
+}
+
+// newWSCallFilter authenticates the websocket upgrade request. Like the http
+// requests, the calls to other modules are served without authentication and
+// the calls to the protected modules fail on a connection not authenticated.
+func newWSCallFilter(a *auth.Authenticator, protected []string) func(r *http.Request) rpc.CallFilter {
+	if !a.Enabled() || len(protected) == 0 {
+		return nil
+	}
+	modules := protectedMap(protected)
+	return func(r *http.Request) rpc.CallFilter {
+		authErr := a.Check(r)
+		if authErr == nil {
+			return nil
+		}
+		return func(method string) error {
+			if !isProtected(modules, method) {
+				return nil
+			}
+			log.WithError(authErr).WithField("method", method).Warn("unauthorized ws request")
+			return fmt.Errorf("unauthorized: %w", authErr)
+		}
+	}
 }
 
 func newCorsHandler(srv http.Handler, allowedOrigins []string) http.Handler {
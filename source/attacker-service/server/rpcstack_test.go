@@ -0,0 +1,46 @@
+package server
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/tsinghua-cel/attacker-service/attackclient"
+	"github.com/tsinghua-cel/attacker-service/config"
+	"github.com/tsinghua-cel/attacker-service/rpc"
+)
+
+func TestWSAuth(t *testing.T) {
+	_, addr := startAuthTestServer(t, config.AuthConfig{ApiKey: "secret"})
+	ctx := context.Background()
+
+	tests := []struct {
+		name      string
+		options   []rpc.ClientOption
+		wantAdmin bool
+	}{
+		{name: "unauthenticated"},
+		{name: "invalid key", options: []rpc.ClientOption{attackclient.WithApiKey("wrong")}},
+		{name: "authenticated", options: []rpc.ClientOption{attackclient.WithApiKey("secret")}, wantAdmin: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := attackclient.Dial("ws://"+addr, tt.options...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer c.Close()
+			// the hooks are served without authentication.
+			if _, err := c.BlockDelayForReceiveBlock(ctx, 1, nil); err != nil {
+				t.Fatalf("block call failed: %v", err)
+			}
+			err = c.AdminSetRoleNormal(ctx, 1)
+			if tt.wantAdmin && err != nil {
+				t.Fatalf("admin call failed: %v", err)
+			}
+			if !tt.wantAdmin && (err == nil || !strings.Contains(err.Error(), "unauthorized")) {
+				t.Fatalf("admin call not rejected, err %v", err)
+			}
+		})
+	}
+}
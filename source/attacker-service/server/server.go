@@ -6,37 +6,54 @@ import (
 	"fmt"
 	apiv1 "github.com/attestantio/go-eth2-client/api/v1"
 	ethtype "github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/golang/groupcache/lru"
 	ethpb "github.com/prysmaticlabs/prysm/v5/proto/prysm/v1alpha1"
 	log "github.com/sirupsen/logrus"
+	"github.com/tsinghua-cel/attacker-service/auth"
 	"github.com/tsinghua-cel/attacker-service/beaconapi"
 	"github.com/tsinghua-cel/attacker-service/config"
 	"github.com/tsinghua-cel/attacker-service/dbmodel"
+	"github.com/tsinghua-cel/attacker-service/evaluator"
+	"github.com/tsinghua-cel/attacker-service/metrics"
 	"github.com/tsinghua-cel/attacker-service/openapi"
 	"github.com/tsinghua-cel/attacker-service/plugins"
+	"github.com/tsinghua-cel/attacker-service/proxy"
 	"github.com/tsinghua-cel/attacker-service/rpc"
 	"github.com/tsinghua-cel/attacker-service/server/apis"
+	"github.com/tsinghua-cel/attacker-service/signer"
 	"github.com/tsinghua-cel/attacker-service/strategy"
 	"github.com/tsinghua-cel/attacker-service/strategy/slotstrategy"
+	"github.com/tsinghua-cel/attacker-service/trace"
 	"github.com/tsinghua-cel/attacker-service/types"
 	"math/big"
+	"sync"
 	"strconv"
 	"time"
 )
 
+// endpointHealthInterval is the interval of the beacon and execute endpoint health checks.
+const endpointHealthInterval = 30 * time.Second
+
 type Server struct {
 	config       *config.Config
 	rpcAPIs      []rpc.API   // List of APIs currently provided by the node
 	http         *httpServer //
 	strategy     *types.Strategy
 	internal     []slotstrategy.InternalSlotStrategy
-	execClient   *ethclient.Client
+	execClient   *execClient
 	beaconClient *beaconapi.BeaconGwClient
+	chain        *chainCache
 
 	validatorSetInfo *types.ValidatorDataSet
 	openApi          *openapi.OpenAPI
 	cache            *lru.Cache
+	auth             *auth.Authenticator
+	proxy            *proxy.Proxy
+	signer           *signer.Signer
+	evaluator        *evaluator.Evaluator
+
+	parentMu    sync.Mutex
+	parentRoots map[uint64][]byte // parent roots of the blocks being produced by ProduceBlock
 }
 
 func (n *Server) GetBlockBySlot(slot uint64) (interface{}, error) {
@@ -52,16 +69,39 @@ func NewServer(conf *config.Config, plugin plugins.AttackerPlugin) *Server {
 	s.cache = lru.New(10000)
 	s.config = conf
 	s.rpcAPIs = apis.GetAPIs(s, plugin)
-	client, err := ethclient.Dial(conf.ExecuteRpc)
-	if err != nil {
-		panic(fmt.Sprintf("dial execute failed with err:%v", err))
-	}
-	s.execClient = client
-	s.beaconClient = beaconapi.NewBeaconGwClient(conf.BeaconRpc)
+	s.execClient = newExecClient(conf.ExecuteEndpoints()...)
+	s.beaconClient = beaconapi.NewBeaconGwClient(conf.BeaconEndpoints()...)
+	s.chain = newChainCache(s.beaconClient)
 	s.http = newHTTPServer(log.WithField("module", "server"), rpc.DefaultHTTPTimeouts)
+	if conf.Trace.Seed != 0 {
+		slotstrategy.SetSeed(conf.Trace.Seed)
+	}
+	if err := trace.Init(conf.Trace.Record, conf.Trace.Replay); err != nil {
+		panic(fmt.Sprintf("init trace failed with err:%v", err))
+	}
 	s.strategy = strategy.ParseStrategy(s, conf.Strategy)
 	s.validatorSetInfo = types.NewValidatorSet()
-	s.openApi = openapi.NewOpenAPI(s, conf)
+	var err error
+	s.auth, err = auth.NewAuthenticator(conf.Auth)
+	if err != nil {
+		panic(fmt.Sprintf("init auth failed with err:%v", err))
+	}
+	s.signer, err = signer.NewSigner(conf.Signer, s.beaconClient, s.chain.Spec)
+	if err != nil {
+		panic(fmt.Sprintf("init signer failed with err:%v", err))
+	}
+	s.evaluator = evaluator.NewEvaluator(s, conf.Evaluator.Label)
+	s.openApi = openapi.NewOpenAPI(s, conf, s.auth)
+	if conf.Proxy.Port > 0 {
+		upstream := conf.Proxy.Upstream
+		if upstream == "" {
+			upstream = conf.BeaconRpc
+		}
+		s.proxy, err = proxy.NewProxy(upstream, apis.NewAttestAPI(s, plugin), apis.NewBlockAPI(s, plugin), s.validatorSetInfo)
+		if err != nil {
+			panic(fmt.Sprintf("init proxy failed with err:%v", err))
+		}
+	}
 	return s
 }
 
@@ -77,6 +117,8 @@ func (n *Server) startRPC() error {
 	rpcConfig := rpcEndpointConfig{
 		batchItemLimit:         config.APIBatchItemLimit,
 		batchResponseSizeLimit: config.APIBatchResponseSizeLimit,
+		auth:                   n.auth,
+		protectedModules:       config.ProtectedModules,
 	}
 
 	initHttp := func(server *httpServer, port int) error {
@@ -84,7 +126,7 @@ func (n *Server) startRPC() error {
 			return err
 		}
 		if err := server.enableRPC(n.rpcAPIs, httpConfig{
-			CorsAllowedOrigins: config.DefaultCors,
+			CorsAllowedOrigins: n.config.GetCorsOrigins(),
 			Vhosts:             config.DefaultVhosts,
 			Modules:            config.DefaultModules,
 			prefix:             config.DefaultPrefix,
@@ -92,6 +134,15 @@ func (n *Server) startRPC() error {
 		}); err != nil {
 			return err
 		}
+		// websocket is served at the same port, it is needed by the subscriptions.
+		if err := server.enableWS(n.rpcAPIs, wsConfig{
+			Origins:           n.config.GetCorsOrigins(),
+			Modules:           config.DefaultModules,
+			prefix:            config.DefaultPrefix,
+			rpcEndpointConfig: rpcConfig,
+		}); err != nil {
+			return err
+		}
 		servers = append(servers, server)
 		return nil
 	}
@@ -112,49 +163,42 @@ func (n *Server) startRPC() error {
 }
 
 func (s *Server) monitorEvent() {
-	ticker := time.NewTicker(time.Minute * 2)
-	defer ticker.Stop()
-
-	handler := func(ch chan *apiv1.ChainReorgEvent) {
-		for {
-			select {
-			case reorg := <-ch:
-				log.WithFields(log.Fields{
-					"slot": reorg.Slot,
-				}).Info("reorg event")
-				ev := types.ReorgEvent{
-					Epoch:        int64(reorg.Epoch),
-					Slot:         int64(reorg.Slot),
-					Depth:        int64(reorg.Depth),
-					OldHeadState: reorg.OldHeadState.String(),
-					NewHeadState: reorg.NewHeadState.String(),
-				}
-				if oldHeader, err := s.beaconClient.GetBlockHeaderById(reorg.OldHeadBlock.String()); err == nil {
-					ev.OldBlockSlot = int64(oldHeader.Header.Message.Slot)
-					ev.OldBlockProposerIndex = int64(oldHeader.Header.Message.ProposerIndex)
-				}
-				if newHeader, err := s.beaconClient.GetBlockHeaderById(reorg.NewHeadBlock.String()); err == nil {
-					ev.NewBlockSlot = int64(newHeader.Header.Message.Slot)
-					ev.NewBlockProposerIndex = int64(newHeader.Header.Message.ProposerIndex)
-				}
-				dbmodel.InsertNewReorg(ev)
-			}
+	ch := make(chan *apiv1.ChainReorgEvent, 100)
+	go func() {
+		for reorg := range ch {
+			s.handleReorg(reorg)
 		}
+	}()
+	followEvents(context.Background(), s.beaconClient.Endpoint, func(ctx context.Context, endpoint string) error {
+		log.WithField("endpoint", endpoint).Info("subscribe reorg event")
+		return s.beaconClient.StreamReorgEvents(ctx, endpoint, ch)
+	})
+}
+
+func (s *Server) handleReorg(reorg *apiv1.ChainReorgEvent) {
+	log.WithFields(log.Fields{
+		"slot": reorg.Slot,
+	}).Info("reorg event")
+	metrics.ReorgObserved(int64(reorg.Depth))
+	s.chain.reorgObserved(int64(reorg.Epoch), int64(reorg.Depth))
+	s.chain.invalidate()
+	ev := types.ReorgEvent{
+		Epoch:        int64(reorg.Epoch),
+		Slot:         int64(reorg.Slot),
+		Depth:        int64(reorg.Depth),
+		OldHeadBlock: reorg.OldHeadBlock.String(),
+		OldHeadState: reorg.OldHeadState.String(),
+		NewHeadState: reorg.NewHeadState.String(),
 	}
-
-	for {
-		select {
-		case <-ticker.C:
-			eventCh := s.beaconClient.MonitorReorgEvent()
-			if eventCh != nil {
-				go handler(eventCh)
-				ticker.Reset(time.Hour * 256)
-			} else {
-				ticker.Reset(time.Minute)
-			}
-		}
+	if oldHeader, err := s.beaconClient.GetBlockHeaderById(reorg.OldHeadBlock.String()); err == nil {
+		ev.OldBlockSlot = int64(oldHeader.Header.Message.Slot)
+		ev.OldBlockProposerIndex = int64(oldHeader.Header.Message.ProposerIndex)
 	}
-
+	if newHeader, err := s.beaconClient.GetBlockHeaderById(reorg.NewHeadBlock.String()); err == nil {
+		ev.NewBlockSlot = int64(newHeader.Header.Message.Slot)
+		ev.NewBlockProposerIndex = int64(newHeader.Header.Message.ProposerIndex)
+	}
+	dbmodel.InsertNewReorg(ev)
 }
 
 func (s *Server) monitorDuties() {
@@ -164,7 +208,6 @@ func (s *Server) monitorDuties() {
 	dutyTicker := time.NewTicker(time.Minute)
 	defer dutyTicker.Stop()
 
-	slotsPerEpoch := int64(32)
 	dumped := make(map[int64]bool)
 
 	for {
@@ -176,8 +219,13 @@ func (s *Server) monitorDuties() {
 				log.WithError(err).Debug("duty ticker get latest beacon header failed")
 				continue
 			}
+			slotsPerEpoch, err := s.GetSlotsPerEpoch()
+			if err != nil {
+				log.WithError(err).Debug("duty ticker get slots per epoch failed")
+				continue
+			}
 			curSlot, _ := strconv.ParseInt(header.Header.Message.Slot, 10, 64)
-			curEpoch := curSlot / slotsPerEpoch
+			curEpoch := curSlot / int64(slotsPerEpoch)
 			nextEpoch := curEpoch + 1
 			if curEpoch == 0 && dumped[curEpoch] == false {
 				//
@@ -215,17 +263,36 @@ func (s *Server) monitorDuties() {
 }
 
 func (s *Server) Start() {
+	// the strategies need the chain spec, wait for it before serving.
+	s.beaconClient.CheckHealth()
+	s.chain.waitSpec()
+	s.chain.onEpoch = s.onEpoch
+	go s.chain.run()
 	// start RPC endpoints
 	err := s.startRPC()
 	if err != nil {
 		s.stopRPC()
 	}
 	s.openApi.Start()
+	if s.proxy != nil {
+		s.proxy.Start(s.config.Proxy.Port)
+	}
+	s.startMetrics()
+	s.beaconClient.StartHealthCheck(endpointHealthInterval)
+	s.execClient.StartHealthCheck(endpointHealthInterval)
 	// start collect duties info.
 	go s.monitorDuties()
 	go s.monitorEvent()
 }
 
+func (s *Server) startMetrics() {
+	metrics.RegisterCacheSize("validators", s.validatorSetInfo.ValidatorCount)
+	metrics.RegisterCacheSize("attest_set", s.validatorSetInfo.AttestSetSize)
+	metrics.RegisterCacheSize("block_set", s.validatorSetInfo.BlockSetSize)
+	metrics.RegisterCacheSize("slot_cache", s.cache.Len)
+	metrics.StartServer(s.config.MetricPort())
+}
+
 func (s *Server) stopRPC() {
 	s.http.stop()
 }
@@ -236,15 +303,15 @@ func (s *Server) SomeNeedBackend() bool {
 }
 
 func (s *Server) GetBlockHeight() (uint64, error) {
-	return s.execClient.BlockNumber(context.Background())
+	return s.execClient.BlockNumber()
 }
 
 func (s *Server) GetBlockByNumber(number *big.Int) (*ethtype.Block, error) {
-	return s.execClient.BlockByNumber(context.Background(), number)
+	return s.execClient.BlockByNumber(number)
 }
 
 func (s *Server) GetHeightByNumber(number *big.Int) (*ethtype.Header, error) {
-	return s.execClient.HeaderByNumber(context.Background(), number)
+	return s.execClient.HeaderByNumber(number)
 }
 
 func (s *Server) GetStrategy() *types.Strategy {
@@ -260,27 +327,39 @@ func (s *Server) GetValidatorRoleByPubkey(slot int, pubkey string) types.RoleTyp
 }
 
 func (s *Server) GetCurrentEpochProposeDuties() ([]types.ProposerDuty, error) {
-	return s.beaconClient.GetCurrentEpochProposerDuties()
+	spec, err := s.chain.Spec()
+	if err != nil {
+		return nil, err
+	}
+	return s.chain.ProposerDuties(spec.CurrentEpoch())
 }
 
 func (s *Server) GetCurrentEpochAttestDuties() ([]types.AttestDuty, error) {
 	return s.beaconClient.GetCurrentEpochAttestDuties()
 }
 
-func (s *Server) GetSlotsPerEpoch() int {
-	count, err := s.beaconClient.GetIntConfig(beaconapi.SLOTS_PER_EPOCH)
+func (s *Server) GetChainSpec() (types.ChainSpec, error) {
+	return s.chain.Spec()
+}
+
+// GetSlotsPerEpoch returns the cached SLOTS_PER_EPOCH, or ErrChainSpecUnavailable
+// if the spec is not loaded from the beacon node yet.
+func (s *Server) GetSlotsPerEpoch() (int, error) {
+	spec, err := s.chain.Spec()
 	if err != nil {
-		return 6
+		return 0, err
 	}
-	return count
+	return spec.SlotsPerEpoch, nil
 }
 
+// GetIntervalPerSlot returns the cached SECONDS_PER_SLOT, the spec is always
+// available once the server is started.
 func (s *Server) GetIntervalPerSlot() int {
-	interval, err := s.beaconClient.GetIntConfig(beaconapi.SECONDS_PER_SLOT)
+	spec, err := s.chain.Spec()
 	if err != nil {
-		return 12
+		log.WithError(err).Error("get interval per slot failed")
 	}
-	return interval
+	return spec.SecondsPerSlot
 }
 
 func (s *Server) AddSignedAttestation(slot uint64, pubkey string, attestation *ethpb.Attestation) {
@@ -304,9 +383,12 @@ func (s *Server) GetValidatorDataSet() *types.ValidatorDataSet {
 }
 
 func (s *Server) GetValidatorByProposeSlot(slot uint64) (int, error) {
-	epochPerSlot := uint64(s.GetSlotsPerEpoch())
-	epoch := slot / epochPerSlot
-	duties, err := s.beaconClient.GetProposerDuties(int(epoch))
+	spec, err := s.chain.Spec()
+	if err != nil {
+		return 0, err
+	}
+	epoch := slot / uint64(spec.SlotsPerEpoch)
+	duties, err := s.chain.ProposerDuties(int64(epoch))
 	if err != nil {
 		return 0, err
 	}
@@ -321,28 +403,31 @@ func (s *Server) GetValidatorByProposeSlot(slot uint64) (int, error) {
 }
 
 func (s *Server) GetProposeDuties(epoch int) ([]types.ProposerDuty, error) {
-	return s.beaconClient.GetProposerDuties(epoch)
+	return s.chain.ProposerDuties(int64(epoch))
 }
 
-func (s *Server) SlotsPerEpoch() int {
+func (s *Server) SlotsPerEpoch() (int, error) {
 	return s.GetSlotsPerEpoch()
 }
 
 func (s *Server) GetValidatorRole(slot int, valIdx int) types.RoleType {
 	if slot < 0 {
-		header, err := s.beaconClient.GetLatestBeaconHeader()
+		spec, err := s.chain.Spec()
 		if err != nil {
 			return types.NormalRole
 		}
-		slot, _ = strconv.Atoi(header.Header.Message.Slot)
+		slot = int(spec.CurrentSlot())
 	}
-	return s.strategy.GetValidatorRole(valIdx, int64(slot))
+	if s.strategy.IsAttacker(valIdx, int64(slot)) {
+		return types.AttackerRole
+	}
+	return types.NormalRole
 }
 
 func (s *Server) GetInternalSlotStrategy() []slotstrategy.InternalSlotStrategy {
 	var err error
 	if len(s.internal) == 0 {
-		s.internal, err = slotstrategy.ParseToInternalSlotStrategy(s, s.strategy.Slots)
+		s.internal, err = slotstrategy.ParseToInternalSlotStrategy(s, s.strategy)
 		if err != nil {
 			log.WithError(err).Error("parse strategy failed")
 			return nil
@@ -354,6 +439,81 @@ func (s *Server) GetSlotRoot(slot int64) (string, error) {
 	return s.beaconClient.GetSlotRoot(slot)
 }
 
+func (s *Server) GetBeaconHeader(id string) (types.BeaconHeaderInfo, error) {
+	return s.beaconClient.GetBeaconHeaderById(id)
+}
+
+// GetValidators returns the validators of the head state, they are added to
+// the validator set to resolve the pubkeys of the hooks.
+func (s *Server) GetValidators() ([]types.Validator, error) {
+	list, err := s.beaconClient.GetValidators("head")
+	if err != nil {
+		return nil, err
+	}
+	validators := make([]types.Validator, 0, len(list))
+	for _, v := range list {
+		index, err := strconv.ParseInt(v.Index, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid validator index %s", v.Index)
+		}
+		balance, err := strconv.ParseUint(v.Validator.EffectiveBalance, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid effective balance %s of validator %d", v.Validator.EffectiveBalance, index)
+		}
+		s.validatorSetInfo.AddValidator(int(index), v.Validator.Pubkey)
+		validators = append(validators, types.Validator{
+			Index:            index,
+			Pubkey:           v.Validator.Pubkey,
+			EffectiveBalance: balance,
+			Status:           v.Status,
+		})
+	}
+	return validators, nil
+}
+
+func (s *Server) SignBlock(pubkey string, block *ethpb.BeaconBlockDeneb) ([]byte, error) {
+	return s.signer.SignBlock(pubkey, block)
+}
+
+func (s *Server) SignAttestation(pubkey string, data *ethpb.AttestationData) ([]byte, error) {
+	return s.signer.SignAttestation(pubkey, data)
+}
+
+// ProduceBlock produces a block by the beacon node. A parentRoot is returned to
+// the BlockGetNewParentRoot hook of the beacon node while the block is produced,
+// so only a beacon node calling the attacker service produces the block on it.
+func (s *Server) ProduceBlock(slot uint64, randaoReveal []byte, graffiti []byte, parentRoot []byte) (*ethpb.BeaconBlockContentsDeneb, error) {
+	if parentRoot != nil {
+		s.parentMu.Lock()
+		if s.parentRoots == nil {
+			s.parentRoots = make(map[uint64][]byte)
+		}
+		s.parentRoots[slot] = parentRoot
+		s.parentMu.Unlock()
+		defer func() {
+			s.parentMu.Lock()
+			delete(s.parentRoots, slot)
+			s.parentMu.Unlock()
+		}()
+	}
+	return s.beaconClient.ProduceBlock(slot, randaoReveal, graffiti)
+}
+
+func (s *Server) ParentRootOverride(slot uint64) ([]byte, bool) {
+	s.parentMu.Lock()
+	defer s.parentMu.Unlock()
+	root, ok := s.parentRoots[slot]
+	return root, ok
+}
+
+func (s *Server) PublishBlock(block *ethpb.SignedBeaconBlockContentsDeneb) error {
+	return s.beaconClient.PublishBlock(block)
+}
+
+func (s *Server) SubmitAttestation(attestation *ethpb.Attestation) error {
+	return s.beaconClient.SubmitAttestations([]*ethpb.Attestation{attestation})
+}
+
 func (s *Server) dumpDuties(epoch int64) error {
 	duties, err := s.GetProposeDuties(int(epoch))
 	if err != nil {
@@ -370,20 +530,51 @@ func (s *Server) dumpDuties(epoch int64) error {
 }
 
 func (s *Server) UpdateStrategy(strategy *types.Strategy) error {
-	parsed, err := slotstrategy.ParseToInternalSlotStrategy(s, strategy.Slots)
+	parsed, err := slotstrategy.ParseToInternalSlotStrategy(s, strategy)
 	if err != nil {
 		return err
 	}
 	s.strategy = strategy
 	s.internal = parsed
+	// evaluate the new conditional strategies without waiting for the next epoch.
+	if spec, err := s.chain.Spec(); err == nil && len(strategy.Conditional) > 0 {
+		go s.evaluateConditions(spec.CurrentEpoch())
+	}
 	return nil
 }
 
+func (s *Server) onEpoch(epoch int64) {
+	s.evaluateConditions(epoch)
+	go s.evaluator.OnEpoch(epoch)
+}
+
+// evaluateConditions evaluates the triggers of the conditional strategies at the epoch.
+func (s *Server) evaluateConditions(epoch int64) {
+	for _, condition := range slotstrategy.Conditions(s.GetInternalSlotStrategy()) {
+		condition.Evaluate(s, epoch)
+	}
+}
+
+func (s *Server) GetFinalizedEpoch() (int64, error) {
+	checkpoints, err := s.beaconClient.GetFinalityCheckpoints()
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(checkpoints.Finalized.Epoch, 10, 64)
+}
+
+func (s *Server) GetMaxReorgDepth(fromEpoch, toEpoch int64) int64 {
+	return s.chain.MaxReorgDepth(fromEpoch, toEpoch)
+}
+
 func (s *Server) GetSlotStartTime(slot int) (int64, bool) {
 	key := fmt.Sprintf("slot_start_time_%d", slot)
 	if v, ok := s.cache.Get(key); ok {
 		return v.(int64), true
 	}
+	if spec, err := s.chain.Spec(); err == nil {
+		return spec.SlotStartTime(int64(slot)), true
+	}
 	return 0, false
 }
 
@@ -0,0 +1,192 @@
+package signer
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/prysmaticlabs/prysm/v5/beacon-chain/core/signing"
+	"github.com/prysmaticlabs/prysm/v5/config/params"
+	"github.com/prysmaticlabs/prysm/v5/crypto/bls"
+	ethpb "github.com/prysmaticlabs/prysm/v5/proto/prysm/v1alpha1"
+	log "github.com/sirupsen/logrus"
+	"github.com/tsinghua-cel/attacker-service/config"
+	"github.com/tsinghua-cel/attacker-service/types"
+)
+
+var (
+	ErrNoSigner = errors.New("no signer configured")
+	ErrNoKey    = errors.New("no key of the validator")
+)
+
+// ChainInfo provides the fork and genesis data of the signing domain.
+type ChainInfo interface {
+	GetGenesis() (types.GenesisInfo, error)
+	GetFork() (types.ForkInfo, error)
+}
+
+type hashRoot interface {
+	HashTreeRoot() ([32]byte, error)
+}
+
+// Signer signs blocks and attestations with the keys of the attacker
+// validators, it is only used by the slashable actions to produce the
+// second message of an equivocation.
+type Signer struct {
+	chain ChainInfo
+	spec  func() (types.ChainSpec, error)
+
+	mu   sync.Mutex
+	keys map[string]bls.SecretKey // hex encoded pubkey without 0x prefix
+	gvr  []byte
+}
+
+// NewSigner loads the keys of the signer config, it returns nil if no key is configured.
+func NewSigner(conf config.SignerConfig, chain ChainInfo, spec func() (types.ChainSpec, error)) (*Signer, error) {
+	keys := make(map[string]bls.SecretKey)
+	if conf.InteropValidators > 0 {
+		for i := 0; i < conf.InteropValidators; i++ {
+			sk, err := interopKey(uint32(i))
+			if err != nil {
+				return nil, err
+			}
+			keys[hex.EncodeToString(sk.PublicKey().Marshal())] = sk
+		}
+	}
+	if conf.KeysFile != "" {
+		data, err := os.ReadFile(conf.KeysFile)
+		if err != nil {
+			return nil, err
+		}
+		var secrets []string
+		if err := json.Unmarshal(data, &secrets); err != nil {
+			return nil, fmt.Errorf("invalid keys file %s: %w", conf.KeysFile, err)
+		}
+		for _, secret := range secrets {
+			b, err := hexutil.Decode(secret)
+			if err != nil {
+				return nil, fmt.Errorf("invalid secret key: %w", err)
+			}
+			sk, err := bls.SecretKeyFromBytes(b)
+			if err != nil {
+				return nil, err
+			}
+			keys[hex.EncodeToString(sk.PublicKey().Marshal())] = sk
+		}
+	}
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	log.WithField("keys", len(keys)).Info("signer keys loaded")
+	return &Signer{chain: chain, spec: spec, keys: keys}, nil
+}
+
+// interopKey derives the secret key of the interop validator like the mocked
+// start of the beacon chain: sha256 of the little endian index modulo the curve order.
+func interopKey(index uint32) (bls.SecretKey, error) {
+	enc := make([]byte, 32)
+	binary.LittleEndian.PutUint32(enc, index)
+	h := sha256.Sum256(enc)
+	for i, j := 0, len(h)-1; i < j; i, j = i+1, j-1 {
+		h[i], h[j] = h[j], h[i]
+	}
+	order, _ := new(big.Int).SetString(bls.CurveOrder, 10)
+	num := new(big.Int).Mod(new(big.Int).SetBytes(h[:]), order)
+	b := make([]byte, 32)
+	num.FillBytes(b)
+	return bls.SecretKeyFromBytes(b)
+}
+
+// HasKey returns true if the signer has the key of the pubkey.
+func (s *Signer) HasKey(pubkey string) bool {
+	if s == nil {
+		return false
+	}
+	_, exist := s.keys[strings.TrimPrefix(pubkey, "0x")]
+	return exist
+}
+
+// SignBlock returns the proposer signature of the block.
+func (s *Signer) SignBlock(pubkey string, block *ethpb.BeaconBlockDeneb) ([]byte, error) {
+	if s == nil {
+		return nil, ErrNoSigner
+	}
+	spec, err := s.spec()
+	if err != nil {
+		return nil, err
+	}
+	return s.sign(pubkey, params.BeaconConfig().DomainBeaconProposer, uint64(block.Slot)/uint64(spec.SlotsPerEpoch), block)
+}
+
+// SignAttestation returns the attester signature of the attestation data.
+func (s *Signer) SignAttestation(pubkey string, data *ethpb.AttestationData) ([]byte, error) {
+	return s.sign(pubkey, params.BeaconConfig().DomainBeaconAttester, uint64(data.Target.Epoch), data)
+}
+
+func (s *Signer) sign(pubkey string, domainType [4]byte, epoch uint64, object hashRoot) ([]byte, error) {
+	if s == nil {
+		return nil, ErrNoSigner
+	}
+	sk, exist := s.keys[strings.TrimPrefix(pubkey, "0x")]
+	if !exist {
+		return nil, fmt.Errorf("%w %s", ErrNoKey, pubkey)
+	}
+	domain, err := s.domain(domainType, epoch)
+	if err != nil {
+		return nil, err
+	}
+	objectRoot, err := object.HashTreeRoot()
+	if err != nil {
+		return nil, err
+	}
+	root, err := signing.ComputeSigningRootForRoot(objectRoot, domain)
+	if err != nil {
+		return nil, err
+	}
+	return sk.Sign(root[:]).Marshal(), nil
+}
+
+// domain computes the signing domain of the epoch with the fork of the head state.
+func (s *Signer) domain(domainType [4]byte, epoch uint64) ([]byte, error) {
+	s.mu.Lock()
+	gvr := s.gvr
+	s.mu.Unlock()
+	if gvr == nil {
+		genesis, err := s.chain.GetGenesis()
+		if err != nil {
+			return nil, err
+		}
+		if gvr, err = hexutil.Decode(genesis.GenesisValidatorsRoot); err != nil {
+			return nil, err
+		}
+		s.mu.Lock()
+		s.gvr = gvr
+		s.mu.Unlock()
+	}
+	fork, err := s.chain.GetFork()
+	if err != nil {
+		return nil, err
+	}
+	forkEpoch, err := strconv.ParseUint(fork.Epoch, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	version := fork.CurrentVersion
+	if epoch < forkEpoch {
+		version = fork.PreviousVersion
+	}
+	forkVersion, err := hexutil.Decode(version)
+	if err != nil {
+		return nil, err
+	}
+	return signing.ComputeDomain(domainType, forkVersion, gvr)
+}
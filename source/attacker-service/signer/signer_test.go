@@ -0,0 +1,135 @@
+package signer
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/prysmaticlabs/prysm/v5/beacon-chain/core/signing"
+	"github.com/prysmaticlabs/prysm/v5/config/params"
+	ethpb "github.com/prysmaticlabs/prysm/v5/proto/prysm/v1alpha1"
+	"github.com/tsinghua-cel/attacker-service/config"
+	"github.com/tsinghua-cel/attacker-service/types"
+)
+
+type testChain struct {
+	genesisCalls int
+	fork         types.ForkInfo
+}
+
+func (c *testChain) GetGenesis() (types.GenesisInfo, error) {
+	c.genesisCalls++
+	return types.GenesisInfo{GenesisValidatorsRoot: hexutil.Encode(bytes.Repeat([]byte{0x11}, 32))}, nil
+}
+
+func (c *testChain) GetFork() (types.ForkInfo, error) {
+	return c.fork, nil
+}
+
+func testSpec() (types.ChainSpec, error) {
+	return types.ChainSpec{SlotsPerEpoch: 4, SecondsPerSlot: 12}, nil
+}
+
+func TestNewSigner(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	tests := []struct {
+		name    string
+		conf    config.SignerConfig
+		wantErr string
+	}{
+		{name: "no key"},
+		{name: "empty keys file", conf: config.SignerConfig{KeysFile: write("empty.json", "[]")}},
+		{name: "missing keys file", conf: config.SignerConfig{KeysFile: filepath.Join(dir, "missing.json")}, wantErr: "no such file"},
+		{name: "invalid keys file", conf: config.SignerConfig{KeysFile: write("invalid.json", "{}")}, wantErr: "invalid keys file"},
+		{name: "invalid secret key", conf: config.SignerConfig{KeysFile: write("secret.json", `["0xzz"]`)}, wantErr: "invalid secret key"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := NewSigner(tt.conf, &testChain{}, testSpec)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got err %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s != nil {
+				t.Fatalf("got a signer without key")
+			}
+		})
+	}
+}
+
+func TestSignWithoutKey(t *testing.T) {
+	var none *Signer
+	if none.HasKey("0xaa") {
+		t.Error("no signer has a key")
+	}
+	block := &ethpb.BeaconBlockDeneb{Slot: 9}
+	data := &ethpb.AttestationData{Target: &ethpb.Checkpoint{Epoch: 2}}
+	if _, err := none.SignBlock("0xaa", block); !errors.Is(err, ErrNoSigner) {
+		t.Errorf("sign block without signer, err %v", err)
+	}
+	if _, err := none.SignAttestation("0xaa", data); !errors.Is(err, ErrNoSigner) {
+		t.Errorf("sign attestation without signer, err %v", err)
+	}
+
+	s := &Signer{chain: &testChain{}, spec: testSpec}
+	if s.HasKey("0xaa") {
+		t.Error("the signer has no key")
+	}
+	if _, err := s.SignBlock("0xaa", block); !errors.Is(err, ErrNoKey) {
+		t.Errorf("sign block without key, err %v", err)
+	}
+	if _, err := s.SignAttestation("0xaa", data); !errors.Is(err, ErrNoKey) {
+		t.Errorf("sign attestation without key, err %v", err)
+	}
+}
+
+func TestDomain(t *testing.T) {
+	chain := &testChain{fork: types.ForkInfo{
+		PreviousVersion: "0x03000000",
+		CurrentVersion:  "0x04000000",
+		Epoch:           "5",
+	}}
+	s := &Signer{chain: chain, spec: testSpec}
+	gvr := bytes.Repeat([]byte{0x11}, 32)
+	domainType := params.BeaconConfig().DomainBeaconAttester
+	tests := []struct {
+		epoch   uint64
+		version []byte
+	}{
+		{epoch: 4, version: []byte{3, 0, 0, 0}},
+		{epoch: 5, version: []byte{4, 0, 0, 0}},
+		{epoch: 6, version: []byte{4, 0, 0, 0}},
+	}
+	for _, tt := range tests {
+		domain, err := s.domain(domainType, tt.epoch)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, err := signing.ComputeDomain(domainType, tt.version, gvr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(domain, want) {
+			t.Errorf("domain of epoch %d is %x, want %x", tt.epoch, domain, want)
+		}
+	}
+	if chain.genesisCalls != 1 {
+		t.Errorf("genesis requested %d times, want once", chain.genesisCalls)
+	}
+}
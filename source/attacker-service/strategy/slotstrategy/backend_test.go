@@ -0,0 +1,156 @@
+package slotstrategy
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+
+	ethpb "github.com/prysmaticlabs/prysm/v5/proto/prysm/v1alpha1"
+	"github.com/tsinghua-cel/attacker-service/types"
+)
+
+// testBackend is a chain of blocks with one proposer per slot, the methods not
+// used by the tested actions panic.
+type testBackend struct {
+	types.ServiceBackend
+	slotsPerEpoch int
+	headers       map[string]types.BeaconHeaderInfo // by slot and by root
+	proposers     map[uint64]int
+	attackers     map[int]bool
+	blockSets     map[uint64]*types.SlotBlockSet
+
+	// the slashable actions.
+	spec            types.ChainSpec
+	pubkeyAttackers map[string]bool
+	// produce returns the block produced by the beacon node for the parent
+	// root asked, the default one is produced on it.
+	produce   func(slot uint64, parentRoot []byte) *ethpb.BeaconBlockDeneb
+	produced  [][]byte // the parent roots asked
+	published []*ethpb.SignedBeaconBlockContentsDeneb
+	submitted []*ethpb.Attestation
+}
+
+// newTestBackend returns a chain with 4 slots per epoch and a block proposed
+// by validator slot at every slot up to head but the empty ones.
+func newTestBackend(head int64, empty ...int64) *testBackend {
+	b := &testBackend{
+		slotsPerEpoch: 4,
+		headers:       make(map[string]types.BeaconHeaderInfo),
+		proposers:     make(map[uint64]int),
+		attackers:     make(map[int]bool),
+		blockSets:     make(map[uint64]*types.SlotBlockSet),
+		// the wall clock is far after all the test slots.
+		spec:            types.ChainSpec{SlotsPerEpoch: 4, SecondsPerSlot: 12},
+		pubkeyAttackers: make(map[string]bool),
+	}
+	isEmpty := make(map[int64]bool)
+	for _, slot := range empty {
+		isEmpty[slot] = true
+	}
+	parent := rootHex(-1)
+	for slot := int64(0); slot <= head; slot++ {
+		b.proposers[uint64(slot)] = int(slot)
+		if isEmpty[slot] {
+			continue
+		}
+		var header types.BeaconHeaderInfo
+		header.Root = rootHex(slot)
+		header.Header.Message.Slot = strconv.FormatInt(slot, 10)
+		header.Header.Message.ProposerIndex = strconv.FormatInt(slot, 10)
+		header.Header.Message.ParentRoot = parent
+		b.headers[header.Root] = header
+		b.headers[header.Header.Message.Slot] = header
+		parent = header.Root
+	}
+	return b
+}
+
+// rootHex returns the root of the block at slot, the parent of the genesis
+// block is the zero root.
+func rootHex(slot int64) string {
+	return fmt.Sprintf("0x%064x", slot+1)
+}
+
+func root(slot int64) []byte {
+	r := make([]byte, 32)
+	r[31] = byte(slot + 1)
+	return r
+}
+
+func (b *testBackend) GetSlotsPerEpoch() (int, error) {
+	return b.slotsPerEpoch, nil
+}
+
+func (b *testBackend) GetBeaconHeader(id string) (types.BeaconHeaderInfo, error) {
+	if header, ok := b.headers[id]; ok {
+		return header, nil
+	}
+	return types.BeaconHeaderInfo{}, errors.New("header not found")
+}
+
+func (b *testBackend) GetValidatorByProposeSlot(slot uint64) (int, error) {
+	if idx, ok := b.proposers[slot]; ok {
+		return idx, nil
+	}
+	return 0, errors.New("no duty")
+}
+
+func (b *testBackend) GetValidatorRole(slot int, valIdx int) types.RoleType {
+	if b.attackers[valIdx] {
+		return types.AttackerRole
+	}
+	return types.NormalRole
+}
+
+func (b *testBackend) GetBlockSet(slot uint64) *types.SlotBlockSet {
+	return b.blockSets[slot]
+}
+
+func (b *testBackend) GetChainSpec() (types.ChainSpec, error) {
+	return b.spec, nil
+}
+
+func (b *testBackend) GetValidatorRoleByPubkey(slot int, pubkey string) types.RoleType {
+	if b.pubkeyAttackers[pubkey] {
+		return types.AttackerRole
+	}
+	return types.NormalRole
+}
+
+// testSignature is the signature of every message signed by the test backend.
+var testSignature = bytes.Repeat([]byte{0xab}, 96)
+
+func (b *testBackend) SignBlock(pubkey string, block *ethpb.BeaconBlockDeneb) ([]byte, error) {
+	return testSignature, nil
+}
+
+func (b *testBackend) SignAttestation(pubkey string, data *ethpb.AttestationData) ([]byte, error) {
+	return testSignature, nil
+}
+
+func (b *testBackend) ProduceBlock(slot uint64, randaoReveal []byte, graffiti []byte, parentRoot []byte) (*ethpb.BeaconBlockContentsDeneb, error) {
+	b.produced = append(b.produced, parentRoot)
+	var block *ethpb.BeaconBlockDeneb
+	if b.produce != nil {
+		block = b.produce(slot, parentRoot)
+	} else {
+		block = testBlock(slot, slot, parentRoot).Block
+	}
+	block.Body.Graffiti = graffiti
+	return &ethpb.BeaconBlockContentsDeneb{Block: block}, nil
+}
+
+func (b *testBackend) ParentRootOverride(slot uint64) ([]byte, bool) {
+	return nil, false
+}
+
+func (b *testBackend) PublishBlock(block *ethpb.SignedBeaconBlockContentsDeneb) error {
+	b.published = append(b.published, block)
+	return nil
+}
+
+func (b *testBackend) SubmitAttestation(attestation *ethpb.Attestation) error {
+	b.submitted = append(b.submitted, attestation)
+	return nil
+}
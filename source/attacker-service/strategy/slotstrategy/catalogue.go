@@ -0,0 +1,96 @@
+package slotstrategy
+
+import (
+	"github.com/tsinghua-cel/attacker-service/types"
+	"github.com/tsinghua-cel/attacker-service/versions"
+)
+
+// functionSlots are the function slots of GetFunctionSlot, period takes the
+// period, the offset and optionally the first and last slot as parameters.
+var functionSlots = []string{
+	"every",
+	"attackerSlot",
+	"firstSlotInCurrentEpoch",
+	"lastSlotInCurrentEpoch",
+	"firstSlotInNextEpoch",
+	"lastSlotInNextEpoch",
+	"lastAttackerSlotInCurrentEpoch",
+	"lastAttackerSlotInNextEpoch",
+	"period:n:k:from:to",
+}
+
+func intParam(v int) *int { return &v }
+
+func pointNames(points ...[]types.ActionPoint) []string {
+	names := make([]string, 0)
+	for _, list := range points {
+		for _, p := range list {
+			names = append(names, string(p))
+		}
+	}
+	return names
+}
+
+// catalogueActions describes the actions of GetFunctionAction, keep them in sync.
+func catalogueActions() []types.CatalogueAction {
+	all := pointNames(types.BlockActionPoints, types.AttestActionPoints)
+	signedBlock := pointNames([]types.ActionPoint{types.BlockBeforeSign, types.BlockAfterSign, types.BlockBeforePropose, types.BlockAfterPropose})
+	signedAttest := pointNames([]types.ActionPoint{types.AttestAfterSign, types.AttestBeforePropose, types.AttestAfterPropose})
+	vote := pointNames([]types.ActionPoint{types.AttestBeforeSign})
+	return []types.CatalogueAction{
+		{Name: "null", Desc: "do nothing", Points: all},
+		{Name: "return", Desc: "stop the duty, the message is not signed or not sent", Points: all},
+		{Name: "continue", Desc: "continue the duty", Points: all},
+		{Name: "abort", Desc: "abort the duty", Points: all},
+		{Name: "skip", Desc: "skip the duty", Points: all},
+		{Name: "exit", Desc: "exit the duty", Points: all},
+		{Name: "delayWithSecond", Desc: "delay for seconds", Points: all, Params: []types.CatalogueParam{
+			{Name: "seconds", Desc: "the seconds to delay", Min: 0, Max: 10, ValidMin: intParam(0)},
+		}},
+		{Name: "delayToNextSlot", Desc: "delay to the next slot start", Points: all},
+		{Name: "delayToAfterNextSlot", Desc: "delay to seconds after the next slot start", Points: all, Params: []types.CatalogueParam{
+			{Name: "seconds", Desc: "the seconds after the next slot start", Min: 0, Max: 10, ValidMin: intParam(0)},
+		}},
+		{Name: "delayToNextNEpochStart", Desc: "delay to the start of the n-th next epoch", Points: all, Params: []types.CatalogueParam{
+			{Name: "n", Desc: "the number of epochs", Default: intParam(1), Min: 1, Max: 4, ValidMin: intParam(1)},
+		}},
+		{Name: "delayToNextNEpochEnd", Desc: "delay to the last slot of the n-th next epoch", Points: all, Params: []types.CatalogueParam{
+			{Name: "n", Desc: "the number of epochs", Default: intParam(0), Min: 0, Max: 4, ValidMin: intParam(0)},
+		}},
+		{Name: "delayToNextNEpochHalf", Desc: "delay to the middle of the n-th next epoch", Points: all, Params: []types.CatalogueParam{
+			{Name: "n", Desc: "the number of epochs", Default: intParam(1), Min: 1, Max: 4, ValidMin: intParam(1)},
+		}},
+		{Name: "delayToEpochEnd", Desc: "delay to the last slot of the epoch", Points: all},
+		{Name: "delayHalfEpoch", Desc: "delay for half an epoch", Points: all},
+		{Name: "storeSignedBlock", Desc: "keep the block for voteAttackerChainTip", Points: signedBlock},
+		{Name: "storeSignedAttest", Desc: "keep the attestation for rePackAttestation", Points: signedAttest},
+		{Name: "rePackAttestation", Desc: "add the attestations of the attacker validators in the epoch to the block", Points: pointNames([]types.ActionPoint{types.BlockBeforeSign})},
+		{Name: "voteHeadOfSlot", Desc: "vote for the block n slots before", Points: vote, Params: []types.CatalogueParam{
+			{Name: "n", Desc: "the number of slots", Default: intParam(1), Min: 1, Max: 8, ValidMin: intParam(1)},
+		}},
+		{Name: "voteAttackerChainTip", Desc: "vote for the latest attacker block", Points: vote},
+		{Name: "voteParentOfHead", Desc: "vote for the parent of the head", Points: vote},
+		{Name: "voteStaleTarget", Desc: "vote for the previous checkpoint as target", Points: vote},
+		{Name: "doubleProposal", Desc: "publish a second block of the slot", Points: pointNames([]types.ActionPoint{types.BlockAfterSign, types.BlockBeforePropose, types.BlockAfterPropose}), Slashable: true, Params: []types.CatalogueParam{
+			{Name: "mode", Desc: "0: a different body, 1: a different parent", Default: intParam(0), Min: 0, Max: 1, ValidMin: intParam(0), ValidMax: intParam(1)},
+		}},
+		{Name: "doubleVote", Desc: "publish a second vote of the target epoch", Points: signedAttest, Slashable: true},
+		{Name: "surroundVote", Desc: "publish a vote surrounding the signed one", Points: signedAttest, Slashable: true},
+	}
+}
+
+// GetCatalogue returns the action points, actions and function slots of the service.
+func GetCatalogue() types.Catalogue {
+	c := types.Catalogue{
+		Version:       versions.TagVersion,
+		Actions:       catalogueActions(),
+		SlotFunctions: functionSlots,
+	}
+	for _, p := range types.BlockActionPoints {
+		c.Points = append(c.Points, types.CataloguePoint{Name: string(p), Kind: "block"})
+	}
+	for _, p := range types.AttestActionPoints {
+		c.Points = append(c.Points, types.CataloguePoint{Name: string(p), Kind: "attest"})
+	}
+	return c
+}
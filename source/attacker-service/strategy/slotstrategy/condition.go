@@ -0,0 +1,178 @@
+package slotstrategy
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/tsinghua-cel/attacker-service/common"
+	"github.com/tsinghua-cel/attacker-service/types"
+)
+
+// ConditionBackend provides the chain conditions checked by the triggers.
+type ConditionBackend interface {
+	GetSlotsPerEpoch() (int, error)
+	GetProposeDuties(epoch int) ([]types.ProposerDuty, error)
+	GetValidatorRole(slot int, valIdx int) types.RoleType
+	GetFinalizedEpoch() (int64, error)
+	// GetMaxReorgDepth returns the max depth of the reorgs observed in the epochs.
+	GetMaxReorgDepth(fromEpoch, toEpoch int64) int64
+}
+
+// Condition is the enabled state of a conditional strategy block, the slot
+// rules of the block only match slots of the epochs it is enabled for.
+type Condition struct {
+	Name     string
+	triggers []types.Trigger
+	until    []types.Trigger
+	epochs   int64
+	tool     common.SlotTool
+
+	mu          sync.RWMutex
+	evaluated   int64
+	enabledFrom int64
+	enabledTo   int64
+}
+
+func newCondition(backend types.ServiceBackend, c types.ConditionalStrategy) (*Condition, error) {
+	for _, t := range append(append([]types.Trigger{}, c.Triggers...), c.Until...) {
+		switch t.Type {
+		case types.TriggerAttackerProposers, types.TriggerFinalityLag, types.TriggerReorgDepth:
+		default:
+			return nil, fmt.Errorf("conditional strategy %s: unknown trigger type %s", c.Name, t.Type)
+		}
+	}
+	if len(c.Triggers) == 0 {
+		return nil, fmt.Errorf("conditional strategy %s: no trigger", c.Name)
+	}
+	slotsPerEpoch, err := backend.GetSlotsPerEpoch()
+	if err != nil {
+		return nil, err
+	}
+	epochs := int64(c.Epochs)
+	if epochs <= 0 {
+		epochs = 1
+	}
+	return &Condition{
+		Name:      c.Name,
+		triggers:  c.Triggers,
+		until:     c.Until,
+		epochs:    epochs,
+		tool:      common.SlotTool{SlotsPerEpoch: slotsPerEpoch},
+		evaluated: -1,
+		enabledTo: -1,
+	}, nil
+}
+
+// Enabled returns whether the block is enabled at the epoch.
+func (c *Condition) Enabled(epoch int64) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return epoch >= c.enabledFrom && epoch <= c.enabledTo
+}
+
+// EnabledAt returns whether the block is enabled at the epoch of the slot.
+func (c *Condition) EnabledAt(slot int64) bool {
+	return c.Enabled(c.tool.SlotToEpoch(slot))
+}
+
+// Evaluate checks the triggers in the epoch, and enables the block for the
+// epochs after it when all of them hold. Each epoch is only evaluated once.
+func (c *Condition) Evaluate(backend ConditionBackend, epoch int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if epoch <= c.evaluated {
+		return
+	}
+	c.evaluated = epoch
+	for _, t := range c.until {
+		if hold, err := checkTrigger(backend, t, epoch); err == nil && hold {
+			if c.enabledTo >= epoch {
+				log.WithFields(log.Fields{
+					"name":    c.Name,
+					"epoch":   epoch,
+					"trigger": t.Type,
+				}).Info("conditional strategy disabled")
+				c.enabledTo = epoch - 1
+			}
+			return
+		}
+	}
+	for _, t := range c.triggers {
+		hold, err := checkTrigger(backend, t, epoch)
+		if err != nil {
+			log.WithError(err).WithFields(log.Fields{
+				"name":    c.Name,
+				"epoch":   epoch,
+				"trigger": t.Type,
+			}).Warn("check trigger failed")
+			return
+		}
+		if !hold {
+			return
+		}
+	}
+	if c.enabledTo < epoch {
+		c.enabledFrom = epoch + 1
+	}
+	c.enabledTo = epoch + c.epochs
+	log.WithFields(log.Fields{
+		"name": c.Name,
+		"from": c.enabledFrom,
+		"to":   c.enabledTo,
+	}).Info("conditional strategy enabled")
+}
+
+func checkTrigger(backend ConditionBackend, t types.Trigger, epoch int64) (bool, error) {
+	switch t.Type {
+	case types.TriggerAttackerProposers:
+		n, err := maxAttackerProposers(backend, epoch+1)
+		return n >= t.Value, err
+	case types.TriggerFinalityLag:
+		finalized, err := backend.GetFinalizedEpoch()
+		if err != nil {
+			return false, err
+		}
+		return epoch-finalized > int64(t.Value), nil
+	case types.TriggerReorgDepth:
+		return backend.GetMaxReorgDepth(epoch-1, epoch) >= int64(t.Value), nil
+	}
+	return false, fmt.Errorf("unknown trigger type %s", t.Type)
+}
+
+// maxAttackerProposers returns the longest run of consecutive slots proposed
+// by attacker validators in the epoch.
+func maxAttackerProposers(backend ConditionBackend, epoch int64) (int, error) {
+	duties, err := backend.GetProposeDuties(int(epoch))
+	if err != nil {
+		return 0, err
+	}
+	maxLength, length := 0, 0
+	for _, duty := range duties {
+		slot, _ := strconv.Atoi(duty.Slot)
+		valIdx, err := strconv.Atoi(duty.ValidatorIndex)
+		if err == nil && backend.GetValidatorRole(slot, valIdx) == types.AttackerRole {
+			length++
+			if length > maxLength {
+				maxLength = length
+			}
+		} else {
+			length = 0
+		}
+	}
+	return maxLength, nil
+}
+
+// Conditions returns the distinct conditions of the parsed slot strategies.
+func Conditions(is []InternalSlotStrategy) []*Condition {
+	seen := make(map[*Condition]bool)
+	conditions := make([]*Condition, 0)
+	for _, s := range is {
+		if s.Condition != nil && !seen[s.Condition] {
+			seen[s.Condition] = true
+			conditions = append(conditions, s.Condition)
+		}
+	}
+	return conditions
+}
@@ -0,0 +1,269 @@
+package slotstrategy
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/tsinghua-cel/attacker-service/types"
+)
+
+// conditionBackend is a chain of 4 slots per epoch, validator i is the
+// proposer of slot i of every epoch unless the duties of the epoch are set.
+type conditionBackend struct {
+	duties    map[int][]int // validator indices by epoch
+	dutiesErr error
+	attackers map[int]bool
+	finalized int64
+	reorgs    map[int64]int64 // max reorg depth by epoch
+}
+
+func (b *conditionBackend) GetSlotsPerEpoch() (int, error) {
+	return 4, nil
+}
+
+func (b *conditionBackend) GetProposeDuties(epoch int) ([]types.ProposerDuty, error) {
+	if b.dutiesErr != nil {
+		return nil, b.dutiesErr
+	}
+	validators, ok := b.duties[epoch]
+	if !ok {
+		validators = []int{0, 1, 2, 3}
+	}
+	duties := make([]types.ProposerDuty, 0, len(validators))
+	for i, idx := range validators {
+		duties = append(duties, types.ProposerDuty{
+			Slot:           strconv.Itoa(epoch*4 + i),
+			ValidatorIndex: strconv.Itoa(idx),
+		})
+	}
+	return duties, nil
+}
+
+func (b *conditionBackend) GetValidatorRole(slot int, valIdx int) types.RoleType {
+	if b.attackers[valIdx] {
+		return types.AttackerRole
+	}
+	return types.NormalRole
+}
+
+func (b *conditionBackend) GetFinalizedEpoch() (int64, error) {
+	return b.finalized, nil
+}
+
+func (b *conditionBackend) GetMaxReorgDepth(fromEpoch, toEpoch int64) int64 {
+	depth := int64(0)
+	for epoch := fromEpoch; epoch <= toEpoch; epoch++ {
+		if b.reorgs[epoch] > depth {
+			depth = b.reorgs[epoch]
+		}
+	}
+	return depth
+}
+
+func TestParseConditional(t *testing.T) {
+	slots := []types.SlotStrategy{
+		{Slot: "every", Actions: map[string]string{"BlockBeforeBroadCast": "delayToNextSlot"}},
+		{Slot: "12", Actions: map[string]string{"AttestBeforeSign": "voteParentOfHead"}},
+	}
+	tests := []struct {
+		name        string
+		conditional types.ConditionalStrategy
+		wantErr     string
+	}{
+		{
+			name: "triggers and until",
+			conditional: types.ConditionalStrategy{
+				Name:     "sandwich",
+				Triggers: []types.Trigger{{Type: types.TriggerAttackerProposers, Value: 2}},
+				Until:    []types.Trigger{{Type: types.TriggerFinalityLag, Value: 3}},
+				Slots:    slots,
+			},
+		},
+		{
+			name: "no trigger",
+			conditional: types.ConditionalStrategy{
+				Name:  "sandwich",
+				Until: []types.Trigger{{Type: types.TriggerFinalityLag, Value: 3}},
+				Slots: slots,
+			},
+			wantErr: "sandwich: no trigger",
+		},
+		{
+			name: "unknown trigger",
+			conditional: types.ConditionalStrategy{
+				Name:     "sandwich",
+				Triggers: []types.Trigger{{Type: "attackerproposers", Value: 2}},
+				Slots:    slots,
+			},
+			wantErr: "unknown trigger type attackerproposers",
+		},
+		{
+			name: "unknown until trigger",
+			conditional: types.ConditionalStrategy{
+				Name:     "sandwich",
+				Triggers: []types.Trigger{{Type: types.TriggerReorgDepth, Value: 1}},
+				Until:    []types.Trigger{{Type: ""}},
+				Slots:    slots,
+			},
+			wantErr: "unknown trigger type",
+		},
+		{
+			name: "invalid slot rule",
+			conditional: types.ConditionalStrategy{
+				Name:     "sandwich",
+				Triggers: []types.Trigger{{Type: types.TriggerReorgDepth, Value: 1}},
+				Slots:    []types.SlotStrategy{{Slot: "12", Actions: map[string]string{"NoSuchPoint": "null"}}},
+			},
+			wantErr: "action point NoSuchPoint not exist",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			strategy := &types.Strategy{
+				Slots:       []types.SlotStrategy{{Slot: "3", Actions: map[string]string{"AttestBeforeSign": "null"}}},
+				Conditional: []types.ConditionalStrategy{tt.conditional},
+			}
+			is, err := ParseToInternalSlotStrategy(newTestBackend(12), strategy)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got err %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(is) != 3 || is[0].Condition != nil {
+				t.Fatalf("parsed %d rules, the unconditional one has condition %v", len(is), is[0].Condition)
+			}
+			if is[1].Condition == nil || is[1].Condition != is[2].Condition {
+				t.Fatal("the rules of the block do not share its condition")
+			}
+			conditions := Conditions(is)
+			if len(conditions) != 1 || conditions[0].Name != "sandwich" || conditions[0].epochs != 1 {
+				t.Fatalf("got conditions %v", conditions)
+			}
+			// a conditional rule is disabled until its condition is evaluated.
+			if is[2].Match(12) || !is[0].Match(3) {
+				t.Error("the disabled conditional rule matches")
+			}
+		})
+	}
+}
+
+func TestConditionEvaluate(t *testing.T) {
+	tests := []struct {
+		name      string
+		triggers  []types.Trigger
+		until     []types.Trigger
+		epochs    int
+		backend   *conditionBackend
+		evaluate  []int64 // the epochs evaluated in order
+		wantFrom  int64
+		wantTo    int64
+		wantNever bool
+	}{
+		{
+			name:     "all triggers hold",
+			triggers: []types.Trigger{{Type: types.TriggerAttackerProposers, Value: 2}, {Type: types.TriggerFinalityLag, Value: 1}},
+			backend:  &conditionBackend{attackers: map[int]bool{1: true, 2: true}},
+			evaluate: []int64{3},
+			wantFrom: 4,
+			wantTo:   4,
+		},
+		{
+			name:      "one trigger fails",
+			triggers:  []types.Trigger{{Type: types.TriggerAttackerProposers, Value: 2}, {Type: types.TriggerFinalityLag, Value: 5}},
+			backend:   &conditionBackend{attackers: map[int]bool{1: true, 2: true}},
+			evaluate:  []int64{3},
+			wantNever: true,
+		},
+		{
+			name:      "attackers not consecutive",
+			triggers:  []types.Trigger{{Type: types.TriggerAttackerProposers, Value: 2}},
+			backend:   &conditionBackend{attackers: map[int]bool{1: true, 3: true}},
+			evaluate:  []int64{3},
+			wantNever: true,
+		},
+		{
+			name:     "attackers of the next epoch",
+			triggers: []types.Trigger{{Type: types.TriggerAttackerProposers, Value: 2}},
+			backend: &conditionBackend{
+				duties:    map[int][]int{3: {0, 4, 5, 1}, 4: {4, 0, 5, 1}},
+				attackers: map[int]bool{4: true, 5: true},
+			},
+			evaluate: []int64{2, 3},
+			wantFrom: 3,
+			wantTo:   3,
+		},
+		{
+			name:      "until takes precedence",
+			triggers:  []types.Trigger{{Type: types.TriggerReorgDepth, Value: 1}},
+			until:     []types.Trigger{{Type: types.TriggerFinalityLag, Value: 2}},
+			backend:   &conditionBackend{reorgs: map[int64]int64{3: 1}},
+			evaluate:  []int64{3},
+			wantNever: true,
+		},
+		{
+			name:     "until disables the enabled block",
+			triggers: []types.Trigger{{Type: types.TriggerReorgDepth, Value: 1}},
+			until:    []types.Trigger{{Type: types.TriggerFinalityLag, Value: 3}},
+			epochs:   5,
+			backend:  &conditionBackend{reorgs: map[int64]int64{2: 1}},
+			evaluate: []int64{2, 3, 4},
+			wantFrom: 3,
+			wantTo:   3,
+		},
+		{
+			name:     "enabled again extends the block",
+			triggers: []types.Trigger{{Type: types.TriggerReorgDepth, Value: 1}},
+			epochs:   2,
+			backend:  &conditionBackend{reorgs: map[int64]int64{2: 1}},
+			evaluate: []int64{2, 3},
+			wantFrom: 3,
+			wantTo:   5,
+		},
+		{
+			name:     "evaluated once per epoch",
+			triggers: []types.Trigger{{Type: types.TriggerReorgDepth, Value: 2}},
+			backend:  &conditionBackend{reorgs: map[int64]int64{4: 2}},
+			evaluate: []int64{5, 4},
+			wantFrom: 6,
+			wantTo:   6,
+		},
+		{
+			name:      "trigger error",
+			triggers:  []types.Trigger{{Type: types.TriggerAttackerProposers, Value: 1}},
+			backend:   &conditionBackend{dutiesErr: errors.New("no duties")},
+			evaluate:  []int64{3},
+			wantNever: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := newCondition(newTestBackend(0), types.ConditionalStrategy{
+				Name:     tt.name,
+				Triggers: tt.triggers,
+				Until:    tt.until,
+				Epochs:   tt.epochs,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, epoch := range tt.evaluate {
+				c.Evaluate(tt.backend, epoch)
+			}
+			for epoch := int64(0); epoch < 10; epoch++ {
+				want := !tt.wantNever && epoch >= tt.wantFrom && epoch <= tt.wantTo
+				if c.Enabled(epoch) != want {
+					t.Errorf("enabled at epoch %d is %v, want %v", epoch, c.Enabled(epoch), want)
+				}
+			}
+			if want := !tt.wantNever && tt.wantFrom == 3; c.EnabledAt(12) != want {
+				t.Errorf("enabled at slot 12 is %v, want %v", c.EnabledAt(12), want)
+			}
+		})
+	}
+}
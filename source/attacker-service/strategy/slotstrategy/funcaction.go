@@ -7,9 +7,10 @@ import (
 	attaggregation "github.com/prysmaticlabs/prysm/v5/proto/prysm/v1alpha1/attestation/aggregation/attestations"
 	log "github.com/sirupsen/logrus"
 	"github.com/tsinghua-cel/attacker-service/common"
+	"github.com/tsinghua-cel/attacker-service/decision"
+	"github.com/tsinghua-cel/attacker-service/metrics"
 	"github.com/tsinghua-cel/attacker-service/plugins"
 	"github.com/tsinghua-cel/attacker-service/types"
-	"math/rand"
 	"strconv"
 	"strings"
 	"time"
@@ -30,6 +31,13 @@ func (f FunctionAction) RunAction(backend types.ServiceBackend, slot int64, pubk
 	}
 }
 
+// delay delays the duty for the given duration, it is tracked as an active
+// delay and pushed to the decision subscribers. It returns the decision id
+// without waiting if the validator client holds the duty itself.
+func delay(action string, slot int64, pubkey string, d time.Duration) string {
+	return decision.Delay(action, slot, pubkey, d, metrics.DelayStart(action))
+}
+
 func getCmdFromName(name string) types.AttackerCommand {
 	switch name {
 	case "null":
@@ -78,6 +86,46 @@ func GetFunctionAction(backend types.ServiceBackend, action string) (ActionDo, e
 			}
 			return r
 		}, nil
+	case "voteHeadOfSlot":
+		n := 1
+		if len(params) > 0 {
+			n = params[0]
+		}
+		return voteAction(name, voteHeadOfSlot(n)), nil
+	case "voteAttackerChainTip":
+		return voteAction(name, voteAttackerChainTip), nil
+	case "voteParentOfHead":
+		return voteAction(name, voteParentOfHead), nil
+	case "voteStaleTarget":
+		return voteAction(name, voteStaleTarget), nil
+	case "doubleProposal":
+		mode := doubleProposalBody
+		if len(params) > 0 {
+			mode = params[0]
+		}
+		return slashableAction(name, doubleProposal(mode)), nil
+	case "doubleVote":
+		return slashableAction(name, doubleVote), nil
+	case "surroundVote":
+		return slashableAction(name, surroundVote), nil
+	case "storeSignedBlock":
+		return func(backend types.ServiceBackend, slot int64, pubkey string, params ...interface{}) plugins.PluginResponse {
+			r := plugins.PluginResponse{
+				Cmd: types.CMD_NULL,
+			}
+			if len(params) == 0 {
+				return r
+			}
+			r.Result = params[0]
+			if block, ok := params[0].(*ethpb.SignedBeaconBlockDeneb); ok {
+				backend.AddSignedBlock(uint64(slot), pubkey, &ethpb.GenericSignedBeaconBlock{
+					Block: &ethpb.GenericSignedBeaconBlock_Deneb{
+						Deneb: &ethpb.SignedBeaconBlockContentsDeneb{Block: block},
+					},
+				})
+			}
+			return r
+		}, nil
 	case "storeSignedAttest":
 		return func(backend types.ServiceBackend, slot int64, pubkey string, params ...interface{}) plugins.PluginResponse {
 			var attestation *ethpb.Attestation
@@ -97,7 +145,7 @@ func GetFunctionAction(backend types.ServiceBackend, action string) (ActionDo, e
 	case "delayWithSecond":
 		var seconds int
 		if len(params) == 0 {
-			seconds = rand.Intn(10)
+			seconds = randIntn(10)
 		} else {
 			seconds = params[0]
 		}
@@ -111,11 +159,15 @@ func GetFunctionAction(backend types.ServiceBackend, action string) (ActionDo, e
 				"slot":    slot,
 				"seconds": seconds,
 			}).Info("delayWithSecond")
-			time.Sleep(time.Second * time.Duration(seconds))
+			r.Decision = delay(name, slot, pubkey, time.Second*time.Duration(seconds))
 			return r
 		}, nil
 	case "delayToNextSlot":
-		seconds := backend.GetIntervalPerSlot()
+		spec, err := backend.GetChainSpec()
+		if err != nil {
+			return nil, err
+		}
+		seconds := spec.SecondsPerSlot
 		return func(backend types.ServiceBackend, slot int64, pubkey string, params ...interface{}) plugins.PluginResponse {
 			r := plugins.PluginResponse{
 				Cmd: types.CMD_NULL,
@@ -131,12 +183,16 @@ func GetFunctionAction(backend types.ServiceBackend, action string) (ActionDo, e
 				"slot":    slot,
 				"seconds": esti,
 			}).Info("delayToNextSlot")
-			time.Sleep(time.Second * time.Duration(esti))
+			r.Decision = delay(name, slot, pubkey, time.Second*time.Duration(esti))
 			return r
 		}, nil
 	case "delayToAfterNextSlot":
-		seconds := backend.GetIntervalPerSlot()
-		afters := rand.Intn(10)
+		spec, err := backend.GetChainSpec()
+		if err != nil {
+			return nil, err
+		}
+		seconds := spec.SecondsPerSlot
+		afters := randIntn(10)
 		if len(params) > 0 {
 			afters = params[0]
 		}
@@ -156,7 +212,7 @@ func GetFunctionAction(backend types.ServiceBackend, action string) (ActionDo, e
 				"slot":    slot,
 				"seconds": esti,
 			}).Info("delayToAfterNextSlot")
-			time.Sleep(time.Second * time.Duration(esti))
+			r.Decision = delay(name, slot, pubkey, time.Second*time.Duration(esti))
 			return r
 		}, nil
 	case "delayToNextNEpochStart":
@@ -164,8 +220,12 @@ func GetFunctionAction(backend types.ServiceBackend, action string) (ActionDo, e
 		if len(params) > 0 {
 			n = params[0]
 		}
-		slotsPerEpoch := backend.GetSlotsPerEpoch()
-		seconds := backend.GetIntervalPerSlot()
+		spec, err := backend.GetChainSpec()
+		if err != nil {
+			return nil, err
+		}
+		slotsPerEpoch := spec.SlotsPerEpoch
+		seconds := spec.SecondsPerSlot
 		return func(backend types.ServiceBackend, slot int64, pubkey string, params ...interface{}) plugins.PluginResponse {
 			tool := common.SlotTool{
 				SlotsPerEpoch: slotsPerEpoch,
@@ -177,13 +237,13 @@ func GetFunctionAction(backend types.ServiceBackend, action string) (ActionDo, e
 				"slot":  slot,
 				"total": total,
 			}).Info("delayToNextNEpochStart")
-			time.Sleep(time.Second * time.Duration(total))
 			r := plugins.PluginResponse{
 				Cmd: types.CMD_NULL,
 			}
 			if len(params) > 0 {
 				r.Result = params[0]
 			}
+			r.Decision = delay(name, slot, pubkey, time.Second*time.Duration(total))
 			return r
 		}, nil
 	case "delayToNextNEpochEnd":
@@ -191,8 +251,12 @@ func GetFunctionAction(backend types.ServiceBackend, action string) (ActionDo, e
 		if len(params) > 0 {
 			n = params[0]
 		}
-		slotsPerEpoch := backend.GetSlotsPerEpoch()
-		seconds := backend.GetIntervalPerSlot()
+		spec, err := backend.GetChainSpec()
+		if err != nil {
+			return nil, err
+		}
+		slotsPerEpoch := spec.SlotsPerEpoch
+		seconds := spec.SecondsPerSlot
 		return func(backend types.ServiceBackend, slot int64, pubkey string, params ...interface{}) plugins.PluginResponse {
 			tool := common.SlotTool{
 				SlotsPerEpoch: slotsPerEpoch,
@@ -204,13 +268,13 @@ func GetFunctionAction(backend types.ServiceBackend, action string) (ActionDo, e
 				"slot":  slot,
 				"total": total,
 			}).Info("delayToNextNEpochEnd")
-			time.Sleep(time.Second * time.Duration(total))
 			r := plugins.PluginResponse{
 				Cmd: types.CMD_NULL,
 			}
 			if len(params) > 0 {
 				r.Result = params[0]
 			}
+			r.Decision = delay(name, slot, pubkey, time.Second*time.Duration(total))
 			return r
 		}, nil
 	case "delayToNextNEpochHalf":
@@ -218,8 +282,12 @@ func GetFunctionAction(backend types.ServiceBackend, action string) (ActionDo, e
 		if len(params) > 0 {
 			n = params[0]
 		}
-		slotsPerEpoch := backend.GetSlotsPerEpoch()
-		seconds := backend.GetIntervalPerSlot()
+		spec, err := backend.GetChainSpec()
+		if err != nil {
+			return nil, err
+		}
+		slotsPerEpoch := spec.SlotsPerEpoch
+		seconds := spec.SecondsPerSlot
 		return func(backend types.ServiceBackend, slot int64, pubkey string, params ...interface{}) plugins.PluginResponse {
 			tool := common.SlotTool{
 				SlotsPerEpoch: slotsPerEpoch,
@@ -231,19 +299,29 @@ func GetFunctionAction(backend types.ServiceBackend, action string) (ActionDo, e
 				"slot":  slot,
 				"total": total,
 			}).Info("delayToNextNEpochHalf")
-			time.Sleep(time.Second * time.Duration(total))
 			r := plugins.PluginResponse{
 				Cmd: types.CMD_NULL,
 			}
 			if len(params) > 0 {
 				r.Result = params[0]
 			}
+			r.Decision = delay(name, slot, pubkey, time.Second*time.Duration(total))
 			return r
 		}, nil
 
 	case "delayToEpochEnd":
 		return func(backend types.ServiceBackend, slot int64, pubkey string, params ...interface{}) plugins.PluginResponse {
-			slotsPerEpoch := backend.GetSlotsPerEpoch()
+			r := plugins.PluginResponse{
+				Cmd: types.CMD_NULL,
+			}
+			if len(params) > 0 {
+				r.Result = params[0]
+			}
+			slotsPerEpoch, err := backend.GetSlotsPerEpoch()
+			if err != nil {
+				log.WithError(err).Error("delayToEpochEnd get slots per epoch failed")
+				return r
+			}
 			tool := common.SlotTool{
 				SlotsPerEpoch: slotsPerEpoch,
 			}
@@ -256,32 +334,29 @@ func GetFunctionAction(backend types.ServiceBackend, action string) (ActionDo, e
 				"slot":  slot,
 				"total": total,
 			}).Info("delayToEpochEnd")
-			time.Sleep(time.Second * time.Duration(total))
+			r.Decision = delay(name, slot, pubkey, time.Second*time.Duration(total))
+			return r
+		}, nil
+	case "delayHalfEpoch":
+		return func(backend types.ServiceBackend, slot int64, pubkey string, params ...interface{}) plugins.PluginResponse {
 			r := plugins.PluginResponse{
 				Cmd: types.CMD_NULL,
 			}
-
 			if len(params) > 0 {
 				r.Result = params[0]
 			}
-			return r
-		}, nil
-	case "delayHalfEpoch":
-		return func(backend types.ServiceBackend, slot int64, pubkey string, params ...interface{}) plugins.PluginResponse {
-			slotsPerEpoch := backend.GetSlotsPerEpoch()
+			slotsPerEpoch, err := backend.GetSlotsPerEpoch()
+			if err != nil {
+				log.WithError(err).Error("delayHalfEpoch get slots per epoch failed")
+				return r
+			}
 			seconds := backend.GetIntervalPerSlot()
 			total := (seconds) * (slotsPerEpoch / 2)
 			log.WithFields(log.Fields{
 				"slot":  slot,
 				"total": total,
 			}).Info("delayHalfEpoch")
-			time.Sleep(time.Second * time.Duration(total))
-			r := plugins.PluginResponse{
-				Cmd: types.CMD_NULL,
-			}
-			if len(params) > 0 {
-				r.Result = params[0]
-			}
+			r.Decision = delay(name, slot, pubkey, time.Second*time.Duration(total))
 			return r
 		}, nil
 	case "rePackAttestation":
@@ -295,8 +370,13 @@ func GetFunctionAction(backend types.ServiceBackend, action string) (ActionDo, e
 			}
 			block := params[0].(*ethpb.SignedBeaconBlockDeneb)
 
+			slotsPerEpoch, err := backend.SlotsPerEpoch()
+			if err != nil {
+				log.WithError(err).Error("rePackAttestation get slots per epoch failed")
+				return r
+			}
 			tool := common.SlotTool{
-				SlotsPerEpoch: backend.SlotsPerEpoch(),
+				SlotsPerEpoch: slotsPerEpoch,
 			}
 			epoch := tool.SlotToEpoch(slot)
 			startEpoch := tool.EpochStart(epoch)
@@ -6,19 +6,26 @@ import (
 	log "github.com/sirupsen/logrus"
 	"github.com/tsinghua-cel/attacker-service/common"
 	"github.com/tsinghua-cel/attacker-service/types"
+	"math"
 	"strconv"
 )
 
-type SlotCalc func(slot int64) int64
+type SlotCalc func(slot int64) (int64, error)
 
 type FunctionSlot struct {
 	calcFunc SlotCalc
 }
 
+// Compare returns 1 (no match) if the slot can not be calculated.
 func (f FunctionSlot) Compare(slot int64) int {
 	cSlot := int64(0)
 	if f.calcFunc != nil {
-		cSlot = f.calcFunc(slot)
+		var err error
+		cSlot, err = f.calcFunc(slot)
+		if err != nil {
+			log.WithError(err).WithField("slot", slot).Warn("calculate function slot failed")
+			return 1
+		}
 	}
 	if cSlot > slot {
 		return 1
@@ -30,114 +37,109 @@ func (f FunctionSlot) Compare(slot int64) int {
 }
 
 func GetFunctionSlot(backend types.ServiceBackend, name string) (SlotCalc, error) {
+	spec, err := backend.GetChainSpec()
+	if err != nil {
+		return nil, err
+	}
+	tool := common.SlotTool{
+		SlotsPerEpoch: spec.SlotsPerEpoch,
+	}
+	// lastAttackerSlot returns the last slot of the epoch proposed by an attacker, or -1.
+	lastAttackerSlot := func(epoch int64) (int64, error) {
+		latestSlotWithAttacker := int64(-1)
+		duties, err := backend.GetProposeDuties(int(epoch))
+		if err != nil {
+			return latestSlotWithAttacker, err
+		}
 
-	switch name {
+		for _, duty := range duties {
+			dutySlot, _ := strconv.ParseInt(duty.Slot, 10, 64)
+			dutyValIdx, _ := strconv.Atoi(duty.ValidatorIndex)
+			if backend.GetValidatorRole(int(dutySlot), dutyValIdx) == types.AttackerRole && dutySlot > latestSlotWithAttacker {
+				latestSlotWithAttacker = dutySlot
+			}
+		}
+		return latestSlotWithAttacker, nil
+	}
+
+	base, params := ParseActionName(name)
+	switch base {
+	case "period":
+		// period:n:k matches the slots with slot % n == k, period:n:k:from:to
+		// only the ones from slot from to slot to.
+		if len(params) == 0 || len(params) == 3 || len(params) > 4 || params[0] <= 0 {
+			return nil, fmt.Errorf("invalid period of function slot:%s", name)
+		}
+		period, offset := int64(params[0]), int64(0)
+		if len(params) > 1 {
+			offset = int64(params[1])
+		}
+		if offset < 0 || offset >= period {
+			return nil, fmt.Errorf("invalid offset of function slot:%s", name)
+		}
+		from, to := int64(0), int64(math.MaxInt64)
+		if len(params) == 4 {
+			from, to = int64(params[2]), int64(params[3])
+		}
+		if from < 0 || from > to {
+			return nil, fmt.Errorf("invalid slots of function slot:%s", name)
+		}
+		return func(slot int64) (int64, error) {
+			if slot%period == offset && slot >= from && slot <= to {
+				return slot, nil
+			}
+			return slot + 1, nil
+		}, nil
 	case "every":
-		return func(slot int64) int64 {
-			return slot
+		return func(slot int64) (int64, error) {
+			return slot, nil
 		}, nil
 	case "attackerSlot":
-		return func(slot int64) int64 {
-			slotsPerEpoch := backend.SlotsPerEpoch()
-			tool := common.SlotTool{
-				SlotsPerEpoch: slotsPerEpoch,
-			}
+		return func(slot int64) (int64, error) {
 			epoch := tool.SlotToEpoch(slot)
 			duties, err := backend.GetProposeDuties(int(epoch))
 			if err != nil {
-				return slot + 1
+				return 0, err
 			}
 
 			for _, duty := range duties {
 				dutySlot, _ := strconv.ParseInt(duty.Slot, 10, 64)
 				dutyValIdx, _ := strconv.Atoi(duty.ValidatorIndex)
 				if backend.GetValidatorRole(int(dutySlot), dutyValIdx) == types.AttackerRole && dutySlot == slot {
-					return slot
+					return slot, nil
 				}
 			}
-			return slot + 1
+			return slot + 1, nil
 		}, nil
 
 	case "lastSlotInCurrentEpoch":
-		slotsPerEpoch := backend.SlotsPerEpoch()
-		tool := common.SlotTool{
-			SlotsPerEpoch: slotsPerEpoch,
-		}
-		return func(slot int64) int64 {
+		return func(slot int64) (int64, error) {
 			epoch := tool.SlotToEpoch(slot)
-			return tool.EpochEnd(epoch)
+			return tool.EpochEnd(epoch), nil
 		}, nil
 	case "lastSlotInNextEpoch":
-		slotsPerEpoch := backend.SlotsPerEpoch()
-		tool := common.SlotTool{
-			SlotsPerEpoch: slotsPerEpoch,
-		}
-		return func(slot int64) int64 {
+		return func(slot int64) (int64, error) {
 			epoch := tool.SlotToEpoch(slot)
-			return tool.EpochEnd(epoch + 1)
+			return tool.EpochEnd(epoch + 1), nil
 		}, nil
 
 	case "firstSlotInCurrentEpoch":
-		slotsPerEpoch := backend.SlotsPerEpoch()
-		tool := common.SlotTool{
-			SlotsPerEpoch: slotsPerEpoch,
-		}
-		return func(slot int64) int64 {
+		return func(slot int64) (int64, error) {
 			epoch := tool.SlotToEpoch(slot)
-			return tool.EpochStart(epoch)
+			return tool.EpochStart(epoch), nil
 		}, nil
 	case "firstSlotInNextEpoch":
-		slotsPerEpoch := backend.SlotsPerEpoch()
-		tool := common.SlotTool{
-			SlotsPerEpoch: slotsPerEpoch,
-		}
-		return func(slot int64) int64 {
+		return func(slot int64) (int64, error) {
 			epoch := tool.SlotToEpoch(slot)
-			return tool.EpochStart(epoch + 1)
+			return tool.EpochStart(epoch + 1), nil
 		}, nil
 	case "lastAttackerSlotInCurrentEpoch":
-		return func(slot int64) int64 {
-			slotsPerEpoch := backend.SlotsPerEpoch()
-			tool := common.SlotTool{
-				SlotsPerEpoch: slotsPerEpoch,
-			}
-			epoch := tool.SlotToEpoch(slot)
-			latestSlotWithAttacker := int64(-1)
-			duties, err := backend.GetProposeDuties(int(epoch))
-			if err != nil {
-				return latestSlotWithAttacker
-			}
-
-			for _, duty := range duties {
-				dutySlot, _ := strconv.ParseInt(duty.Slot, 10, 64)
-				dutyValIdx, _ := strconv.Atoi(duty.ValidatorIndex)
-				if backend.GetValidatorRole(int(dutySlot), dutyValIdx) == types.AttackerRole && dutySlot > latestSlotWithAttacker {
-					latestSlotWithAttacker = dutySlot
-				}
-			}
-			return latestSlotWithAttacker
+		return func(slot int64) (int64, error) {
+			return lastAttackerSlot(tool.SlotToEpoch(slot))
 		}, nil
 	case "lastAttackerSlotInNextEpoch":
-		return func(slot int64) int64 {
-			slotsPerEpoch := backend.SlotsPerEpoch()
-			tool := common.SlotTool{
-				SlotsPerEpoch: slotsPerEpoch,
-			}
-			epoch := tool.SlotToEpoch(slot)
-			latestSlotWithAttacker := int64(-1)
-			duties, err := backend.GetProposeDuties(int(epoch + 1))
-			if err != nil {
-				return latestSlotWithAttacker
-			}
-
-			for _, duty := range duties {
-				dutySlot, _ := strconv.ParseInt(duty.Slot, 10, 64)
-				dutyValIdx, _ := strconv.Atoi(duty.ValidatorIndex)
-				if backend.GetValidatorRole(int(dutySlot), dutyValIdx) == types.AttackerRole && dutySlot > latestSlotWithAttacker {
-					latestSlotWithAttacker = dutySlot
-				}
-			}
-			return latestSlotWithAttacker
+		return func(slot int64) (int64, error) {
+			return lastAttackerSlot(tool.SlotToEpoch(slot) + 1)
 		}, nil
 	default:
 		log.WithField("name", name).Error("unknown function slot name")
@@ -0,0 +1,50 @@
+package slotstrategy
+
+import "testing"
+
+func TestPeriodFunctionSlot(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    []int64 // the matched slots of 0 to 15
+		wantErr bool
+	}{
+		{name: "period:4", want: []int64{0, 4, 8, 12}},
+		{name: "period:4:1", want: []int64{1, 5, 9, 13}},
+		{name: "period:1:0:5:7", want: []int64{5, 6, 7}},
+		{name: "period:4:2:3:10", want: []int64{6, 10}},
+		{name: "period:0", wantErr: true},
+		{name: "period:4:4", wantErr: true},
+		{name: "period:4:1:5", wantErr: true},
+		{name: "period:4:1:7:5", wantErr: true},
+		{name: "period:4:1:-1:5", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calc, err := GetFunctionSlot(newTestBackend(0), tt.name)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("parsed the invalid function slot")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			f := FunctionSlot{calcFunc: calc}
+			got := make([]int64, 0)
+			for slot := int64(0); slot < 16; slot++ {
+				if f.Compare(slot) == 0 {
+					got = append(got, slot)
+				}
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("matched %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("matched %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
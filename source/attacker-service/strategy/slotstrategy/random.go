@@ -0,0 +1,27 @@
+package slotstrategy
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// rng is used by all random actions, it is seeded by SetSeed to make the
+// random parameters of a strategy reproducible.
+var (
+	rngMu sync.Mutex
+	rng   = rand.New(rand.NewSource(time.Now().UnixNano()))
+)
+
+// SetSeed seeds the random source of the actions.
+func SetSeed(seed int64) {
+	rngMu.Lock()
+	defer rngMu.Unlock()
+	rng = rand.New(rand.NewSource(seed))
+}
+
+func randIntn(n int) int {
+	rngMu.Lock()
+	defer rngMu.Unlock()
+	return rng.Intn(n)
+}
@@ -0,0 +1,239 @@
+package slotstrategy
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/prysmaticlabs/prysm/v5/consensus-types/primitives"
+	ethpb "github.com/prysmaticlabs/prysm/v5/proto/prysm/v1alpha1"
+	log "github.com/sirupsen/logrus"
+	"github.com/tsinghua-cel/attacker-service/common"
+	"github.com/tsinghua-cel/attacker-service/plugins"
+	"github.com/tsinghua-cel/attacker-service/types"
+)
+
+// The slashable actions make an attacker validator sign a second, conflicting
+// block or vote. The extra message is signed with the keys of the signer config
+// and published by the service, the message of the validator client is not changed.
+
+var ErrSlashableNotAllowed = errors.New("slashable action is not allowed, set allow_slashable in the strategy")
+
+var slashableActions = map[string]bool{
+	"doubleProposal": true,
+	"doubleVote":     true,
+	"surroundVote":   true,
+}
+
+// IsSlashableAction returns true if the action makes the validator slashable.
+func IsSlashableAction(action string) bool {
+	name, _ := ParseActionName(action)
+	return slashableActions[name]
+}
+
+var doubleProposalGraffiti = []byte("attacker double proposal")
+
+// The modes of doubleProposal.
+const (
+	// doubleProposalBody conflicts by the body, the block is produced with another graffiti.
+	doubleProposalBody = 0
+	// doubleProposalParent conflicts by the parent, the block is produced on
+	// the parent of the parent of the signed block.
+	doubleProposalParent = 1
+)
+
+type slashableDo func(backend types.ServiceBackend, slot int64, pubkey string, param interface{}) error
+
+func slashableAction(name string, do slashableDo) ActionDo {
+	return func(backend types.ServiceBackend, slot int64, pubkey string, params ...interface{}) plugins.PluginResponse {
+		r := plugins.PluginResponse{
+			Cmd: types.CMD_NULL,
+		}
+		if len(params) == 0 {
+			return r
+		}
+		r.Result = params[0]
+		if err := do(backend, slot, pubkey, params[0]); err != nil {
+			log.WithError(err).WithField("slot", slot).Warn(name + " failed")
+			return r
+		}
+		log.WithFields(log.Fields{
+			"slot":   slot,
+			"pubkey": pubkey,
+		}).Info(name)
+		return r
+	}
+}
+
+// doubleProposal publishes a second block of the same slot, it is produced by
+// the beacon node with another graffiti so it has a different body. In the
+// parent mode it is produced on the parent of the parent of the signed block,
+// which needs a beacon node calling the BlockGetNewParentRoot hook.
+func doubleProposal(mode int) slashableDo {
+	return func(backend types.ServiceBackend, slot int64, pubkey string, param interface{}) error {
+		block, ok := param.(*ethpb.SignedBeaconBlockDeneb)
+		if !ok || block == nil || block.Block == nil {
+			return common.ErrUnsupportedBeaconBlock
+		}
+		if backend.GetValidatorRole(int(slot), int(block.Block.ProposerIndex)) != types.AttackerRole {
+			return fmt.Errorf("proposer %d is not attacker", block.Block.ProposerIndex)
+		}
+		var parentRoot []byte
+		if mode == doubleProposalParent {
+			grandparent, err := grandparentRoot(backend, block.Block.ParentRoot)
+			if err != nil {
+				return err
+			}
+			parentRoot = grandparent
+		}
+		contents, err := backend.ProduceBlock(uint64(block.Block.Slot), block.Block.Body.RandaoReveal, doubleProposalGraffiti, parentRoot)
+		if err != nil {
+			return err
+		}
+		if contents.Block.ProposerIndex != block.Block.ProposerIndex {
+			return fmt.Errorf("produced block proposer %d mismatch", contents.Block.ProposerIndex)
+		}
+		if parentRoot != nil && !bytes.Equal(contents.Block.ParentRoot, parentRoot) {
+			return errors.New("the block is not produced on the grandparent, the beacon node doesn't call the attacker service")
+		}
+		signature, err := backend.SignBlock(pubkey, contents.Block)
+		if err != nil {
+			return err
+		}
+		return backend.PublishBlock(&ethpb.SignedBeaconBlockContentsDeneb{
+			Block:     &ethpb.SignedBeaconBlockDeneb{Block: contents.Block, Signature: signature},
+			KzgProofs: contents.KzgProofs,
+			Blobs:     contents.Blobs,
+		})
+	}
+}
+
+// grandparentRoot returns the root of the parent of the block parentRoot.
+func grandparentRoot(backend types.ServiceBackend, parentRoot []byte) ([]byte, error) {
+	parent, err := backend.GetBeaconHeader(hexutil.Encode(parentRoot))
+	if err != nil {
+		return nil, err
+	}
+	grandparent, err := hexutil.Decode(parent.Header.Message.ParentRoot)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.Equal(grandparent, make([]byte, len(grandparent))) {
+		return nil, errors.New("the parent block has no parent")
+	}
+	return grandparent, nil
+}
+
+// doubleVote publishes a second vote of the same target epoch for the parent
+// of the voted head.
+func doubleVote(backend types.ServiceBackend, slot int64, pubkey string, param interface{}) error {
+	return conflictingVote(backend, slot, pubkey, param, voteParentOfHead)
+}
+
+// surroundVote publishes a second vote from the checkpoint before the voted
+// source to the epoch after the voted target, it surrounds the signed vote.
+// The vote is of the same slot of the next epoch and submitted once it starts.
+func surroundVote(backend types.ServiceBackend, slot int64, pubkey string, param interface{}) error {
+	return conflictingVote(backend, slot, pubkey, param, voteSurrounding)
+}
+
+func conflictingVote(backend types.ServiceBackend, slot int64, pubkey string, param interface{}, rewrite voteRewrite) error {
+	att, ok := param.(*ethpb.Attestation)
+	if !ok || att == nil || att.Data == nil {
+		return errors.New("no signed attestation")
+	}
+	if backend.GetValidatorRoleByPubkey(int(slot), pubkey) != types.AttackerRole {
+		return fmt.Errorf("validator %s is not attacker", pubkey)
+	}
+	data := ethpb.CopyAttestationData(att.Data)
+	if err := rewrite(backend, slot, data); err != nil {
+		return err
+	}
+	origin, err := att.Data.HashTreeRoot()
+	if err != nil {
+		return err
+	}
+	conflict, err := data.HashTreeRoot()
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(origin[:], conflict[:]) {
+		return errors.New("conflicting vote is the same as the origin vote")
+	}
+	signature, err := backend.SignAttestation(pubkey, data)
+	if err != nil {
+		return err
+	}
+	return submitAtSlot(backend, &ethpb.Attestation{
+		AggregationBits: att.AggregationBits,
+		Data:            data,
+		Signature:       signature,
+	})
+}
+
+// submitAtSlot submits the attestation, or schedules it at the start of its
+// slot if the slot is in the future since the nodes reject it before.
+func submitAtSlot(backend types.ServiceBackend, att *ethpb.Attestation) error {
+	spec, err := backend.GetChainSpec()
+	if err != nil {
+		return err
+	}
+	wait := time.Until(time.Unix(spec.SlotStartTime(int64(att.Data.Slot)), 0))
+	if wait <= 0 {
+		return backend.SubmitAttestation(att)
+	}
+	time.AfterFunc(wait, func() {
+		if err := backend.SubmitAttestation(att); err != nil {
+			log.WithError(err).WithField("slot", att.Data.Slot).Warn("submit scheduled attestation failed")
+		}
+	})
+	return nil
+}
+
+// voteSurrounding rewrites the vote so that its source is before and its
+// target after the ones of the origin vote. The target epoch must be the epoch
+// of the slot, so the vote is moved to the same slot of the next epoch. That
+// epoch has no block yet, so its checkpoint is the voted head.
+func voteSurrounding(backend types.ServiceBackend, slot int64, data *ethpb.AttestationData) error {
+	if data.Source == nil || data.Target == nil || data.Source.Epoch == 0 {
+		return errNoBlock
+	}
+	if err := voteOlderSource(backend, slot, data); err != nil {
+		return err
+	}
+	slotsPerEpoch, err := backend.GetSlotsPerEpoch()
+	if err != nil {
+		return err
+	}
+	data.Slot += primitives.Slot(slotsPerEpoch)
+	data.Target = &ethpb.Checkpoint{
+		Epoch: data.Target.Epoch + 1,
+		Root:  append([]byte{}, data.BeaconBlockRoot...),
+	}
+	return nil
+}
+
+// voteOlderSource votes the checkpoint of the epoch before the source.
+func voteOlderSource(backend types.ServiceBackend, slot int64, data *ethpb.AttestationData) error {
+	if data.Source == nil || data.Source.Epoch == 0 {
+		return errNoBlock
+	}
+	epoch := data.Source.Epoch - 1
+	slotsPerEpoch, err := backend.GetSlotsPerEpoch()
+	if err != nil {
+		return err
+	}
+	tool := common.SlotTool{SlotsPerEpoch: slotsPerEpoch}
+	header, err := blockAtOrBefore(backend, tool.EpochStart(int64(epoch)))
+	if err != nil {
+		return err
+	}
+	root, err := hexutil.Decode(header.Root)
+	if err != nil {
+		return err
+	}
+	data.Source = &ethpb.Checkpoint{Epoch: epoch, Root: root}
+	return nil
+}
@@ -0,0 +1,223 @@
+package slotstrategy
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	ethpb "github.com/prysmaticlabs/prysm/v5/proto/prysm/v1alpha1"
+	"github.com/tsinghua-cel/attacker-service/types"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestDoubleProposal(t *testing.T) {
+	tests := []struct {
+		name       string
+		action     string
+		slot       uint64
+		attacker   bool
+		produce    func(slot uint64, parentRoot []byte) *ethpb.BeaconBlockDeneb
+		wantParent []byte // the parent root asked to the beacon node
+		publish    bool
+	}{
+		{
+			name:     "body",
+			action:   "doubleProposal",
+			slot:     10,
+			attacker: true,
+			publish:  true,
+		},
+		{
+			name:       "parent of the parent",
+			action:     "doubleProposal:1",
+			slot:       10,
+			attacker:   true,
+			wantParent: root(8),
+			publish:    true,
+		},
+		{
+			name:     "beacon node ignores the parent",
+			action:   "doubleProposal:1",
+			slot:     10,
+			attacker: true,
+			produce: func(slot uint64, parentRoot []byte) *ethpb.BeaconBlockDeneb {
+				return testBlock(slot, slot, root(9)).Block
+			},
+			wantParent: root(8),
+		},
+		{
+			name:     "parent has no parent",
+			action:   "doubleProposal:1",
+			slot:     1,
+			attacker: true,
+		},
+		{
+			name:     "produced by another proposer",
+			action:   "doubleProposal",
+			slot:     10,
+			attacker: true,
+			produce: func(slot uint64, parentRoot []byte) *ethpb.BeaconBlockDeneb {
+				return testBlock(slot, 3, parentRoot).Block
+			},
+		},
+		{
+			name:   "not attacker",
+			action: "doubleProposal",
+			slot:   10,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend := newTestBackend(12)
+			backend.attackers[int(tt.slot)] = tt.attacker
+			backend.produce = tt.produce
+			action, err := GetFunctionAction(backend, tt.action)
+			if err != nil {
+				t.Fatal(err)
+			}
+			block := testBlock(tt.slot, tt.slot, root(int64(tt.slot)-1))
+			if r := action(backend, int64(tt.slot), "0xaa", block); r.Cmd != types.CMD_NULL || r.Result != block {
+				t.Fatalf("got %v %v, want the block unchanged", r.Cmd, r.Result)
+			}
+			if tt.wantParent != nil && (len(backend.produced) != 1 || !bytes.Equal(backend.produced[0], tt.wantParent)) {
+				t.Fatalf("produced on %x, want %x", backend.produced, tt.wantParent)
+			}
+			if !tt.publish {
+				if len(backend.published) != 0 {
+					t.Fatalf("published %d blocks, want none", len(backend.published))
+				}
+				return
+			}
+			if len(backend.published) != 1 {
+				t.Fatalf("published %d blocks, want 1", len(backend.published))
+			}
+			published := backend.published[0].Block
+			if published.Block.Slot != block.Block.Slot || published.Block.ProposerIndex != block.Block.ProposerIndex {
+				t.Errorf("published block of slot %d proposer %d", published.Block.Slot, published.Block.ProposerIndex)
+			}
+			if !bytes.Equal(published.Block.Body.Graffiti, doubleProposalGraffiti) {
+				t.Errorf("published graffiti %q", published.Block.Body.Graffiti)
+			}
+			if !bytes.Equal(published.Signature, testSignature) {
+				t.Errorf("published signature %x", published.Signature)
+			}
+		})
+	}
+}
+
+func TestConflictingVotes(t *testing.T) {
+	tests := []struct {
+		name     string
+		action   string
+		attacker bool
+		vote     func() *ethpb.AttestationData
+		spec     types.ChainSpec
+		want     *ethpb.AttestationData // the submitted vote, nil if none
+	}{
+		{
+			name:     "double vote",
+			action:   "doubleVote",
+			attacker: true,
+			want: &ethpb.AttestationData{
+				Slot:            10,
+				BeaconBlockRoot: root(8),
+				Source:          &ethpb.Checkpoint{Epoch: 1, Root: root(4)},
+				Target:          &ethpb.Checkpoint{Epoch: 2, Root: root(8)},
+			},
+		},
+		{
+			name:     "surround vote",
+			action:   "surroundVote",
+			attacker: true,
+			want: &ethpb.AttestationData{
+				Slot:            14,
+				BeaconBlockRoot: root(9),
+				Source:          &ethpb.Checkpoint{Epoch: 0, Root: root(0)},
+				Target:          &ethpb.Checkpoint{Epoch: 3, Root: root(9)},
+			},
+		},
+		{
+			name:     "surround vote is scheduled at its slot",
+			action:   "surroundVote",
+			attacker: true,
+			spec:     types.ChainSpec{SlotsPerEpoch: 4, SecondsPerSlot: 1000, GenesisTime: time.Now().Unix()},
+		},
+		{
+			name:     "surround vote of source epoch 0",
+			action:   "surroundVote",
+			attacker: true,
+			vote: func() *ethpb.AttestationData {
+				data := testVote()
+				data.Source = &ethpb.Checkpoint{Epoch: 0, Root: root(0)}
+				return data
+			},
+		},
+		{
+			name:   "not attacker",
+			action: "doubleVote",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend := newTestBackend(12)
+			backend.pubkeyAttackers["0xaa"] = tt.attacker
+			if tt.spec.SlotsPerEpoch != 0 {
+				backend.spec = tt.spec
+			}
+			action, err := GetFunctionAction(backend, tt.action)
+			if err != nil {
+				t.Fatal(err)
+			}
+			vote := testVote
+			if tt.vote != nil {
+				vote = tt.vote
+			}
+			att := &ethpb.Attestation{AggregationBits: []byte{0x03}, Data: vote(), Signature: make([]byte, 96)}
+			if r := action(backend, 10, "0xaa", att); r.Cmd != types.CMD_NULL || r.Result != att {
+				t.Fatalf("got %v %v, want the attestation unchanged", r.Cmd, r.Result)
+			}
+			if !proto.Equal(att.Data, vote()) {
+				t.Fatalf("the signed vote is changed to %v", att.Data)
+			}
+			if tt.want == nil {
+				if len(backend.submitted) != 0 {
+					t.Fatalf("submitted %d votes, want none", len(backend.submitted))
+				}
+				return
+			}
+			if len(backend.submitted) != 1 {
+				t.Fatalf("submitted %d votes, want 1", len(backend.submitted))
+			}
+			submitted := backend.submitted[0]
+			if !proto.Equal(submitted.Data, tt.want) {
+				t.Errorf("submitted %v, want %v", submitted.Data, tt.want)
+			}
+			if !bytes.Equal(submitted.Signature, testSignature) || !bytes.Equal(submitted.AggregationBits, att.AggregationBits) {
+				t.Errorf("submitted signature %x bits %x", submitted.Signature, submitted.AggregationBits)
+			}
+		})
+	}
+}
+
+func TestSlashableNotAllowed(t *testing.T) {
+	for _, action := range []string{"doubleProposal:1", "doubleVote", "surroundVote"} {
+		if !IsSlashableAction(action) {
+			t.Errorf("%s is not slashable", action)
+		}
+		strategy := &types.Strategy{Slots: []types.SlotStrategy{{
+			Slot:    "10",
+			Actions: map[string]string{"AttestAfterSign": action},
+		}}}
+		if _, err := ParseToInternalSlotStrategy(newTestBackend(12), strategy); !errors.Is(err, ErrSlashableNotAllowed) {
+			t.Errorf("%s parsed without allow_slashable, err %v", action, err)
+		}
+		strategy.AllowSlashable = true
+		if _, err := ParseToInternalSlotStrategy(newTestBackend(12), strategy); err != nil {
+			t.Errorf("%s with allow_slashable: %v", action, err)
+		}
+	}
+	if IsSlashableAction("voteParentOfHead") {
+		t.Error("voteParentOfHead is slashable")
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"github.com/tsinghua-cel/attacker-service/plugins"
 	"github.com/tsinghua-cel/attacker-service/types"
+	"sort"
 	"strconv"
 )
 
@@ -23,33 +24,77 @@ type InternalSlotStrategy struct {
 	Slot    SlotIns              `json:"slot"`
 	Level   int                  `json:"level"`
 	Actions map[string]ActionIns `json:"actions"`
+	// Condition is set for the slots of a conditional strategy, they only
+	// match while it is enabled.
+	Condition *Condition `json:"-"`
 }
 
-func ParseToInternalSlotStrategy(backend types.ServiceBackend, strategy []types.SlotStrategy) ([]InternalSlotStrategy, error) {
-	is := make([]InternalSlotStrategy, len(strategy))
-	for i, s := range strategy {
-		is[i].Level = s.Level
-		if n, err := strconv.ParseInt(s.Slot, 10, 64); err == nil {
-			is[i].Slot = NumberSlot(n)
-		} else {
-			calc, err := GetFunctionSlot(backend, s.Slot)
-			if err != nil {
-				return nil, err
-			}
-			is[i].Slot = FunctionSlot{calcFunc: calc}
+func (s InternalSlotStrategy) Match(slot int64) bool {
+	if s.Slot.Compare(slot) != 0 {
+		return false
+	}
+	return s.Condition == nil || s.Condition.EnabledAt(slot)
+}
+
+func ParseToInternalSlotStrategy(backend types.ServiceBackend, strategy *types.Strategy) ([]InternalSlotStrategy, error) {
+	is := make([]InternalSlotStrategy, 0, len(strategy.Slots))
+	for _, s := range strategy.Slots {
+		parsed, err := parseSlotStrategy(backend, strategy, s)
+		if err != nil {
+			return nil, err
 		}
-		is[i].Actions = make(map[string]ActionIns)
-		for point, action := range s.Actions {
-			if types.CheckActionPointExist(point) == false {
-				return nil, errors.New(fmt.Sprintf("action point %s not exist", point))
-			}
-			actionDo, err := GetFunctionAction(backend, action)
+		is = append(is, parsed)
+	}
+	for _, c := range strategy.Conditional {
+		condition, err := newCondition(backend, c)
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range c.Slots {
+			parsed, err := parseSlotStrategy(backend, strategy, s)
 			if err != nil {
 				return nil, err
 			}
-			is[i].Actions[point] = FunctionAction{doFunc: actionDo}
+			parsed.Condition = condition
+			is = append(is, parsed)
 		}
 	}
 	//log.Printf("parsed internal slot strategy is %v\n", is)
 	return is, nil
 }
+
+func parseSlotStrategy(backend types.ServiceBackend, strategy *types.Strategy, s types.SlotStrategy) (InternalSlotStrategy, error) {
+	var is InternalSlotStrategy
+	is.Level = s.Level
+	if n, err := strconv.ParseInt(s.Slot, 10, 64); err == nil {
+		is.Slot = NumberSlot(n)
+	} else {
+		calc, err := GetFunctionSlot(backend, s.Slot)
+		if err != nil {
+			return is, err
+		}
+		is.Slot = FunctionSlot{calcFunc: calc}
+	}
+	is.Actions = make(map[string]ActionIns)
+	// parse in a fixed order, so a seeded random source gives the same parameters.
+	points := make([]string, 0, len(s.Actions))
+	for point := range s.Actions {
+		points = append(points, point)
+	}
+	sort.Strings(points)
+	for _, point := range points {
+		action := s.Actions[point]
+		if types.CheckActionPointExist(point) == false {
+			return is, errors.New(fmt.Sprintf("action point %s not exist", point))
+		}
+		if IsSlashableAction(action) && !strategy.AllowSlashable {
+			return is, fmt.Errorf("%w: %s", ErrSlashableNotAllowed, action)
+		}
+		actionDo, err := GetFunctionAction(backend, action)
+		if err != nil {
+			return is, err
+		}
+		is.Actions[point] = FunctionAction{doFunc: actionDo}
+	}
+	return is, nil
+}
@@ -0,0 +1,189 @@
+package slotstrategy
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethpb "github.com/prysmaticlabs/prysm/v5/proto/prysm/v1alpha1"
+	log "github.com/sirupsen/logrus"
+	"github.com/tsinghua-cel/attacker-service/common"
+	"github.com/tsinghua-cel/attacker-service/plugins"
+	"github.com/tsinghua-cel/attacker-service/types"
+)
+
+// The vote actions rewrite the AttestationData passed to AttestBeforeSign,
+// on any other action point or on lookup failure the data is not changed.
+
+var errNoBlock = errors.New("no block found")
+
+type voteRewrite func(backend types.ServiceBackend, slot int64, data *ethpb.AttestationData) error
+
+func voteAction(name string, rewrite voteRewrite) ActionDo {
+	return func(backend types.ServiceBackend, slot int64, pubkey string, params ...interface{}) plugins.PluginResponse {
+		r := plugins.PluginResponse{
+			Cmd: types.CMD_NULL,
+		}
+		if len(params) == 0 {
+			return r
+		}
+		r.Result = params[0]
+		data, ok := params[0].(*ethpb.AttestationData)
+		if !ok || data == nil {
+			return r
+		}
+		if err := rewrite(backend, slot, data); err != nil {
+			log.WithError(err).WithField("slot", slot).Warn(name + " keep origin attestation data")
+			return r
+		}
+		log.WithFields(log.Fields{
+			"slot":   slot,
+			"head":   hexutil.Encode(data.BeaconBlockRoot),
+			"target": hexutil.Encode(data.Target.Root),
+		}).Info(name)
+		r.Result = data
+		return r
+	}
+}
+
+// voteHeadOfSlot votes for the block of slot-n, or the nearest block before it
+// if that slot is empty.
+func voteHeadOfSlot(n int) voteRewrite {
+	return func(backend types.ServiceBackend, slot int64, data *ethpb.AttestationData) error {
+		header, err := blockAtOrBefore(backend, slot-int64(n))
+		if err != nil {
+			return err
+		}
+		return setHead(backend, data, header)
+	}
+}
+
+// voteAttackerChainTip votes for the latest block proposed by an attacker,
+// blocks stored by storeSignedBlock are preferred so a withheld chain can be voted.
+func voteAttackerChainTip(backend types.ServiceBackend, slot int64, data *ethpb.AttestationData) error {
+	slotsPerEpoch, err := backend.GetSlotsPerEpoch()
+	if err != nil {
+		return err
+	}
+	for s := slot; s >= 0 && s > slot-2*int64(slotsPerEpoch); s-- {
+		valIdx, err := backend.GetValidatorByProposeSlot(uint64(s))
+		if err != nil || backend.GetValidatorRole(int(s), valIdx) != types.AttackerRole {
+			continue
+		}
+		if root, ok := storedBlockRoot(backend, s); ok {
+			data.BeaconBlockRoot = root[:]
+			return setTargetIfBefore(backend, data, s, root[:])
+		}
+		header, err := backend.GetBeaconHeader(strconv.FormatInt(s, 10))
+		if err != nil {
+			continue
+		}
+		if header.Header.Message.ProposerIndex != strconv.Itoa(valIdx) {
+			continue
+		}
+		return setHead(backend, data, header)
+	}
+	return errNoBlock
+}
+
+// voteParentOfHead votes for the parent of the head block chosen by the node.
+func voteParentOfHead(backend types.ServiceBackend, slot int64, data *ethpb.AttestationData) error {
+	head, err := backend.GetBeaconHeader(hexutil.Encode(data.BeaconBlockRoot))
+	if err != nil {
+		return err
+	}
+	parent, err := backend.GetBeaconHeader(head.Header.Message.ParentRoot)
+	if err != nil {
+		return err
+	}
+	return setHead(backend, data, parent)
+}
+
+// voteStaleTarget keeps the target epoch but votes the checkpoint block of
+// the previous epoch as target root.
+func voteStaleTarget(backend types.ServiceBackend, slot int64, data *ethpb.AttestationData) error {
+	if data.Target == nil || data.Target.Epoch == 0 {
+		return errNoBlock
+	}
+	slotsPerEpoch, err := backend.GetSlotsPerEpoch()
+	if err != nil {
+		return err
+	}
+	tool := common.SlotTool{SlotsPerEpoch: slotsPerEpoch}
+	header, err := blockAtOrBefore(backend, tool.EpochStart(int64(data.Target.Epoch)-1))
+	if err != nil {
+		return err
+	}
+	root, err := hexutil.Decode(header.Root)
+	if err != nil {
+		return err
+	}
+	data.Target = &ethpb.Checkpoint{Epoch: data.Target.Epoch, Root: root}
+	return nil
+}
+
+// blockAtOrBefore returns the header of the block at slot, or of the nearest
+// block before it within one epoch.
+func blockAtOrBefore(backend types.ServiceBackend, slot int64) (types.BeaconHeaderInfo, error) {
+	slotsPerEpoch, err := backend.GetSlotsPerEpoch()
+	if err != nil {
+		return types.BeaconHeaderInfo{}, err
+	}
+	for s := slot; s >= 0 && s > slot-int64(slotsPerEpoch); s-- {
+		if header, err := backend.GetBeaconHeader(strconv.FormatInt(s, 10)); err == nil {
+			return header, nil
+		}
+	}
+	return types.BeaconHeaderInfo{}, fmt.Errorf("%w at or before slot %d", errNoBlock, slot)
+}
+
+// storedBlockRoot returns the root of an attacker block kept by storeSignedBlock.
+func storedBlockRoot(backend types.ServiceBackend, slot int64) ([32]byte, bool) {
+	set := backend.GetBlockSet(uint64(slot))
+	if set == nil {
+		return [32]byte{}, false
+	}
+	for _, block := range set.Blocks {
+		deneb := block.GetDeneb()
+		if deneb == nil || deneb.Block == nil || deneb.Block.Block == nil {
+			continue
+		}
+		root, err := deneb.Block.Block.HashTreeRoot()
+		if err != nil {
+			continue
+		}
+		return root, true
+	}
+	return [32]byte{}, false
+}
+
+func setHead(backend types.ServiceBackend, data *ethpb.AttestationData, header types.BeaconHeaderInfo) error {
+	root, err := hexutil.Decode(header.Root)
+	if err != nil {
+		return err
+	}
+	headSlot, err := strconv.ParseInt(header.Header.Message.Slot, 10, 64)
+	if err != nil {
+		return err
+	}
+	data.BeaconBlockRoot = root
+	return setTargetIfBefore(backend, data, headSlot, root)
+}
+
+// setTargetIfBefore keeps the vote consistent: when the new head is before
+// the target epoch start, the head itself is the target checkpoint block.
+func setTargetIfBefore(backend types.ServiceBackend, data *ethpb.AttestationData, headSlot int64, root []byte) error {
+	if data.Target == nil {
+		return nil
+	}
+	slotsPerEpoch, err := backend.GetSlotsPerEpoch()
+	if err != nil {
+		return err
+	}
+	tool := common.SlotTool{SlotsPerEpoch: slotsPerEpoch}
+	if headSlot < tool.EpochStart(int64(data.Target.Epoch)) {
+		data.Target = &ethpb.Checkpoint{Epoch: data.Target.Epoch, Root: root}
+	}
+	return nil
+}
@@ -0,0 +1,192 @@
+package slotstrategy
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/prysmaticlabs/prysm/v5/consensus-types/primitives"
+	enginev1 "github.com/prysmaticlabs/prysm/v5/proto/engine/v1"
+	ethpb "github.com/prysmaticlabs/prysm/v5/proto/prysm/v1alpha1"
+	"github.com/tsinghua-cel/attacker-service/types"
+)
+
+// testBlock returns a deneb block with the minimum marshalable fields.
+func testBlock(slot uint64, proposer uint64, parentRoot []byte) *ethpb.SignedBeaconBlockDeneb {
+	return &ethpb.SignedBeaconBlockDeneb{
+		Block: &ethpb.BeaconBlockDeneb{
+			Slot:          primitives.Slot(slot),
+			ProposerIndex: primitives.ValidatorIndex(proposer),
+			ParentRoot:    parentRoot,
+			StateRoot:     make([]byte, 32),
+			Body: &ethpb.BeaconBlockBodyDeneb{
+				RandaoReveal: make([]byte, 96),
+				Eth1Data:     &ethpb.Eth1Data{DepositRoot: make([]byte, 32), BlockHash: make([]byte, 32)},
+				Graffiti:     make([]byte, 32),
+				SyncAggregate: &ethpb.SyncAggregate{
+					SyncCommitteeBits:      make([]byte, 64),
+					SyncCommitteeSignature: make([]byte, 96),
+				},
+				ExecutionPayload: &enginev1.ExecutionPayloadDeneb{
+					ParentHash:    make([]byte, 32),
+					FeeRecipient:  make([]byte, 20),
+					StateRoot:     make([]byte, 32),
+					ReceiptsRoot:  make([]byte, 32),
+					LogsBloom:     make([]byte, 256),
+					PrevRandao:    make([]byte, 32),
+					BaseFeePerGas: make([]byte, 32),
+					BlockHash:     make([]byte, 32),
+				},
+			},
+		},
+		Signature: make([]byte, 96),
+	}
+}
+
+// testVote is the vote of slot 10 for the head block 9, in epoch 2 of the test chain.
+func testVote() *ethpb.AttestationData {
+	return &ethpb.AttestationData{
+		Slot:            10,
+		BeaconBlockRoot: root(9),
+		Source:          &ethpb.Checkpoint{Epoch: 1, Root: root(4)},
+		Target:          &ethpb.Checkpoint{Epoch: 2, Root: root(8)},
+	}
+}
+
+func TestVoteActions(t *testing.T) {
+	// the stored block of attacker 9 is withheld, the chain has no block 9.
+	withheld := testBlock(9, 9, root(8))
+	withheldRoot, err := withheld.Block.HashTreeRoot()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		action     string
+		empty      []int64
+		attackers  []int
+		store      bool
+		vote       func() *ethpb.AttestationData
+		wantHead   []byte
+		wantTarget *ethpb.Checkpoint
+	}{
+		{
+			name:       "head of previous slot",
+			action:     "voteHeadOfSlot",
+			wantHead:   root(9),
+			wantTarget: &ethpb.Checkpoint{Epoch: 2, Root: root(8)},
+		},
+		{
+			name:       "head before target epoch is the target",
+			action:     "voteHeadOfSlot:4",
+			empty:      []int64{6},
+			wantHead:   root(5),
+			wantTarget: &ethpb.Checkpoint{Epoch: 2, Root: root(5)},
+		},
+		{
+			name:       "attacker chain tip",
+			action:     "voteAttackerChainTip",
+			attackers:  []int{7},
+			wantHead:   root(7),
+			wantTarget: &ethpb.Checkpoint{Epoch: 2, Root: root(7)},
+		},
+		{
+			name:       "stored attacker block",
+			action:     "voteAttackerChainTip",
+			attackers:  []int{9},
+			store:      true,
+			wantHead:   withheldRoot[:],
+			wantTarget: &ethpb.Checkpoint{Epoch: 2, Root: root(8)},
+		},
+		{
+			name:       "no attacker block keeps the vote",
+			action:     "voteAttackerChainTip",
+			wantHead:   root(9),
+			wantTarget: &ethpb.Checkpoint{Epoch: 2, Root: root(8)},
+		},
+		{
+			name:       "parent of head",
+			action:     "voteParentOfHead",
+			wantHead:   root(8),
+			wantTarget: &ethpb.Checkpoint{Epoch: 2, Root: root(8)},
+		},
+		{
+			name:   "unknown head keeps the vote",
+			action: "voteParentOfHead",
+			vote: func() *ethpb.AttestationData {
+				data := testVote()
+				data.BeaconBlockRoot = root(20)
+				return data
+			},
+			wantHead:   root(20),
+			wantTarget: &ethpb.Checkpoint{Epoch: 2, Root: root(8)},
+		},
+		{
+			name:       "stale target",
+			action:     "voteStaleTarget",
+			wantHead:   root(9),
+			wantTarget: &ethpb.Checkpoint{Epoch: 2, Root: root(4)},
+		},
+		{
+			name:   "stale target of epoch 0 keeps the vote",
+			action: "voteStaleTarget",
+			vote: func() *ethpb.AttestationData {
+				data := testVote()
+				data.Target = &ethpb.Checkpoint{Epoch: 0, Root: root(0)}
+				return data
+			},
+			wantHead:   root(9),
+			wantTarget: &ethpb.Checkpoint{Epoch: 0, Root: root(0)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend := newTestBackend(9, tt.empty...)
+			for _, idx := range tt.attackers {
+				backend.attackers[idx] = true
+			}
+			if tt.store {
+				delete(backend.headers, "9")
+				backend.blockSets[9] = &types.SlotBlockSet{Blocks: map[string]*ethpb.GenericSignedBeaconBlock{
+					"0x09": {Block: &ethpb.GenericSignedBeaconBlock_Deneb{Deneb: &ethpb.SignedBeaconBlockContentsDeneb{Block: withheld}}},
+				}}
+			}
+			action, err := GetFunctionAction(backend, tt.action)
+			if err != nil {
+				t.Fatal(err)
+			}
+			vote := testVote
+			if tt.vote != nil {
+				vote = tt.vote
+			}
+			r := action(backend, 10, "", vote())
+			if r.Cmd != types.CMD_NULL {
+				t.Fatalf("got cmd %v", r.Cmd)
+			}
+			data, ok := r.Result.(*ethpb.AttestationData)
+			if !ok {
+				t.Fatalf("got result %T", r.Result)
+			}
+			if !bytes.Equal(data.BeaconBlockRoot, tt.wantHead) {
+				t.Errorf("head %x, want %x", data.BeaconBlockRoot, tt.wantHead)
+			}
+			if data.Target.Epoch != tt.wantTarget.Epoch || !bytes.Equal(data.Target.Root, tt.wantTarget.Root) {
+				t.Errorf("target %d %x, want %d %x", data.Target.Epoch, data.Target.Root, tt.wantTarget.Epoch, tt.wantTarget.Root)
+			}
+		})
+	}
+}
+
+func TestVoteActionKeepsOtherPayloads(t *testing.T) {
+	action, err := GetFunctionAction(newTestBackend(9), "voteParentOfHead")
+	if err != nil {
+		t.Fatal(err)
+	}
+	block := testBlock(10, 10, root(9))
+	if r := action(nil, 10, "", block); r.Result != block {
+		t.Fatalf("got result %v, want the block unchanged", r.Result)
+	}
+	if r := action(nil, 10, ""); r.Result != nil {
+		t.Fatalf("got result %v without payload", r.Result)
+	}
+}
@@ -0,0 +1,185 @@
+package trace
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/tsinghua-cel/attacker-service/types"
+)
+
+// Entry is one hook invocation of the trace file, the trace file has one
+// json record per line.
+type Entry struct {
+	Point       string                `json:"point"`
+	Slot        uint64                `json:"slot"`
+	Pubkey      string                `json:"pubkey,omitempty"`
+	PayloadHash string                `json:"payload_hash,omitempty"`
+	Cmd         types.AttackerCommand `json:"cmd"`
+	Result      string                `json:"result,omitempty"` // empty if the payload is returned unchanged
+	Error       string                `json:"error,omitempty"`
+	Time        int64                 `json:"time"`     // unix milliseconds the hook is called
+	Duration    int64                 `json:"duration"` // milliseconds the hook takes
+}
+
+func (r Entry) key() string {
+	return fmt.Sprintf("%s/%d/%s/%s", r.Point, r.Slot, r.Pubkey, r.PayloadHash)
+}
+
+var (
+	mu       sync.Mutex
+	writer   *bufio.Writer
+	file     *os.File
+	recorded map[string][]Entry
+)
+
+// Init opens the trace file to record to, and loads the trace file to replay
+// from, an empty path disables it.
+func Init(recordPath string, replayPath string) error {
+	mu.Lock()
+	defer mu.Unlock()
+	if replayPath != "" {
+		records, err := load(replayPath)
+		if err != nil {
+			return err
+		}
+		recorded = make(map[string][]Entry)
+		for _, r := range records {
+			recorded[r.key()] = append(recorded[r.key()], r)
+		}
+		log.WithFields(log.Fields{
+			"file":    replayPath,
+			"records": len(records),
+		}).Info("replay hook trace")
+	}
+	if recordPath != "" {
+		f, err := os.OpenFile(recordPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+		if err != nil {
+			return err
+		}
+		file = f
+		writer = bufio.NewWriter(f)
+		log.WithField("file", recordPath).Info("record hook trace")
+	}
+	return nil
+}
+
+func load(path string) ([]Entry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	records := make([]Entry, 0)
+	scanner := bufio.NewScanner(f)
+	// the results of block hooks are large.
+	scanner.Buffer(make([]byte, 1024*1024), 64*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var r Entry
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			return nil, fmt.Errorf("invalid trace entry at line %d: %w", line, err)
+		}
+		records = append(records, r)
+	}
+	return records, scanner.Err()
+}
+
+// Close flushes the recorded trace.
+func Close() error {
+	mu.Lock()
+	defer mu.Unlock()
+	if file == nil {
+		return nil
+	}
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+	err := file.Close()
+	file, writer = nil, nil
+	return err
+}
+
+func payloadHash(payload string) string {
+	if payload == "" {
+		return ""
+	}
+	h := sha256.Sum256([]byte(payload))
+	return hex.EncodeToString(h[:])
+}
+
+// Record writes a hook invocation to the trace file if recording is enabled.
+func Record(point string, slot uint64, pubkey string, payload string, start time.Time, response types.AttackerResponse) {
+	mu.Lock()
+	defer mu.Unlock()
+	if writer == nil {
+		return
+	}
+	r := Entry{
+		Point:       point,
+		Slot:        slot,
+		Pubkey:      pubkey,
+		PayloadHash: payloadHash(payload),
+		Cmd:         response.Cmd,
+		Error:       response.Error,
+		Time:        start.UnixMilli(),
+		Duration:    time.Since(start).Milliseconds(),
+	}
+	if response.Result != payload {
+		r.Result = response.Result
+	}
+	data, err := json.Marshal(r)
+	if err != nil {
+		log.WithError(err).Error("marshal trace record failed")
+		return
+	}
+	writer.Write(data)
+	writer.WriteByte('\n')
+	// flush every record, so the trace is complete if the service is killed.
+	if err := writer.Flush(); err != nil {
+		log.WithError(err).Error("write trace record failed")
+	}
+}
+
+// Replay returns the recorded response of an identical hook invocation, it
+// takes as long as the recorded one. It returns false if replay is disabled
+// or the invocation is not recorded.
+func Replay(point string, slot uint64, pubkey string, payload string) (types.AttackerResponse, bool) {
+	mu.Lock()
+	if recorded == nil {
+		mu.Unlock()
+		return types.AttackerResponse{}, false
+	}
+	key := Entry{Point: point, Slot: slot, Pubkey: pubkey, PayloadHash: payloadHash(payload)}.key()
+	records := recorded[key]
+	if len(records) == 0 {
+		mu.Unlock()
+		log.WithFields(log.Fields{
+			"point": point,
+			"slot":  slot,
+		}).Warn("hook invocation not found in trace, run the strategy")
+		return types.AttackerResponse{}, false
+	}
+	r := records[0]
+	recorded[key] = records[1:]
+	mu.Unlock()
+
+	time.Sleep(time.Duration(r.Duration) * time.Millisecond)
+	response := types.AttackerResponse{
+		Cmd:    r.Cmd,
+		Result: payload,
+		Error:  r.Error,
+	}
+	if r.Result != "" {
+		response.Result = r.Result
+	}
+	return response, true
+}
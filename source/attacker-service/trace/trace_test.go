@@ -0,0 +1,129 @@
+package trace
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tsinghua-cel/attacker-service/types"
+)
+
+func resetTrace(t *testing.T) {
+	t.Cleanup(func() {
+		if err := Close(); err != nil {
+			t.Error(err)
+		}
+		mu.Lock()
+		recorded = nil
+		mu.Unlock()
+	})
+}
+
+func TestRecordReplay(t *testing.T) {
+	resetTrace(t)
+	path := filepath.Join(t.TempDir(), "trace.jsonl")
+	if err := Init(path, ""); err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	Record("AttestBeforeSign", 10, "0xaa", "vote", start, types.AttackerResponse{Cmd: types.CMD_CONTINUE, Result: "vote"})
+	Record("BlockBeforeSign", 10, "0xaa", "block", start, types.AttackerResponse{Cmd: types.CMD_CONTINUE, Result: "modified"})
+	Record("BlockBeforeSign", 10, "0xaa", "block", start, types.AttackerResponse{Cmd: types.CMD_RETURN, Error: "withheld"})
+	Record("BlockDelayForReceiveBlock", 11, "", "", start, types.AttackerResponse{Cmd: types.CMD_NULL})
+	if err := Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 4 {
+		t.Fatalf("loaded %d records, want 4", len(records))
+	}
+	if records[0].Result != "" || records[0].PayloadHash != payloadHash("vote") {
+		t.Errorf("unchanged payload recorded as %+v", records[0])
+	}
+	if records[1].Result != "modified" || records[1].Time != start.UnixMilli() {
+		t.Errorf("modified payload recorded as %+v", records[1])
+	}
+	if records[3].PayloadHash != "" {
+		t.Errorf("empty payload hashed to %s", records[3].PayloadHash)
+	}
+
+	if err := Init("", path); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		point   string
+		slot    uint64
+		payload string
+		want    types.AttackerResponse
+		found   bool
+	}{
+		{
+			name:    "unchanged payload",
+			point:   "AttestBeforeSign",
+			slot:    10,
+			payload: "vote",
+			want:    types.AttackerResponse{Cmd: types.CMD_CONTINUE, Result: "vote"},
+			found:   true,
+		},
+		{
+			name:    "first invocation",
+			point:   "BlockBeforeSign",
+			slot:    10,
+			payload: "block",
+			want:    types.AttackerResponse{Cmd: types.CMD_CONTINUE, Result: "modified"},
+			found:   true,
+		},
+		{
+			name:    "second invocation",
+			point:   "BlockBeforeSign",
+			slot:    10,
+			payload: "block",
+			want:    types.AttackerResponse{Cmd: types.CMD_RETURN, Result: "block", Error: "withheld"},
+			found:   true,
+		},
+		{name: "invocations used up", point: "BlockBeforeSign", slot: 10, payload: "block"},
+		{name: "other payload", point: "AttestBeforeSign", slot: 10, payload: "other vote"},
+		{name: "other slot", point: "BlockDelayForReceiveBlock", slot: 12},
+		{name: "empty payload", point: "BlockDelayForReceiveBlock", slot: 11, want: types.AttackerResponse{Cmd: types.CMD_NULL}, found: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pubkey := "0xaa"
+			if tt.point == "BlockDelayForReceiveBlock" {
+				pubkey = ""
+			}
+			got, found := Replay(tt.point, tt.slot, pubkey, tt.payload)
+			if found != tt.found || got != tt.want {
+				t.Errorf("got %+v %v, want %+v %v", got, found, tt.want, tt.found)
+			}
+		})
+	}
+}
+
+func TestReplayDisabled(t *testing.T) {
+	resetTrace(t)
+	if err := Init("", ""); err != nil {
+		t.Fatal(err)
+	}
+	Record("AttestBeforeSign", 10, "0xaa", "vote", time.Now(), types.AttackerResponse{})
+	if _, found := Replay("AttestBeforeSign", 10, "0xaa", "vote"); found {
+		t.Error("replayed without a trace")
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "trace.jsonl")
+	if err := os.WriteFile(path, []byte("{\"point\":\"AttestBeforeSign\"}\n\nnot json\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := load(path); err == nil || !strings.Contains(err.Error(), "line 3") {
+		t.Errorf("got err %v, want invalid line 3", err)
+	}
+}
@@ -18,22 +18,38 @@ const (
 	BlockAfterSign            ActionPoint = "BlockAfterSign"
 	BlockBeforePropose        ActionPoint = "BlockBeforePropose"
 	BlockAfterPropose         ActionPoint = "BlockAfterPropose"
+	BlockGetNewParentRoot     ActionPoint = "BlockGetNewParentRoot"
 )
 
+// BlockActionPoints are the action points of the block duty.
+var BlockActionPoints = []ActionPoint{
+	BlockDelayForReceiveBlock,
+	BlockGetNewParentRoot,
+	BlockBeforeSign,
+	BlockAfterSign,
+	BlockBeforePropose,
+	BlockAfterPropose,
+	BlockBeforeBroadCast,
+	BlockAfterBroadCast,
+}
+
+// AttestActionPoints are the action points of the attest duty.
+var AttestActionPoints = []ActionPoint{
+	AttestBeforeSign,
+	AttestAfterSign,
+	AttestBeforePropose,
+	AttestAfterPropose,
+	AttestBeforeBroadCast,
+	AttestAfterBroadCast,
+}
+
 func init() {
-	allActionPoints[AttestBeforeBroadCast] = true
-	allActionPoints[AttestAfterBroadCast] = true
-	allActionPoints[AttestBeforeSign] = true
-	allActionPoints[AttestAfterSign] = true
-	allActionPoints[AttestBeforePropose] = true
-	allActionPoints[AttestAfterPropose] = true
-	allActionPoints[BlockDelayForReceiveBlock] = true
-	allActionPoints[BlockBeforeBroadCast] = true
-	allActionPoints[BlockAfterBroadCast] = true
-	allActionPoints[BlockBeforeSign] = true
-	allActionPoints[BlockAfterSign] = true
-	allActionPoints[BlockBeforePropose] = true
-	allActionPoints[BlockAfterPropose] = true
+	for _, point := range BlockActionPoints {
+		allActionPoints[point] = true
+	}
+	for _, point := range AttestActionPoints {
+		allActionPoints[point] = true
+	}
 }
 func CheckActionPointExist(action string) bool {
 	_, ok := allActionPoints[ActionPoint(action)]
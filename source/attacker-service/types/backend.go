@@ -14,15 +14,18 @@ type ExecuteBackend interface {
 }
 
 type BeaconBackend interface {
+	GetChainSpec() (ChainSpec, error)
 	GetCurrentEpochProposeDuties() ([]ProposerDuty, error)
-	GetSlotsPerEpoch() int
-	SlotsPerEpoch() int
+	GetSlotsPerEpoch() (int, error)
+	SlotsPerEpoch() (int, error)
 	GetIntervalPerSlot() int
 	GetValidatorByProposeSlot(slot uint64) (int, error)
 	GetProposeDuties(epoch int) ([]ProposerDuty, error)
 	GetSlotRoot(slot int64) (string, error)
+	GetBeaconHeader(id string) (BeaconHeaderInfo, error)
 	GetBlockBySlot(slot uint64) (interface{}, error)
 	GetLatestBeaconHeader() (BeaconHeaderInfo, error)
+	GetValidators() ([]Validator, error)
 }
 
 type CacheBackend interface {
@@ -38,6 +41,21 @@ type CacheBackend interface {
 	SetSlotStartTime(slot int, time int64)
 }
 
+// SlashableBackend signs and publishes the extra blocks and attestations of
+// the slashable actions.
+type SlashableBackend interface {
+	SignBlock(pubkey string, block *ethpb.BeaconBlockDeneb) ([]byte, error)
+	SignAttestation(pubkey string, data *ethpb.AttestationData) ([]byte, error)
+	// ProduceBlock produces a block by the beacon node, on parentRoot if it is
+	// set, see ParentRootOverride.
+	ProduceBlock(slot uint64, randaoReveal []byte, graffiti []byte, parentRoot []byte) (*ethpb.BeaconBlockContentsDeneb, error)
+	// ParentRootOverride returns the parent root of the block of the slot
+	// being produced by ProduceBlock, it is returned by BlockGetNewParentRoot.
+	ParentRootOverride(slot uint64) ([]byte, bool)
+	PublishBlock(block *ethpb.SignedBeaconBlockContentsDeneb) error
+	SubmitAttestation(attestation *ethpb.Attestation) error
+}
+
 type StrategyBackend interface {
 	// update strategy
 	GetStrategy() *Strategy
@@ -51,4 +69,5 @@ type ServiceBackend interface {
 	BeaconBackend
 	CacheBackend
 	StrategyBackend
+	SlashableBackend
 }
@@ -46,6 +46,40 @@ type AttestDuty struct {
 	Slot                    string `json:"slot"`
 }
 
+type GenesisInfo struct {
+	GenesisTime           string `json:"genesis_time"`
+	GenesisValidatorsRoot string `json:"genesis_validators_root"`
+	GenesisForkVersion    string `json:"genesis_fork_version"`
+}
+
+type ForkInfo struct {
+	PreviousVersion string `json:"previous_version"`
+	CurrentVersion  string `json:"current_version"`
+	Epoch           string `json:"epoch"`
+}
+
+type Checkpoint struct {
+	Epoch string `json:"epoch"`
+	Root  string `json:"root"`
+}
+
+type FinalityCheckpoints struct {
+	PreviousJustified Checkpoint `json:"previous_justified"`
+	CurrentJustified  Checkpoint `json:"current_justified"`
+	Finalized         Checkpoint `json:"finalized"`
+}
+
+// BeaconValidator is a validator of the beacon state api.
+type BeaconValidator struct {
+	Index     string `json:"index"`
+	Balance   string `json:"balance"`
+	Status    string `json:"status"`
+	Validator struct {
+		Pubkey           string `json:"pubkey"`
+		EffectiveBalance string `json:"effective_balance"`
+	} `json:"validator"`
+}
+
 type BeaconResponse struct {
 	Data json.RawMessage `json:"data"`
 }
@@ -54,6 +88,7 @@ type ReorgEvent struct {
 	Epoch                 int64  `json:"epoch"`
 	Slot                  int64  `json:"slot"`
 	Depth                 int64  `json:"depth"`
+	OldHeadBlock          string `json:"old_head_block"`
 	OldBlockSlot          int64  `json:"old_block_slot"`
 	NewBlockSlot          int64  `json:"new_block_slot"`
 	OldBlockProposerIndex int64  `json:"old_block_proposer_index"`
@@ -0,0 +1,40 @@
+package types
+
+// Catalogue is the machine readable list of the action points, actions and
+// function slots a service supports, strategy tools load it to generate
+// strategies that are valid for the service version.
+type Catalogue struct {
+	Version       string            `json:"version"`
+	Points        []CataloguePoint  `json:"points"`
+	Actions       []CatalogueAction `json:"actions"`
+	SlotFunctions []string          `json:"slot_functions"`
+}
+
+type CataloguePoint struct {
+	Name string `json:"name"`
+	// Kind is block or attest.
+	Kind string `json:"kind"`
+}
+
+// CatalogueAction is an action and the points it can be used at.
+type CatalogueAction struct {
+	Name      string           `json:"name"`
+	Desc      string           `json:"desc"`
+	Params    []CatalogueParam `json:"params,omitempty"`
+	Points    []string         `json:"points"`
+	Slashable bool             `json:"slashable,omitempty"`
+}
+
+// CatalogueParam is an integer parameter of an action, Min and Max are the
+// useful range a random value is drawn from when a parameter without Default
+// is omitted. ValidMin and ValidMax bound the accepted values, a nil one is
+// unbounded.
+type CatalogueParam struct {
+	Name     string `json:"name"`
+	Desc     string `json:"desc"`
+	Default  *int   `json:"default,omitempty"`
+	Min      int    `json:"min"`
+	Max      int    `json:"max"`
+	ValidMin *int   `json:"valid_min,omitempty"`
+	ValidMax *int   `json:"valid_max,omitempty"`
+}
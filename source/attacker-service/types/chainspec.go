@@ -0,0 +1,38 @@
+package types
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrChainSpecUnavailable = errors.New("chain spec is not available")
+	ErrDutiesUnavailable    = errors.New("proposer duties are not available")
+)
+
+// ChainSpec is the part of the beacon chain config used by the strategies,
+// it is loaded once from the beacon node at startup.
+type ChainSpec struct {
+	SlotsPerEpoch  int   `json:"slots_per_epoch"`
+	SecondsPerSlot int   `json:"seconds_per_slot"`
+	GenesisTime    int64 `json:"genesis_time"`
+}
+
+// SlotStartTime returns the unix time the slot starts at.
+func (c ChainSpec) SlotStartTime(slot int64) int64 {
+	return c.GenesisTime + slot*int64(c.SecondsPerSlot)
+}
+
+// CurrentSlot returns the wall clock slot.
+func (c ChainSpec) CurrentSlot() int64 {
+	now := time.Now().Unix()
+	if now < c.GenesisTime {
+		return 0
+	}
+	return (now - c.GenesisTime) / int64(c.SecondsPerSlot)
+}
+
+// CurrentEpoch returns the wall clock epoch.
+func (c ChainSpec) CurrentEpoch() int64 {
+	return c.CurrentSlot() / int64(c.SlotsPerEpoch)
+}
@@ -13,3 +13,28 @@ const (
 	CMD_EXIT
 	CMD_UPDATE_STATE
 )
+
+func (c AttackerCommand) String() string {
+	switch c {
+	case CMD_NULL:
+		return "null"
+	case CMD_CONTINUE:
+		return "continue"
+	case CMD_RETURN:
+		return "return"
+	case CMD_ABORT:
+		return "abort"
+	case CMD_SKIP:
+		return "skip"
+	case CMD_ROLE_TO_NORMAL:
+		return "roleToNormal"
+	case CMD_ROLE_TO_ATTACKER:
+		return "roleToAttacker"
+	case CMD_EXIT:
+		return "exit"
+	case CMD_UPDATE_STATE:
+		return "updateState"
+	default:
+		return "unknown"
+	}
+}
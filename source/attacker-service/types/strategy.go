@@ -1,29 +1,19 @@
 package types
 
-type SlotStrategy struct {
-	Slot    string            `json:"slot"`
-	Level   int               `json:"level"`
-	Actions map[string]string `json:"actions"`
-}
+import schema "github.com/tsinghua-cel/strategy-schema"
 
-type ValidatorStrategy struct {
-	ValidatorIndex    int `json:"validator_index"`
-	AttackerStartSlot int `json:"attacker_start_slot"`
-	AttackerEndSlot   int `json:"attacker_end_slot"`
-}
+// The strategy types are shared with strategy-gen by the strategy-schema module.
+type (
+	SlotStrategy        = schema.SlotStrategy
+	ValidatorStrategy   = schema.ValidatorStrategy
+	Trigger             = schema.Trigger
+	ConditionalStrategy = schema.ConditionalStrategy
+	Strategy            = schema.Strategy
+)
 
-type Strategy struct {
-	Slots      []SlotStrategy      `json:"slots"`
-	Validators []ValidatorStrategy `json:"validator"`
-}
-
-func (s *Strategy) GetValidatorRole(valIdx int, slot int64) RoleType {
-	for _, v := range s.Validators {
-		if v.ValidatorIndex == valIdx {
-			if slot >= int64(v.AttackerStartSlot) && slot <= int64(v.AttackerEndSlot) {
-				return AttackerRole
-			}
-		}
-	}
-	return NormalRole
-}
+// Trigger types of a conditional strategy.
+const (
+	TriggerAttackerProposers = schema.TriggerAttackerProposers
+	TriggerFinalityLag       = schema.TriggerFinalityLag
+	TriggerReorgDepth        = schema.TriggerReorgDepth
+)
@@ -5,6 +5,13 @@ import "encoding/json"
 type AttackerResponse struct {
 	Cmd    AttackerCommand `json:"cmd"`
 	Result string          `json:"result"`
+	// Error is set when the payload can't be decoded or the result can't be
+	// encoded, the result is the unchanged payload then.
+	Error string `json:"error,omitempty"`
+	// Decision is the id of a delay the client holds itself, the duty goes
+	// on once the release decision with this id is received on the
+	// decisions subscription.
+	Decision string `json:"decision,omitempty"`
 }
 
 type ClientInfo struct {
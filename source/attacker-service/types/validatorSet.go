@@ -11,6 +11,14 @@ type ValidatorInfo struct {
 	Pubkey string `json:"pubkey"`
 }
 
+// Validator is a validator of the head state, the effective balance is in gwei.
+type Validator struct {
+	Index            int64  `json:"index"`
+	Pubkey           string `json:"pubkey"`
+	EffectiveBalance uint64 `json:"effective_balance"`
+	Status           string `json:"status"`
+}
+
 type ValidatorDataSet struct {
 	ValidatorByIndex  sync.Map                  //map[int]*ValidatorInfo
 	ValidatorByPubkey sync.Map                  //map[string]*ValidatorInfo
@@ -86,6 +94,27 @@ func (vs *ValidatorDataSet) GetBlockSet(slot uint64) *SlotBlockSet {
 	}
 }
 
+func (vs *ValidatorDataSet) AttestSetSize() int {
+	vs.lock.RLock()
+	defer vs.lock.RUnlock()
+	return len(vs.AttestSet)
+}
+
+func (vs *ValidatorDataSet) BlockSetSize() int {
+	vs.lock.RLock()
+	defer vs.lock.RUnlock()
+	return len(vs.BlockSet)
+}
+
+func (vs *ValidatorDataSet) ValidatorCount() int {
+	count := 0
+	vs.ValidatorByIndex.Range(func(_, _ interface{}) bool {
+		count++
+		return true
+	})
+	return count
+}
+
 func (vs *ValidatorDataSet) AddSignedAttestation(slot uint64, pubkey string, attestation *ethpb.Attestation) {
 	pubkey = padPubkey(pubkey)
 	vs.lock.Lock()
@@ -98,6 +98,11 @@ var appFlags = []cli.Flag{
 	cmd.P2PPrivKey,
 	cmd.P2PPrivHex,
 	cmd.Attacker,
+	cmd.AttackerTimeout,
+	cmd.AttackerHookTimeouts,
+	cmd.AttackerFailPolicy,
+	cmd.AttackerApiKey,
+	cmd.AttackerJwtSecret,
 	cmd.P2PStaticID,
 	cmd.P2PMetadata,
 	cmd.P2PAllowList,
@@ -307,6 +312,12 @@ func startNode(ctx *cli.Context, cancel context.CancelFunc) error {
 		}
 	}
 	attacker.InitAttacker(ctx.String(cmd.Attacker.Name))
+	if err := attacker.SetHookConfig(ctx.String(cmd.AttackerFailPolicy.Name), ctx.Duration(cmd.AttackerTimeout.Name), ctx.String(cmd.AttackerHookTimeouts.Name)); err != nil {
+		return err
+	}
+	if err := attacker.SetAuth(ctx.String(cmd.AttackerApiKey.Name), ctx.String(cmd.AttackerJwtSecret.Name)); err != nil {
+		return err
+	}
 
 	beacon, err := node.New(ctx, cancel, opts...)
 	if err != nil {
@@ -281,6 +281,36 @@ var (
 		Usage: "The url of attacker service.",
 		Value: "",
 	}
+	// AttackerTimeout is the timeout of the attacker hook calls.
+	AttackerTimeout = &cli.DurationFlag{
+		Name:  "attacker-timeout",
+		Usage: "Timeout of the attacker hook calls, 0 means no timeout since the delay actions hold the call on purpose.",
+		Value: 0,
+	}
+	// AttackerHookTimeouts overrides the timeout of single attacker hooks.
+	AttackerHookTimeouts = &cli.StringFlag{
+		Name:  "attacker-hook-timeouts",
+		Usage: "Timeouts of single attacker hooks, e.g. AttestBeforeSign=2s,BlockGetNewParentRoot=1s.",
+		Value: "",
+	}
+	// AttackerFailPolicy is what a hook does when the attacker service fails.
+	AttackerFailPolicy = &cli.StringFlag{
+		Name:  "attacker-fail-policy",
+		Usage: "What to do when an attacker hook fails: open goes on with the duty, closed stops it.",
+		Value: "open",
+	}
+	// AttackerApiKey is the api key sent to the attacker service.
+	AttackerApiKey = &cli.StringFlag{
+		Name:  "attacker-api-key",
+		Usage: "The api key sent to the attacker service in the X-API-Key header.",
+		Value: "",
+	}
+	// AttackerJwtSecret is the jwt secret file of the attacker service.
+	AttackerJwtSecret = &cli.StringFlag{
+		Name:  "attacker-jwt-secret",
+		Usage: "The jwt secret file of the attacker service, a bearer token signed by it is sent with every request.",
+		Value: "",
+	}
 )
 
 // LoadFlagsFromConfig sets flags values from config file if ConfigFileFlag is set.
@@ -75,6 +75,11 @@ var appHelpFlagGroups = []flagGroup{
 			cmd.AcceptTosFlag,
 			cmd.ApiTimeoutFlag,
 			cmd.Attacker,
+			cmd.AttackerTimeout,
+			cmd.AttackerHookTimeouts,
+			cmd.AttackerFailPolicy,
+			cmd.AttackerApiKey,
+			cmd.AttackerJwtSecret,
 		},
 	},
 	{
@@ -3,14 +3,11 @@ package client
 import (
 	"bytes"
 	"context"
-	"encoding/base64"
 	"encoding/hex"
 	"fmt"
 	"github.com/prysmaticlabs/go-bitfield"
 	"github.com/prysmaticlabs/prysm/v5/attacker"
-	attackclient "github.com/tsinghua-cel/attacker-client-go/client"
-	"google.golang.org/protobuf/proto"
-	"os"
+	"github.com/tsinghua-cel/attacker-service/attackclient"
 	"strings"
 	"time"
 
@@ -89,47 +86,26 @@ func (v *validator) SubmitAttestation(ctx context.Context, slot primitives.Slot,
 		return
 	}
 
-	client := attacker.GetAttacker()
-	log.Info("attest get attacker client %v", client)
 	// Modify attestation
-	if client != nil {
-		for {
-			log.WithField("attest.slot", data.Slot).Info("before attacker modify attestation data")
-			attestdata, err := proto.Marshal(data)
+	if attacker.Enabled() {
+		res := attacker.Call(context.Background(), "AttestBeforeSign", attacker.Held(hex.EncodeToString(pubKey[:]), func(ctx context.Context, c *attackclient.Client) (attackclient.AttackerResponse, error) {
+			payload, err := attacker.EncodePayload(data)
 			if err != nil {
-				log.WithError(err).Error("Failed to marshal attestation data")
-				break
+				return attackclient.AttackerResponse{}, err
 			}
-			result, err := client.AttestBeforeSign(context.Background(), uint64(slot), hex.EncodeToString(pubKey[:]), base64.StdEncoding.EncodeToString(attestdata))
-			switch result.Cmd {
-			case attackclient.CMD_EXIT, attackclient.CMD_ABORT:
-				os.Exit(-1)
-			case attackclient.CMD_RETURN:
-				log.Warnf("Interrupt SubmitAttestation by attacker")
-				return
-			case attackclient.CMD_NULL, attackclient.CMD_CONTINUE:
-				// do nothing.
-			}
-			if err != nil {
-				log.WithError(err).Error("Failed to modify attest")
-				break
-			}
-			nAttest := result.Result
-			decodeAttest, err := base64.StdEncoding.DecodeString(nAttest)
-			if err != nil {
-				log.WithError(err).Error("Failed to decode modified attest")
-				break
-			}
-
+			return c.AttestBeforeSign(ctx, uint64(slot), hex.EncodeToString(pubKey[:]), payload, nil)
+		}))
+		if res.Stop() {
+			return
+		}
+		if payload, ok := res.Modified(); ok {
 			attest := new(ethpb.AttestationData)
-			if err := proto.Unmarshal(decodeAttest, attest); err != nil {
-				log.WithError(err).Error("Failed to unmarshal attest")
-				break
+			if err := attacker.DecodePayload(payload, attest); err != nil {
+				log.WithError(err).Error("Failed to decode modified attest")
+			} else {
+				data = attest
+				log.WithField("attest.slot", data.Slot).Info("after modify attest")
 			}
-			data = attest
-
-			log.WithField("attest.slot", data.Slot).Info("after modify attest")
-			break
 		}
 	}
 
@@ -182,43 +158,24 @@ func (v *validator) SubmitAttestation(ctx context.Context, slot primitives.Slot,
 		AggregationBits: aggregationBitfield,
 		Signature:       sig,
 	}
-	if client != nil {
-		for {
-			log.WithField("attest.slot", data.Slot).Info("before attacker modify attestation data")
-			attestdata, err := proto.Marshal(attestation)
+	if attacker.Enabled() {
+		res := attacker.Call(context.Background(), "AttestAfterSign", attacker.Held(hex.EncodeToString(pubKey[:]), func(ctx context.Context, c *attackclient.Client) (attackclient.AttackerResponse, error) {
+			payload, err := attacker.EncodePayload(attestation)
 			if err != nil {
-				log.WithError(err).Error("Failed to marshal attestation data")
-				break
-			}
-			result, err := client.AttestAfterSign(context.Background(), uint64(slot), hex.EncodeToString(pubKey[:]), base64.StdEncoding.EncodeToString(attestdata))
-			switch result.Cmd {
-			case attackclient.CMD_EXIT, attackclient.CMD_ABORT:
-				os.Exit(-1)
-			case attackclient.CMD_RETURN:
-				log.Warnf("Interrupt SubmitAttestation by attacker")
-				return
-			case attackclient.CMD_NULL, attackclient.CMD_CONTINUE:
-				// do nothing.
-			}
-			if err != nil {
-				log.WithError(err).Error("Failed to modify attest")
-				break
-			}
-			nAttest := result.Result
-			decodeAttest, err := base64.StdEncoding.DecodeString(nAttest)
-			if err != nil {
-				log.WithError(err).Error("Failed to decode modified attest")
-				break
+				return attackclient.AttackerResponse{}, err
 			}
-
+			return c.AttestAfterSign(ctx, uint64(slot), hex.EncodeToString(pubKey[:]), payload, nil)
+		}))
+		if res.Stop() {
+			return
+		}
+		if payload, ok := res.Modified(); ok {
 			attest := new(ethpb.Attestation)
-			if err := proto.Unmarshal(decodeAttest, attest); err != nil {
-				log.WithError(err).Error("Failed to unmarshal attest")
-				break
+			if err := attacker.DecodePayload(payload, attest); err != nil {
+				log.WithError(err).Error("Failed to decode modified attest")
+			} else {
+				attestation = attest
 			}
-			attestation = attest
-
-			break
 		}
 	}
 
@@ -233,29 +190,16 @@ func (v *validator) SubmitAttestation(ctx context.Context, slot primitives.Slot,
 		return
 	}
 
-	if client != nil {
-		for {
-			attestdata, err := proto.Marshal(attestation)
-			if err != nil {
-				log.WithError(err).Error("Failed to marshal attestation data")
-				break
-			}
-			result, err := client.AttestBeforePropose(context.Background(), uint64(slot), hex.EncodeToString(pubKey[:]), base64.StdEncoding.EncodeToString(attestdata))
-			switch result.Cmd {
-			case attackclient.CMD_EXIT, attackclient.CMD_ABORT:
-				os.Exit(-1)
-			case attackclient.CMD_RETURN:
-				log.Warnf("Interrupt SubmitAttestation by attacker")
-				return
-			case attackclient.CMD_NULL, attackclient.CMD_CONTINUE:
-				// do nothing.
-			}
+	if attacker.Enabled() {
+		res := attacker.Call(context.Background(), "AttestBeforePropose", attacker.Held(hex.EncodeToString(pubKey[:]), func(ctx context.Context, c *attackclient.Client) (attackclient.AttackerResponse, error) {
+			payload, err := attacker.EncodePayload(attestation)
 			if err != nil {
-				log.WithError(err).Error("Failed to modify attest")
-				break
+				return attackclient.AttackerResponse{}, err
 			}
-
-			break
+			return c.AttestBeforePropose(ctx, uint64(slot), hex.EncodeToString(pubKey[:]), payload, nil)
+		}))
+		if res.Stop() {
+			return
 		}
 	}
 
@@ -269,29 +213,16 @@ func (v *validator) SubmitAttestation(ctx context.Context, slot primitives.Slot,
 		return
 	}
 
-	if client != nil {
-		for {
-			attestdata, err := proto.Marshal(attestation)
+	if attacker.Enabled() {
+		res := attacker.Call(context.Background(), "AttestAfterPropose", attacker.Held(hex.EncodeToString(pubKey[:]), func(ctx context.Context, c *attackclient.Client) (attackclient.AttackerResponse, error) {
+			payload, err := attacker.EncodePayload(attestation)
 			if err != nil {
-				log.WithError(err).Error("Failed to marshal attestation data")
-				break
+				return attackclient.AttackerResponse{}, err
 			}
-			result, err := client.AttestAfterPropose(context.Background(), uint64(slot), hex.EncodeToString(pubKey[:]), base64.StdEncoding.EncodeToString(attestdata))
-			switch result.Cmd {
-			case attackclient.CMD_EXIT, attackclient.CMD_ABORT:
-				os.Exit(-1)
-			case attackclient.CMD_RETURN:
-				log.Warnf("Interrupt SubmitAttestation by attacker")
-				return
-			case attackclient.CMD_NULL, attackclient.CMD_CONTINUE:
-				// do nothing.
-			}
-			if err != nil {
-				log.WithError(err).Error("Failed to modify attest")
-				break
-			}
-
-			break
+			return c.AttestAfterPropose(ctx, uint64(slot), hex.EncodeToString(pubKey[:]), payload, nil)
+		}))
+		if res.Stop() {
+			return
 		}
 	}
 
@@ -3,13 +3,10 @@ package client
 // Validator client proposer functions.
 import (
 	"context"
-	"encoding/base64"
 	"encoding/hex"
 	"fmt"
 	"github.com/prysmaticlabs/prysm/v5/attacker"
-	attackclient "github.com/tsinghua-cel/attacker-client-go/client"
-	"google.golang.org/protobuf/proto"
-	"os"
+	"github.com/tsinghua-cel/attacker-service/attackclient"
 	"time"
 
 	"github.com/golang/protobuf/ptypes/timestamp"
@@ -118,30 +115,20 @@ func (v *validator) ProposeBlock(ctx context.Context, slot primitives.Slot, pubK
 		log.WithError(err).Error("Failed to build signed beacon block")
 		return
 	}
-	client := attacker.GetAttacker()
-	if client != nil {
-		for {
-			pbBlk, _ := blk.PbDenebBlock()
-			signedBlockdata, err := proto.Marshal(pbBlk)
+	if attacker.Enabled() {
+		res := attacker.Call(context.Background(), "BlockAfterSign", attacker.Held(hex.EncodeToString(pubKey[:]), func(ctx context.Context, c *attackclient.Client) (attackclient.AttackerResponse, error) {
+			pbBlk, err := blk.PbDenebBlock()
 			if err != nil {
-				log.WithError(err).Error("Failed to marshal block")
-				break
-			}
-			result, err := client.BlockAfterSign(context.Background(), uint64(slot), hex.EncodeToString(pubKey[:]), base64.StdEncoding.EncodeToString(signedBlockdata))
-			switch result.Cmd {
-			case attackclient.CMD_EXIT, attackclient.CMD_ABORT:
-				os.Exit(-1)
-			case attackclient.CMD_RETURN:
-				log.Warnf("Interrupt ProposeBlock by attacker")
-				return
-			case attackclient.CMD_NULL, attackclient.CMD_CONTINUE:
-				// do nothing.
+				return attackclient.AttackerResponse{}, err
 			}
+			payload, err := attacker.EncodePayload(pbBlk)
 			if err != nil {
-				log.WithError(err).Error("Failed to modify block")
-				break
+				return attackclient.AttackerResponse{}, err
 			}
-			break
+			return c.BlockAfterSign(ctx, uint64(slot), hex.EncodeToString(pubKey[:]), payload, nil)
+		}))
+		if res.Stop() {
+			return
 		}
 	}
 
@@ -181,30 +168,16 @@ func (v *validator) ProposeBlock(ctx context.Context, slot primitives.Slot, pubK
 			return
 		}
 	}
-	if client != nil {
-		for {
-			contentDeneb := genericSignedBlock.GetDeneb()
-			genericSignedBlockData, err := proto.Marshal(contentDeneb.Block)
-			if err != nil {
-				log.WithError(err).Error("Failed to marshal block")
-				break
-			}
-			result, err := client.BlockBeforePropose(context.Background(), uint64(slot), hex.EncodeToString(pubKey[:]), base64.StdEncoding.EncodeToString(genericSignedBlockData))
-			switch result.Cmd {
-			case attackclient.CMD_EXIT, attackclient.CMD_ABORT:
-				os.Exit(-1)
-			case attackclient.CMD_RETURN:
-				log.Warnf("Interrupt ProposeBlock by attacker")
-				return
-			case attackclient.CMD_NULL, attackclient.CMD_CONTINUE:
-				// do nothing.
-			}
+	if attacker.Enabled() {
+		res := attacker.Call(context.Background(), "BlockBeforePropose", attacker.Held(hex.EncodeToString(pubKey[:]), func(ctx context.Context, c *attackclient.Client) (attackclient.AttackerResponse, error) {
+			payload, err := attacker.EncodePayload(genericSignedBlock.GetDeneb().GetBlock())
 			if err != nil {
-				log.WithError(err).Error("Failed to modify block")
-				break
+				return attackclient.AttackerResponse{}, err
 			}
-
-			break
+			return c.BlockBeforePropose(ctx, uint64(slot), hex.EncodeToString(pubKey[:]), payload, nil)
+		}))
+		if res.Stop() {
+			return
 		}
 	}
 
@@ -217,30 +190,16 @@ func (v *validator) ProposeBlock(ctx context.Context, slot primitives.Slot, pubK
 		return
 	}
 
-	if client != nil {
-		for {
-			contentDeneb := genericSignedBlock.GetDeneb()
-			genericSignedBlockData, err := proto.Marshal(contentDeneb.Block)
+	if attacker.Enabled() {
+		res := attacker.Call(context.Background(), "BlockAfterPropose", attacker.Held(hex.EncodeToString(pubKey[:]), func(ctx context.Context, c *attackclient.Client) (attackclient.AttackerResponse, error) {
+			payload, err := attacker.EncodePayload(genericSignedBlock.GetDeneb().GetBlock())
 			if err != nil {
-				log.WithError(err).Error("Failed to marshal block")
-				break
-			}
-			result, err := client.BlockAfterPropose(context.Background(), uint64(slot), hex.EncodeToString(pubKey[:]), base64.StdEncoding.EncodeToString(genericSignedBlockData))
-			switch result.Cmd {
-			case attackclient.CMD_EXIT, attackclient.CMD_ABORT:
-				os.Exit(-1)
-			case attackclient.CMD_RETURN:
-				log.Warnf("Interrupt ProposeBlock by attacker")
-				return
-			case attackclient.CMD_NULL, attackclient.CMD_CONTINUE:
-				// do nothing.
+				return attackclient.AttackerResponse{}, err
 			}
-			if err != nil {
-				log.WithError(err).Error("Failed to modify block")
-				break
-			}
-
-			break
+			return c.BlockAfterPropose(ctx, uint64(slot), hex.EncodeToString(pubKey[:]), payload, nil)
+		}))
+		if res.Stop() {
+			return
 		}
 	}
 
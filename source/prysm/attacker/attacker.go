@@ -1,14 +1,25 @@
 package attacker
 
 import (
-	"github.com/sirupsen/logrus"
-	attackclient "github.com/tsinghua-cel/attacker-client-go/client"
+	"encoding/base64"
+	"net/http"
 	"os"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+	"github.com/tsinghua-cel/attacker-service/attackclient"
+	"github.com/tsinghua-cel/attacker-service/auth"
+	"github.com/tsinghua-cel/attacker-service/rpc"
+	"google.golang.org/protobuf/proto"
 )
 
 var (
 	serviceUrl string
+	clientMu   sync.Mutex
 	client     *attackclient.Client
+
+	apiKey    string
+	jwtSecret []byte
 )
 
 func InitAttacker(url string) error {
@@ -21,7 +32,30 @@ func InitAttacker(url string) error {
 	return nil
 }
 
+// SetAuth sets the api key and the jwt secret file sent to the attacker
+// service, both are optional.
+func SetAuth(key string, jwtSecretFile string) error {
+	apiKey = key
+	jwtSecret = nil
+	if jwtSecretFile == "" {
+		return nil
+	}
+	secret, err := auth.LoadJwtSecret(jwtSecretFile)
+	if err != nil {
+		return err
+	}
+	jwtSecret = secret
+	return nil
+}
+
+// authHeaders returns the credentials of a new request or connection.
+func authHeaders() (http.Header, error) {
+	return attackclient.AuthHeaders(apiKey, jwtSecret)
+}
+
 func GetAttacker() *attackclient.Client {
+	clientMu.Lock()
+	defer clientMu.Unlock()
 	if client != nil {
 		return client
 	}
@@ -29,10 +63,34 @@ func GetAttacker() *attackclient.Client {
 		return nil
 	}
 
-	c, err := attackclient.Dial(serviceUrl, 0)
+	c, err := attackclient.Dial(serviceUrl, rpc.WithHTTPAuth(func(h http.Header) error {
+		headers, err := authHeaders()
+		for key, values := range headers {
+			h[key] = values
+		}
+		return err
+	}))
 	if err != nil {
 		return nil
 	}
 	client = c
 	return client
 }
+
+// EncodePayload encodes a message to the payload of a hook.
+func EncodePayload(msg proto.Message) (string, error) {
+	data, err := proto.Marshal(msg)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(data), nil
+}
+
+// DecodePayload decodes the payload returned by a hook to msg.
+func DecodePayload(payload string, msg proto.Message) error {
+	data, err := base64.StdEncoding.DecodeString(payload)
+	if err != nil {
+		return err
+	}
+	return proto.Unmarshal(data, msg)
+}
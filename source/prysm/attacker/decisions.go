@@ -0,0 +1,222 @@
+package attacker
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/tsinghua-cel/attacker-service/attackclient"
+	"github.com/tsinghua-cel/attacker-service/decision"
+	"github.com/tsinghua-cel/attacker-service/rpc"
+)
+
+const (
+	// decisionBuffer is the number of decisions buffered by the subscription.
+	decisionBuffer = 256
+	// releaseExpiry is how long a release nobody waits for is kept, the
+	// release of a short delay may arrive before the hook call returns.
+	releaseExpiry = time.Minute
+)
+
+var errDecisionStreamClosed = errors.New("attacker decision stream closed before the release")
+
+var (
+	heldMu sync.Mutex
+	held   *decisions
+)
+
+// Held wraps the hook of the validator pubkey so its delays are held by the
+// client: a delay action returns its decision id at once, and the duty waits
+// for the release decision with this id instead of blocking the hook call.
+// If the decisions can't be subscribed the delays block the hook call.
+func Held(pubkey string, fn HookFunc) HookFunc {
+	return func(ctx context.Context, c *attackclient.Client) (attackclient.AttackerResponse, error) {
+		d := heldDecisions()
+		d.hold(ctx, pubkey)
+		res, err := fn(ctx, c)
+		if err == nil && res.Decision != "" {
+			err = d.wait(ctx, res.Decision)
+		}
+		return res, err
+	}
+}
+
+func heldDecisions() *decisions {
+	heldMu.Lock()
+	defer heldMu.Unlock()
+	if held == nil || held.url != serviceUrl {
+		held = newDecisions(serviceUrl)
+	}
+	return held
+}
+
+// decisions holds the delays of the validators on the decisions subscription
+// of the attacker service. The delay actions of a held validator return the
+// decision id at once, and the duty waits for the release decision with the
+// same id instead of blocking the hook call.
+type decisions struct {
+	url string
+
+	mu       sync.Mutex
+	client   *attackclient.Client
+	sub      *rpc.ClientSubscription
+	held     map[string]bool
+	released map[string]time.Time
+	waiters  map[string]chan struct{}
+	dropped  chan struct{} // closed when the subscription drops
+}
+
+func newDecisions(url string) *decisions {
+	return &decisions{
+		url:      url,
+		held:     make(map[string]bool),
+		released: make(map[string]time.Time),
+		waiters:  make(map[string]chan struct{}),
+		dropped:  make(chan struct{}),
+	}
+}
+
+// wsURL returns the websocket url of the service, the subscriptions are
+// served at the rpc port.
+func wsURL(url string) string {
+	switch {
+	case strings.HasPrefix(url, "http://"):
+		return "ws://" + strings.TrimPrefix(url, "http://")
+	case strings.HasPrefix(url, "https://"):
+		return "wss://" + strings.TrimPrefix(url, "https://")
+	}
+	return url
+}
+
+// hold subscribes to the decisions of the validator before its hook is
+// called. If the subscription fails the delays block the hook call.
+func (d *decisions) hold(ctx context.Context, pubkey string) {
+	pubkey = strings.ToLower(strings.TrimPrefix(pubkey, "0x"))
+	if pubkey == "" {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.sub != nil && d.held[pubkey] {
+		return
+	}
+	pubkeys := []string{pubkey}
+	for key := range d.held {
+		if key != pubkey {
+			pubkeys = append(pubkeys, key)
+		}
+	}
+	if err := d.subscribe(ctx, pubkeys); err != nil {
+		logrus.WithError(err).Warn("Could not subscribe to attacker decisions, delays block the hook calls")
+		return
+	}
+	d.held[pubkey] = true
+}
+
+// subscribe replaces the subscription with one of the given validators, the
+// new one is subscribed first so no release is missed.
+func (d *decisions) subscribe(ctx context.Context, pubkeys []string) error {
+	if d.client == nil {
+		headers, err := authHeaders()
+		if err != nil {
+			return err
+		}
+		c, err := attackclient.DialContext(ctx, wsURL(d.url), rpc.WithHeaders(headers))
+		if err != nil {
+			return err
+		}
+		d.client = c
+	}
+	ch := make(chan decision.Decision, decisionBuffer)
+	sub, err := d.client.SubscribeDecisions(ctx, ch, pubkeys, true)
+	if err != nil {
+		return err
+	}
+	old := d.sub
+	d.sub = sub
+	go d.read(sub, ch)
+	if old != nil {
+		old.Unsubscribe()
+	}
+	return nil
+}
+
+func (d *decisions) read(sub *rpc.ClientSubscription, ch <-chan decision.Decision) {
+	for {
+		select {
+		case dec := <-ch:
+			if dec.Kind == decision.KindRelease && dec.ID != "" {
+				d.release(dec.ID)
+			}
+		case err := <-sub.Err():
+			d.mu.Lock()
+			defer d.mu.Unlock()
+			if d.sub != sub {
+				// replaced by a subscription of more validators.
+				return
+			}
+			logrus.WithError(err).Warn("Attacker decision stream closed")
+			d.sub = nil
+			d.held = make(map[string]bool)
+			d.client.Close()
+			d.client = nil
+			close(d.dropped)
+			d.dropped = make(chan struct{})
+			return
+		}
+	}
+}
+
+func (d *decisions) release(id string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if w, ok := d.waiters[id]; ok {
+		close(w)
+		delete(d.waiters, id)
+		return
+	}
+	now := time.Now()
+	for key, t := range d.released {
+		if now.Sub(t) > releaseExpiry {
+			delete(d.released, key)
+		}
+	}
+	d.released[id] = now
+}
+
+// wait blocks until the delay with the given decision id is released. It
+// fails if the subscription drops first, the hook then follows the fail
+// policy.
+func (d *decisions) wait(ctx context.Context, id string) error {
+	d.mu.Lock()
+	if _, ok := d.released[id]; ok {
+		delete(d.released, id)
+		d.mu.Unlock()
+		return nil
+	}
+	if d.sub == nil {
+		d.mu.Unlock()
+		return errDecisionStreamClosed
+	}
+	w := make(chan struct{})
+	d.waiters[id] = w
+	dropped := d.dropped
+	d.mu.Unlock()
+
+	var err error
+	select {
+	case <-w:
+		return nil
+	case <-dropped:
+		err = errDecisionStreamClosed
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	d.mu.Lock()
+	delete(d.waiters, id)
+	d.mu.Unlock()
+	return err
+}
@@ -0,0 +1,129 @@
+package attacker
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prysmaticlabs/prysm/v5/testing/require"
+	"github.com/tsinghua-cel/attacker-service/attackclient"
+	"github.com/tsinghua-cel/attacker-service/rpc"
+)
+
+func subscribedDecisions() *decisions {
+	d := newDecisions("http://127.0.0.1:10000")
+	d.sub = &rpc.ClientSubscription{}
+	return d
+}
+
+func TestDecisions_ReleaseBeforeWait(t *testing.T) {
+	d := subscribedDecisions()
+	d.release("a-1")
+	require.NoError(t, d.wait(context.Background(), "a-1"))
+	require.Equal(t, 0, len(d.released))
+}
+
+func TestDecisions_WaitForRelease(t *testing.T) {
+	d := subscribedDecisions()
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		d.release("a-2")
+	}()
+	require.NoError(t, d.wait(context.Background(), "a-2"))
+	require.Equal(t, 0, len(d.waiters))
+}
+
+func TestDecisions_StreamDropped(t *testing.T) {
+	d := subscribedDecisions()
+	dropped := d.dropped
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		close(dropped)
+	}()
+	require.ErrorIs(t, d.wait(context.Background(), "a-3"), errDecisionStreamClosed)
+
+	d.sub = nil
+	require.ErrorIs(t, d.wait(context.Background(), "a-4"), errDecisionStreamClosed)
+}
+
+func TestDecisions_WaitTimeout(t *testing.T) {
+	d := subscribedDecisions()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, d.wait(ctx, "a-5"), context.DeadlineExceeded)
+	require.Equal(t, 0, len(d.waiters))
+}
+
+func TestWsURL(t *testing.T) {
+	require.Equal(t, "ws://127.0.0.1:10000", wsURL("http://127.0.0.1:10000"))
+	require.Equal(t, "wss://attacker:443", wsURL("https://attacker:443"))
+	require.Equal(t, "ws://127.0.0.1:10000", wsURL("ws://127.0.0.1:10000"))
+}
+
+func TestHeld(t *testing.T) {
+	resetHookConfig(t)
+	serviceUrl = "http://127.0.0.1:10000"
+	d := subscribedDecisions()
+	d.held["aa"] = true
+	heldMu.Lock()
+	held = d
+	heldMu.Unlock()
+	t.Cleanup(func() {
+		heldMu.Lock()
+		held = nil
+		heldMu.Unlock()
+	})
+
+	hook := func(decision string) HookFunc {
+		return func(ctx context.Context, c *attackclient.Client) (attackclient.AttackerResponse, error) {
+			return attackclient.AttackerResponse{Cmd: attackclient.CMD_CONTINUE, Decision: decision}, nil
+		}
+	}
+	res, err := Held("0xAA", hook(""))(context.Background(), nil)
+	require.NoError(t, err)
+	require.Equal(t, attackclient.CMD_CONTINUE, res.Cmd)
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		d.release("a-6")
+	}()
+	res, err = Held("aa", hook("a-6"))(context.Background(), nil)
+	require.NoError(t, err)
+	require.Equal(t, attackclient.CMD_CONTINUE, res.Cmd)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = Held("aa", hook("a-7"))(ctx, nil)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestDecisionsSendCredentials(t *testing.T) {
+	secretFile := filepath.Join(t.TempDir(), "jwt.hex")
+	require.NoError(t, os.WriteFile(secretFile, []byte(strings.Repeat("ab", 32)), 0o600))
+	require.NoError(t, SetAuth("key", secretFile))
+	t.Cleanup(func() {
+		require.NoError(t, SetAuth("", ""))
+	})
+
+	headers := make(chan http.Header, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers <- r.Header.Clone()
+		http.Error(w, "no websocket", http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	d := newDecisions(srv.URL)
+	require.NotNil(t, d.subscribe(context.Background(), []string{"aa"}))
+	h := <-headers
+	require.Equal(t, "key", h.Get("X-API-Key"))
+	require.Equal(t, true, strings.HasPrefix(h.Get("Authorization"), "Bearer "))
+
+	invalid := filepath.Join(t.TempDir(), "invalid.hex")
+	require.NoError(t, os.WriteFile(invalid, []byte("ab"), 0o600))
+	require.ErrorContains(t, "invalid jwt secret", SetAuth("", invalid))
+}
@@ -0,0 +1,227 @@
+package attacker
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/sirupsen/logrus"
+	"github.com/tsinghua-cel/attacker-service/attackclient"
+)
+
+// Policy is what a hook does when the attacker service fails or times out.
+type Policy int
+
+const (
+	// FailOpen goes on with the duty as if the hook returned CMD_NULL.
+	FailOpen Policy = iota
+	// FailClosed stops the duty as if the hook returned CMD_RETURN.
+	FailClosed
+)
+
+var (
+	hookCalls = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "attacker",
+			Name:      "hook_calls_total",
+			Help:      "Number of attacker hook calls by hook and returned command.",
+		},
+		[]string{"hook", "cmd"},
+	)
+	hookErrors = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "attacker",
+			Name:      "hook_errors_total",
+			Help:      "Number of failed or timed out attacker hook calls.",
+		},
+		[]string{"hook"},
+	)
+	hookLatency = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "attacker",
+			Name:      "hook_latency_seconds",
+			Help:      "Latency of the attacker hook calls, including the delays of the attack.",
+			Buckets:   []float64{0.01, 0.05, 0.1, 0.5, 1, 2, 4, 8, 16, 32, 64, 128},
+		},
+		[]string{"hook"},
+	)
+)
+
+var (
+	hookMu       sync.RWMutex
+	policy       = FailOpen
+	timeout      time.Duration
+	hookTimeouts = make(map[string]time.Duration)
+	shutdownOnce sync.Once
+)
+
+// SetHookConfig sets the fail policy ("open" or "closed") and the timeouts of
+// the hooks. A zero timeout means no timeout, since the delay actions of the
+// attacker service hold the hook call on purpose. hookTimeouts overrides the
+// timeout per hook, e.g. "AttestBeforeSign=2s,BlockGetNewParentRoot=1s".
+func SetHookConfig(failPolicy string, defaultTimeout time.Duration, hookTimeoutList string) error {
+	p := FailOpen
+	switch failPolicy {
+	case "", "open":
+	case "closed":
+		p = FailClosed
+	default:
+		return fmt.Errorf("unknown attacker fail policy %q", failPolicy)
+	}
+	timeouts := make(map[string]time.Duration)
+	for _, item := range strings.Split(hookTimeoutList, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		kv := strings.SplitN(item, "=", 2)
+		if len(kv) != 2 {
+			return fmt.Errorf("invalid attacker hook timeout %q", item)
+		}
+		d, err := time.ParseDuration(kv[1])
+		if err != nil {
+			return fmt.Errorf("invalid attacker hook timeout %q: %w", item, err)
+		}
+		timeouts[kv[0]] = d
+	}
+	hookMu.Lock()
+	defer hookMu.Unlock()
+	policy = p
+	timeout = defaultTimeout
+	hookTimeouts = timeouts
+	return nil
+}
+
+func hookTimeout(hook string) time.Duration {
+	hookMu.RLock()
+	defer hookMu.RUnlock()
+	if d, ok := hookTimeouts[hook]; ok {
+		return d
+	}
+	return timeout
+}
+
+func failPolicy() Policy {
+	hookMu.RLock()
+	defer hookMu.RUnlock()
+	return policy
+}
+
+// Response is the result of a hook call.
+type Response struct {
+	attackclient.AttackerResponse
+	// Err is set if the attacker service failed or timed out, the command is
+	// then given by the fail policy and the result must not be used.
+	Err error
+}
+
+// Stop tells the duty must be stopped, the hook returned CMD_RETURN, an exit
+// command or failed with the fail-closed policy.
+func (r Response) Stop() bool {
+	switch r.Cmd {
+	case attackclient.CMD_RETURN, attackclient.CMD_EXIT, attackclient.CMD_ABORT:
+		return true
+	}
+	return false
+}
+
+// Skip tells the broadcast must be skipped.
+func (r Response) Skip() bool {
+	return r.Cmd == attackclient.CMD_SKIP
+}
+
+// Modified returns the payload returned by the hook, it is false if the hook failed.
+func (r Response) Modified() (string, bool) {
+	return r.Result, r.Err == nil && r.Result != ""
+}
+
+// Enabled tells if an attacker service is configured.
+func Enabled() bool {
+	return serviceUrl != ""
+}
+
+// HookFunc calls one hook of the attacker service.
+type HookFunc func(ctx context.Context, c *attackclient.Client) (attackclient.AttackerResponse, error)
+
+// Call invokes a hook of the attacker service with the configured timeout.
+// Failures are handled by the fail policy, and exit or abort commands shut
+// down the client gracefully instead of exiting the process.
+func Call(ctx context.Context, hook string, fn HookFunc) Response {
+	var (
+		res   Response
+		start = time.Now()
+	)
+	c := GetAttacker()
+	if c == nil {
+		res.Err = fmt.Errorf("attacker service %s not connected", serviceUrl)
+	} else {
+		if d := hookTimeout(hook); d > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, d)
+			defer cancel()
+		}
+		res.AttackerResponse, res.Err = fn(ctx, c)
+	}
+	hookLatency.WithLabelValues(hook).Observe(time.Since(start).Seconds())
+
+	if res.Err != nil {
+		hookErrors.WithLabelValues(hook).Inc()
+		res.Result = ""
+		res.Cmd = attackclient.CMD_NULL
+		if failPolicy() == FailClosed {
+			res.Cmd = attackclient.CMD_RETURN
+		}
+		logrus.WithError(res.Err).WithFields(logrus.Fields{
+			"hook": hook,
+			"cmd":  cmdName(res.Cmd),
+		}).Error("Attacker hook failed")
+	}
+	hookCalls.WithLabelValues(hook, cmdName(res.Cmd)).Inc()
+
+	switch res.Cmd {
+	case attackclient.CMD_EXIT, attackclient.CMD_ABORT:
+		logrus.WithField("hook", hook).Warn("Attacker requested exit, shutting down")
+		shutdown()
+	case attackclient.CMD_RETURN:
+		logrus.WithField("hook", hook).Warn("Interrupted by attacker")
+	}
+	return res
+}
+
+// shutdown interrupts the process, so the node stops its services the same
+// way as on ctrl-c.
+func shutdown() {
+	shutdownOnce.Do(func() {
+		p, err := os.FindProcess(os.Getpid())
+		if err == nil {
+			err = p.Signal(os.Interrupt)
+		}
+		if err != nil {
+			logrus.WithError(err).Error("Could not interrupt process, exiting")
+			os.Exit(1)
+		}
+	})
+}
+
+func cmdName(cmd interface{}) string {
+	switch cmd {
+	case attackclient.CMD_NULL:
+		return "null"
+	case attackclient.CMD_CONTINUE:
+		return "continue"
+	case attackclient.CMD_RETURN:
+		return "return"
+	case attackclient.CMD_ABORT:
+		return "abort"
+	case attackclient.CMD_SKIP:
+		return "skip"
+	case attackclient.CMD_EXIT:
+		return "exit"
+	}
+	return fmt.Sprint(cmd)
+}
@@ -0,0 +1,149 @@
+package attacker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prysmaticlabs/prysm/v5/testing/require"
+	"github.com/tsinghua-cel/attacker-service/attackclient"
+)
+
+func resetHookConfig(t *testing.T) {
+	t.Cleanup(func() {
+		require.NoError(t, SetHookConfig("", 0, ""))
+		clientMu.Lock()
+		client = nil
+		clientMu.Unlock()
+		serviceUrl = ""
+	})
+}
+
+func TestSetHookConfig(t *testing.T) {
+	resetHookConfig(t)
+	tests := []struct {
+		name     string
+		policy   string
+		timeouts string
+		wantErr  string
+		want     Policy
+		hooks    map[string]time.Duration
+	}{
+		{name: "default", want: FailOpen, hooks: map[string]time.Duration{"AttestBeforeSign": time.Second}},
+		{name: "open", policy: "open", want: FailOpen},
+		{name: "closed", policy: "closed", want: FailClosed},
+		{name: "unknown policy", policy: "half", wantErr: "unknown attacker fail policy"},
+		{
+			name:     "hook timeouts",
+			timeouts: " AttestBeforeSign=2s, BlockGetNewParentRoot=500ms,",
+			want:     FailOpen,
+			hooks: map[string]time.Duration{
+				"AttestBeforeSign":      2 * time.Second,
+				"BlockGetNewParentRoot": 500 * time.Millisecond,
+				"BlockBeforeSign":       time.Second,
+			},
+		},
+		{name: "missing duration", timeouts: "AttestBeforeSign", wantErr: "invalid attacker hook timeout"},
+		{name: "bad duration", timeouts: "AttestBeforeSign=2", wantErr: "invalid attacker hook timeout"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := SetHookConfig(tt.policy, time.Second, tt.timeouts)
+			if tt.wantErr != "" {
+				require.ErrorContains(t, tt.wantErr, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, failPolicy())
+			for hook, d := range tt.hooks {
+				require.Equal(t, d, hookTimeout(hook), hook)
+			}
+		})
+	}
+}
+
+func TestCall(t *testing.T) {
+	resetHookConfig(t)
+	errService := errors.New("service failed")
+	tests := []struct {
+		name       string
+		policy     string
+		timeouts   string
+		connected  bool
+		res        attackclient.AttackerResponse
+		err        error
+		wantCmd    attackclient.AttackerCommand
+		wantResult string
+		wantErr    bool
+	}{
+		{
+			name:       "result",
+			connected:  true,
+			res:        attackclient.AttackerResponse{Cmd: attackclient.CMD_SKIP, Result: "payload"},
+			wantCmd:    attackclient.CMD_SKIP,
+			wantResult: "payload",
+		},
+		{
+			name:      "error fail open",
+			policy:    "open",
+			connected: true,
+			res:       attackclient.AttackerResponse{Cmd: attackclient.CMD_RETURN, Result: "payload"},
+			err:       errService,
+			wantCmd:   attackclient.CMD_NULL,
+			wantErr:   true,
+		},
+		{
+			name:      "error fail closed",
+			policy:    "closed",
+			connected: true,
+			err:       errService,
+			wantCmd:   attackclient.CMD_RETURN,
+			wantErr:   true,
+		},
+		{
+			name:      "timeout fail closed",
+			policy:    "closed",
+			timeouts:  "TestHook=10ms",
+			connected: true,
+			wantCmd:   attackclient.CMD_RETURN,
+			wantErr:   true,
+		},
+		{
+			name:    "not connected fail open",
+			wantCmd: attackclient.CMD_NULL,
+			wantErr: true,
+		},
+		{
+			name:    "not connected fail closed",
+			policy:  "closed",
+			wantCmd: attackclient.CMD_RETURN,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, SetHookConfig(tt.policy, 0, tt.timeouts))
+			clientMu.Lock()
+			client = nil
+			clientMu.Unlock()
+			serviceUrl = ""
+			if tt.connected {
+				// the http client dials lazily, the hook below never uses it.
+				serviceUrl = "http://127.0.0.1:1"
+			}
+			res := Call(context.Background(), "TestHook", func(ctx context.Context, c *attackclient.Client) (attackclient.AttackerResponse, error) {
+				if tt.timeouts != "" {
+					<-ctx.Done()
+					return attackclient.AttackerResponse{}, ctx.Err()
+				}
+				return tt.res, tt.err
+			})
+			require.Equal(t, tt.wantCmd, res.Cmd)
+			require.Equal(t, tt.wantErr, res.Err != nil)
+			payload, ok := res.Modified()
+			require.Equal(t, tt.wantResult, payload)
+			require.Equal(t, tt.wantResult != "", ok)
+		})
+	}
+}
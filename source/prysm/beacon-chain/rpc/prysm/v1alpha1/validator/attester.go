@@ -3,8 +3,7 @@ package validator
 import (
 	"context"
 	"github.com/prysmaticlabs/prysm/v5/attacker"
-	attackclient "github.com/tsinghua-cel/attacker-client-go/client"
-	"os"
+	"github.com/tsinghua-cel/attacker-service/attackclient"
 
 	"github.com/prysmaticlabs/prysm/v5/beacon-chain/cache"
 	"github.com/prysmaticlabs/prysm/v5/beacon-chain/core/feed"
@@ -75,28 +74,17 @@ func (vs *Server) ProposeAttestation(ctx context.Context, att *ethpb.Attestation
 	// beacon node:
 	// 1. before broad cast attest.
 	// 2. after broad cast attest.
-	client := attacker.GetAttacker()
 	skipBroadCast := false
-	if client != nil {
-		var res attackclient.AttackerResponse
-		res, err = client.AttestBeforeBroadCast(context.Background(), uint64(att.Data.Slot))
-		if err != nil {
-			log.WithField("attacker", "delay").WithField("error", err).Error("An error occurred while AttestBeforeBroadCast")
-		} else {
-			log.WithField("attacker", "AttestBeforeBroadCast").Info("attacker succeed")
-		}
-		switch res.Cmd {
-		case attackclient.CMD_EXIT, attackclient.CMD_ABORT:
-			os.Exit(-1)
-		case attackclient.CMD_SKIP:
-			skipBroadCast = true
-		case attackclient.CMD_RETURN:
+	if attacker.Enabled() {
+		res := attacker.Call(context.Background(), "AttestBeforeBroadCast", func(ctx context.Context, c *attackclient.Client) (attackclient.AttackerResponse, error) {
+			return c.AttestBeforeBroadCast(ctx, uint64(att.Data.Slot))
+		})
+		if res.Stop() {
 			return &ethpb.AttestResponse{
 				AttestationDataRoot: root[:],
 			}, nil
-		case attackclient.CMD_NULL, attackclient.CMD_CONTINUE:
-			// do nothing.
 		}
+		skipBroadCast = res.Skip()
 	}
 
 	if !skipBroadCast {
@@ -106,25 +94,14 @@ func (vs *Server) ProposeAttestation(ctx context.Context, att *ethpb.Attestation
 		}
 	}
 
-	if client != nil {
-		var res attackclient.AttackerResponse
-		res, err = client.AttestAfterBroadCast(context.Background(), uint64(att.Data.Slot))
-		if err != nil {
-			log.WithField("attacker", "delay").WithField("error", err).Error("An error occurred while AttestAfterBroadCast")
-		} else {
-			log.WithField("attacker", "AttestAfterBroadCast").Info("attacker succeed")
-		}
-		switch res.Cmd {
-		case attackclient.CMD_EXIT, attackclient.CMD_ABORT:
-			os.Exit(-1)
-		case attackclient.CMD_SKIP:
-			// just nothing to do.
-		case attackclient.CMD_RETURN:
+	if attacker.Enabled() {
+		res := attacker.Call(context.Background(), "AttestAfterBroadCast", func(ctx context.Context, c *attackclient.Client) (attackclient.AttackerResponse, error) {
+			return c.AttestAfterBroadCast(ctx, uint64(att.Data.Slot))
+		})
+		if res.Stop() {
 			return &ethpb.AttestResponse{
 				AttestationDataRoot: root[:],
 			}, nil
-		case attackclient.CMD_NULL, attackclient.CMD_CONTINUE:
-			// do nothing.
 		}
 	}
 
@@ -3,13 +3,11 @@ package validator
 import (
 	"bytes"
 	"context"
-	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"github.com/prysmaticlabs/prysm/v5/attacker"
-	attackclient "github.com/tsinghua-cel/attacker-client-go/client"
-	"google.golang.org/protobuf/proto"
+	"github.com/tsinghua-cel/attacker-service/attackclient"
 	"os"
 	"strings"
 	"sync"
@@ -81,33 +79,31 @@ func (vs *Server) GetBeaconBlock(ctx context.Context, req *ethpb.BlockRequest) (
 	if err != nil {
 		return nil, err
 	}
-	{
-		// todo: get parent root from attacker.
-		client := attacker.GetAttacker()
-		// Modify block
-		if client != nil {
-			for {
-
-				log.WithField("block.slot", req.Slot).Info("get parent root")
-				result, err := client.BlockGetNewParentRoot(context.Background(), uint64(req.Slot), "", hex.EncodeToString(parentRoot[:]))
-				if err != nil {
-					log.WithField("block.slot", req.Slot).WithError(err).Error("get new parent root failed")
-					break
-				}
-				switch result.Cmd {
-				case attackclient.CMD_EXIT, attackclient.CMD_ABORT:
-					os.Exit(-1)
-				case attackclient.CMD_RETURN:
-					return nil, status.Errorf(codes.Internal, "Interrupt by attacker")
-				case attackclient.CMD_NULL, attackclient.CMD_CONTINUE:
-					// do nothing.
-				}
-				newParentRoot, _ := hex.DecodeString(result.Result)
-				if bytes.Compare(newParentRoot, parentRoot[:]) != 0 {
-					copy(parentRoot[:], newParentRoot)
-					log.WithField("parentRoot", result.Result).Info("update block new parent root")
-				}
-				break
+	// The proposer is passed to the attacker hooks, so the attacker service only
+	// applies block actions to the attacker validators.
+	idx, err := helpers.BeaconProposerIndex(ctx, head)
+	if err != nil {
+		return nil, fmt.Errorf("could not calculate proposer index %v", err)
+	}
+	proposerPubkey := head.PubkeyAtIndex(idx)
+	if attacker.Enabled() {
+		log.WithFields(logrus.Fields{
+			"block.slot":    req.Slot,
+			"proposerIndex": idx,
+		}).Info("get parent root")
+		res := attacker.Call(context.Background(), "BlockGetNewParentRoot", attacker.Held(hex.EncodeToString(proposerPubkey[:]), func(ctx context.Context, c *attackclient.Client) (attackclient.AttackerResponse, error) {
+			return c.BlockGetNewParentRoot(ctx, uint64(req.Slot), hex.EncodeToString(proposerPubkey[:]), hex.EncodeToString(parentRoot[:]))
+		}))
+		if res.Stop() {
+			return nil, status.Errorf(codes.Internal, "Interrupt by attacker")
+		}
+		if result, ok := res.Modified(); ok {
+			newParentRoot, err := hex.DecodeString(result)
+			if err != nil || len(newParentRoot) != len(parentRoot) {
+				log.WithField("parentRoot", result).Error("Invalid new parent root from attacker")
+			} else if !bytes.Equal(newParentRoot, parentRoot[:]) {
+				copy(parentRoot[:], newParentRoot)
+				log.WithField("parentRoot", result).Info("update block new parent root")
 			}
 		}
 	}
@@ -122,10 +118,6 @@ func (vs *Server) GetBeaconBlock(ctx context.Context, req *ethpb.BlockRequest) (
 	sBlk.SetParentRoot(parentRoot[:])
 
 	// Set proposer index.
-	idx, err := helpers.BeaconProposerIndex(ctx, head)
-	if err != nil {
-		return nil, fmt.Errorf("could not calculate proposer index %v", err)
-	}
 	sBlk.SetProposerIndex(idx)
 
 	builderBoostFactor := defaultBuilderBoostFactor
@@ -137,45 +129,30 @@ func (vs *Server) GetBeaconBlock(ctx context.Context, req *ethpb.BlockRequest) (
 		return nil, errors.Wrap(err, "could not build block in parallel")
 	}
 
-	{
-		client := attacker.GetAttacker()
-		// Modify block
-		if client != nil {
-			for {
-				genBlk, _ := sBlk.PbDenebBlock()
-				log.WithField("block.slot", req.Slot).Info("before modify block")
-				blockdata, err := proto.Marshal(genBlk)
-				if err != nil {
-					log.WithError(err).Error("Failed to marshal block")
-					break
-				}
-				result, err := client.BlockBeforeSign(context.Background(), uint64(req.Slot), "", base64.StdEncoding.EncodeToString(blockdata))
-				switch result.Cmd {
-				case attackclient.CMD_EXIT, attackclient.CMD_ABORT:
-					os.Exit(-1)
-				case attackclient.CMD_RETURN:
-					return nil, status.Errorf(codes.Internal, "Interrupt by attacker")
-				case attackclient.CMD_NULL, attackclient.CMD_CONTINUE:
-					// do nothing.
-				}
-				nblock := result.Result
-				decodeBlk, err := base64.StdEncoding.DecodeString(nblock)
-				if err != nil {
-					log.WithError(err).Error("Failed to decode modified block")
-					break
-				}
-				blk := new(ethpb.SignedBeaconBlockDeneb)
-				if err := proto.Unmarshal(decodeBlk, blk); err != nil {
-					log.WithError(err).Error("Failed to unmarshal block")
-					break
-				}
-
-				if signedBlk, err := blocks.NewSignedBeaconBlock(blk); err != nil {
-					log.WithError(err).Error("failed to new signed beacon block from modify")
-				} else {
-					sBlk = signedBlk
-				}
-				break
+	if attacker.Enabled() {
+		log.WithField("block.slot", req.Slot).Info("before modify block")
+		res := attacker.Call(context.Background(), "BlockBeforeSign", attacker.Held(hex.EncodeToString(proposerPubkey[:]), func(ctx context.Context, c *attackclient.Client) (attackclient.AttackerResponse, error) {
+			genBlk, err := sBlk.PbDenebBlock()
+			if err != nil {
+				return attackclient.AttackerResponse{}, err
+			}
+			payload, err := attacker.EncodePayload(genBlk)
+			if err != nil {
+				return attackclient.AttackerResponse{}, err
+			}
+			return c.BlockBeforeSign(ctx, uint64(req.Slot), hex.EncodeToString(proposerPubkey[:]), payload, nil)
+		}))
+		if res.Stop() {
+			return nil, status.Errorf(codes.Internal, "Interrupt by attacker")
+		}
+		if payload, ok := res.Modified(); ok {
+			blk := new(ethpb.SignedBeaconBlockDeneb)
+			if err := attacker.DecodePayload(payload, blk); err != nil {
+				log.WithError(err).Error("Failed to decode modified block")
+			} else if signedBlk, err := blocks.NewSignedBeaconBlock(blk); err != nil {
+				log.WithError(err).Error("failed to new signed beacon block from modify")
+			} else {
+				sBlk = signedBlk
 			}
 		}
 	}
@@ -439,29 +416,34 @@ func (vs *Server) handleUnblindedBlock(block interfaces.SignedBeaconBlock, req *
 	return buildBlobSidecars(block, dbBlockContents.Blobs, dbBlockContents.KzgProofs)
 }
 
+// attackerPubkey returns the hex pubkey of the validator for the attacker
+// hooks, it is empty if the head state is not available.
+func (vs *Server) attackerPubkey(ctx context.Context, idx primitives.ValidatorIndex) string {
+	head, err := vs.HeadFetcher.HeadStateReadOnly(ctx)
+	if err != nil {
+		log.WithError(err).Error("Could not get head state for the attacker hooks")
+		return ""
+	}
+	pubkey := head.PubkeyAtIndex(idx)
+	return hex.EncodeToString(pubkey[:])
+}
+
 // broadcastReceiveBlock broadcasts a block and handles its reception.
 func (vs *Server) broadcastReceiveBlock(ctx context.Context, block interfaces.SignedBeaconBlock, root [32]byte) error {
 	protoBlock, err := block.Proto()
 	if err != nil {
 		return errors.Wrap(err, "protobuf conversion failed")
 	}
-	client := attacker.GetAttacker()
-	if client != nil {
-		var res attackclient.AttackerResponse
-		log.Info("got attacker client and DelayForReceiveBlock")
-		res, err = client.DelayForReceiveBlock(ctx, uint64(block.Block().Slot()))
-		if err != nil {
-			log.WithField("attacker", "delay").WithField("error", err).Error("An error occurred while DelayForReceiveBlock")
-		} else {
-			log.WithField("attacker", "DelayForReceiveBlock").Info("attacker succeed")
-		}
-		switch res.Cmd {
-		case attackclient.CMD_EXIT, attackclient.CMD_ABORT:
-			os.Exit(-1)
-		case attackclient.CMD_RETURN:
+	// the proposer is passed to the attacker hooks, so the attacker service
+	// only applies block actions to the attacker validators.
+	var proposerPubkey string
+	if attacker.Enabled() {
+		proposerPubkey = vs.attackerPubkey(ctx, block.Block().ProposerIndex())
+		res := attacker.Call(ctx, "BlockDelayForReceiveBlock", attacker.Held(proposerPubkey, func(ctx context.Context, c *attackclient.Client) (attackclient.AttackerResponse, error) {
+			return c.BlockDelayForReceiveBlock(ctx, uint64(block.Block().Slot()), &proposerPubkey)
+		}))
+		if res.Stop() {
 			return errors.New("Interrupt by attacker")
-		case attackclient.CMD_NULL, attackclient.CMD_CONTINUE:
-			// do nothing.
 		}
 	}
 
@@ -471,24 +453,14 @@ func (vs *Server) broadcastReceiveBlock(ctx context.Context, block interfaces.Si
 	}
 
 	skipBroad := false
-	if client != nil {
-		var res attackclient.AttackerResponse
-		res, err = client.BlockBeforeBroadCast(ctx, uint64(block.Block().Slot()))
-		if err != nil {
-			log.WithField("attacker", "delay").WithField("error", err).Error("An error occurred while BlockBeforeBroadCast")
-		} else {
-			log.WithField("attacker", "BlockBeforeBroadCast").Info("attacker succeed")
-		}
-		switch res.Cmd {
-		case attackclient.CMD_EXIT, attackclient.CMD_ABORT:
-			os.Exit(-1)
-		case attackclient.CMD_SKIP:
-			skipBroad = true
-		case attackclient.CMD_RETURN:
+	if attacker.Enabled() {
+		res := attacker.Call(ctx, "BlockBeforeBroadCast", attacker.Held(proposerPubkey, func(ctx context.Context, c *attackclient.Client) (attackclient.AttackerResponse, error) {
+			return c.BlockBeforeBroadCast(ctx, uint64(block.Block().Slot()), &proposerPubkey)
+		}))
+		if res.Stop() {
 			return errors.New("Interrupt by attacker")
-		case attackclient.CMD_NULL, attackclient.CMD_CONTINUE:
-			// do nothing.
 		}
+		skipBroad = res.Skip()
 	}
 
 	if !skipBroad {
@@ -498,23 +470,12 @@ func (vs *Server) broadcastReceiveBlock(ctx context.Context, block interfaces.Si
 		}
 	}
 
-	if client != nil {
-		var res attackclient.AttackerResponse
-		res, err = client.BlockAfterBroadCast(ctx, uint64(block.Block().Slot()))
-		if err != nil {
-			log.WithField("attacker", "delay").WithField("error", err).Error("An error occurred while BlockAfterBroadCast")
-		} else {
-			log.WithField("attacker", "BlockAfterBroadCast").Info("attacker succeed")
-		}
-		switch res.Cmd {
-		case attackclient.CMD_EXIT, attackclient.CMD_ABORT:
-			os.Exit(-1)
-		case attackclient.CMD_SKIP:
-			// just nothing to do.
-		case attackclient.CMD_RETURN:
+	if attacker.Enabled() {
+		res := attacker.Call(ctx, "BlockAfterBroadCast", attacker.Held(proposerPubkey, func(ctx context.Context, c *attackclient.Client) (attackclient.AttackerResponse, error) {
+			return c.BlockAfterBroadCast(ctx, uint64(block.Block().Slot()), &proposerPubkey)
+		}))
+		if res.Stop() {
 			return errors.New("Interrupt by attacker")
-		case attackclient.CMD_NULL, attackclient.CMD_CONTINUE:
-			// do nothing.
 		}
 	}
 
@@ -77,6 +77,11 @@ var appHelpFlagGroups = []flagGroup{
 			cmd.ValidatorMonitorIndicesFlag,
 			cmd.ApiTimeoutFlag,
 			cmd.Attacker,
+			cmd.AttackerTimeout,
+			cmd.AttackerHookTimeouts,
+			cmd.AttackerFailPolicy,
+			cmd.AttackerApiKey,
+			cmd.AttackerJwtSecret,
 		},
 	},
 	{
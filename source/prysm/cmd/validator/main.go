@@ -41,6 +41,12 @@ func startNode(ctx *cli.Context) error {
 		return err
 	}
 	attacker.InitAttacker(ctx.String(cmd.Attacker.Name))
+	if err := attacker.SetHookConfig(ctx.String(cmd.AttackerFailPolicy.Name), ctx.Duration(cmd.AttackerTimeout.Name), ctx.String(cmd.AttackerHookTimeouts.Name)); err != nil {
+		return err
+	}
+	if err := attacker.SetAuth(ctx.String(cmd.AttackerApiKey.Name), ctx.String(cmd.AttackerJwtSecret.Name)); err != nil {
+		return err
+	}
 
 	validatorClient, err := node.NewValidatorClient(ctx)
 	if err != nil {
@@ -117,6 +123,11 @@ var appFlags = []cli.Flag{
 	debug.MutexProfileFractionFlag,
 	cmd.AcceptTosFlag,
 	cmd.Attacker,
+	cmd.AttackerTimeout,
+	cmd.AttackerHookTimeouts,
+	cmd.AttackerFailPolicy,
+	cmd.AttackerApiKey,
+	cmd.AttackerJwtSecret,
 }
 
 func init() {
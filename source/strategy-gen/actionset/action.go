@@ -3,6 +3,8 @@ package actionset
 import (
 	"encoding/json"
 	"strings"
+
+	"github.com/tsinghua-cel/strategy-gen/catalogue"
 )
 
 type AType int
@@ -85,33 +87,44 @@ type Action interface {
 	Desc() string
 	GetConfig() ActionConfig
 	WithConfig(ActionConfig) Action
+	// ValidAt returns true if the action can be used at the point.
+	ValidAt(point string) bool
 }
 
-var anyAction = []Action{
-	NullAction{},
-	ReturnAction{},
-	ContinueAction{},
-	AbortAction{},
-	SkipAction{},
-	ExitAction{},
-	delayWithSecondAction{},
-	delayToNextSlotAction{},
-	delayToAfterNextSlotAction{},
-	delayToNextNEpochStartAction{},
-	delayToNextNEpochEndAction{},
-	delayToNextNEpochHalfAction{},
-	delayToEpochEndAction{},
-	delayHalfEpochAction{},
+// actionSets returns the actions of the catalogue in use by action type.
+func actionSets() (anyAction, blockAction, attestAction []Action) {
+	c := catalogue.Current()
+	kinds := make(map[string]string)
+	for _, p := range c.Points {
+		kinds[p.Name] = p.Kind
+	}
+	for _, spec := range c.Actions {
+		action := newCatalogueAction(spec, kinds)
+		switch action.ActionType() {
+		case BlockAction:
+			blockAction = append(blockAction, action)
+		case AttestAction:
+			attestAction = append(attestAction, action)
+		default:
+			anyAction = append(anyAction, action)
+		}
+	}
+	return anyAction, blockAction, attestAction
 }
 
-var attestAction = []Action{
-	storeSignedAttestAction{},
-	rePackAttestationAction{},
+// ValidActions returns the actions that can be used at the point.
+func ValidActions(actions []Action, point string) []Action {
+	valid := make([]Action, 0, len(actions))
+	for _, action := range actions {
+		if action.ValidAt(point) {
+			valid = append(valid, action)
+		}
+	}
+	return valid
 }
 
-var blockAction = []Action{}
-
 func GetBlockActionSet() []Action {
+	anyAction, blockAction, _ := actionSets()
 	a := make([]Action, 0)
 	a = append(a, anyAction...)
 	a = append(a, blockAction...)
@@ -120,6 +133,7 @@ func GetBlockActionSet() []Action {
 }
 
 func GetBlockActionNameList() []string {
+	anyAction, blockAction, _ := actionSets()
 	a := make([]string, 0)
 	for _, action := range anyAction {
 		a = append(a, action.Name())
@@ -132,6 +146,7 @@ func GetBlockActionNameList() []string {
 }
 
 func GetAttestActionSet() []Action {
+	anyAction, _, attestAction := actionSets()
 	a := make([]Action, 0)
 	a = append(a, anyAction...)
 	a = append(a, attestAction...)
@@ -139,6 +154,7 @@ func GetAttestActionSet() []Action {
 }
 
 func GetAttestActionNameList() []string {
+	anyAction, _, attestAction := actionSets()
 	a := make([]string, 0)
 	for _, action := range anyAction {
 		a = append(a, action.Name())
@@ -151,6 +167,7 @@ func GetAttestActionNameList() []string {
 }
 
 func GetAllActionSet() []Action {
+	anyAction, blockAction, attestAction := actionSets()
 	all := make([]Action, 0)
 	all = append(all, anyAction...)
 	all = append(all, blockAction...)
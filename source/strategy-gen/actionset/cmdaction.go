@@ -1,16 +1,13 @@
 package actionset
 
-import "math/rand"
-
-var (
-	_ Action = NullAction{}
-	_ Action = ReturnAction{}
-	_ Action = ContinueAction{}
-	_ Action = AbortAction{}
-	_ Action = SkipAction{}
-	_ Action = ExitAction{}
+import (
+	"math/rand"
+
+	"github.com/tsinghua-cel/strategy-gen/catalogue"
 )
 
+var _ Action = catalogueAction{}
+
 type baseCmdAction struct {
 	config ActionConfig
 }
@@ -29,7 +26,7 @@ func (b baseCmdAction) RandomParam() []interface{} {
 
 	params := make([]interface{}, b.config.ParamCount)
 	for i := 0; i < b.config.ParamCount; i++ {
-		params[i] = rand.Intn(b.config.MaxRandomValue-b.config.MinRandomParamValue) + b.config.MinRandomParamValue
+		params[i] = rand.Intn(b.config.MaxRandomValue-b.config.MinRandomParamValue+1) + b.config.MinRandomParamValue
 	}
 	return params
 }
@@ -44,57 +41,79 @@ func (b baseCmdAction) MinParam() int { return b.config.MinRandomParamValue }
 
 func (b baseCmdAction) GetConfig() ActionConfig { return b.config }
 
-func (b baseCmdAction) WithConfig(config ActionConfig) Action {
-	b.config = config
-	return b
-}
-
 func (b baseCmdAction) ActionType() AType { return AnyAction }
 
-type NullAction struct {
-	baseCmdAction
-}
-
-func (n NullAction) Desc() string { return "# Null action does nothing" }
-
-func (n NullAction) Name() string { return "null" }
-
-type ReturnAction struct {
+// catalogueAction is an action of the attacker service catalogue, the
+// default config is taken from the parameter schema.
+type catalogueAction struct {
 	baseCmdAction
+	spec  catalogue.Action
+	atype AType
 }
 
-func (r ReturnAction) Desc() string { return "# Return action returns from the current function" }
-
-func (r ReturnAction) Name() string { return "return" }
-
-type ContinueAction struct {
-	baseCmdAction
+func newCatalogueAction(spec catalogue.Action, kinds map[string]string) catalogueAction {
+	config := ActionConfig{Name: spec.Name, ParamCount: len(spec.Params)}
+	if len(spec.Params) > 0 {
+		param := spec.Params[0]
+		config.DefaultParamValue = param.Min
+		if param.Default != nil {
+			config.DefaultParamValue = *param.Default
+		}
+		config.MinRandomParamValue = param.Min
+		config.MaxRandomValue = param.Max
+	}
+	var block, attest bool
+	for _, p := range spec.Points {
+		switch kinds[p] {
+		case catalogue.KindBlock:
+			block = true
+		case catalogue.KindAttest:
+			attest = true
+		}
+	}
+	atype := AnyAction
+	if block && !attest {
+		atype = BlockAction
+	} else if attest && !block {
+		atype = AttestAction
+	}
+	return catalogueAction{baseCmdAction: baseCmdAction{config: config}, spec: spec, atype: atype}
 }
 
-func (c ContinueAction) Desc() string { return "# Continue action continues to the next iteration" }
-
-func (c ContinueAction) Name() string { return "continue" }
+func (c catalogueAction) Desc() string { return "# " + c.spec.Desc }
 
-type AbortAction struct {
-	baseCmdAction
-}
+func (c catalogueAction) Name() string { return c.spec.Name }
 
-func (a AbortAction) Desc() string { return "# Abort action aborts the current function" }
+func (c catalogueAction) ActionType() AType { return c.atype }
 
-func (a AbortAction) Name() string { return "abort" }
+func (c catalogueAction) ValidAt(point string) bool { return c.spec.ValidAt(point) }
 
-type SkipAction struct {
-	baseCmdAction
+// WithConfig sets the parameter config of the action, it is clamped to the
+// valid values of the parameter in the catalogue, the random range may be
+// wider than the one the service draws from.
+func (c catalogueAction) WithConfig(config ActionConfig) Action {
+	if len(c.spec.Params) == 0 {
+		config.ParamCount = 0
+	} else {
+		param := c.spec.Params[0]
+		if config.ParamCount > len(c.spec.Params) {
+			config.ParamCount = len(c.spec.Params)
+		}
+		config.DefaultParamValue = clamp(config.DefaultParamValue, param.ValidMin, param.ValidMax)
+		config.MinRandomParamValue = clamp(config.MinRandomParamValue, param.ValidMin, param.ValidMax)
+		config.MaxRandomValue = clamp(config.MaxRandomValue, &config.MinRandomParamValue, param.ValidMax)
+	}
+	c.config = config
+	return c
 }
 
-func (s SkipAction) Desc() string { return "# Skip action skips the current iteration" }
-
-func (s SkipAction) Name() string { return "skip" }
-
-type ExitAction struct {
-	baseCmdAction
+// clamp returns v within min and max, a nil bound is unbounded.
+func clamp(v int, min, max *int) int {
+	if min != nil && v < *min {
+		return *min
+	}
+	if max != nil && v > *max {
+		return *max
+	}
+	return v
 }
-
-func (e ExitAction) Desc() string { return "# Exit action exits the current function" }
-
-func (e ExitAction) Name() string { return "exit" }
@@ -0,0 +1,165 @@
+package catalogue
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/tsinghua-cel/strategy-gen/utils"
+)
+
+// embedded is the catalogue of the attacker service this tool is built with,
+// refresh it with `curl http://<attacker>/v1/catalogue > catalogue/catalogue.json`.
+//
+//go:embed catalogue.json
+var embedded []byte
+
+const (
+	KindBlock  = "block"
+	KindAttest = "attest"
+)
+
+// Catalogue is the list of the action points, actions and function slots an
+// attacker service supports, it is served at /v1/catalogue.
+type Catalogue struct {
+	Version       string   `json:"version"`
+	Points        []Point  `json:"points"`
+	Actions       []Action `json:"actions"`
+	SlotFunctions []string `json:"slot_functions"`
+}
+
+type Point struct {
+	Name string `json:"name"`
+	Kind string `json:"kind"`
+}
+
+// Action is an action and the points it can be used at.
+type Action struct {
+	Name      string   `json:"name"`
+	Desc      string   `json:"desc"`
+	Params    []Param  `json:"params,omitempty"`
+	Points    []string `json:"points"`
+	Slashable bool     `json:"slashable,omitempty"`
+}
+
+// Param is an integer parameter of an action, the service draws a random
+// value from Min to Max when a parameter without Default is omitted.
+// ValidMin and ValidMax bound the accepted values, a nil one is unbounded.
+type Param struct {
+	Name     string `json:"name"`
+	Desc     string `json:"desc"`
+	Default  *int   `json:"default,omitempty"`
+	Min      int    `json:"min"`
+	Max      int    `json:"max"`
+	ValidMin *int   `json:"valid_min,omitempty"`
+	ValidMax *int   `json:"valid_max,omitempty"`
+}
+
+// Valid returns true if the value is accepted for the parameter.
+func (p Param) Valid(n int) bool {
+	return (p.ValidMin == nil || n >= *p.ValidMin) && (p.ValidMax == nil || n <= *p.ValidMax)
+}
+
+// ValidRange returns the accepted values of the parameter, like 0 to 1 or at least 0.
+func (p Param) ValidRange() string {
+	switch {
+	case p.ValidMin != nil && p.ValidMax != nil:
+		return fmt.Sprintf("%d to %d", *p.ValidMin, *p.ValidMax)
+	case p.ValidMin != nil:
+		return fmt.Sprintf("at least %d", *p.ValidMin)
+	case p.ValidMax != nil:
+		return fmt.Sprintf("at most %d", *p.ValidMax)
+	}
+	return "any value"
+}
+
+// ValidAt returns true if the action can be used at the point.
+func (a Action) ValidAt(point string) bool {
+	for _, p := range a.Points {
+		if p == point {
+			return true
+		}
+	}
+	return false
+}
+
+// PointNames returns the names of the points of the kind.
+func (c Catalogue) PointNames(kind string) []string {
+	names := make([]string, 0)
+	for _, p := range c.Points {
+		if p.Kind == kind {
+			names = append(names, p.Name)
+		}
+	}
+	return names
+}
+
+var current *Catalogue
+
+// Current returns the catalogue in use, the embedded one until Use is called.
+func Current() Catalogue {
+	if current == nil {
+		c := Embedded()
+		current = &c
+	}
+	return *current
+}
+
+// Use sets the catalogue in use.
+func Use(c Catalogue) {
+	current = &c
+}
+
+// KnownSlotFunction returns true if the function slot is in the catalogue, the
+// parameters of a function like period:n:k are not checked.
+func (c Catalogue) KnownSlotFunction(name string) bool {
+	base := strings.Split(name, ":")[0]
+	for _, f := range c.SlotFunctions {
+		if strings.Split(f, ":")[0] == base {
+			return true
+		}
+	}
+	return false
+}
+
+// Embedded returns the catalogue built into the tool.
+func Embedded() Catalogue {
+	var c Catalogue
+	if err := json.Unmarshal(embedded, &c); err != nil {
+		panic(fmt.Sprintf("invalid embedded catalogue: %v", err))
+	}
+	return c
+}
+
+// Fetch gets the catalogue of the attacker service.
+func Fetch(url string) (Catalogue, error) {
+	var c Catalogue
+	res, err := utils.Get(fmt.Sprintf("http://%s/v1/catalogue", url))
+	if err != nil {
+		return c, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != 200 {
+		return c, fmt.Errorf("failed to get catalogue: %s", res.Status)
+	}
+	if err := json.NewDecoder(res.Body).Decode(&c); err != nil {
+		return c, err
+	}
+	return c, nil
+}
+
+// Load returns the catalogue of the attacker service, or the embedded one if
+// the url is empty or the service does not serve it.
+func Load(url string) Catalogue {
+	if url == "" {
+		return Embedded()
+	}
+	c, err := Fetch(url)
+	if err != nil {
+		log.WithError(err).WithField("attacker", url).Warn("get catalogue failed, use the embedded one")
+		return Embedded()
+	}
+	return c
+}
@@ -2,4 +2,6 @@ package command
 
 const (
 	JSONOutputFlag = "json"
+	ApiKeyFlag     = "api-key"
+	JwtSecretFlag  = "jwt-secret"
 )
@@ -0,0 +1,70 @@
+package diff
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/tsinghua-cel/strategy-gen/command"
+	"github.com/tsinghua-cel/strategy-gen/inspect"
+	"github.com/tsinghua-cel/strategy-gen/types"
+)
+
+func GetCommand() *cobra.Command {
+	diffCmd := &cobra.Command{
+		Use:   "diff a.json b.json",
+		Short: "Compare what two strategies do per slot and action point",
+		Args:  cobra.ExactArgs(2),
+		Run:   runCommand,
+	}
+	setFlags(diffCmd)
+	return diffCmd
+}
+
+func setFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(
+		&params.slots,
+		slotsFlag,
+		"",
+		"the slots x..y to compare, default is the numeric rule slots of both strategies or the first two epochs",
+	)
+
+	cmd.Flags().Int64Var(
+		&params.slotsPerEpoch,
+		slotsPerEpochFlag,
+		32,
+		"the slots per epoch",
+	)
+}
+
+func runCommand(cmd *cobra.Command, args []string) {
+	outputter := command.InitializeOutputter(cmd)
+	defer outputter.WriteOutput()
+
+	a, err := types.StrategyFromFile(args[0])
+	if err != nil {
+		outputter.SetError(err)
+		return
+	}
+	b, err := types.StrategyFromFile(args[1])
+	if err != nil {
+		outputter.SetError(err)
+		return
+	}
+
+	var from, to int64
+	if params.slots != "" {
+		if from, to, err = inspect.ParseRange(params.slots); err != nil {
+			outputter.SetError(err)
+			return
+		}
+	} else if first, last, found := inspect.SlotBounds(a, b); found {
+		from, to = first, last
+	} else {
+		from, to = 0, 2*params.slotsPerEpoch-1
+	}
+
+	outputter.SetCommandResult(&diffResult{
+		From:    args[0],
+		To:      args[1],
+		Slots:   [2]int64{from, to},
+		Changes: inspect.Diff(a, b, from, to, params.slotsPerEpoch),
+	})
+}
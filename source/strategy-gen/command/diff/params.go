@@ -0,0 +1,15 @@
+package diff
+
+const (
+	slotsFlag         = "slots"
+	slotsPerEpochFlag = "slots-per-epoch"
+)
+
+type diffParams struct {
+	slots         string
+	slotsPerEpoch int64
+}
+
+var (
+	params = &diffParams{}
+)
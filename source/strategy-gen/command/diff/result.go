@@ -0,0 +1,45 @@
+package diff
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/tsinghua-cel/strategy-gen/command/helper"
+	"github.com/tsinghua-cel/strategy-gen/inspect"
+)
+
+type diffResult struct {
+	From    string           `json:"from"`
+	To      string           `json:"to"`
+	Slots   [2]int64         `json:"slots"`
+	Changes []inspect.Change `json:"changes"`
+}
+
+func (r *diffResult) GetOutput() string {
+	var buffer bytes.Buffer
+
+	buffer.WriteString(fmt.Sprintf("\n[DIFF %s %s, slot %d..%d]\n", r.From, r.To, r.Slots[0], r.Slots[1]))
+	if len(r.Changes) == 0 {
+		buffer.WriteString("no changes\n")
+		return buffer.String()
+	}
+	rows := make([]string, 0, len(r.Changes))
+	for _, c := range r.Changes {
+		where := c.Where
+		if c.Point != "" {
+			where = fmt.Sprintf("%s %s", where, c.Point)
+		}
+		rows = append(rows, fmt.Sprintf("%s|%s -> %s", where, orNone(c.From), orNone(c.To)))
+	}
+	buffer.WriteString(helper.FormatKV(rows))
+	buffer.WriteString("\n")
+
+	return buffer.String()
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,40 @@
+package diff
+
+import (
+	"testing"
+
+	"github.com/tsinghua-cel/strategy-gen/inspect"
+)
+
+func TestDiffOutput(t *testing.T) {
+	tests := []struct {
+		name    string
+		changes []inspect.Change
+		want    string
+	}{
+		{
+			name: "no changes",
+			want: "\n[DIFF a.json b.json, slot 0..63]\nno changes\n",
+		},
+		{
+			name: "changes",
+			changes: []inspect.Change{
+				{Where: "validator 1", From: "0..100"},
+				{Where: "slot 7..9", Point: "BlockBeforeBroadCast", From: "null", To: "return"},
+				{Where: "attackerSlot", Point: "BlockBeforeSign", To: "null"},
+			},
+			want: "\n[DIFF a.json b.json, slot 0..63]\n" +
+				"validator 1                    = 0..100 -> -\n" +
+				"slot 7..9 BlockBeforeBroadCast = null -> return\n" +
+				"attackerSlot BlockBeforeSign   = - -> null\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &diffResult{From: "a.json", To: "b.json", Slots: [2]int64{0, 63}, Changes: tt.changes}
+			if got := r.GetOutput(); got != tt.want {
+				t.Errorf("output\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
@@ -3,27 +3,41 @@ package display
 import (
 	"github.com/spf13/cobra"
 	"github.com/tsinghua-cel/strategy-gen/actionset"
+	"github.com/tsinghua-cel/strategy-gen/catalogue"
 	"github.com/tsinghua-cel/strategy-gen/command"
 	"github.com/tsinghua-cel/strategy-gen/pointset"
 )
 
 func GetCommand() *cobra.Command {
-	return &cobra.Command{
+	displayCmd := &cobra.Command{
 		Use:   "display",
 		Short: "Display all action and action-point information",
 		Args:  cobra.NoArgs,
 		Run:   runCommand,
 	}
+	setFlags(displayCmd)
+	return displayCmd
+}
+
+func setFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(
+		&params.attacker,
+		attackerFlag,
+		"",
+		"the attacker service to get the action catalogue from, the embedded catalogue is used if empty",
+	)
 }
 
 func runCommand(cmd *cobra.Command, _ []string) {
 	outputter := command.InitializeOutputter(cmd)
 	defer outputter.WriteOutput()
 
+	catalogue.Use(catalogue.Load(params.attacker))
+
 	outputter.SetCommandResult(DisplayResult{
 		ActionPointResult: ActionPointResult{
-			BlockPoints: pointset.BlockPointSet,
-			AttPoints:   pointset.AttestPointSet,
+			BlockPoints: pointset.BlockPointSet(),
+			AttPoints:   pointset.AttestPointSet(),
 		},
 		ActionResult: ActionResult{
 			BlockActionList: actionset.GetBlockActionNameList(),
@@ -0,0 +1,13 @@
+package display
+
+const (
+	attackerFlag = "attacker"
+)
+
+type displayParams struct {
+	attacker string
+}
+
+var (
+	params = &displayParams{}
+)
@@ -4,12 +4,17 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/tsinghua-cel/strategy-gen/actionset"
+	"github.com/tsinghua-cel/strategy-gen/catalogue"
+	"github.com/tsinghua-cel/strategy-gen/inspect"
 	"github.com/tsinghua-cel/strategy-gen/pointset"
+	"github.com/tsinghua-cel/strategy-gen/selector"
 	"github.com/tsinghua-cel/strategy-gen/types"
+	"github.com/tsinghua-cel/strategy-gen/utils"
 	"gopkg.in/yaml.v3"
 	"log"
 	"math/rand"
 	"os"
+	"strconv"
 	"strings"
 )
 
@@ -23,6 +28,18 @@ type Config struct {
 	EnableAttPoints    string                            `json:"enable_att_points" yaml:"enable_att_points"`
 	EnableAttActions   string                            `json:"enable_att_actions" yaml:"enable_att_actions"`
 	ActionsConfig      map[string]actionset.ActionConfig `json:"actions_config" yaml:"actions_config"`
+	// Layout is slots to generate one slot strategy per slot from StartSlot
+	// to EndSlot, or rules to generate a few layered slot rules on them.
+	Layout        string `json:"layout" yaml:"layout"`
+	Periods       string `json:"periods" yaml:"periods"`
+	SlotFunctions string `json:"slot_functions" yaml:"slot_functions"`
+	// Validators is the selector of the attacker validators, see
+	// selector.Selector, the first ValidatorCount validators if empty.
+	Validators string `json:"validators" yaml:"validators"`
+	// ValidatorSetSize is the number of validators of the chain the random
+	// samples are drawn from when the set is not given by the attacker service.
+	ValidatorSetSize int   `json:"validator_set_size" yaml:"validator_set_size"`
+	ValidatorSeed    int64 `json:"validator_seed" yaml:"validator_seed"`
 }
 
 const (
@@ -33,12 +50,19 @@ const (
 	DefaultEnableAttPoints    = "AttestBeforeBroadCast"
 	DefaultEnableBlockActions = "null,delayWithSecond,delayToAfterNextSlot,delayToNextNEpochStart,delayToNextNEpochHalf,delayToEpochEnd,return"
 	DefaultEnableAttActions   = "null,delayWithSecond,delayToAfterNextSlot,return"
+	DefaultLayout             = LayoutSlots
+	DefaultPeriods            = "4,8"
+	DefaultSlotFunctions      = "attackerSlot,lastAttackerSlotInCurrentEpoch"
 )
 
-// DefaultConfig returns the default server configuration
-func DefaultConfig() *Config {
-	defaultConfigInit()
+const (
+	LayoutRules = "rules"
+	LayoutSlots = "slots"
+)
 
+// DefaultConfig returns the default server configuration, the actions take
+// the parameter range of the catalogue unless they are in ActionsConfig.
+func DefaultConfig() *Config {
 	conf := &Config{
 		ValidatorCount:     DefaultValidatorCount,
 		StartSlot:          DefaultStartSlot,
@@ -48,11 +72,10 @@ func DefaultConfig() *Config {
 		EnableAttPoints:    DefaultEnableAttPoints,
 		EnableAttActions:   DefaultEnableAttActions,
 		ActionsConfig:      make(map[string]actionset.ActionConfig),
+		Layout:             DefaultLayout,
+		Periods:            DefaultPeriods,
+		SlotFunctions:      DefaultSlotFunctions,
 	}
-	for _, action := range defaultActionConfigs {
-		conf.ActionsConfig[action.Name] = action
-	}
-
 	return conf
 }
 
@@ -85,107 +108,179 @@ func ReadConfigFile(path string) (*Config, error) {
 	return config, nil
 }
 
-func ConfigToStrategy(mode int, conf Config) types.Strategy {
+func ConfigToStrategy(mode int, conf Config, set selector.Set) (types.Strategy, error) {
 	strategy := types.Strategy{}
-	valEndIndex := conf.ValidatorCount - 1
-	validators := types.GetValidatorStrategy(0, valEndIndex, conf.StartSlot, conf.EndSlot)
-	attActions := make([]actionset.Action, 0)
-
-	enableAttPoints := make([]string, 0)
-	{
-		points := strings.Split(conf.EnableAttPoints, ",")
-		for _, point := range points {
-			p := pointset.GetPointByName(point)
-			if p != "" {
-				enableAttPoints = append(enableAttPoints, p)
+	if conf.StartSlot < 0 || conf.StartSlot > conf.EndSlot {
+		return strategy, fmt.Errorf("slots %d to %d are invalid", conf.StartSlot, conf.EndSlot)
+	}
+	validators, err := conf.AttackerValidators(set)
+	if err != nil {
+		return strategy, err
+	}
+	enableAttPoints := EnabledPoints(conf.EnableAttPoints)
+	enableBlockPoints := EnabledPoints(conf.EnableBlockPoints)
+	attActions := conf.EnabledActions(conf.EnableAttActions, actionset.AttestAction)
+	blockActions := conf.EnabledActions(conf.EnableBlockActions, actionset.BlockAction)
+
+	newSlotStrategy := func(slot string, level int) types.SlotStrategy {
+		strate := types.SlotStrategy{Slot: slot, Level: level, Actions: make(map[string]string)}
+		for _, point := range enableBlockPoints {
+			if action := randomAction(actionset.ValidActions(blockActions, point)); action != nil {
+				strate.Actions[point] = actionString(mode, action)
 			}
 		}
-	}
-	enableBlockPoints := make([]string, 0)
-	{
-		points := strings.Split(conf.EnableBlockPoints, ",")
-		for _, point := range points {
-			p := pointset.GetPointByName(point)
-			if p != "" {
-				enableBlockPoints = append(enableBlockPoints, p)
+		for _, point := range enableAttPoints {
+			if action := randomAction(actionset.ValidActions(attActions, point)); action != nil {
+				strate.Actions[point] = actionString(mode, action)
 			}
 		}
+		return strate
 	}
 
-	enables := strings.Split(conf.EnableAttActions, ",")
-	for _, enable := range enables {
-		actionConfig, exist := conf.ActionsConfig[enable]
-		if !exist {
-			log.Printf("action %s not exist in config\n", enable)
+	slotStrategy := make([]types.SlotStrategy, 0)
+	switch conf.Layout {
+	case LayoutSlots:
+		for slot := conf.StartSlot; slot <= conf.EndSlot; slot++ {
+			slotStrategy = append(slotStrategy, newSlotStrategy(fmt.Sprintf("%d", slot), 0))
+		}
+	case LayoutRules:
+		for level, slot := range conf.SlotRules() {
+			slotStrategy = append(slotStrategy, newSlotStrategy(slot, level))
+		}
+	default:
+		return strategy, fmt.Errorf("layout %s is invalid", conf.Layout)
+	}
+	strategy.Slots = slotStrategy
+	strategy.Validators = validators
+
+	return strategy, nil
+}
+
+// AttackerValidators resolves the validator selector in the set, the set of
+// ValidatorSetSize validators with the same stake is used if it is empty.
+func (conf Config) AttackerValidators(set selector.Set) ([]types.ValidatorStrategy, error) {
+	expr := conf.Validators
+	if expr == "" {
+		if conf.ValidatorCount <= 0 {
+			return nil, fmt.Errorf("validator count %d is invalid", conf.ValidatorCount)
+		}
+		expr = fmt.Sprintf("0-%d", conf.ValidatorCount-1)
+	}
+	sel, err := selector.Parse(expr)
+	if err != nil {
+		return nil, err
+	}
+	if len(set) == 0 && conf.ValidatorSetSize > 0 {
+		set = selector.EqualSet(conf.ValidatorSetSize)
+	}
+	return sel.Resolve(set, conf.ValidatorSeed, conf.StartSlot, conf.EndSlot)
+}
+
+// ValidatorSet gets the validators and their stake from the attacker service.
+func ValidatorSet(attacker string) (selector.Set, error) {
+	if attacker == "" {
+		return nil, nil
+	}
+	validators, err := utils.GetValidators(attacker)
+	if err != nil {
+		return nil, err
+	}
+	set := make(selector.Set, 0, len(validators))
+	for _, v := range validators {
+		set = append(set, selector.Validator{Index: v.Index, Pubkey: v.Pubkey, Stake: v.EffectiveBalance})
+	}
+	return set, nil
+}
+
+// SlotRules returns the slots of the rules layout from the lowest level to
+// the highest: every slot, the periodic patterns with a random offset and
+// the function slots, a later rule overrides the earlier ones on its slots.
+// The periodic rules only match the slots from StartSlot to EndSlot, and the
+// function slots of the attacker duties are bounded by the validator slots,
+// the other function slots can not be bounded and are skipped.
+func (conf Config) SlotRules() []string {
+	rules := []string{fmt.Sprintf("period:1:0:%d:%d", conf.StartSlot, conf.EndSlot)}
+	for _, period := range strings.Split(conf.Periods, ",") {
+		if period == "" {
 			continue
 		}
-		action := actionset.GetActionByName(enable).WithConfig(actionConfig)
-		if action.ActionType() != actionset.AttestAction && action.ActionType() != actionset.AnyAction {
-			log.Printf("action %s is not block action\n", enable)
+		n, err := strconv.Atoi(period)
+		if err != nil || n <= 1 {
+			log.Printf("period %s is invalid\n", period)
 			continue
 		}
-		attActions = append(attActions, action)
+		rules = append(rules, fmt.Sprintf("period:%d:%d:%d:%d", n, rand.Intn(n), conf.StartSlot, conf.EndSlot))
 	}
-
-	blockActions := make([]actionset.Action, 0)
-	enables = strings.Split(conf.EnableBlockActions, ",")
-	for _, enable := range enables {
-		actionConfig, exist := conf.ActionsConfig[enable]
-		if !exist {
-			log.Printf("block action %s not exist in config\n", enable)
+	for _, name := range strings.Split(conf.SlotFunctions, ",") {
+		if name == "" || name == "every" {
 			continue
 		}
-		action := actionset.GetActionByName(enable).WithConfig(actionConfig)
-		if action.ActionType() != actionset.BlockAction && action.ActionType() != actionset.AnyAction {
-			log.Printf("action %s is not block action\n", enable)
+		if !catalogue.Current().KnownSlotFunction(name) {
+			log.Printf("slot function %s not exist\n", name)
 			continue
 		}
-		blockActions = append(blockActions, action)
+		if !inspect.DependsOnDuties(name) {
+			log.Printf("slot function %s is not bounded by the slots\n", name)
+			continue
+		}
+		rules = append(rules, name)
 	}
+	return rules
+}
 
-	slotStrategy := make([]types.SlotStrategy, 0)
-	for slot := conf.StartSlot; slot <= conf.EndSlot; slot++ {
-		strate := types.SlotStrategy{Actions: make(map[string]string)}
-		strate.Slot = fmt.Sprintf("%d", slot)
-		strate.Level = 0
-		for _, point := range enableBlockPoints {
-			action := randomAction(blockActions)
-			astr := action.Name()
-			if action.GetConfig().ParamCount > 0 {
-				param := []interface{}{}
-				if mode == 1 {
-					param = action.RandomParam()
-				} else {
-					param = action.DefaultParam()
-				}
-				for _, p := range param {
-					astr = fmt.Sprintf("%s:%v", astr, p)
-				}
-			}
-			strate.Actions[point] = astr
+func actionString(mode int, action actionset.Action) string {
+	astr := action.Name()
+	if action.GetConfig().ParamCount > 0 {
+		param := []interface{}{}
+		if mode == 1 {
+			param = action.RandomParam()
+		} else {
+			param = action.DefaultParam()
 		}
-		for _, point := range enableAttPoints {
-			action := randomAction(attActions)
-			astr := action.Name()
-			if action.GetConfig().ParamCount > 0 {
-				param := []interface{}{}
-				if mode == 1 {
-					param = action.RandomParam()
-				} else {
-					param = action.DefaultParam()
-				}
-				for _, p := range param {
-					astr = fmt.Sprintf("%s:%v", astr, p)
-				}
-			}
-			strate.Actions[point] = astr
+		for _, p := range param {
+			astr = fmt.Sprintf("%s:%v", astr, p)
 		}
-		slotStrategy = append(slotStrategy, strate)
 	}
-	strategy.Slots = slotStrategy
-	strategy.Validators = validators
+	return astr
+}
+
+// EnabledPoints returns the known action points of the comma separated list.
+func EnabledPoints(list string) []string {
+	enabled := make([]string, 0)
+	for _, point := range strings.Split(list, ",") {
+		p := pointset.GetPointByName(point)
+		if p != "" {
+			enabled = append(enabled, p)
+		}
+	}
+	return enabled
+}
 
-	return strategy
+// EnabledActions returns the configured actions of the comma separated list
+// that can be used at the points of the action type.
+func (conf Config) EnabledActions(list string, actionType actionset.AType) []actionset.Action {
+	kind := "block"
+	if actionType == actionset.AttestAction {
+		kind = "attest"
+	}
+	actions := make([]actionset.Action, 0)
+	for _, enable := range strings.Split(list, ",") {
+		action := actionset.GetActionByName(enable)
+		if action == nil {
+			log.Printf("%s action %s not exist\n", kind, enable)
+			continue
+		}
+		// the actions without config use the parameter schema of the catalogue.
+		if actionConfig, exist := conf.ActionsConfig[enable]; exist {
+			action = action.WithConfig(actionConfig)
+		}
+		if action.ActionType() != actionType && action.ActionType() != actionset.AnyAction {
+			log.Printf("action %s is not %s action\n", enable, kind)
+			continue
+		}
+		actions = append(actions, action)
+	}
+	return actions
 }
 
 func randomAction(actions []actionset.Action) actionset.Action {
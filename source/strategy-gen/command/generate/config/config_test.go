@@ -0,0 +1,109 @@
+package config
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/tsinghua-cel/strategy-gen/inspect"
+	"github.com/tsinghua-cel/strategy-gen/types"
+)
+
+func testConfig(layout string) Config {
+	conf := *DefaultConfig()
+	conf.ValidatorCount = 2
+	conf.StartSlot = 8
+	conf.EndSlot = 23
+	conf.Layout = layout
+	return conf
+}
+
+// checkSlots checks every rule of the strategy is valid, and a rule matches
+// the slots from 8 to 23 but no other slot, when validator 0 proposes all of them.
+func checkSlots(t *testing.T, strategy types.Strategy) {
+	t.Helper()
+	for _, v := range strategy.Validators {
+		if v.AttackerStartSlot != 8 || v.AttackerEndSlot != 23 {
+			t.Errorf("validator %d is an attacker from slot %d to %d", v.ValidatorIndex, v.AttackerStartSlot, v.AttackerEndSlot)
+		}
+	}
+	for _, s := range strategy.Slots {
+		if err := inspect.CheckSlot(s.Slot); err != nil {
+			t.Error(err)
+		}
+		if len(s.Actions) == 0 {
+			t.Errorf("slot %s has no action", s.Slot)
+		}
+	}
+	slots := inspect.NewSlots(strategy, 4, func(int64) (int, bool) { return 0, true })
+	for slot := int64(0); slot < 40; slot++ {
+		_, ok := slots.Resolve(slot)
+		if want := slot >= 8 && slot <= 23; ok != want {
+			t.Errorf("slot %d is matched %v, want %v", slot, ok, want)
+		}
+	}
+}
+
+func TestConfigToStrategySlots(t *testing.T) {
+	strategy, err := ConfigToStrategy(0, testConfig(LayoutSlots), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(strategy.Slots) != 16 || len(strategy.Validators) != 2 {
+		t.Fatalf("got %d slots and %d validators, want 16 and 2", len(strategy.Slots), len(strategy.Validators))
+	}
+	for i, s := range strategy.Slots {
+		if s.Slot != strconv.Itoa(8+i) || s.Level != 0 {
+			t.Errorf("slots[%d] is slot %s level %d", i, s.Slot, s.Level)
+		}
+	}
+	checkSlots(t, strategy)
+}
+
+func TestConfigToStrategyRules(t *testing.T) {
+	conf := testConfig(LayoutRules)
+	conf.Periods = "4,x,1,8"
+	conf.SlotFunctions = "attackerSlot,every,firstSlotInCurrentEpoch,noSuchSlot,lastAttackerSlotInCurrentEpoch"
+	strategy, err := ConfigToStrategy(1, conf, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"period:1:0:8:23", "period:4:", "period:8:", "attackerSlot", "lastAttackerSlotInCurrentEpoch"}
+	if len(strategy.Slots) != len(want) {
+		t.Fatalf("got %d rules, want %d", len(strategy.Slots), len(want))
+	}
+	for i, s := range strategy.Slots {
+		if !strings.HasPrefix(s.Slot, want[i]) || s.Level != i {
+			t.Errorf("rules[%d] is slot %s level %d, want %s level %d", i, s.Slot, s.Level, want[i], i)
+		}
+		if strings.HasPrefix(s.Slot, "period:") && !strings.HasSuffix(s.Slot, ":8:23") {
+			t.Errorf("periodic rule %s is not bounded by the slots", s.Slot)
+		}
+	}
+	checkSlots(t, strategy)
+}
+
+func TestConfigToStrategyInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		conf func(*Config)
+	}{
+		{name: "unknown layout", conf: func(c *Config) { c.Layout = "slot" }},
+		{name: "negative start slot", conf: func(c *Config) { c.StartSlot = -1 }},
+		{name: "end before start", conf: func(c *Config) { c.EndSlot = 7 }},
+		{name: "no validator", conf: func(c *Config) { c.ValidatorCount = 0 }},
+	}
+	for _, tt := range tests {
+		conf := testConfig(LayoutRules)
+		tt.conf(&conf)
+		if _, err := ConfigToStrategy(0, conf, nil); err == nil {
+			t.Errorf("%s: generated the invalid config", tt.name)
+		}
+	}
+}
+
+func TestDefaultLayout(t *testing.T) {
+	if DefaultConfig().Layout != LayoutSlots {
+		t.Errorf("default layout %s, want %s", DefaultConfig().Layout, LayoutSlots)
+	}
+}
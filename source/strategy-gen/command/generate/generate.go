@@ -2,6 +2,7 @@ package generate
 
 import (
 	"github.com/spf13/cobra"
+	"github.com/tsinghua-cel/strategy-gen/catalogue"
 	"github.com/tsinghua-cel/strategy-gen/command"
 	"github.com/tsinghua-cel/strategy-gen/command/generate/config"
 	"github.com/tsinghua-cel/strategy-gen/command/generate/export"
@@ -43,11 +44,39 @@ func setFlags(cmd *cobra.Command) {
 		"the mode to generate strategy param, 0: default, 1:random",
 	)
 
+	cmd.Flags().StringVar(
+		&params.attacker,
+		attackerFlag,
+		"",
+		"the attacker service to get the action catalogue and the validator set from, the embedded catalogue is used if empty",
+	)
+
 	cmd.Flags().IntVar(
 		&params.rawConfig.ValidatorCount,
 		validatorCountFlag,
 		defaultConfig.ValidatorCount,
-		"the number of validators",
+		"the number of attacker validators 0..n-1, used if the validator selector is empty",
+	)
+
+	cmd.Flags().StringVar(
+		&params.rawConfig.Validators,
+		validatorsFlag,
+		defaultConfig.Validators,
+		"the attacker validator selector, subsets split with ';' of indexes n, ranges n-m, random:n, random:p% of stake and pubkeys:file, with an optional slot window @start..end",
+	)
+
+	cmd.Flags().IntVar(
+		&params.rawConfig.ValidatorSetSize,
+		validatorSetSizeFlag,
+		defaultConfig.ValidatorSetSize,
+		"the size of the validator set to sample from without the attacker service",
+	)
+
+	cmd.Flags().Int64Var(
+		&params.rawConfig.ValidatorSeed,
+		validatorSeedFlag,
+		defaultConfig.ValidatorSeed,
+		"the seed of the random validator samples",
 	)
 
 	cmd.Flags().IntVar(
@@ -94,6 +123,27 @@ func setFlags(cmd *cobra.Command) {
 		defaultConfig.EnableBlockActions,
 		"the enabled block actions, split with comma",
 	)
+
+	cmd.Flags().StringVar(
+		&params.rawConfig.Layout,
+		layoutFlag,
+		defaultConfig.Layout,
+		"the layout of the strategy, slots: one strategy per slot from start-slot to end-slot, rules: a few layered slot rules on them",
+	)
+
+	cmd.Flags().StringVar(
+		&params.rawConfig.Periods,
+		periodsFlag,
+		defaultConfig.Periods,
+		"the periods of the periodic slot rules, split with comma",
+	)
+
+	cmd.Flags().StringVar(
+		&params.rawConfig.SlotFunctions,
+		slotFunctionsFlag,
+		defaultConfig.SlotFunctions,
+		"the function slots of the slot rules from low to high level, split with comma",
+	)
 }
 
 func registerSubcommands(baseCmd *cobra.Command) {
@@ -132,7 +182,15 @@ func runCommand(cmd *cobra.Command, _ []string) {
 }
 
 func runGenerate(conf *config.Config) error {
+	catalogue.Use(catalogue.Load(params.attacker))
 	outputname := params.outputFile
-	strategy := config.ConfigToStrategy(params.generateMode, *conf)
+	set, err := config.ValidatorSet(params.attacker)
+	if err != nil {
+		return err
+	}
+	strategy, err := config.ConfigToStrategy(params.generateMode, *conf, set)
+	if err != nil {
+		return err
+	}
 	return strategy.ToFile(outputname)
 }
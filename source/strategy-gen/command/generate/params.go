@@ -8,12 +8,19 @@ import (
 const (
 	configFlag            = "config"
 	validatorCountFlag    = "validator-count"
+	validatorsFlag        = "validators"
+	validatorSetSizeFlag  = "validator-set-size"
+	validatorSeedFlag     = "validator-seed"
 	startSlotFlag         = "start-slot"
 	endSlotFlag           = "end-slot"
 	enableAttFlag         = "enable-att-points"
 	enableBlockFlag       = "enable-block-points"
 	enableAttActionFlag   = "enable-att-actions"
 	enableBlockActionFlag = "enable-block-actions"
+	layoutFlag            = "layout"
+	periodsFlag           = "periods"
+	slotFunctionsFlag     = "slot-functions"
+	attackerFlag          = "attacker"
 )
 
 var (
@@ -24,6 +31,7 @@ type toolParam struct {
 	configPath   string
 	outputFile   string
 	generateMode int
+	attacker     string
 	rawConfig    *config.Config
 }
 
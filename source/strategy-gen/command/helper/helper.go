@@ -26,6 +26,15 @@ func FormatKV(in []string) string {
 	return columnize.Format(in, columnConf)
 }
 
+// FormatList formats the rows of a table, the cells are separated by |
+// and a blank cell is shown as <none>.
+func FormatList(in []string) string {
+	columnConf := columnize.DefaultConfig()
+	columnConf.Empty = "<none>"
+
+	return columnize.Format(in, columnConf)
+}
+
 // Creates a file at path and with perms level permissions.
 // If file already exists, owner and permissions are
 // verified, and the file is overwritten.
@@ -112,3 +121,18 @@ func RegisterJSONOutputFlag(cmd *cobra.Command) {
 		"get all outputs in json format (default false)",
 	)
 }
+
+// RegisterAuthFlags registers the credentials of the attacker service for all child commands
+func RegisterAuthFlags(cmd *cobra.Command) {
+	cmd.PersistentFlags().String(
+		command.ApiKeyFlag,
+		"",
+		"the api key sent to the attacker service in the X-API-Key header",
+	)
+
+	cmd.PersistentFlags().String(
+		command.JwtSecretFlag,
+		"",
+		"the jwt secret file of the attacker service, a bearer token signed by it is sent with every request",
+	)
+}
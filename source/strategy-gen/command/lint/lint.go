@@ -0,0 +1,86 @@
+package lint
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/tsinghua-cel/strategy-gen/catalogue"
+	"github.com/tsinghua-cel/strategy-gen/command"
+	"github.com/tsinghua-cel/strategy-gen/inspect"
+	"github.com/tsinghua-cel/strategy-gen/types"
+	"github.com/tsinghua-cel/strategy-gen/utils"
+)
+
+func GetCommand() *cobra.Command {
+	lintCmd := &cobra.Command{
+		Use:   "lint [strategy.json]",
+		Short: "Check a strategy for unknown actions, bad parameters, unreachable rules and validators outside the set",
+		Args:  cobra.MaximumNArgs(1),
+		Run:   runCommand,
+	}
+	setFlags(lintCmd)
+	return lintCmd
+}
+
+func setFlags(cmd *cobra.Command) {
+	cmd.Flags().IntVar(
+		&params.validators,
+		validatorsFlag,
+		0,
+		"the size of the validator set, the validator indexes are not checked if 0",
+	)
+
+	cmd.Flags().Int64Var(
+		&params.slotsPerEpoch,
+		slotsPerEpochFlag,
+		32,
+		"the slots per epoch, the chain spec of the attacker service is used if it is set",
+	)
+
+	cmd.Flags().StringVar(
+		&params.attacker,
+		attackerFlag,
+		"",
+		"the attacker service to get the action catalogue and chain spec from, the embedded catalogue is used if empty",
+	)
+}
+
+func runCommand(cmd *cobra.Command, args []string) {
+	outputter := command.InitializeOutputter(cmd)
+	defer outputter.WriteOutput()
+
+	file := "strategy.json"
+	if len(args) > 0 {
+		file = args[0]
+	}
+	strategy, err := types.StrategyFromFile(file)
+	if err != nil {
+		outputter.SetError(err)
+		return
+	}
+
+	c := catalogue.Load(params.attacker)
+	if params.attacker != "" {
+		spec, err := utils.GetChainSpec(params.attacker)
+		if err != nil {
+			outputter.SetError(err)
+			return
+		}
+		params.slotsPerEpoch = int64(spec.SlotsPerEpoch)
+	}
+	catalogue.Use(c)
+
+	result := &lintResult{
+		File: file,
+		Issues: inspect.Lint(strategy, c, inspect.LintOptions{
+			Validators:    params.validators,
+			SlotsPerEpoch: params.slotsPerEpoch,
+		}),
+	}
+	if n := result.errors(); n > 0 {
+		outputter.WriteCommandResult(result)
+		outputter.SetError(fmt.Errorf("lint of %s failed with %d errors", file, n))
+		return
+	}
+	outputter.SetCommandResult(result)
+}
@@ -0,0 +1,17 @@
+package lint
+
+const (
+	validatorsFlag    = "validators"
+	slotsPerEpochFlag = "slots-per-epoch"
+	attackerFlag      = "attacker"
+)
+
+type lintParams struct {
+	validators    int
+	slotsPerEpoch int64
+	attacker      string
+}
+
+var (
+	params = &lintParams{}
+)
@@ -0,0 +1,42 @@
+package lint
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/tsinghua-cel/strategy-gen/command/helper"
+	"github.com/tsinghua-cel/strategy-gen/inspect"
+)
+
+type lintResult struct {
+	File   string          `json:"file"`
+	Issues []inspect.Issue `json:"issues"`
+}
+
+func (r *lintResult) errors() int {
+	n := 0
+	for _, issue := range r.Issues {
+		if issue.Severity == inspect.SeverityError {
+			n++
+		}
+	}
+	return n
+}
+
+func (r *lintResult) GetOutput() string {
+	var buffer bytes.Buffer
+
+	buffer.WriteString(fmt.Sprintf("\n[LINT %s]\n", r.File))
+	if len(r.Issues) == 0 {
+		buffer.WriteString("no issues\n")
+		return buffer.String()
+	}
+	rows := make([]string, 0, len(r.Issues))
+	for _, issue := range r.Issues {
+		rows = append(rows, fmt.Sprintf("%s %s|%s", issue.Severity, issue.Where, issue.Message))
+	}
+	buffer.WriteString(helper.FormatKV(rows))
+	buffer.WriteString("\n")
+
+	return buffer.String()
+}
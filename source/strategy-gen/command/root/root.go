@@ -3,12 +3,19 @@ package root
 import (
 	"fmt"
 	"github.com/spf13/cobra"
+	"github.com/tsinghua-cel/strategy-gen/command"
+	"github.com/tsinghua-cel/strategy-gen/command/diff"
 	"github.com/tsinghua-cel/strategy-gen/command/display"
 	"github.com/tsinghua-cel/strategy-gen/command/generate"
 	"github.com/tsinghua-cel/strategy-gen/command/helper"
+	"github.com/tsinghua-cel/strategy-gen/command/lint"
 	"github.com/tsinghua-cel/strategy-gen/command/runtime"
+	"github.com/tsinghua-cel/strategy-gen/command/search"
+	"github.com/tsinghua-cel/strategy-gen/command/simulate"
+	"github.com/tsinghua-cel/strategy-gen/command/timeline"
 	"github.com/tsinghua-cel/strategy-gen/command/update"
 	"github.com/tsinghua-cel/strategy-gen/command/version"
+	"github.com/tsinghua-cel/strategy-gen/utils"
 	"os"
 )
 
@@ -19,11 +26,13 @@ type RootCommand struct {
 func NewRootCommand() *RootCommand {
 	rootCommand := &RootCommand{
 		baseCmd: &cobra.Command{
-			Short: "Strategy-gen is a tool to generate strategy file for attacker service",
+			Short:             "Strategy-gen is a tool to generate strategy file for attacker service",
+			PersistentPreRunE: setAuth,
 		},
 	}
 
 	helper.RegisterJSONOutputFlag(rootCommand.baseCmd)
+	helper.RegisterAuthFlags(rootCommand.baseCmd)
 	rootCommand.baseCmd.CompletionOptions.HiddenDefaultCmd = true
 	rootCommand.registerSubCommands()
 
@@ -35,11 +44,23 @@ func (rc *RootCommand) registerSubCommands() {
 		runtime.GetCommand(),
 		generate.GetCommand(),
 		update.GetCommand(),
+		search.GetCommand(),
+		simulate.GetCommand(),
 		display.GetCommand(),
+		lint.GetCommand(),
+		diff.GetCommand(),
+		timeline.GetCommand(),
 		version.GetCommand(),
 	)
 }
 
+// setAuth sets the credentials of the attacker service for the commands calling it.
+func setAuth(cmd *cobra.Command, _ []string) error {
+	apiKey, _ := cmd.Flags().GetString(command.ApiKeyFlag)
+	jwtSecret, _ := cmd.Flags().GetString(command.JwtSecretFlag)
+	return utils.SetAuth(apiKey, jwtSecret)
+}
+
 func (rc *RootCommand) Execute() {
 	if err := rc.baseCmd.Execute(); err != nil {
 		_, _ = fmt.Fprintln(os.Stderr, err)
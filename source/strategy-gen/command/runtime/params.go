@@ -1,13 +1,21 @@
 package runtime
 
+import "time"
+
 const (
 	attackerFlag          = "attacker"
 	maxValidatorIndexFlag = "max-validator-index"
+	conditionalFlag       = "conditional"
+	templateFlag          = "template"
+	leadTimeFlag          = "lead-time"
 )
 
 type updateParam struct {
 	attacker          string
 	maxValidatorIndex int
+	conditional       bool
+	template          string
+	leadTime          time.Duration
 }
 
 var (
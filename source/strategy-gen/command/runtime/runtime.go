@@ -1,15 +1,12 @@
 package runtime
 
 import (
-	"bytes"
-	"encoding/json"
 	"fmt"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/tsinghua-cel/strategy-gen/library"
-	"github.com/tsinghua-cel/strategy-gen/types"
 	"github.com/tsinghua-cel/strategy-gen/utils"
-	"net/http"
+	"strings"
 	"time"
 )
 
@@ -42,67 +39,111 @@ func setFlags(cmd *cobra.Command) {
 		"127.0.0.1:12001",
 		"the attacker service to update",
 	)
+
+	cmd.Flags().BoolVar(
+		&params.conditional,
+		conditionalFlag,
+		false,
+		"update a conditional strategy once and let the attacker service check the duties every epoch",
+	)
+
+	cmd.Flags().StringVar(
+		&params.template,
+		templateFlag,
+		"selfish",
+		fmt.Sprintf("the attack template, one of %s", strings.Join(library.TemplateNames(), "|")),
+	)
+
+	cmd.Flags().DurationVar(
+		&params.leadTime,
+		leadTimeFlag,
+		4*time.Second,
+		"update the strategy of an epoch this long before the epoch starts",
+	)
 }
 
 func runCommand(cmd *cobra.Command, _ []string) {
-	var latestEpoch int64
-	ticker := time.NewTicker(time.Second * 3)
-	slotTool := utils.SlotTool{SlotsPerEpoch: 32}
-	for {
-		select {
-		case <-ticker.C:
-			slot, err := utils.GetSlot(params.attacker)
-			if err != nil {
-				log.WithField("error", err).Error("failed to get slot")
-				continue
-			}
-			epoch := slotTool.SlotToEpoch(int64(slot))
-			if epoch == latestEpoch {
-				continue
-			}
-			if int64(slot) < slotTool.EpochEnd(epoch) {
-				continue
-			}
-			latestEpoch = epoch
-			// get next epoch duties
-			duties, err := utils.GetEpochDuties(params.attacker, epoch+1)
-			if err != nil {
-				log.WithFields(log.Fields{
-					"error": err,
-					"epoch": epoch + 1,
-				}).Error("failed to get duties")
-				latestEpoch = epoch - 1
-				continue
+	if params.conditional {
+		strategy := library.ConditionalStrategy(params.maxValidatorIndex, 3)
+		if err := utils.UpdateStrategy(params.attacker, strategy); err != nil {
+			log.WithField("error", err).Error("failed to update strategy")
+		} else {
+			log.Info("update conditional strategy successfully")
+		}
+		return
+	}
+	template, ok := library.GetTemplate(params.template)
+	if !ok {
+		log.WithField("template", params.template).Errorf("unknown template, use one of %s", strings.Join(library.TemplateNames(), "|"))
+		return
+	}
+	spec := waitChainSpec(params.attacker)
+	templateParams := library.Params{
+		MaxValidatorIndex: params.maxValidatorIndex,
+		SlotsPerEpoch:     spec.SlotsPerEpoch,
+		SecondsPerSlot:    spec.SecondsPerSlot,
+	}
+	slotTool := utils.SlotTool{SlotsPerEpoch: spec.SlotsPerEpoch}
+	lead := params.leadTime
+	if epochDuration := time.Duration(spec.SlotsPerEpoch) * spec.SlotDuration(); lead > epochDuration {
+		lead = epochDuration
+	}
+	log.WithFields(log.Fields{
+		"slotsPerEpoch":  spec.SlotsPerEpoch,
+		"secondsPerSlot": spec.SecondsPerSlot,
+		"leadTime":       lead,
+	}).Info("runtime started")
+
+	for epoch := slotTool.SlotToEpoch(spec.CurrentSlot()) + 1; ; epoch++ {
+		start := spec.SlotStartTime(slotTool.EpochStart(epoch))
+		time.Sleep(time.Until(start.Add(-lead)))
+		// the duties are retried until the epoch starts.
+		for {
+			err := updateEpochStrategy(template, templateParams, epoch)
+			if err == nil {
+				break
 			}
-			if hackDuties, happen := library.CheckDuties(params.maxValidatorIndex, duties); happen {
-				strategy := types.Strategy{}
-				strategy.Validators = library.ValidatorStrategy(hackDuties)
-				strategy.Slots = library.GenSlotStrategy(hackDuties)
-				if err = updateStrategy(params.attacker, strategy); err != nil {
-					log.WithField("error", err).Error("failed to update strategy")
-				} else {
-					log.WithFields(log.Fields{
-						"epoch":    epoch + 1,
-						"strategy": strategy,
-					}).Info("update strategy successfully")
-				}
+			log.WithFields(log.Fields{
+				"error": err,
+				"epoch": epoch,
+			}).Error("failed to update epoch strategy")
+			if !time.Now().Add(time.Second).Before(start) {
+				break
 			}
+			time.Sleep(time.Second)
 		}
 	}
 }
 
-func updateStrategy(url string, strategy types.Strategy) error {
-	d, err := json.Marshal(strategy)
-	if err != nil {
-		return err
+// waitChainSpec gets the chain timing from the attacker service, it waits
+// for the service to load it from the beacon node.
+func waitChainSpec(url string) utils.ChainSpec {
+	for {
+		spec, err := utils.GetChainSpec(url)
+		if err == nil {
+			return spec
+		}
+		log.WithField("error", err).Error("failed to get chain spec")
+		time.Sleep(time.Second * 3)
 	}
+}
 
-	res, err := http.Post(fmt.Sprintf("http://%s/v1/update-strategy", url), "application/json", bytes.NewReader(d))
+func updateEpochStrategy(template library.Template, p library.Params, epoch int64) error {
+	duties, err := utils.GetEpochDuties(params.attacker, epoch)
 	if err != nil {
 		return err
 	}
-	if res.StatusCode != 200 {
-		return fmt.Errorf("failed to generate strategy: %s", res.Status)
+	strategy, happen := template.Generate(p, duties)
+	if !happen {
+		return nil
+	}
+	if err := utils.UpdateStrategy(params.attacker, strategy); err != nil {
+		return err
 	}
+	log.WithFields(log.Fields{
+		"epoch":    epoch,
+		"template": template.Name(),
+		"strategy": strategy,
+	}).Info("update strategy successfully")
 	return nil
 }
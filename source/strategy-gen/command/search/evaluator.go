@@ -0,0 +1,123 @@
+package search
+
+import (
+	"errors"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/tsinghua-cel/strategy-gen/search"
+	"github.com/tsinghua-cel/strategy-gen/utils"
+)
+
+// rewardDelay is the number of epochs the attacker service waits before it
+// collects the rewards of an epoch.
+const rewardDelay = 2
+
+// liveEvaluator runs a candidate on the attacker service for some epochs and
+// reads back the reorgs and the rewards of these epochs.
+type liveEvaluator struct {
+	attacker  string
+	template  search.Template
+	epochs    int
+	attackers map[int]bool // the indexes of the attacker validators
+}
+
+func (e *liveEvaluator) Evaluate(actions map[string]string) (search.Outcome, error) {
+	var outcome search.Outcome
+	if err := utils.UpdateStrategy(e.attacker, e.template.Strategy(actions)); err != nil {
+		return outcome, err
+	}
+	epoch, err := utils.GetEpoch(e.attacker)
+	if err != nil {
+		return outcome, err
+	}
+	// the current epoch is partly played by the previous candidate.
+	from := int64(epoch) + 1
+	to := from + int64(e.epochs)
+	log.WithFields(log.Fields{
+		"from":    from,
+		"to":      to - 1,
+		"actions": actions,
+	}).Info("run candidate")
+	// the strategy is kept until the rewards of the last epoch are collected.
+	if err := e.waitEpoch(to - 1 + rewardDelay); err != nil {
+		return outcome, err
+	}
+
+	reorgs, err := utils.GetReorgs(e.attacker)
+	if err != nil {
+		return outcome, err
+	}
+	rewards := make([]utils.Reward, 0)
+	for ep := from; ep < to; ep++ {
+		epochRewards, err := e.getRewards(ep)
+		if err != nil {
+			return outcome, err
+		}
+		rewards = append(rewards, epochRewards...)
+	}
+	return e.outcome(reorgs, rewards, from, to), nil
+}
+
+// outcome counts the reorgs of the epochs from to before to, and the reward
+// and stake share of the attacker validators in the rewards of these epochs.
+func (e *liveEvaluator) outcome(reorgs []utils.Reorg, rewards []utils.Reward, from, to int64) search.Outcome {
+	var outcome search.Outcome
+	for _, reorg := range reorgs {
+		if reorg.Epoch >= from && reorg.Epoch < to {
+			outcome.Reorgs++
+		}
+	}
+	var attackerReward, totalReward int64
+	var attackerCount, totalCount int
+	for _, reward := range rewards {
+		amount := reward.HeadAmount + reward.TargetAmount
+		if e.attackers[reward.ValidatorIndex] {
+			attackerReward += amount
+			attackerCount++
+		}
+		totalReward += amount
+		totalCount++
+	}
+	if totalReward > 0 {
+		outcome.AttackerRewardShare = float64(attackerReward) / float64(totalReward)
+	}
+	if totalCount > 0 {
+		outcome.AttackerStakeShare = float64(attackerCount) / float64(totalCount)
+	}
+	return outcome
+}
+
+func (e *liveEvaluator) waitEpoch(target int64) error {
+	ticker := time.NewTicker(time.Second * 3)
+	defer ticker.Stop()
+	failed := 0
+	for range ticker.C {
+		epoch, err := utils.GetEpoch(e.attacker)
+		if err != nil {
+			failed++
+			if failed > 20 {
+				return err
+			}
+			continue
+		}
+		failed = 0
+		if int64(epoch) >= target {
+			return nil
+		}
+	}
+	return nil
+}
+
+// getRewards returns the rewards of the epoch, it waits a while for the
+// attacker service to collect them.
+func (e *liveEvaluator) getRewards(epoch int64) ([]utils.Reward, error) {
+	for i := 0; i < 20; i++ {
+		rewards, err := utils.GetRewards(e.attacker, epoch)
+		if err == nil && len(rewards) > 0 {
+			return rewards, nil
+		}
+		time.Sleep(time.Second * 3)
+	}
+	return nil, errors.New("rewards not collected")
+}
@@ -0,0 +1,87 @@
+package search
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/tsinghua-cel/strategy-gen/command/generate/config"
+	"github.com/tsinghua-cel/strategy-gen/utils"
+)
+
+func TestOutcome(t *testing.T) {
+	reorgs := []utils.Reorg{{Epoch: 4}, {Epoch: 5}, {Epoch: 5}, {Epoch: 7}, {Epoch: 6}}
+	// the attackers 1 and 4 are not the validators up to an index.
+	rewards := []utils.Reward{
+		{Epoch: 5, ValidatorIndex: 1, HeadAmount: 30, TargetAmount: 30},
+		{Epoch: 5, ValidatorIndex: 2, HeadAmount: 10, TargetAmount: 10},
+		{Epoch: 5, ValidatorIndex: 3, HeadAmount: 10, TargetAmount: 10},
+		{Epoch: 6, ValidatorIndex: 4, HeadAmount: 20, TargetAmount: 20},
+	}
+	tests := []struct {
+		name          string
+		attackers     map[int]bool
+		wantReward    float64
+		wantStake     float64
+		wantReorgs    int
+		wantRelative  float64
+		rewardsAbsent bool
+	}{
+		{name: "non contiguous attackers", attackers: map[int]bool{1: true, 4: true}, wantReward: 100.0 / 140, wantStake: 0.5, wantReorgs: 3, wantRelative: 200.0 / 140},
+		{name: "no attacker", attackers: map[int]bool{}, wantReorgs: 3},
+		{name: "attacker without reward", attackers: map[int]bool{9: true}, wantReorgs: 3},
+		{name: "no reward", attackers: map[int]bool{1: true}, wantReorgs: 3, rewardsAbsent: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := &liveEvaluator{attackers: tt.attackers}
+			epochRewards := rewards
+			if tt.rewardsAbsent {
+				epochRewards = nil
+			}
+			o := e.outcome(reorgs, epochRewards, 5, 7)
+			if o.Reorgs != tt.wantReorgs {
+				t.Errorf("reorgs %d, want %d", o.Reorgs, tt.wantReorgs)
+			}
+			if math.Abs(o.AttackerRewardShare-tt.wantReward) > 1e-9 || math.Abs(o.AttackerStakeShare-tt.wantStake) > 1e-9 {
+				t.Errorf("reward share %v stake share %v, want %v %v", o.AttackerRewardShare, o.AttackerStakeShare, tt.wantReward, tt.wantStake)
+			}
+			if math.Abs(o.RelativeRewardShare()-tt.wantRelative) > 1e-9 {
+				t.Errorf("relative reward share %v, want %v", o.RelativeRewardShare(), tt.wantRelative)
+			}
+		})
+	}
+}
+
+func TestAttackerValidators(t *testing.T) {
+	defer func(max int, attacker string) {
+		params.maxValidatorIndex, params.attacker = max, attacker
+	}(params.maxValidatorIndex, params.attacker)
+	params.attacker = ""
+
+	tests := []struct {
+		name     string
+		maxIndex int
+		selector string
+		want     map[int]bool
+	}{
+		{name: "max validator index", maxIndex: 2, selector: "5,7", want: map[int]bool{0: true, 1: true, 2: true}},
+		{name: "selector", maxIndex: -1, selector: "5,7;9-10@0..100", want: map[int]bool{5: true, 7: true, 9: true, 10: true}},
+		{name: "validator count", maxIndex: -1, want: map[int]bool{0: true, 1: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params.maxValidatorIndex = tt.maxIndex
+			conf := config.DefaultConfig()
+			conf.ValidatorCount = 2
+			conf.Validators = tt.selector
+			validators, err := attackerValidators(conf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := attackerIndexes(validators); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("attackers %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package search
+
+import (
+	"github.com/tsinghua-cel/strategy-gen/command/generate/config"
+)
+
+const (
+	configFlag            = "config"
+	attackerFlag          = "attacker"
+	maxValidatorIndexFlag = "max-validator-index"
+	slotFlag              = "slot"
+	modeFlag              = "mode"
+	seedFlag              = "seed"
+	populationFlag        = "population"
+	roundsFlag            = "rounds"
+	epochsFlag            = "epochs"
+	mutationRateFlag      = "mutation-rate"
+	eliteFlag             = "elite"
+	epsilonFlag           = "epsilon"
+	reorgWeightFlag       = "reorg-weight"
+	rewardWeightFlag      = "reward-weight"
+	outputFlag            = "output"
+	historyFlag           = "history"
+)
+
+type searchParam struct {
+	configPath        string
+	attacker          string
+	maxValidatorIndex int
+	slot              string
+	mode              string
+	seed              int64
+	population        int
+	rounds            int
+	epochs            int
+	mutationRate      float64
+	elite             int
+	epsilon           float64
+	reorgWeight       float64
+	rewardWeight      float64
+	outputFile        string
+	historyFile       string
+	rawConfig         *config.Config
+}
+
+var (
+	params = &searchParam{
+		rawConfig: config.DefaultConfig(),
+	}
+)
+
+func (p *searchParam) initConfigFromFile() error {
+	var parseErr error
+
+	if p.rawConfig, parseErr = config.ReadConfigFile(p.configPath); parseErr != nil {
+		return parseErr
+	}
+
+	return nil
+}
@@ -0,0 +1,247 @@
+package search
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/tsinghua-cel/strategy-gen/actionset"
+	"github.com/tsinghua-cel/strategy-gen/catalogue"
+	"github.com/tsinghua-cel/strategy-gen/command/generate/config"
+	"github.com/tsinghua-cel/strategy-gen/search"
+	"github.com/tsinghua-cel/strategy-gen/types"
+)
+
+func GetCommand() *cobra.Command {
+	searchCmd := &cobra.Command{
+		Use:     "search",
+		Short:   "Search the most damaging strategy by running candidates on the attacker service.",
+		PreRunE: runPreRun,
+		Run:     runCommand,
+	}
+	log.SetFormatter(&log.TextFormatter{
+		DisableColors: true,
+		FullTimestamp: true,
+	})
+	setFlags(searchCmd)
+	return searchCmd
+}
+
+func setFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(
+		&params.configPath,
+		configFlag,
+		"",
+		"the generate config with the enabled points and actions. Supports .json and .yml",
+	)
+
+	cmd.Flags().StringVar(
+		&params.attacker,
+		attackerFlag,
+		"127.0.0.1:12001",
+		"the attacker service to update",
+	)
+
+	cmd.Flags().IntVar(
+		&params.maxValidatorIndex,
+		maxValidatorIndexFlag,
+		-1,
+		"the max hack validator index, the validators of the config selector are attackers if it is not set",
+	)
+
+	cmd.Flags().StringVar(
+		&params.slot,
+		slotFlag,
+		"attackerSlot",
+		"the slot the candidate actions apply to, a number or a slot function",
+	)
+
+	cmd.Flags().StringVar(
+		&params.mode,
+		modeFlag,
+		search.ModeGenetic,
+		"the search mode, ga: genetic algorithm, bandit: epsilon-greedy bandit",
+	)
+
+	cmd.Flags().Int64Var(
+		&params.seed,
+		seedFlag,
+		1,
+		"the seed of the candidate proposals",
+	)
+
+	cmd.Flags().IntVar(
+		&params.population,
+		populationFlag,
+		8,
+		"the population size, or the number of arms of the bandit",
+	)
+
+	cmd.Flags().IntVar(
+		&params.rounds,
+		roundsFlag,
+		5,
+		"the number of generations, the bandit runs rounds*population candidates",
+	)
+
+	cmd.Flags().IntVar(
+		&params.epochs,
+		epochsFlag,
+		2,
+		"the number of epochs each candidate runs",
+	)
+
+	cmd.Flags().Float64Var(
+		&params.mutationRate,
+		mutationRateFlag,
+		0.2,
+		"the probability to mutate the action of a point",
+	)
+
+	cmd.Flags().IntVar(
+		&params.elite,
+		eliteFlag,
+		2,
+		"the number of best candidates kept in the next generation",
+	)
+
+	cmd.Flags().Float64Var(
+		&params.epsilon,
+		epsilonFlag,
+		0.1,
+		"the exploration rate of the bandit",
+	)
+
+	cmd.Flags().Float64Var(
+		&params.reorgWeight,
+		reorgWeightFlag,
+		1,
+		"the fitness weight of a reorg",
+	)
+
+	cmd.Flags().Float64Var(
+		&params.rewardWeight,
+		rewardWeightFlag,
+		10,
+		"the fitness weight of the attacker relative reward share",
+	)
+
+	cmd.Flags().StringVar(
+		&params.outputFile,
+		outputFlag,
+		"best-strategy.json",
+		"the output file of the best strategy",
+	)
+
+	cmd.Flags().StringVar(
+		&params.historyFile,
+		historyFlag,
+		"",
+		"append every evaluated candidate to this jsonl file",
+	)
+}
+
+func runPreRun(cmd *cobra.Command, _ []string) error {
+	if cmd.Flags().Changed(configFlag) {
+		if err := params.initConfigFromFile(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func runCommand(cmd *cobra.Command, _ []string) {
+	catalogue.Use(catalogue.Load(params.attacker))
+	conf := params.rawConfig
+	space := search.NewSpace(
+		config.EnabledPoints(conf.EnableBlockPoints),
+		conf.EnabledActions(conf.EnableBlockActions, actionset.BlockAction),
+		config.EnabledPoints(conf.EnableAttPoints),
+		conf.EnabledActions(conf.EnableAttActions, actionset.AttestAction),
+	)
+	validators, err := attackerValidators(conf)
+	if err != nil {
+		log.WithError(err).Error("failed to resolve attacker validators")
+		return
+	}
+	template := search.Template{
+		Validators: validators,
+		Slot:       params.slot,
+	}
+	opts := search.Options{
+		Mode:         params.mode,
+		Seed:         params.seed,
+		Population:   params.population,
+		Rounds:       params.rounds,
+		MutationRate: params.mutationRate,
+		Elite:        params.elite,
+		Epsilon:      params.epsilon,
+		ReorgWeight:  params.reorgWeight,
+		RewardWeight: params.rewardWeight,
+	}
+	if params.historyFile != "" {
+		f, err := os.OpenFile(params.historyFile, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			log.WithError(err).Error("failed to open history file")
+			return
+		}
+		defer f.Close()
+		enc := json.NewEncoder(f)
+		opts.OnEvaluated = func(c search.Candidate) {
+			if err := enc.Encode(c); err != nil {
+				log.WithError(err).Warn("failed to write history")
+			}
+		}
+	}
+
+	evaluator := &liveEvaluator{
+		attacker:  params.attacker,
+		template:  template,
+		epochs:    params.epochs,
+		attackers: attackerIndexes(validators),
+	}
+	searcher, err := search.NewSearcher(opts, space, evaluator)
+	if err != nil {
+		log.WithError(err).Error("failed to create searcher")
+		return
+	}
+	best, err := searcher.Run()
+	if err != nil {
+		log.WithError(err).Error("search failed")
+		return
+	}
+	if err := template.Strategy(best.Actions).ToFile(params.outputFile); err != nil {
+		log.WithError(err).Error("failed to write best strategy")
+		return
+	}
+	log.WithFields(log.Fields{
+		"actions": best.Actions,
+		"fitness": best.Fitness,
+		"output":  params.outputFile,
+	}).Info("search finished")
+}
+
+// attackerValidators returns the validators up to --max-validator-index if it
+// is set, or the validators of the selector of the config.
+func attackerValidators(conf *config.Config) ([]types.ValidatorStrategy, error) {
+	if params.maxValidatorIndex >= 0 {
+		return types.GetValidatorStrategy(0, params.maxValidatorIndex, 0, math.MaxInt32), nil
+	}
+	set, err := config.ValidatorSet(params.attacker)
+	if err != nil {
+		return nil, err
+	}
+	return conf.AttackerValidators(set)
+}
+
+// attackerIndexes returns the set of the attacker validator indexes, the
+// selectors may pick any validators, not only the ones up to an index.
+func attackerIndexes(validators []types.ValidatorStrategy) map[int]bool {
+	indexes := make(map[int]bool, len(validators))
+	for _, v := range validators {
+		indexes[v.ValidatorIndex] = true
+	}
+	return indexes
+}
@@ -0,0 +1,29 @@
+package simulate
+
+const (
+	strategyFlag       = "strategy"
+	validatorsFlag     = "validators"
+	slotsPerEpochFlag  = "slots-per-epoch"
+	secondsPerSlotFlag = "seconds-per-slot"
+	epochsFlag         = "epochs"
+	latencyFlag        = "latency"
+	jitterFlag         = "jitter"
+	seedFlag           = "seed"
+	rulesFlag          = "rules"
+)
+
+type simulateParam struct {
+	strategyFile   string
+	validators     int
+	slotsPerEpoch  int64
+	secondsPerSlot int64
+	epochs         int64
+	latency        int64
+	jitter         int64
+	seed           int64
+	rules          []string
+}
+
+var (
+	params = &simulateParam{}
+)
@@ -0,0 +1,38 @@
+package simulate
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/tsinghua-cel/strategy-gen/command/helper"
+	"github.com/tsinghua-cel/strategy-gen/simulate"
+)
+
+type simulateResult struct {
+	Results []simulate.Result `json:"results"`
+}
+
+func (r *simulateResult) GetOutput() string {
+	var buffer bytes.Buffer
+
+	for _, res := range r.Results {
+		buffer.WriteString(fmt.Sprintf("\n[SIMULATE %s]\n", strings.ToUpper(res.Rule)))
+		buffer.WriteString(helper.FormatKV([]string{
+			fmt.Sprintf("Slots|%d", res.Slots),
+			fmt.Sprintf("Reorgs|%d", res.Reorgs),
+			fmt.Sprintf("Max reorg depth|%d", res.MaxReorgDepth),
+			fmt.Sprintf("Honest proposals|%d", res.HonestProposals),
+			fmt.Sprintf("Honest orphaned|%d", res.HonestOrphaned),
+			fmt.Sprintf("Attacker proposals|%d", res.AttackerProposals),
+			fmt.Sprintf("Attacker canonical|%d", res.AttackerCanonical),
+			fmt.Sprintf("Attacker block share|%.4f", res.AttackerBlockShare),
+			fmt.Sprintf("Attacker stake share|%.4f", res.AttackerStakeShare),
+			fmt.Sprintf("Relative block share|%.4f", res.RelativeBlockShare),
+			fmt.Sprintf("Not simulated|%s", strings.Join(res.Ignored, ",")),
+		}))
+		buffer.WriteString("\n")
+	}
+
+	return buffer.String()
+}
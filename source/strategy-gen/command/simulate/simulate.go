@@ -0,0 +1,114 @@
+package simulate
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/tsinghua-cel/strategy-gen/command"
+	"github.com/tsinghua-cel/strategy-gen/simulate"
+	"github.com/tsinghua-cel/strategy-gen/types"
+)
+
+func GetCommand() *cobra.Command {
+	simulateCmd := &cobra.Command{
+		Use:   "simulate",
+		Short: "Predict the reorgs and the attacker share of a strategy with an offline consensus simulation",
+		Run:   runCommand,
+	}
+	setFlags(simulateCmd)
+	return simulateCmd
+}
+
+func setFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(
+		&params.strategyFile,
+		strategyFlag,
+		"strategy.json",
+		"the strategy file to simulate",
+	)
+
+	cmd.Flags().IntVar(
+		&params.validators,
+		validatorsFlag,
+		256,
+		"the number of validators",
+	)
+
+	cmd.Flags().Int64Var(
+		&params.slotsPerEpoch,
+		slotsPerEpochFlag,
+		32,
+		"the slots per epoch",
+	)
+
+	cmd.Flags().Int64Var(
+		&params.secondsPerSlot,
+		secondsPerSlotFlag,
+		12,
+		"the seconds per slot",
+	)
+
+	cmd.Flags().Int64Var(
+		&params.epochs,
+		epochsFlag,
+		4,
+		"the number of epochs to simulate",
+	)
+
+	cmd.Flags().Int64Var(
+		&params.latency,
+		latencyFlag,
+		200,
+		"the network delay of a message in milliseconds",
+	)
+
+	cmd.Flags().Int64Var(
+		&params.jitter,
+		jitterFlag,
+		100,
+		"the max random jitter added to the network delay in milliseconds",
+	)
+
+	cmd.Flags().Int64Var(
+		&params.seed,
+		seedFlag,
+		1,
+		"the seed of the proposers, committees and network jitter",
+	)
+
+	cmd.Flags().StringSliceVar(
+		&params.rules,
+		rulesFlag,
+		[]string{simulate.RuleGhost, simulate.RuleStable},
+		"the fork choice rules of the honest nodes, ghost: lmd-ghost with proposer boost, stable: reorg-fix stable longest chain",
+	)
+}
+
+func runCommand(cmd *cobra.Command, _ []string) {
+	outputter := command.InitializeOutputter(cmd)
+	defer outputter.WriteOutput()
+
+	strategy, err := types.StrategyFromFile(params.strategyFile)
+	if err != nil {
+		outputter.SetError(err)
+		return
+	}
+
+	result := &simulateResult{}
+	for _, rule := range params.rules {
+		res, err := simulate.Run(simulate.Config{
+			Validators:     params.validators,
+			SlotsPerEpoch:  params.slotsPerEpoch,
+			SecondsPerSlot: params.secondsPerSlot,
+			Epochs:         params.epochs,
+			Latency:        params.latency,
+			Jitter:         params.jitter,
+			Seed:           params.seed,
+			Rule:           rule,
+		}, strategy)
+		if err != nil {
+			outputter.SetError(err)
+			return
+		}
+		result.Results = append(result.Results, res)
+	}
+	outputter.SetCommandResult(result)
+}
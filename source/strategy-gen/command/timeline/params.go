@@ -0,0 +1,26 @@
+package timeline
+
+const (
+	epochsFlag        = "epochs"
+	slotsPerEpochFlag = "slots-per-epoch"
+	attackerFlag      = "attacker"
+	formatFlag        = "format"
+	outputFlag        = "output"
+)
+
+const (
+	formatASCII = "ascii"
+	formatHTML  = "html"
+)
+
+type timelineParams struct {
+	epochs        string
+	slotsPerEpoch int64
+	attacker      string
+	format        string
+	output        string
+}
+
+var (
+	params = &timelineParams{}
+)
@@ -0,0 +1,112 @@
+package timeline
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/tsinghua-cel/strategy-gen/command/helper"
+	"github.com/tsinghua-cel/strategy-gen/inspect"
+)
+
+type timelineResult struct {
+	inspect.Timeline
+	format string
+}
+
+func (r *timelineResult) GetOutput() string {
+	var buffer bytes.Buffer
+	if err := r.write(&buffer); err != nil {
+		return err.Error()
+	}
+	return buffer.String()
+}
+
+func (r *timelineResult) write(w io.Writer) error {
+	if r.format == formatHTML {
+		return htmlTemplate.Execute(w, r)
+	}
+	_, err := io.WriteString(w, r.ascii())
+	return err
+}
+
+// ascii renders one line per slot, an attacker proposer is marked with *
+// and the points without an action with a dot.
+func (r *timelineResult) ascii() string {
+	var buffer bytes.Buffer
+
+	buffer.WriteString("\n[TIMELINE]\n")
+	rows := make([]string, 0, len(r.Rows)+1)
+	rows = append(rows, "Slot|Epoch|Proposer|"+strings.Join(r.Points, "|"))
+	for _, row := range r.Rows {
+		actions := make([]string, 0, len(row.Actions))
+		for _, a := range row.Actions {
+			if a == "" {
+				a = "."
+			}
+			actions = append(actions, a)
+		}
+		rows = append(rows, fmt.Sprintf("%d|%d|%s|%s", row.Slot, row.Epoch, proposer(row), strings.Join(actions, "|")))
+	}
+	buffer.WriteString(helper.FormatList(rows))
+	buffer.WriteString("\n")
+	if len(r.Unresolved) > 0 {
+		buffer.WriteString("\n[DEPENDS ON DUTIES]\n")
+		buffer.WriteString(strings.Join(r.Unresolved, "\n"))
+		buffer.WriteString("\n")
+	}
+
+	return buffer.String()
+}
+
+func proposer(row inspect.TimelineRow) string {
+	if row.Proposer < 0 {
+		return "?"
+	}
+	if row.AttackerProposer {
+		return strconv.Itoa(row.Proposer) + "*"
+	}
+	return strconv.Itoa(row.Proposer)
+}
+
+var htmlTemplate = template.Must(template.New("timeline").Funcs(template.FuncMap{
+	"proposer": proposer,
+	"odd":      func(n int64) bool { return n%2 == 1 },
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>strategy timeline</title>
+<style>
+body { font-family: monospace; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: 2px 6px; }
+tr.odd td { background: #f3f3f3; }
+tr td.attacker { background: #f4cccc; }
+tr td.action { background: #d9ead3; }
+</style>
+</head>
+<body>
+<table>
+<tr><th>slot</th><th>epoch</th><th>proposer</th>{{range .Points}}<th>{{.}}</th>{{end}}</tr>
+{{range .Rows}}<tr{{if odd .Epoch}} class="odd"{{end}} title="{{.Rule}}">
+<td>{{.Slot}}</td><td>{{.Epoch}}</td><td{{if .AttackerProposer}} class="attacker"{{end}}>{{proposer .}}</td>
+{{range .Actions}}<td{{if .}} class="action"{{end}}>{{.}}</td>{{end}}
+</tr>
+{{end}}</table>
+{{if .Unresolved}}<h4>depends on duties</h4>
+<ul>{{range .Unresolved}}<li>{{.}}</li>{{end}}</ul>
+{{end}}</body>
+</html>
+`))
+
+type savedResult struct {
+	File string `json:"file"`
+}
+
+func (r *savedResult) GetOutput() string {
+	return fmt.Sprintf("timeline written to %s", r.File)
+}
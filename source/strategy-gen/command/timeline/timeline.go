@@ -0,0 +1,151 @@
+package timeline
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"github.com/tsinghua-cel/strategy-gen/catalogue"
+	"github.com/tsinghua-cel/strategy-gen/command"
+	"github.com/tsinghua-cel/strategy-gen/inspect"
+	"github.com/tsinghua-cel/strategy-gen/types"
+	"github.com/tsinghua-cel/strategy-gen/utils"
+)
+
+func GetCommand() *cobra.Command {
+	timelineCmd := &cobra.Command{
+		Use:   "timeline [strategy.json]",
+		Short: "Render the action that fires at every slot and action point of a strategy",
+		Args:  cobra.MaximumNArgs(1),
+		Run:   runCommand,
+	}
+	setFlags(timelineCmd)
+	return timelineCmd
+}
+
+func setFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(
+		&params.epochs,
+		epochsFlag,
+		"0..1",
+		"the epochs x..y to render",
+	)
+
+	cmd.Flags().Int64Var(
+		&params.slotsPerEpoch,
+		slotsPerEpochFlag,
+		32,
+		"the slots per epoch, the chain spec of the attacker service is used if it is set",
+	)
+
+	cmd.Flags().StringVar(
+		&params.attacker,
+		attackerFlag,
+		"",
+		"the attacker service to overlay the proposer duties from, the duty dependent rules are listed apart if empty",
+	)
+
+	cmd.Flags().StringVar(
+		&params.format,
+		formatFlag,
+		formatASCII,
+		"the output format, ascii or html",
+	)
+
+	cmd.Flags().StringVar(
+		&params.output,
+		outputFlag,
+		"",
+		"the file to write the timeline to, default is stdout",
+	)
+}
+
+func runCommand(cmd *cobra.Command, args []string) {
+	outputter := command.InitializeOutputter(cmd)
+	defer outputter.WriteOutput()
+
+	if params.format != formatASCII && params.format != formatHTML {
+		outputter.SetError(fmt.Errorf("unknown format %s", params.format))
+		return
+	}
+	file := "strategy.json"
+	if len(args) > 0 {
+		file = args[0]
+	}
+	strategy, err := types.StrategyFromFile(file)
+	if err != nil {
+		outputter.SetError(err)
+		return
+	}
+	fromEpoch, toEpoch, err := inspect.ParseRange(params.epochs)
+	if err != nil {
+		outputter.SetError(err)
+		return
+	}
+
+	var proposer func(slot int64) (int, bool)
+	if params.attacker != "" {
+		catalogue.Use(catalogue.Load(params.attacker))
+		spec, err := utils.GetChainSpec(params.attacker)
+		if err != nil {
+			outputter.SetError(err)
+			return
+		}
+		params.slotsPerEpoch = int64(spec.SlotsPerEpoch)
+		proposers, err := getProposers(params.attacker, fromEpoch, toEpoch)
+		if err != nil {
+			outputter.SetError(err)
+			return
+		}
+		proposer = func(slot int64) (int, bool) {
+			p, ok := proposers[slot]
+			return p, ok
+		}
+	}
+
+	result := &timelineResult{
+		Timeline: inspect.NewTimeline(strategy,
+			fromEpoch*params.slotsPerEpoch, (toEpoch+1)*params.slotsPerEpoch-1,
+			params.slotsPerEpoch, proposer),
+		format: params.format,
+	}
+	if params.output == "" {
+		outputter.SetCommandResult(result)
+		return
+	}
+	var buffer bytes.Buffer
+	if err := result.write(&buffer); err != nil {
+		outputter.SetError(err)
+		return
+	}
+	if err := os.WriteFile(params.output, buffer.Bytes(), 0644); err != nil {
+		outputter.SetError(err)
+		return
+	}
+	outputter.SetCommandResult(&savedResult{File: params.output})
+}
+
+// getProposers returns the proposer index of the slots of the epochs.
+func getProposers(url string, from, to int64) (map[int64]int, error) {
+	proposers := make(map[int64]int)
+	for epoch := from; epoch <= to; epoch++ {
+		duties, err := utils.GetEpochDuties(url, epoch)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get duties of epoch %d: %w", epoch, err)
+		}
+		for _, duty := range duties {
+			slot, err := strconv.ParseInt(duty.Slot, 10, 64)
+			if err != nil {
+				continue
+			}
+			index, err := strconv.Atoi(duty.ValidatorIndex)
+			if err != nil {
+				continue
+			}
+			proposers[slot] = index
+		}
+	}
+	return proposers, nil
+}
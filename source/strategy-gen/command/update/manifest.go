@@ -0,0 +1,55 @@
+package update
+
+import (
+	"encoding/json"
+	log "github.com/sirupsen/logrus"
+	"os"
+)
+
+// manifestEntry is a strategy file and the epochs it was active in, the
+// cooldown is active from FromEpoch until the next update, ToEpoch is -1.
+// The entry of a failed switch has the Error, the service runs the last
+// strategy or one that is not verified in its epochs.
+type manifestEntry struct {
+	File      string `json:"file"`
+	FromEpoch int64  `json:"from_epoch"`
+	ToEpoch   int64  `json:"to_epoch"`
+	Cooldown  bool   `json:"cooldown,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// manifest records the rotation to align the results of the service by epoch.
+type manifest struct {
+	path          string
+	Attacker      string          `json:"attacker"`
+	SlotsPerEpoch int             `json:"slots_per_epoch"`
+	Entries       []manifestEntry `json:"entries"`
+}
+
+// add appends the entry, or extends the last one if the file is the same
+// and the epochs follow it.
+func (m *manifest) add(e manifestEntry) {
+	if n := len(m.Entries); n > 0 {
+		last := &m.Entries[n-1]
+		if last.File == e.File && last.Cooldown == e.Cooldown && last.Error == "" && e.Error == "" && last.ToEpoch+1 == e.FromEpoch {
+			last.ToEpoch = e.ToEpoch
+			m.save()
+			return
+		}
+	}
+	m.Entries = append(m.Entries, e)
+	m.save()
+}
+
+func (m *manifest) save() {
+	if m.path == "" {
+		return
+	}
+	d, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return
+	}
+	if err := os.WriteFile(m.path, d, 0644); err != nil {
+		log.WithField("error", err).Error("failed to write the manifest")
+	}
+}
@@ -0,0 +1,91 @@
+package update
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestManifestAdd(t *testing.T) {
+	tests := []struct {
+		name string
+		add  []manifestEntry
+		want []manifestEntry
+	}{
+		{
+			name: "same file extends",
+			add:  []manifestEntry{{File: "a", FromEpoch: 1, ToEpoch: 3}, {File: "a", FromEpoch: 4, ToEpoch: 6}},
+			want: []manifestEntry{{File: "a", FromEpoch: 1, ToEpoch: 6}},
+		},
+		{
+			name: "another file",
+			add:  []manifestEntry{{File: "a", FromEpoch: 1, ToEpoch: 3}, {File: "b", FromEpoch: 4, ToEpoch: 6}},
+			want: []manifestEntry{{File: "a", FromEpoch: 1, ToEpoch: 3}, {File: "b", FromEpoch: 4, ToEpoch: 6}},
+		},
+		{
+			name: "epochs do not follow",
+			add:  []manifestEntry{{File: "a", FromEpoch: 1, ToEpoch: 3}, {File: "a", FromEpoch: 5, ToEpoch: 6}},
+			want: []manifestEntry{{File: "a", FromEpoch: 1, ToEpoch: 3}, {File: "a", FromEpoch: 5, ToEpoch: 6}},
+		},
+		{
+			name: "failed switch",
+			add:  []manifestEntry{{File: "a", FromEpoch: 1, ToEpoch: 3}, {File: "a", FromEpoch: 4, ToEpoch: 6, Error: "timeout"}, {File: "a", FromEpoch: 7, ToEpoch: 9}},
+			want: []manifestEntry{{File: "a", FromEpoch: 1, ToEpoch: 3}, {File: "a", FromEpoch: 4, ToEpoch: 6, Error: "timeout"}, {File: "a", FromEpoch: 7, ToEpoch: 9}},
+		},
+		{
+			name: "cooldown",
+			add:  []manifestEntry{{File: "honest", FromEpoch: 1, ToEpoch: 3}, {File: "honest", FromEpoch: 4, ToEpoch: -1, Cooldown: true}},
+			want: []manifestEntry{{File: "honest", FromEpoch: 1, ToEpoch: 3}, {File: "honest", FromEpoch: 4, ToEpoch: -1, Cooldown: true}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &manifest{}
+			for _, e := range tt.add {
+				m.add(e)
+			}
+			if !reflect.DeepEqual(m.Entries, tt.want) {
+				t.Errorf("entries %+v, want %+v", m.Entries, tt.want)
+			}
+		})
+	}
+}
+
+func TestManifestSave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rotation.json")
+	m := &manifest{path: path, Attacker: "127.0.0.1:12001", SlotsPerEpoch: 32}
+	m.add(manifestEntry{File: "a.json", FromEpoch: 2, ToEpoch: 4})
+	m.add(manifestEntry{File: "b.json", FromEpoch: 5, ToEpoch: 7, Error: "the service runs another strategy"})
+	m.add(manifestEntry{File: "honest", FromEpoch: 8, ToEpoch: -1, Cooldown: true})
+
+	d, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var raw map[string]interface{}
+	if err := json.Unmarshal(d, &raw); err != nil {
+		t.Fatal(err)
+	}
+	entries := raw["entries"].([]interface{})
+	if len(entries) != 3 || raw["attacker"] != "127.0.0.1:12001" || raw["slots_per_epoch"] != float64(32) {
+		t.Fatalf("saved manifest %s", d)
+	}
+	// the optional fields are only saved when they are set.
+	first := entries[0].(map[string]interface{})
+	if _, ok := first["error"]; ok {
+		t.Errorf("entry without error saved %v", first)
+	}
+	if _, ok := first["cooldown"]; ok {
+		t.Errorf("entry without cooldown saved %v", first)
+	}
+	var loaded manifest
+	if err := json.Unmarshal(d, &loaded); err != nil {
+		t.Fatal(err)
+	}
+	loaded.path = path
+	if !reflect.DeepEqual(&loaded, m) {
+		t.Errorf("loaded %+v, want %+v", loaded, *m)
+	}
+}
@@ -1,19 +1,34 @@
 package update
 
+import "time"
+
 const (
 	intervalFlag  = "interval"
 	attackerFlag  = "attacker"
 	sliceFlag     = "slice"
 	modeFlag      = "mode"       // 0: sorted loop 1: random loop
 	loopCountFlag = "loop-count" // 0 is never stop.
+	epochsFlag    = "epochs"
+	weightsFlag   = "weights"
+	cooldownFlag  = "cooldown"
+	manifestFlag  = "manifest"
+	leadTimeFlag  = "lead-time"
 )
 
+// cooldownHonest is the cooldown strategy without attacker validators.
+const cooldownHonest = "honest"
+
 type updateParam struct {
 	interval   int
 	fileSlice  []string
 	updateMode int
 	loopCount  int
 	attacker   string
+	epochs     int
+	weights    []int
+	cooldown   string
+	manifest   string
+	leadTime   time.Duration
 }
 
 var (
@@ -23,5 +38,6 @@ var (
 		loopCount:  0,
 		fileSlice:  []string{},
 		attacker:   "",
+		epochs:     3,
 	}
 )
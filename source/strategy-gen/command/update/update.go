@@ -1,15 +1,18 @@
 package update
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/tsinghua-cel/strategy-gen/types"
+	"github.com/tsinghua-cel/strategy-gen/utils"
 	"math/rand"
-	"net/http"
 	"os"
+	"os/signal"
+	"reflect"
+	"syscall"
 	"time"
 )
 
@@ -34,6 +37,7 @@ func setFlags(cmd *cobra.Command) {
 		1200,
 		"the interval to update the strategy",
 	)
+	_ = cmd.Flags().MarkDeprecated(intervalFlag, "the strategies are switched at epoch boundaries, use --epochs")
 
 	cmd.Flags().StringVar(
 		&params.attacker,
@@ -60,7 +64,42 @@ func setFlags(cmd *cobra.Command) {
 		&params.loopCount,
 		loopCountFlag,
 		0,
-		"the loop count, 0 is never stop",
+		"the number of strategy switches, 0 is never stop",
+	)
+
+	cmd.Flags().IntVar(
+		&params.epochs,
+		epochsFlag,
+		3,
+		"the epochs each strategy is active",
+	)
+
+	cmd.Flags().IntSliceVar(
+		&params.weights,
+		weightsFlag,
+		[]int{},
+		"the weight of each strategy file, the sorted loop keeps a file active for weight times the epochs, the random loop picks it with the weight",
+	)
+
+	cmd.Flags().StringVar(
+		&params.cooldown,
+		cooldownFlag,
+		"",
+		"the strategy file updated when the loop stops or is interrupted, honest for no attacker validators",
+	)
+
+	cmd.Flags().StringVar(
+		&params.manifest,
+		manifestFlag,
+		"rotation.json",
+		"the file to record the epochs each strategy was active in",
+	)
+
+	cmd.Flags().DurationVar(
+		&params.leadTime,
+		leadTimeFlag,
+		4*time.Second,
+		"switch the strategy of an epoch this long before the epoch starts",
 	)
 }
 
@@ -69,62 +108,240 @@ func runCommand(cmd *cobra.Command, _ []string) {
 		cmd.Help()
 		return
 	}
+	if params.epochs <= 0 {
+		log.WithField("epochs", params.epochs).Error("epochs must be positive")
+		return
+	}
+	weights, err := fileWeights(params.fileSlice, params.weights)
+	if err != nil {
+		log.WithField("error", err).Error("invalid weights")
+		return
+	}
 	allStrategy := make(map[string]types.Strategy)
+	files := make([]string, 0, len(params.fileSlice))
 	log.Infof("update strategy files: %v", params.fileSlice)
-	for _, file := range params.fileSlice {
-		d, err := os.ReadFile(file)
+	for i, file := range params.fileSlice {
+		strategy, err := types.StrategyFromFile(file)
 		if err != nil {
 			log.WithField("file", file).Errorf("ignore the file because of failed to read file: %s", err)
 			continue
 		}
-		strategy := types.Strategy{}
-		err = json.Unmarshal(d, &strategy)
+		allStrategy[file] = strategy
+		files = append(files, file)
+		weights[len(files)-1] = weights[i]
+	}
+	if len(files) == 0 {
+		return
+	}
+	weights = weights[:len(files)]
+
+	var cooldown *types.Strategy
+	switch params.cooldown {
+	case "":
+	case cooldownHonest:
+		cooldown = &types.Strategy{Slots: []types.SlotStrategy{}, Validators: []types.ValidatorStrategy{}}
+	default:
+		strategy, err := types.StrategyFromFile(params.cooldown)
 		if err != nil {
-			log.WithField("file", file).Errorf("ignore the file because of failed to unmarshal data: %s", err)
-			continue
+			log.WithField("file", params.cooldown).Errorf("failed to read the cooldown strategy: %s", err)
+			return
 		}
-		allStrategy[file] = strategy
+		cooldown = &strategy
+	}
+
+	spec, err := utils.GetChainSpec(params.attacker)
+	if err != nil {
+		log.WithField("error", err).Error("failed to get chain spec")
+		return
+	}
+	slotTool := utils.SlotTool{SlotsPerEpoch: spec.SlotsPerEpoch}
+	lead := params.leadTime
+	if epochDuration := time.Duration(spec.SlotsPerEpoch) * spec.SlotDuration(); lead > epochDuration {
+		lead = epochDuration
 	}
+	m := &manifest{path: params.manifest, Attacker: params.attacker, SlotsPerEpoch: spec.SlotsPerEpoch}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	stopCheck := func(count int) bool {
 		if params.loopCount == 0 {
 			return false
 		}
 		return count >= params.loopCount
 	}
+	epoch := slotTool.SlotToEpoch(spec.CurrentSlot()) + 1
 	total := 0
-	for stopCheck(total) != true {
+	for !stopCheck(total) {
 		var idx = 0
 		if params.updateMode == 0 {
 			// sorted loop
-			idx = total % len(params.fileSlice)
+			idx = total % len(files)
 		} else {
-			idx = rand.Intn(len(params.fileSlice))
+			idx = weightedIndex(weights)
 		}
-		err := updateStrategy(params.attacker, allStrategy[params.fileSlice[idx]])
-		if err != nil {
-			log.WithField("file", params.fileSlice[idx]).Errorf("failed to update strategy: %s", err)
-			time.Sleep(time.Second)
+		epochs := int64(params.epochs)
+		if params.updateMode == 0 {
+			epochs *= int64(weights[idx])
+		}
+		// the switch is retried until the epoch starts.
+		start := spec.SlotStartTime(slotTool.EpochStart(epoch))
+		if !sleepUntil(ctx, start.Add(-lead)) {
+			break
+		}
+		file := files[idx]
+		if err := switchStrategy(ctx, params.attacker, allStrategy[file], start); err != nil {
+			log.WithFields(log.Fields{
+				"file":  file,
+				"epoch": epoch,
+				"error": err,
+			}).Error("failed to update strategy")
+			m.add(manifestEntry{File: file, FromEpoch: epoch, ToEpoch: epoch + epochs - 1, Error: err.Error()})
 		} else {
-			log.WithField("file", params.fileSlice[idx]).Info("update strategy success")
-			time.Sleep(time.Duration(params.interval) * time.Second)
+			log.WithFields(log.Fields{
+				"file":      file,
+				"fromEpoch": epoch,
+				"toEpoch":   epoch + epochs - 1,
+			}).Info("update strategy success")
+			m.add(manifestEntry{File: file, FromEpoch: epoch, ToEpoch: epoch + epochs - 1})
 		}
-
+		epoch += epochs
 		total++
 	}
+
+	deadline := time.Now().Add(spec.SlotDuration())
+	if ctx.Err() == nil && cooldown != nil {
+		start := spec.SlotStartTime(slotTool.EpochStart(epoch))
+		if sleepUntil(ctx, start.Add(-lead)) {
+			deadline = start
+		}
+	}
+	if ctx.Err() != nil {
+		// the epoch of the interrupt ran the last strategy and the cooldown,
+		// a strategy switched before its epoch started never ran.
+		epoch = slotTool.SlotToEpoch(spec.CurrentSlot())
+		if n := len(m.Entries); n > 0 && m.Entries[n-1].FromEpoch > epoch {
+			m.Entries = m.Entries[:n-1]
+			m.save()
+		} else if n > 0 && m.Entries[n-1].ToEpoch > epoch {
+			m.Entries[n-1].ToEpoch = epoch
+			m.save()
+		}
+	}
+	if cooldown == nil {
+		return
+	}
+	if err := switchStrategy(context.Background(), params.attacker, *cooldown, deadline); err != nil {
+		log.WithFields(log.Fields{
+			"file":  params.cooldown,
+			"error": err,
+		}).Error("failed to update the cooldown strategy")
+		return
+	}
+	log.WithFields(log.Fields{
+		"file":  params.cooldown,
+		"epoch": epoch,
+	}).Info("update cooldown strategy success")
+	m.add(manifestEntry{File: params.cooldown, FromEpoch: epoch, ToEpoch: -1, Cooldown: true})
 }
 
-func updateStrategy(url string, strategy types.Strategy) error {
-	d, err := json.Marshal(strategy)
+// fileWeights returns the weight of each file, 1 if no weights are given.
+func fileWeights(files []string, weights []int) ([]int, error) {
+	if len(weights) == 0 {
+		weights = make([]int, len(files))
+		for i := range weights {
+			weights[i] = 1
+		}
+		return weights, nil
+	}
+	if len(weights) != len(files) {
+		return nil, fmt.Errorf("got %d weights for %d files", len(weights), len(files))
+	}
+	for _, w := range weights {
+		if w <= 0 {
+			return nil, fmt.Errorf("weight %d is not positive", w)
+		}
+	}
+	return append([]int{}, weights...), nil
+}
+
+func weightedIndex(weights []int) int {
+	sum := 0
+	for _, w := range weights {
+		sum += w
+	}
+	n := rand.Intn(sum)
+	for i, w := range weights {
+		if n < w {
+			return i
+		}
+		n -= w
+	}
+	return len(weights) - 1
+}
+
+// sleepUntil returns false if it is interrupted before t.
+func sleepUntil(ctx context.Context, t time.Time) bool {
+	timer := time.NewTimer(time.Until(t))
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// switchStrategy updates the strategy and reads it back from the service, it
+// is retried every second until the deadline.
+func switchStrategy(ctx context.Context, url string, strategy types.Strategy, deadline time.Time) error {
+	for {
+		err := utils.UpdateStrategy(url, strategy)
+		if err == nil {
+			err = verifyStrategy(url, strategy)
+		}
+		if err == nil {
+			return nil
+		}
+		if !time.Now().Add(time.Second).Before(deadline) {
+			return err
+		}
+		log.WithField("error", err).Warn("failed to switch strategy, retry")
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+// verifyStrategy checks the service runs the strategy, both are compared
+// after a json round trip.
+func verifyStrategy(url string, strategy types.Strategy) error {
+	active, err := utils.GetStrategy(url)
 	if err != nil {
 		return err
 	}
-
-	res, err := http.Post(fmt.Sprintf("http://%s/v1/update-strategy", url), "application/json", bytes.NewReader(d))
+	want, err := normalize(strategy)
 	if err != nil {
 		return err
 	}
-	if res.StatusCode != 200 {
-		return fmt.Errorf("failed to generate strategy: %s", res.Status)
+	got, err := normalize(active)
+	if err != nil {
+		return err
+	}
+	if !reflect.DeepEqual(want, got) {
+		return fmt.Errorf("the service runs another strategy")
 	}
 	return nil
 }
+
+func normalize(strategy types.Strategy) (types.Strategy, error) {
+	var s types.Strategy
+	d, err := json.Marshal(strategy)
+	if err != nil {
+		return s, err
+	}
+	err = json.Unmarshal(d, &s)
+	return s, err
+}
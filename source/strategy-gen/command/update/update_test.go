@@ -0,0 +1,81 @@
+package update
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFileWeights(t *testing.T) {
+	files := []string{"a.json", "b.json", "c.json"}
+	tests := []struct {
+		name    string
+		weights []int
+		want    []int
+		wantErr bool
+	}{
+		{name: "no weights", want: []int{1, 1, 1}},
+		{name: "weights", weights: []int{2, 1, 3}, want: []int{2, 1, 3}},
+		{name: "fewer weights", weights: []int{2, 1}, wantErr: true},
+		{name: "more weights", weights: []int{2, 1, 3, 4}, wantErr: true},
+		{name: "zero weight", weights: []int{2, 0, 3}, wantErr: true},
+		{name: "negative weight", weights: []int{2, -1, 3}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := fileWeights(files, tt.weights)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("got weights %v, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("weights %v, want %v", got, tt.want)
+			}
+			// the weights are copied, runCommand compacts them in place.
+			if len(tt.weights) > 0 {
+				got[0] = 100
+				if tt.weights[0] == 100 {
+					t.Error("the weights are not copied")
+				}
+			}
+		})
+	}
+}
+
+func TestWeightedIndex(t *testing.T) {
+	tests := []struct {
+		name    string
+		weights []int
+	}{
+		{name: "one file", weights: []int{3}},
+		{name: "equal weights", weights: []int{1, 1}},
+		{name: "weights", weights: []int{1, 3, 6}},
+	}
+	const draws = 20000
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sum := 0
+			for _, w := range tt.weights {
+				sum += w
+			}
+			counts := make([]int, len(tt.weights))
+			for i := 0; i < draws; i++ {
+				idx := weightedIndex(tt.weights)
+				if idx < 0 || idx >= len(tt.weights) {
+					t.Fatalf("index %d of %d weights", idx, len(tt.weights))
+				}
+				counts[idx]++
+			}
+			for i, w := range tt.weights {
+				share, want := float64(counts[i])/draws, float64(w)/float64(sum)
+				if share < want-0.03 || share > want+0.03 {
+					t.Errorf("file %d is picked %.3f of the draws, want %.3f", i, share, want)
+				}
+			}
+		})
+	}
+}
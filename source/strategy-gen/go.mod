@@ -6,6 +6,7 @@ require (
 	github.com/ryanuber/columnize v2.1.2+incompatible
 	github.com/sirupsen/logrus v1.9.3
 	github.com/spf13/cobra v1.8.0
+	github.com/tsinghua-cel/strategy-schema v0.0.0
 	gopkg.in/yaml.v3 v3.0.1
 )
 
@@ -14,3 +15,5 @@ require (
 	github.com/spf13/pflag v1.0.5 // indirect
 	golang.org/x/sys v0.0.0-20220715151400-c0bba94af5f8 // indirect
 )
+
+replace github.com/tsinghua-cel/strategy-schema => ../strategy-schema
@@ -0,0 +1,230 @@
+package inspect
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/tsinghua-cel/strategy-gen/types"
+)
+
+// Change is a difference of two strategies at a slot range, a rule slot, a
+// validator or a conditional block. An empty From or To is missing on that side.
+type Change struct {
+	Where string `json:"where"`
+	Point string `json:"point,omitempty"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+// Diff compares what the strategies do: the action of every point in the
+// slots from..to, the rules depending on the duties by their slot, the
+// validators by index and the conditional blocks by name.
+func Diff(a, b types.Strategy, from, to int64, slotsPerEpoch int64) []Change {
+	changes := diffValidators(a.Validators, b.Validators)
+	if a.AllowSlashable != b.AllowSlashable {
+		changes = append(changes, Change{
+			Where: "allow_slashable",
+			From:  strconv.FormatBool(a.AllowSlashable),
+			To:    strconv.FormatBool(b.AllowSlashable),
+		})
+	}
+	sa := NewSlots(a, slotsPerEpoch, nil)
+	sb := NewSlots(b, slotsPerEpoch, nil)
+	changes = append(changes, diffSlots(sa, sb, from, to)...)
+	changes = append(changes, diffDutyRules(sa, sb)...)
+	changes = append(changes, diffConditional(a.Conditional, b.Conditional)...)
+	return changes
+}
+
+type slotRange struct {
+	first, last int64
+	change      Change
+}
+
+// diffSlots compares the resolved actions slot by slot, the consecutive slots
+// with the same change are merged to a range.
+func diffSlots(sa, sb *Slots, from, to int64) []Change {
+	done := make([]slotRange, 0)
+	open := make(map[Change]*slotRange)
+	for slot := from; slot <= to; slot++ {
+		ra, _ := sa.Resolve(slot)
+		rb, _ := sb.Resolve(slot)
+		for _, point := range unionPoints(ra.Actions, rb.Actions) {
+			if ra.Actions[point] == rb.Actions[point] {
+				continue
+			}
+			c := Change{Point: point, From: ra.Actions[point], To: rb.Actions[point]}
+			if r, ok := open[c]; ok && r.last == slot-1 {
+				r.last = slot
+				continue
+			} else if ok {
+				done = append(done, *r)
+			}
+			open[c] = &slotRange{first: slot, last: slot, change: c}
+		}
+	}
+	for _, r := range open {
+		done = append(done, *r)
+	}
+	sort.Slice(done, func(i, j int) bool {
+		if done[i].first != done[j].first {
+			return done[i].first < done[j].first
+		}
+		return done[i].change.Point < done[j].change.Point
+	})
+	changes := make([]Change, 0, len(done))
+	for _, r := range done {
+		c := r.change
+		c.Where = fmt.Sprintf("slot %d", r.first)
+		if r.last > r.first {
+			c.Where = fmt.Sprintf("slot %d..%d", r.first, r.last)
+		}
+		changes = append(changes, c)
+	}
+	return changes
+}
+
+// dutyRules returns the actions of the rules depending on the duties by
+// slot, the first rule of the max level of each slot.
+func dutyRules(s *Slots) map[string]Rule {
+	rules := make(map[string]Rule)
+	for _, r := range s.Rules {
+		if !DependsOnDuties(r.Slot) {
+			continue
+		}
+		if old, ok := rules[r.Slot]; !ok || r.Level > old.Level {
+			rules[r.Slot] = r
+		}
+	}
+	return rules
+}
+
+func diffDutyRules(sa, sb *Slots) []Change {
+	ra, rb := dutyRules(sa), dutyRules(sb)
+	slots := make([]string, 0)
+	for slot := range ra {
+		slots = append(slots, slot)
+	}
+	for slot := range rb {
+		if _, ok := ra[slot]; !ok {
+			slots = append(slots, slot)
+		}
+	}
+	sort.Strings(slots)
+	changes := make([]Change, 0)
+	for _, slot := range slots {
+		a, okA := ra[slot]
+		b, okB := rb[slot]
+		if okA && okB && a.Level != b.Level {
+			changes = append(changes, Change{Where: slot, Point: "level", From: strconv.Itoa(a.Level), To: strconv.Itoa(b.Level)})
+		}
+		for _, point := range unionPoints(a.Actions, b.Actions) {
+			if a.Actions[point] != b.Actions[point] {
+				changes = append(changes, Change{Where: slot, Point: point, From: a.Actions[point], To: b.Actions[point]})
+			}
+		}
+	}
+	return changes
+}
+
+func diffValidators(a, b []types.ValidatorStrategy) []Change {
+	rangeOf := func(validators []types.ValidatorStrategy) map[int]string {
+		windows := make(map[int][]string)
+		for _, v := range validators {
+			windows[v.ValidatorIndex] = append(windows[v.ValidatorIndex], fmt.Sprintf("%d..%d", v.AttackerStartSlot, v.AttackerEndSlot))
+		}
+		// a validator listed several times is an attacker in all its windows.
+		ranges := make(map[int]string)
+		for idx, w := range windows {
+			sort.Strings(w)
+			ranges[idx] = strings.Join(w, ",")
+		}
+		return ranges
+	}
+	ra, rb := rangeOf(a), rangeOf(b)
+	indexes := make([]int, 0)
+	for idx := range ra {
+		indexes = append(indexes, idx)
+	}
+	for idx := range rb {
+		if _, ok := ra[idx]; !ok {
+			indexes = append(indexes, idx)
+		}
+	}
+	sort.Ints(indexes)
+	changes := make([]Change, 0)
+	for _, idx := range indexes {
+		if ra[idx] != rb[idx] {
+			changes = append(changes, Change{Where: fmt.Sprintf("validator %d", idx), From: ra[idx], To: rb[idx]})
+		}
+	}
+	return changes
+}
+
+func diffConditional(a, b []types.ConditionalStrategy) []Change {
+	byName := func(blocks []types.ConditionalStrategy) map[string]string {
+		m := make(map[string]string)
+		for _, c := range blocks {
+			d, _ := json.Marshal(c)
+			m[c.Name] = string(d)
+		}
+		return m
+	}
+	ma, mb := byName(a), byName(b)
+	names := make([]string, 0)
+	for name := range ma {
+		names = append(names, name)
+	}
+	for name := range mb {
+		if _, ok := ma[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	changes := make([]Change, 0)
+	for _, name := range names {
+		if ma[name] != mb[name] {
+			changes = append(changes, Change{Where: fmt.Sprintf("conditional %s", name), From: ma[name], To: mb[name]})
+		}
+	}
+	return changes
+}
+
+func unionPoints(a, b map[string]string) []string {
+	points := make([]string, 0, len(a)+len(b))
+	for p := range a {
+		points = append(points, p)
+	}
+	for p := range b {
+		if _, ok := a[p]; !ok {
+			points = append(points, p)
+		}
+	}
+	sort.Strings(points)
+	return points
+}
+
+// SlotBounds returns the first and the last numeric rule slot of the strategies.
+func SlotBounds(strategies ...types.Strategy) (int64, int64, bool) {
+	var first, last int64
+	found := false
+	for _, s := range strategies {
+		for _, rule := range s.Slots {
+			n, err := strconv.ParseInt(rule.Slot, 10, 64)
+			if err != nil {
+				continue
+			}
+			if !found || n < first {
+				first = n
+			}
+			if !found || n > last {
+				last = n
+			}
+			found = true
+		}
+	}
+	return first, last, found
+}
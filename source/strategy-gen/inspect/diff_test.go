@@ -0,0 +1,75 @@
+package inspect
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/tsinghua-cel/strategy-gen/types"
+)
+
+func TestDiff(t *testing.T) {
+	a := types.Strategy{
+		Validators: []types.ValidatorStrategy{
+			{ValidatorIndex: 0, AttackerStartSlot: 0, AttackerEndSlot: 100},
+			{ValidatorIndex: 1, AttackerStartSlot: 0, AttackerEndSlot: 100},
+		},
+		Slots: []types.SlotStrategy{
+			rule("every", 0, "BlockBeforeBroadCast", "null"),
+			rule("5", 1, "BlockBeforeBroadCast", "return"),
+			rule("attackerSlot", 2, "AttestBeforeSign", "voteParentOfHead"),
+		},
+		Conditional: []types.ConditionalStrategy{{Name: "sandwich", Epochs: 1}, {Name: "stale", Epochs: 1}},
+	}
+	b := types.Strategy{
+		AllowSlashable: true,
+		Validators: []types.ValidatorStrategy{
+			{ValidatorIndex: 0, AttackerStartSlot: 0, AttackerEndSlot: 100},
+			{ValidatorIndex: 2, AttackerStartSlot: 0, AttackerEndSlot: 50},
+			{ValidatorIndex: 2, AttackerStartSlot: 60, AttackerEndSlot: 100},
+		},
+		Slots: []types.SlotStrategy{
+			rule("period:1:0:0:6", 0, "BlockBeforeBroadCast", "null"),
+			rule("period:2:0:0:6", 1, "BlockBeforeBroadCast", "return"),
+			rule("attackerSlot", 3, "AttestBeforeSign", "voteParentOfHead", "BlockBeforeSign", "null"),
+			rule("lastAttackerSlotInCurrentEpoch", 4, "BlockBeforeBroadCast", "return"),
+		},
+		Conditional: []types.ConditionalStrategy{{Name: "sandwich", Epochs: 2}, {Name: "boost", Epochs: 1}},
+	}
+	want := []Change{
+		{Where: "validator 1", From: "0..100"},
+		{Where: "validator 2", To: "0..50,60..100"},
+		{Where: "allow_slashable", From: "false", To: "true"},
+		// the slots resolved without the duties.
+		{Where: "slot 0", Point: "BlockBeforeBroadCast", From: "null", To: "return"},
+		{Where: "slot 2", Point: "BlockBeforeBroadCast", From: "null", To: "return"},
+		{Where: "slot 4", Point: "BlockBeforeBroadCast", From: "null", To: "return"},
+		{Where: "slot 5", Point: "BlockBeforeBroadCast", From: "return", To: "null"},
+		{Where: "slot 6", Point: "BlockBeforeBroadCast", From: "null", To: "return"},
+		{Where: "slot 7..9", Point: "BlockBeforeBroadCast", From: "null"},
+		// the rules depending on the duties by their slot.
+		{Where: "attackerSlot", Point: "level", From: "2", To: "3"},
+		{Where: "attackerSlot", Point: "BlockBeforeSign", To: "null"},
+		{Where: "lastAttackerSlotInCurrentEpoch", Point: "BlockBeforeBroadCast", To: "return"},
+		{Where: "conditional boost", To: `{"name":"boost","triggers":null,"epochs":1,"slots":null}`},
+		{Where: "conditional sandwich", From: `{"name":"sandwich","triggers":null,"epochs":1,"slots":null}`, To: `{"name":"sandwich","triggers":null,"epochs":2,"slots":null}`},
+		{Where: "conditional stale", From: `{"name":"stale","triggers":null,"epochs":1,"slots":null}`},
+	}
+	got := Diff(a, b, 0, 9, 4)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("diff\n%v\nwant\n%v", got, want)
+	}
+	if changes := Diff(a, a, 0, 9, 4); len(changes) != 0 {
+		t.Errorf("a strategy differs from itself: %v", changes)
+	}
+}
+
+func TestSlotBounds(t *testing.T) {
+	a := types.Strategy{Slots: []types.SlotStrategy{rule("every", 0), rule("12", 0), rule("4", 0)}}
+	b := types.Strategy{Slots: []types.SlotStrategy{rule("20", 0), rule("attackerSlot", 0)}}
+	if first, last, ok := SlotBounds(a, b); !ok || first != 4 || last != 20 {
+		t.Errorf("bounds %d..%d %v, want 4..20", first, last, ok)
+	}
+	if _, _, ok := SlotBounds(types.Strategy{Slots: []types.SlotStrategy{rule("every", 0)}}); ok {
+		t.Error("found the bounds of function slots")
+	}
+}
@@ -0,0 +1,185 @@
+package inspect
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/tsinghua-cel/strategy-gen/catalogue"
+	"github.com/tsinghua-cel/strategy-gen/types"
+)
+
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// Issue is a problem found in a strategy.
+type Issue struct {
+	Severity string `json:"severity"`
+	Where    string `json:"where"`
+	Message  string `json:"message"`
+}
+
+// LintOptions are the chain the strategy is linted for.
+type LintOptions struct {
+	// Validators is the size of the validator set, 0 skips the range check.
+	Validators    int
+	SlotsPerEpoch int64
+}
+
+type linter struct {
+	c      catalogue.Catalogue
+	opts   LintOptions
+	issues []Issue
+}
+
+func (l *linter) add(severity, where, format string, args ...interface{}) {
+	l.issues = append(l.issues, Issue{Severity: severity, Where: where, Message: fmt.Sprintf(format, args...)})
+}
+
+// Lint checks the slots, actions and validators of the strategy against the catalogue.
+func Lint(strategy types.Strategy, c catalogue.Catalogue, opts LintOptions) []Issue {
+	l := &linter{c: c, opts: opts}
+	l.validators(strategy.Validators)
+	rules := NewSlots(strategy, opts.SlotsPerEpoch, nil).Rules
+	l.rules(strategy, rules)
+	for i, cond := range strategy.Conditional {
+		condRules := make([]Rule, 0, len(cond.Slots))
+		for j, rule := range cond.Slots {
+			condRules = append(condRules, Rule{SlotStrategy: rule, Where: fmt.Sprintf("conditional[%d].slots[%d]", i, j)})
+		}
+		l.rules(strategy, condRules)
+	}
+	return l.issues
+}
+
+func (l *linter) validators(validators []types.ValidatorStrategy) {
+	if len(validators) == 0 {
+		l.add(SeverityWarning, "validator", "no attacker validators, the actions never run")
+	}
+	seen := make(map[int][]int)
+	for i, v := range validators {
+		where := fmt.Sprintf("validator[%d]", i)
+		if v.ValidatorIndex < 0 || (l.opts.Validators > 0 && v.ValidatorIndex >= l.opts.Validators) {
+			l.add(SeverityError, where, "validator index %d is outside the validator set", v.ValidatorIndex)
+		}
+		if v.AttackerStartSlot > v.AttackerEndSlot {
+			l.add(SeverityError, where, "attacker start slot %d is after the end slot %d", v.AttackerStartSlot, v.AttackerEndSlot)
+		}
+		// a validator can be listed again with another slot window.
+		for _, j := range seen[v.ValidatorIndex] {
+			o := validators[j]
+			if v.AttackerStartSlot <= o.AttackerEndSlot && o.AttackerStartSlot <= v.AttackerEndSlot {
+				l.add(SeverityWarning, where, "validator index %d overlaps the slots of validator[%d]", v.ValidatorIndex, j)
+				break
+			}
+		}
+		seen[v.ValidatorIndex] = append(seen[v.ValidatorIndex], i)
+	}
+}
+
+func (l *linter) rules(strategy types.Strategy, rules []Rule) {
+	for _, r := range rules {
+		if err := CheckSlot(r.Slot); err != nil {
+			l.add(SeverityError, r.Where, "%v", err)
+		} else if NeverMatches(r.Slot) {
+			l.add(SeverityWarning, r.Where, "function slot %s never matches the slot it is resolved for", r.Slot)
+		}
+		if len(r.Actions) == 0 {
+			l.add(SeverityWarning, r.Where, "rule has no actions")
+		}
+		for point, action := range r.Actions {
+			l.action(strategy, r.Where+" "+point, point, action)
+		}
+	}
+	for j, r := range rules {
+		for i, by := range rules {
+			if i != j && (by.Level > r.Level || (by.Level == r.Level && i < j)) && l.covers(by.Slot, r.Slot) {
+				l.add(SeverityWarning, r.Where, "rule of slot %s level %d is unreachable, %s of slot %s level %d always wins",
+					r.Slot, r.Level, by.Where, by.Slot, by.Level)
+				break
+			}
+		}
+	}
+}
+
+func (l *linter) action(strategy types.Strategy, where, point, action string) {
+	known := false
+	for _, p := range l.c.Points {
+		if p.Name == point {
+			known = true
+		}
+	}
+	if !known {
+		l.add(SeverityError, where, "unknown action point %s", point)
+		return
+	}
+	parts := strings.Split(action, ":")
+	var spec *catalogue.Action
+	for i := range l.c.Actions {
+		if l.c.Actions[i].Name == parts[0] {
+			spec = &l.c.Actions[i]
+		}
+	}
+	if spec == nil {
+		l.add(SeverityError, where, "unknown action %s", parts[0])
+		return
+	}
+	if !spec.ValidAt(point) {
+		l.add(SeverityError, where, "action %s is not valid at %s", spec.Name, point)
+	}
+	params := parts[1:]
+	if len(params) > len(spec.Params) {
+		l.add(SeverityError, where, "action %s takes %d parameters, got %d", spec.Name, len(spec.Params), len(params))
+	}
+	for i, p := range params {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			l.add(SeverityError, where, "parameter %d of action %s is not an integer: %s", i+1, spec.Name, p)
+		} else if i < len(spec.Params) && !spec.Params[i].Valid(n) {
+			l.add(SeverityError, where, "parameter %d of action %s is %d, valid values are %s",
+				i+1, spec.Name, n, spec.Params[i].ValidRange())
+		}
+	}
+	if spec.Slashable && !strategy.AllowSlashable {
+		l.add(SeverityError, where, "slashable action %s needs allow_slashable", spec.Name)
+	}
+}
+
+// covers returns true if the rule slot by matches every slot the rule slot r matches.
+func (l *linter) covers(by, r string) bool {
+	if CheckSlot(by) != nil || CheckSlot(r) != nil {
+		return false
+	}
+	if by == "every" || by == r {
+		return true
+	}
+	spe := l.opts.SlotsPerEpoch
+	if n, err := strconv.ParseInt(r, 10, 64); err == nil {
+		s := &Slots{SlotsPerEpoch: spe, Proposer: func(int64) (int, bool) { return 0, false }}
+		match, known := s.Match(by, n)
+		return match && known
+	}
+	byName, byParams := parseSlot(by)
+	rName, rParams := parseSlot(r)
+	switch {
+	case byName == "period" && rName == "period":
+		n, m := byParams[0], rParams[0]
+		k, j := 0, 0
+		if len(byParams) > 1 {
+			k = byParams[1]
+		}
+		if len(rParams) > 1 {
+			j = rParams[1]
+		}
+		// the slots of by must contain the slots of r.
+		if len(byParams) == 4 && (len(rParams) < 4 || rParams[2] < byParams[2] || rParams[3] > byParams[3]) {
+			return false
+		}
+		return m%n == 0 && j%n == k
+	case byName == "attackerSlot" && rName == "lastAttackerSlotInCurrentEpoch":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,152 @@
+package inspect
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/tsinghua-cel/strategy-gen/catalogue"
+	"github.com/tsinghua-cel/strategy-gen/types"
+)
+
+var testValidators = []types.ValidatorStrategy{{ValidatorIndex: 0, AttackerStartSlot: 0, AttackerEndSlot: 100}}
+
+func rule(slot string, level int, actions ...string) types.SlotStrategy {
+	r := types.SlotStrategy{Slot: slot, Level: level, Actions: make(map[string]string)}
+	for i := 0; i+1 < len(actions); i += 2 {
+		r.Actions[actions[i]] = actions[i+1]
+	}
+	return r
+}
+
+func TestLint(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy types.Strategy
+		want     []Issue // the messages only need to contain the wanted ones
+	}{
+		{
+			name: "clean",
+			strategy: types.Strategy{Validators: testValidators, Slots: []types.SlotStrategy{
+				rule("every", 0, "BlockBeforeBroadCast", "null"),
+				rule("period:4:1:8:23", 1, "BlockBeforeBroadCast", "delayWithSecond:24"),
+				rule("attackerSlot", 2, "AttestBeforeSign", "voteHeadOfSlot:2"),
+				rule("12", 3, "BlockBeforeBroadCast", "delayToNextNEpochStart"),
+			}},
+		},
+		{
+			name: "invalid slots",
+			strategy: types.Strategy{Validators: testValidators, Slots: []types.SlotStrategy{
+				rule("noSuchSlot", 0, "BlockBeforeBroadCast", "null"),
+				rule("period:4:4", 0, "BlockBeforeBroadCast", "null"),
+				rule("period:4:1:9", 0, "BlockBeforeBroadCast", "null"),
+				rule("period:4:1:9:8", 0, "BlockBeforeBroadCast", "null"),
+				rule("attackerSlot:1", 0, "BlockBeforeBroadCast", "null"),
+				rule("firstSlotInNextEpoch", 0, "BlockBeforeBroadCast", "null"),
+				rule("13", 0),
+			}},
+			want: []Issue{
+				{SeverityError, "slots[0]", "unknown function slot noSuchSlot"},
+				{SeverityError, "slots[1]", "invalid offset"},
+				{SeverityError, "slots[2]", "invalid period"},
+				{SeverityError, "slots[3]", "invalid slots"},
+				{SeverityError, "slots[4]", "takes no parameters"},
+				{SeverityWarning, "slots[5]", "never matches"},
+				{SeverityWarning, "slots[6]", "rule has no actions"},
+			},
+		},
+		{
+			name: "invalid actions",
+			strategy: types.Strategy{Validators: testValidators, Slots: []types.SlotStrategy{
+				rule("1", 0, "NoSuchPoint", "null"),
+				rule("2", 0, "BlockBeforeBroadCast", "noSuchAction"),
+				rule("3", 0, "BlockBeforeBroadCast", "voteHeadOfSlot:1"),
+				rule("4", 0, "BlockBeforeBroadCast", "delayWithSecond:1:2"),
+				rule("5", 0, "BlockBeforeBroadCast", "delayWithSecond:x"),
+				rule("6", 0, "BlockBeforeBroadCast", "delayWithSecond:-1"),
+				rule("7", 0, "BlockAfterSign", "doubleProposal:2"),
+			}},
+			want: []Issue{
+				{SeverityError, "slots[0] NoSuchPoint", "unknown action point NoSuchPoint"},
+				{SeverityError, "slots[1] BlockBeforeBroadCast", "unknown action noSuchAction"},
+				{SeverityError, "slots[2] BlockBeforeBroadCast", "voteHeadOfSlot is not valid at BlockBeforeBroadCast"},
+				{SeverityError, "slots[3] BlockBeforeBroadCast", "takes 1 parameters, got 2"},
+				{SeverityError, "slots[4] BlockBeforeBroadCast", "not an integer: x"},
+				{SeverityError, "slots[5] BlockBeforeBroadCast", "is -1, valid values are at least 0"},
+				{SeverityError, "slots[6] BlockAfterSign", "is 2, valid values are 0 to 1"},
+				{SeverityError, "slots[6] BlockAfterSign", "doubleProposal needs allow_slashable"},
+			},
+		},
+		{
+			name: "unreachable rules",
+			strategy: types.Strategy{Validators: testValidators, Slots: []types.SlotStrategy{
+				rule("period:4:1", 0, "BlockBeforeBroadCast", "null"),
+				rule("every", 1, "BlockBeforeBroadCast", "null"),
+				rule("period:8:3:8:23", 2, "BlockBeforeBroadCast", "null"),
+				rule("period:2:1", 3, "BlockBeforeBroadCast", "null"),
+				rule("period:4:1:0:50", 4, "BlockBeforeBroadCast", "null"),
+				rule("period:8:1:10:23", 5, "BlockBeforeBroadCast", "null"),
+				rule("lastAttackerSlotInCurrentEpoch", 6, "BlockBeforeBroadCast", "null"),
+				rule("attackerSlot", 7, "BlockBeforeBroadCast", "null"),
+			}},
+			want: []Issue{
+				{SeverityWarning, "slots[0]", "period:4:1 level 0 is unreachable, slots[1] of slot every"},
+				{SeverityWarning, "slots[2]", "period:8:3:8:23 level 2 is unreachable, slots[3] of slot period:2:1"},
+				{SeverityWarning, "slots[6]", "lastAttackerSlotInCurrentEpoch level 6 is unreachable, slots[7]"},
+			},
+		},
+		{
+			name: "validators",
+			strategy: types.Strategy{
+				Validators: []types.ValidatorStrategy{
+					{ValidatorIndex: 64, AttackerStartSlot: 0, AttackerEndSlot: 10},
+					{ValidatorIndex: 1, AttackerStartSlot: 10, AttackerEndSlot: 5},
+					{ValidatorIndex: 2, AttackerStartSlot: 0, AttackerEndSlot: 10},
+					{ValidatorIndex: 2, AttackerStartSlot: 11, AttackerEndSlot: 20},
+					{ValidatorIndex: 2, AttackerStartSlot: 20, AttackerEndSlot: 30},
+				},
+				Slots: []types.SlotStrategy{rule("every", 0, "BlockBeforeBroadCast", "null")},
+			},
+			want: []Issue{
+				{SeverityError, "validator[0]", "validator index 64 is outside the validator set"},
+				{SeverityError, "validator[1]", "start slot 10 is after the end slot 5"},
+				{SeverityWarning, "validator[4]", "overlaps the slots of validator[3]"},
+			},
+		},
+		{
+			name:     "no validator",
+			strategy: types.Strategy{Slots: []types.SlotStrategy{rule("every", 0, "BlockBeforeBroadCast", "null")}},
+			want:     []Issue{{SeverityWarning, "validator", "no attacker validators"}},
+		},
+		{
+			name: "conditional rules",
+			strategy: types.Strategy{
+				Validators: testValidators,
+				Conditional: []types.ConditionalStrategy{{
+					Name:  "sandwich",
+					Slots: []types.SlotStrategy{rule("every", 0, "BlockBeforeBroadCast", "noSuchAction")},
+				}},
+			},
+			want: []Issue{{SeverityError, "conditional[0].slots[0] BlockBeforeBroadCast", "unknown action noSuchAction"}},
+		},
+	}
+	opts := LintOptions{Validators: 64, SlotsPerEpoch: 4}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issues := Lint(tt.strategy, catalogue.Embedded(), opts)
+			if len(issues) != len(tt.want) {
+				t.Errorf("got %d issues %v, want %d", len(issues), issues, len(tt.want))
+			}
+			for _, want := range tt.want {
+				found := false
+				for _, issue := range issues {
+					if issue.Severity == want.Severity && issue.Where == want.Where && strings.Contains(issue.Message, want.Message) {
+						found = true
+					}
+				}
+				if !found {
+					t.Errorf("missing %s %s: %s in %v", want.Severity, want.Where, want.Message, issues)
+				}
+			}
+		})
+	}
+}
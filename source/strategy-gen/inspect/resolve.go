@@ -0,0 +1,193 @@
+package inspect
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/tsinghua-cel/strategy-gen/catalogue"
+	"github.com/tsinghua-cel/strategy-gen/types"
+)
+
+// Rule is a slot rule of a strategy, Where is its position in the strategy.
+type Rule struct {
+	types.SlotStrategy
+	Where string
+}
+
+// Slots resolves the slot rules of a strategy the way the attacker service does.
+type Slots struct {
+	Rules         []Rule
+	Validators    []types.ValidatorStrategy
+	SlotsPerEpoch int64
+	// Proposer returns the proposer of the slot, false if the duty is unknown.
+	Proposer func(slot int64) (int, bool)
+}
+
+// NewSlots returns the resolver of the slot rules of the strategy, the
+// conditional rules are not included. A nil proposer means the duties are unknown.
+func NewSlots(strategy types.Strategy, slotsPerEpoch int64, proposer func(slot int64) (int, bool)) *Slots {
+	if proposer == nil {
+		proposer = func(int64) (int, bool) { return 0, false }
+	}
+	s := &Slots{
+		Validators:    strategy.Validators,
+		SlotsPerEpoch: slotsPerEpoch,
+		Proposer:      proposer,
+	}
+	for i, rule := range strategy.Slots {
+		s.Rules = append(s.Rules, Rule{SlotStrategy: rule, Where: fmt.Sprintf("slots[%d]", i)})
+	}
+	return s
+}
+
+// IsAttacker returns true if the validator is an attacker at the slot.
+func (s *Slots) IsAttacker(validator int, slot int64) bool {
+	for _, v := range s.Validators {
+		if v.ValidatorIndex == validator && slot >= int64(v.AttackerStartSlot) && slot <= int64(v.AttackerEndSlot) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseSlot splits a function slot like period:n:k:from:to into its name and parameters.
+func parseSlot(slot string) (string, []int) {
+	parts := strings.Split(slot, ":")
+	params := make([]int, 0, len(parts)-1)
+	for _, p := range parts[1:] {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			n = -1
+		}
+		params = append(params, n)
+	}
+	return parts[0], params
+}
+
+// CheckSlot returns an error if the slot of a rule is neither a number nor a
+// function slot of the catalogue.
+func CheckSlot(slot string) error {
+	if _, err := strconv.ParseInt(slot, 10, 64); err == nil {
+		return nil
+	}
+	if !catalogue.Current().KnownSlotFunction(slot) {
+		return fmt.Errorf("unknown function slot %s", slot)
+	}
+	name, params := parseSlot(slot)
+	if name == "period" {
+		if len(params) == 0 || len(params) == 3 || len(params) > 4 || params[0] <= 0 {
+			return fmt.Errorf("invalid period of function slot %s", slot)
+		}
+		if len(params) > 1 && (params[1] < 0 || params[1] >= params[0]) {
+			return fmt.Errorf("invalid offset of function slot %s", slot)
+		}
+		if len(params) == 4 && (params[2] < 0 || params[2] > params[3]) {
+			return fmt.Errorf("invalid slots of function slot %s", slot)
+		}
+	} else if len(params) > 0 {
+		return fmt.Errorf("function slot %s takes no parameters", slot)
+	}
+	return nil
+}
+
+// DependsOnDuties returns true if the function slot needs the proposer duties.
+func DependsOnDuties(slot string) bool {
+	switch slot {
+	case "attackerSlot", "lastAttackerSlotInCurrentEpoch", "lastAttackerSlotInNextEpoch":
+		return true
+	}
+	return false
+}
+
+// NeverMatches returns true if the function slot never returns the slot
+// itself, the next epoch functions are always after it.
+func NeverMatches(slot string) bool {
+	switch slot {
+	case "firstSlotInNextEpoch", "lastSlotInNextEpoch", "lastAttackerSlotInNextEpoch":
+		return true
+	}
+	return false
+}
+
+// Match returns true if the rule slot matches the slot, known is false if it
+// depends on a duty that is unknown.
+func (s *Slots) Match(rule string, slot int64) (match bool, known bool) {
+	if n, err := strconv.ParseInt(rule, 10, 64); err == nil {
+		return n == slot, true
+	}
+	spe := s.SlotsPerEpoch
+	epoch := slot / spe
+	name, params := parseSlot(rule)
+	switch name {
+	case "every":
+		return true, true
+	case "period":
+		if CheckSlot(rule) != nil {
+			return false, true
+		}
+		offset := 0
+		if len(params) > 1 {
+			offset = params[1]
+		}
+		if len(params) == 4 && (slot < int64(params[2]) || slot > int64(params[3])) {
+			return false, true
+		}
+		return slot%int64(params[0]) == int64(offset), true
+	case "firstSlotInCurrentEpoch":
+		return slot == epoch*spe, true
+	case "lastSlotInCurrentEpoch":
+		return slot == (epoch+1)*spe-1, true
+	case "attackerSlot":
+		proposer, ok := s.Proposer(slot)
+		return ok && s.IsAttacker(proposer, slot), ok
+	case "lastAttackerSlotInCurrentEpoch":
+		last, ok := s.lastAttackerSlot(epoch)
+		return ok && last == slot, ok
+	}
+	return false, true
+}
+
+func (s *Slots) lastAttackerSlot(epoch int64) (int64, bool) {
+	spe := s.SlotsPerEpoch
+	for slot := (epoch+1)*spe - 1; slot >= epoch*spe; slot-- {
+		proposer, ok := s.Proposer(slot)
+		if !ok {
+			return -1, false
+		}
+		if s.IsAttacker(proposer, slot) {
+			return slot, true
+		}
+	}
+	return -1, true
+}
+
+// Resolve returns the rule of the slot, the first one of the max level. The
+// rules that depend on unknown duties are skipped.
+func (s *Slots) Resolve(slot int64) (Rule, bool) {
+	var (
+		found Rule
+		ok    bool
+	)
+	for _, r := range s.Rules {
+		match, known := s.Match(r.Slot, slot)
+		if known && match && (!ok || r.Level > found.Level) {
+			found, ok = r, true
+		}
+	}
+	return found, ok
+}
+
+// Unresolved returns the rules that depend on unknown duties in the slots.
+func (s *Slots) Unresolved(from, to int64) []Rule {
+	unresolved := make([]Rule, 0)
+	for _, r := range s.Rules {
+		for slot := from; slot <= to; slot++ {
+			if _, known := s.Match(r.Slot, slot); !known {
+				unresolved = append(unresolved, r)
+				break
+			}
+		}
+	}
+	return unresolved
+}
@@ -0,0 +1,96 @@
+package inspect
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/tsinghua-cel/strategy-gen/catalogue"
+	"github.com/tsinghua-cel/strategy-gen/types"
+)
+
+// Timeline is the grid of the slots and the action points of a strategy.
+type Timeline struct {
+	Points []string      `json:"points"`
+	Rows   []TimelineRow `json:"rows"`
+	// Unresolved are the rules that depend on the unknown duties, they are not in the grid.
+	Unresolved []string `json:"unresolved,omitempty"`
+}
+
+type TimelineRow struct {
+	Slot  int64 `json:"slot"`
+	Epoch int64 `json:"epoch"`
+	// Proposer is the proposer index, -1 if the duty is unknown.
+	Proposer         int      `json:"proposer"`
+	AttackerProposer bool     `json:"attacker_proposer"`
+	Rule             string   `json:"rule,omitempty"`
+	Actions          []string `json:"actions"`
+}
+
+// NewTimeline resolves the action of every point of the strategy in the slots from..to.
+func NewTimeline(strategy types.Strategy, from, to int64, slotsPerEpoch int64, proposer func(slot int64) (int, bool)) Timeline {
+	s := NewSlots(strategy, slotsPerEpoch, proposer)
+	t := Timeline{Points: usedPoints(strategy)}
+	for slot := from; slot <= to; slot++ {
+		row := TimelineRow{Slot: slot, Epoch: slot / slotsPerEpoch, Proposer: -1}
+		if p, ok := s.Proposer(slot); ok {
+			row.Proposer = p
+			row.AttackerProposer = s.IsAttacker(p, slot)
+		}
+		rule, ok := s.Resolve(slot)
+		if ok {
+			row.Rule = fmt.Sprintf("%s %s", rule.Where, rule.Slot)
+		}
+		for _, point := range t.Points {
+			row.Actions = append(row.Actions, rule.Actions[point])
+		}
+		t.Rows = append(t.Rows, row)
+	}
+	for _, r := range s.Unresolved(from, to) {
+		t.Unresolved = append(t.Unresolved, fmt.Sprintf("%s %s level %d", r.Where, r.Slot, r.Level))
+	}
+	return t
+}
+
+// usedPoints returns the points of the strategy rules in the catalogue order.
+func usedPoints(strategy types.Strategy) []string {
+	used := make(map[string]bool)
+	for _, rule := range strategy.Slots {
+		for point := range rule.Actions {
+			used[point] = true
+		}
+	}
+	points := make([]string, 0, len(used))
+	for _, p := range catalogue.Current().Points {
+		if used[p.Name] {
+			points = append(points, p.Name)
+			delete(used, p.Name)
+		}
+	}
+	unknown := make([]string, 0, len(used))
+	for p := range used {
+		unknown = append(unknown, p)
+	}
+	sort.Strings(unknown)
+	return append(points, unknown...)
+}
+
+// ParseRange parses x..y or a single x.
+func ParseRange(s string) (int64, int64, error) {
+	parts := strings.SplitN(s, "..", 2)
+	from, err := strconv.ParseInt(strings.TrimSpace(parts[0]), 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid range %s", s)
+	}
+	to := from
+	if len(parts) == 2 {
+		if to, err = strconv.ParseInt(strings.TrimSpace(parts[1]), 10, 64); err != nil {
+			return 0, 0, fmt.Errorf("invalid range %s", s)
+		}
+	}
+	if to < from {
+		return 0, 0, fmt.Errorf("invalid range %s, the end is before the start", s)
+	}
+	return from, to, nil
+}
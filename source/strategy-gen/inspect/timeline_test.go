@@ -0,0 +1,114 @@
+package inspect
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/tsinghua-cel/strategy-gen/types"
+)
+
+func TestNewTimeline(t *testing.T) {
+	strategy := types.Strategy{
+		Validators: []types.ValidatorStrategy{{ValidatorIndex: 1, AttackerStartSlot: 0, AttackerEndSlot: 5}},
+		Slots: []types.SlotStrategy{
+			rule("every", 0, "AttestBeforeSign", "null", "BlockBeforeBroadCast", "null"),
+			rule("period:3:1", 1, "BlockBeforeBroadCast", "delayWithSecond:2"),
+			rule("period:2:0:4:8", 2, "BlockBeforeBroadCast", "return"),
+			rule("5", 3, "NoSuchPoint", "null"),
+			rule("attackerSlot", 4, "BlockBeforeBroadCast", "delayToNextSlot"),
+		},
+	}
+	// validator 1 proposes the odd slots and is an attacker up to slot 5.
+	proposer := func(slot int64) (int, bool) {
+		if slot > 7 {
+			return 0, false
+		}
+		return int(slot % 2), true
+	}
+	tl := NewTimeline(strategy, 0, 9, 4, proposer)
+	if want := []string{"BlockBeforeBroadCast", "AttestBeforeSign", "NoSuchPoint"}; !reflect.DeepEqual(tl.Points, want) {
+		t.Errorf("points %v, want %v", tl.Points, want)
+	}
+	want := []struct {
+		proposer int
+		attacker bool
+		rule     string
+		actions  []string
+	}{
+		{0, false, "slots[0] every", []string{"null", "null", ""}},
+		{1, true, "slots[4] attackerSlot", []string{"delayToNextSlot", "", ""}},
+		{0, false, "slots[0] every", []string{"null", "null", ""}},
+		{1, true, "slots[4] attackerSlot", []string{"delayToNextSlot", "", ""}},
+		{0, false, "slots[2] period:2:0:4:8", []string{"return", "", ""}},
+		{1, true, "slots[4] attackerSlot", []string{"delayToNextSlot", "", ""}},
+		{0, false, "slots[2] period:2:0:4:8", []string{"return", "", ""}},
+		// the validator is no longer an attacker.
+		{1, false, "slots[1] period:3:1", []string{"delayWithSecond:2", "", ""}},
+		// the attacker slot rule is skipped without the duty.
+		{-1, false, "slots[2] period:2:0:4:8", []string{"return", "", ""}},
+		{-1, false, "slots[0] every", []string{"null", "null", ""}},
+	}
+	if len(tl.Rows) != len(want) {
+		t.Fatalf("got %d rows, want %d", len(tl.Rows), len(want))
+	}
+	for i, row := range tl.Rows {
+		w := want[i]
+		if row.Slot != int64(i) || row.Epoch != int64(i/4) {
+			t.Errorf("row %d is slot %d epoch %d", i, row.Slot, row.Epoch)
+		}
+		if row.Proposer != w.proposer || row.AttackerProposer != w.attacker || row.Rule != w.rule || !reflect.DeepEqual(row.Actions, w.actions) {
+			t.Errorf("slot %d: got %d %v %q %v, want %d %v %q %v", row.Slot,
+				row.Proposer, row.AttackerProposer, row.Rule, row.Actions, w.proposer, w.attacker, w.rule, w.actions)
+		}
+	}
+	if want := []string{"slots[4] attackerSlot level 4"}; !reflect.DeepEqual(tl.Unresolved, want) {
+		t.Errorf("unresolved %v, want %v", tl.Unresolved, want)
+	}
+}
+
+func TestNewTimelineWithoutDuties(t *testing.T) {
+	strategy := types.Strategy{Slots: []types.SlotStrategy{
+		rule("every", 0, "BlockBeforeBroadCast", "null"),
+		rule("lastAttackerSlotInCurrentEpoch", 1, "BlockBeforeBroadCast", "return"),
+		rule("firstSlotInCurrentEpoch", 2, "BlockBeforeBroadCast", "delayToNextSlot"),
+	}}
+	tl := NewTimeline(strategy, 4, 8, 4, nil)
+	rules := make([]string, 0, len(tl.Rows))
+	for _, row := range tl.Rows {
+		if row.Proposer != -1 {
+			t.Errorf("slot %d has proposer %d without the duties", row.Slot, row.Proposer)
+		}
+		rules = append(rules, row.Rule)
+	}
+	want := []string{"slots[2] firstSlotInCurrentEpoch", "slots[0] every", "slots[0] every", "slots[0] every", "slots[2] firstSlotInCurrentEpoch"}
+	if !reflect.DeepEqual(rules, want) {
+		t.Errorf("rules %v, want %v", rules, want)
+	}
+	if want := []string{"slots[1] lastAttackerSlotInCurrentEpoch level 1"}; !reflect.DeepEqual(tl.Unresolved, want) {
+		t.Errorf("unresolved %v, want %v", tl.Unresolved, want)
+	}
+}
+
+func TestParseRange(t *testing.T) {
+	tests := []struct {
+		in       string
+		from, to int64
+		wantErr  bool
+	}{
+		{in: "3", from: 3, to: 3},
+		{in: "0..1", from: 0, to: 1},
+		{in: " 2 .. 5 ", from: 2, to: 5},
+		{in: "5..2", wantErr: true},
+		{in: "x", wantErr: true},
+		{in: "1..", wantErr: true},
+		{in: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		from, to, err := ParseRange(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("range %q: err %v, want error %v", tt.in, err, tt.wantErr)
+		} else if !tt.wantErr && (from != tt.from || to != tt.to) {
+			t.Errorf("range %q is %d..%d, want %d..%d", tt.in, from, to, tt.from, tt.to)
+		}
+	}
+}
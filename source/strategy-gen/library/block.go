@@ -14,17 +14,17 @@ import (
 delay策略：blockdelay 到最后一个恶意节点出块的下一个slot；
 恶意节点的投票者 开始做恶，对投票进行delay，执行的策略和blockdelay一样。
 */
-func BlockStrategy(cur, end int, actions map[string]string) {
+func BlockStrategy(cur, end, secondsPerSlot int, actions map[string]string) {
 	point := pointset.GetPointByName("BlockBeforeBroadCast")
-	actions[point] = fmt.Sprintf("%s:%d", "delayWithSecond", (end+1-cur)*12)
+	actions[point] = fmt.Sprintf("%s:%d", "delayWithSecond", (end+1-cur)*secondsPerSlot)
 }
 
-func AttestStrategy(cur, end int, actions map[string]string) {
+func AttestStrategy(cur, end, secondsPerSlot int, actions map[string]string) {
 	point := pointset.GetPointByName("AttestBeforeBroadCast")
-	actions[point] = fmt.Sprintf("%s:%d", "delayWithSecond", (end+1-cur)*12)
+	actions[point] = fmt.Sprintf("%s:%d", "delayWithSecond", (end+1-cur)*secondsPerSlot)
 }
 
-func GenSlotStrategy(duties []utils.ProposerDuty) []types.SlotStrategy {
+func GenSlotStrategy(duties []utils.ProposerDuty, secondsPerSlot int) []types.SlotStrategy {
 	//begin, _ := strconv.Atoi(duties[0].Slot)
 	end, _ := strconv.Atoi(duties[len(duties)-1].Slot)
 	strategys := make([]types.SlotStrategy, 0)
@@ -36,8 +36,8 @@ func GenSlotStrategy(duties []utils.ProposerDuty) []types.SlotStrategy {
 			Level:   0,
 			Actions: make(map[string]string),
 		}
-		BlockStrategy(slot, end, strategy.Actions)
-		AttestStrategy(slot, end, strategy.Actions)
+		BlockStrategy(slot, end, secondsPerSlot, strategy.Actions)
+		AttestStrategy(slot, end, secondsPerSlot, strategy.Actions)
 		strategys = append(strategys, strategy)
 	}
 	return strategys
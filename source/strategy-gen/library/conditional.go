@@ -0,0 +1,36 @@
+package library
+
+import (
+	"math"
+
+	"github.com/tsinghua-cel/strategy-gen/pointset"
+	"github.com/tsinghua-cel/strategy-gen/types"
+)
+
+// ConditionalStrategy is the service side version of the runtime loop: the
+// validators up to maxValidatorIndex are attackers, and when the next epoch
+// has at least minConsecutive consecutive attacker proposers their blocks
+// and votes are delayed to the end of the epoch.
+func ConditionalStrategy(maxValidatorIndex int, minConsecutive int) types.Strategy {
+	actions := make(map[string]string)
+	actions[pointset.GetPointByName("BlockBeforeBroadCast")] = "delayToEpochEnd"
+	actions[pointset.GetPointByName("AttestBeforeBroadCast")] = "delayToEpochEnd"
+	return types.Strategy{
+		Slots:      make([]types.SlotStrategy, 0),
+		Validators: types.GetValidatorStrategy(0, maxValidatorIndex, 0, math.MaxInt32),
+		Conditional: []types.ConditionalStrategy{
+			{
+				Name: "consecutiveAttackerProposers",
+				Triggers: []types.Trigger{
+					{Type: "attackerProposers", Value: minConsecutive},
+				},
+				Slots: []types.SlotStrategy{
+					{
+						Slot:    "attackerSlot",
+						Actions: actions,
+					},
+				},
+			},
+		},
+	}
+}
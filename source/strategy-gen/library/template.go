@@ -0,0 +1,118 @@
+package library
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/tsinghua-cel/strategy-gen/types"
+	"github.com/tsinghua-cel/strategy-gen/utils"
+)
+
+// Params are the attacker and chain parameters a template generates for.
+type Params struct {
+	// MaxValidatorIndex is the last attacker validator, the validators from 0 to it are attackers.
+	MaxValidatorIndex int
+	SlotsPerEpoch     int
+	SecondsPerSlot    int
+}
+
+func (p Params) isAttacker(duty utils.ProposerDuty) bool {
+	idx, err := strconv.Atoi(duty.ValidatorIndex)
+	return err == nil && idx <= p.MaxValidatorIndex
+}
+
+// Template inspects the proposer duties of the next epoch and emits the
+// strategy of that epoch.
+type Template interface {
+	Name() string
+	Desc() string
+	// Generate returns the strategy for the duties, false if the duties
+	// have no chance for the attack.
+	Generate(p Params, duties []utils.ProposerDuty) (types.Strategy, bool)
+}
+
+var templates = make(map[string]Template)
+
+func init() {
+	RegisterTemplate(honestTemplate{})
+	RegisterTemplate(selfishTemplate{})
+	RegisterTemplate(exAnteTemplate{})
+	RegisterTemplate(sandwichTemplate{})
+	RegisterTemplate(balancingTemplate{})
+}
+
+// RegisterTemplate adds the template to the registry, a template with the same name is replaced.
+func RegisterTemplate(t Template) {
+	templates[t.Name()] = t
+}
+
+func GetTemplate(name string) (Template, bool) {
+	t, ok := templates[name]
+	return t, ok
+}
+
+// TemplateNames returns the names of the registered templates in order.
+func TemplateNames() []string {
+	names := make([]string, 0, len(templates))
+	for name := range templates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// slotRules collects the actions of the slots a template attacks.
+type slotRules map[int]map[string]string
+
+func (r slotRules) set(slot int, point string, action string) {
+	if r[slot] == nil {
+		r[slot] = make(map[string]string)
+	}
+	r[slot][point] = action
+}
+
+func (r slotRules) strategies() []types.SlotStrategy {
+	slots := make([]int, 0, len(r))
+	for slot := range r {
+		slots = append(slots, slot)
+	}
+	sort.Ints(slots)
+	strategies := make([]types.SlotStrategy, 0, len(slots))
+	for _, slot := range slots {
+		strategies = append(strategies, types.SlotStrategy{
+			Slot:    strconv.Itoa(slot),
+			Actions: r[slot],
+		})
+	}
+	return strategies
+}
+
+// epochStrategy makes all attacker validators act during the slots of the duties.
+func epochStrategy(p Params, duties []utils.ProposerDuty, rules slotRules) types.Strategy {
+	begin, _ := strconv.Atoi(duties[0].Slot)
+	end, _ := strconv.Atoi(duties[len(duties)-1].Slot)
+	return types.Strategy{
+		Validators: types.GetValidatorStrategy(0, p.MaxValidatorIndex, begin, end),
+		Slots:      rules.strategies(),
+	}
+}
+
+// attackerPattern returns the slots of the duties where the roles of the
+// proposers from that slot on match the pattern, true for an attacker.
+func attackerPattern(p Params, duties []utils.ProposerDuty, pattern ...bool) []int {
+	slots := make([]int, 0)
+	for i := 0; i+len(pattern) <= len(duties); i++ {
+		match := true
+		for j, attacker := range pattern {
+			if p.isAttacker(duties[i+j]) != attacker {
+				match = false
+				break
+			}
+		}
+		if match {
+			slot, _ := strconv.Atoi(duties[i].Slot)
+			slots = append(slots, slot)
+		}
+	}
+	return slots
+}
@@ -0,0 +1,199 @@
+package library
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/tsinghua-cel/strategy-gen/catalogue"
+	"github.com/tsinghua-cel/strategy-gen/inspect"
+	"github.com/tsinghua-cel/strategy-gen/types"
+	"github.com/tsinghua-cel/strategy-gen/utils"
+)
+
+var testParams = Params{MaxValidatorIndex: 9, SlotsPerEpoch: 32, SecondsPerSlot: 12}
+
+// schedule returns the duties from the first slot, A is an attacker proposer and H an honest one.
+func schedule(first int, roles string) []utils.ProposerDuty {
+	duties := make([]utils.ProposerDuty, 0, len(roles))
+	for i, role := range roles {
+		idx := 100 + i
+		if role == 'A' {
+			idx = i % (testParams.MaxValidatorIndex + 1)
+		}
+		duties = append(duties, utils.ProposerDuty{
+			ValidatorIndex: strconv.Itoa(idx),
+			Slot:           strconv.Itoa(first + i),
+		})
+	}
+	return duties
+}
+
+func generate(t *testing.T, name string, duties []utils.ProposerDuty) (types.Strategy, bool) {
+	t.Helper()
+	template, ok := GetTemplate(name)
+	if !ok {
+		t.Fatalf("template %s not registered", name)
+	}
+	return template.Generate(testParams, duties)
+}
+
+func slotActions(strategy types.Strategy) map[string]map[string]string {
+	actions := make(map[string]map[string]string)
+	for _, s := range strategy.Slots {
+		actions[s.Slot] = s.Actions
+	}
+	return actions
+}
+
+func TestTemplateNames(t *testing.T) {
+	names := TemplateNames()
+	expect := []string{"balancing", "ex-ante", "honest", "sandwich", "selfish"}
+	if len(names) != len(expect) {
+		t.Fatalf("templates %v, expect %v", names, expect)
+	}
+	for i := range expect {
+		if names[i] != expect[i] {
+			t.Fatalf("templates %v, expect %v", names, expect)
+		}
+	}
+}
+
+func TestHonestSchedule(t *testing.T) {
+	duties := schedule(32, "HHHHHHHH")
+	for _, name := range TemplateNames() {
+		_, happen := generate(t, name, duties)
+		if happen != (name == "honest") {
+			t.Errorf("template %s generates %v for an honest schedule", name, happen)
+		}
+	}
+}
+
+func TestHonestTemplate(t *testing.T) {
+	strategy, happen := generate(t, "honest", schedule(32, "AHAH"))
+	if !happen {
+		t.Fatal("honest template does not generate")
+	}
+	if len(strategy.Slots) != 0 {
+		t.Errorf("honest template has slot actions %v", strategy.Slots)
+	}
+	if len(strategy.Validators) != testParams.MaxValidatorIndex+1 {
+		t.Fatalf("honest template has %d validators", len(strategy.Validators))
+	}
+	v := strategy.Validators[0]
+	if v.AttackerStartSlot != 32 || v.AttackerEndSlot != 35 {
+		t.Errorf("honest template validator range %d-%d, expect 32-35", v.AttackerStartSlot, v.AttackerEndSlot)
+	}
+}
+
+func TestSelfishTemplate(t *testing.T) {
+	if _, happen := generate(t, "selfish", schedule(0, "AAHAAH")); happen {
+		t.Error("selfish template generates for 2 consecutive attacker proposers")
+	}
+	strategy, happen := generate(t, "selfish", schedule(0, "HAAAHAA"))
+	if !happen {
+		t.Fatal("selfish template does not generate for 3 consecutive attacker proposers")
+	}
+	actions := slotActions(strategy)
+	expect := map[string]string{"1": "delayWithSecond:36", "2": "delayWithSecond:24", "3": "delayWithSecond:12"}
+	if len(actions) != len(expect) {
+		t.Fatalf("selfish template slots %v", actions)
+	}
+	for slot, delay := range expect {
+		if actions[slot]["BlockBeforeBroadCast"] != delay || actions[slot]["AttestBeforeBroadCast"] != delay {
+			t.Errorf("selfish template slot %s actions %v, expect %s", slot, actions[slot], delay)
+		}
+	}
+}
+
+func TestExAnteTemplate(t *testing.T) {
+	if _, happen := generate(t, "ex-ante", schedule(0, "HHAA")); happen {
+		t.Error("ex-ante template generates without an honest proposer after an attacker one")
+	}
+	strategy, happen := generate(t, "ex-ante", schedule(64, "HAHHAAH"))
+	if !happen {
+		t.Fatal("ex-ante template does not generate")
+	}
+	actions := slotActions(strategy)
+	if len(actions) != 4 {
+		t.Fatalf("ex-ante template slots %v, expect 65, 66, 69 and 70", actions)
+	}
+	for _, slot := range []string{"65", "69"} {
+		if actions[slot]["BlockBeforeBroadCast"] != "delayToNextSlot" || actions[slot]["BlockAfterSign"] != "storeSignedBlock" {
+			t.Errorf("ex-ante template attacker slot %s actions %v", slot, actions[slot])
+		}
+	}
+	for _, slot := range []string{"66", "70"} {
+		if actions[slot]["AttestBeforeSign"] != "voteAttackerChainTip" {
+			t.Errorf("ex-ante template honest slot %s actions %v", slot, actions[slot])
+		}
+		if _, ok := actions[slot]["BlockBeforeBroadCast"]; ok {
+			t.Errorf("ex-ante template delays the honest block of slot %s", slot)
+		}
+	}
+}
+
+func TestSandwichTemplate(t *testing.T) {
+	if _, happen := generate(t, "sandwich", schedule(0, "AHHAHH")); happen {
+		t.Error("sandwich template generates without an attacker proposer after the honest one")
+	}
+	strategy, happen := generate(t, "sandwich", schedule(0, "HAHAHH"))
+	if !happen {
+		t.Fatal("sandwich template does not generate")
+	}
+	actions := slotActions(strategy)
+	if len(actions) != 2 {
+		t.Fatalf("sandwich template slots %v, expect 1 and 2", actions)
+	}
+	if actions["1"]["BlockBeforeBroadCast"] != "delayWithSecond:24" {
+		t.Errorf("sandwich template first attacker slot actions %v", actions["1"])
+	}
+	if actions["2"]["AttestBeforeBroadCast"] != "delayWithSecond:12" {
+		t.Errorf("sandwich template honest slot actions %v", actions["2"])
+	}
+	if _, ok := actions["3"]; ok {
+		t.Error("sandwich template changes the second attacker slot")
+	}
+}
+
+func TestBalancingTemplate(t *testing.T) {
+	strategy, happen := generate(t, "balancing", schedule(32, "AHAA"))
+	if !happen {
+		t.Fatal("balancing template does not generate")
+	}
+	actions := slotActions(strategy)
+	if len(actions) != 2 {
+		t.Fatalf("balancing template slots %v, expect 32 and 33", actions)
+	}
+	if actions["32"]["BlockBeforeBroadCast"] != "delayToAfterNextSlot:4" {
+		t.Errorf("balancing template attacker slot actions %v", actions["32"])
+	}
+	if actions["33"]["AttestBeforeSign"] != "voteAttackerChainTip" || actions["33"]["AttestBeforeBroadCast"] != "delayWithSecond:4" {
+		t.Errorf("balancing template honest slot actions %v", actions["33"])
+	}
+}
+
+// TestTemplatesLint lints the strategies of every template and the
+// conditional strategy against the embedded catalogue.
+func TestTemplatesLint(t *testing.T) {
+	opts := inspect.LintOptions{Validators: 64, SlotsPerEpoch: int64(testParams.SlotsPerEpoch)}
+	duties := map[string][]utils.ProposerDuty{
+		"honest":    schedule(32, "AHAH"),
+		"selfish":   schedule(0, "HAAAHAA"),
+		"ex-ante":   schedule(64, "HAHHAAH"),
+		"sandwich":  schedule(0, "HAHAHH"),
+		"balancing": schedule(32, "AHAA"),
+	}
+	strategies := map[string]types.Strategy{"conditional": ConditionalStrategy(testParams.MaxValidatorIndex, 3)}
+	for _, name := range TemplateNames() {
+		strategy, happen := generate(t, name, duties[name])
+		if !happen {
+			t.Fatalf("template %s does not generate", name)
+		}
+		strategies[name] = strategy
+	}
+	for name, strategy := range strategies {
+		for _, issue := range inspect.Lint(strategy, catalogue.Embedded(), opts) {
+			t.Errorf("%s: %s %s: %s", name, issue.Severity, issue.Where, issue.Message)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package library
+
+import (
+	"fmt"
+
+	"github.com/tsinghua-cel/strategy-gen/pointset"
+	"github.com/tsinghua-cel/strategy-gen/types"
+	"github.com/tsinghua-cel/strategy-gen/utils"
+)
+
+// honestTemplate runs the attacker validators without actions, it is the
+// baseline of the other templates.
+type honestTemplate struct{}
+
+func (honestTemplate) Name() string { return "honest" }
+
+func (honestTemplate) Desc() string { return "no actions, the baseline" }
+
+func (honestTemplate) Generate(p Params, duties []utils.ProposerDuty) (types.Strategy, bool) {
+	if len(duties) == 0 {
+		return types.Strategy{}, false
+	}
+	return epochStrategy(p, duties, slotRules{}), true
+}
+
+// selfishTemplate withholds the blocks and votes of the longest run of at
+// least 3 attacker proposers to the slot after the run.
+type selfishTemplate struct{}
+
+func (selfishTemplate) Name() string { return "selfish" }
+
+func (selfishTemplate) Desc() string {
+	return "withhold the blocks and votes of 3 or more consecutive attacker proposers to the end of the run"
+}
+
+func (selfishTemplate) Generate(p Params, duties []utils.ProposerDuty) (types.Strategy, bool) {
+	hackDuties, happen := CheckDuties(p.MaxValidatorIndex, duties)
+	if !happen {
+		return types.Strategy{}, false
+	}
+	return types.Strategy{
+		Validators: ValidatorStrategy(hackDuties),
+		Slots:      GenSlotStrategy(hackDuties, p.SecondsPerSlot),
+	}, true
+}
+
+// exAnteTemplate is the ex-ante reorg: an attacker proposer followed by an
+// honest one keeps its block and the attacker votes for it private until the
+// next slot, then the attacker votes of that slot also go to the attacker
+// block to reorg the honest one.
+type exAnteTemplate struct{}
+
+func (exAnteTemplate) Name() string { return "ex-ante" }
+
+func (exAnteTemplate) Desc() string {
+	return "release the attacker block and votes in the next honest slot and vote for it to reorg the honest block"
+}
+
+func (exAnteTemplate) Generate(p Params, duties []utils.ProposerDuty) (types.Strategy, bool) {
+	slots := attackerPattern(p, duties, true, false)
+	if len(slots) == 0 {
+		return types.Strategy{}, false
+	}
+	rules := slotRules{}
+	for _, slot := range slots {
+		rules.set(slot, pointset.GetPointByName("BlockAfterSign"), "storeSignedBlock")
+		rules.set(slot, pointset.GetPointByName("BlockBeforeBroadCast"), "delayToNextSlot")
+		rules.set(slot, pointset.GetPointByName("AttestBeforeSign"), "voteAttackerChainTip")
+		rules.set(slot, pointset.GetPointByName("AttestBeforeBroadCast"), "delayToNextSlot")
+		rules.set(slot+1, pointset.GetPointByName("AttestBeforeSign"), "voteAttackerChainTip")
+	}
+	return epochStrategy(p, duties, rules), true
+}
+
+// sandwichTemplate reorgs an honest block between two attacker proposers: the
+// first attacker block and the attacker votes of both slots are released with
+// the second attacker block.
+type sandwichTemplate struct{}
+
+func (sandwichTemplate) Name() string { return "sandwich" }
+
+func (sandwichTemplate) Desc() string {
+	return "withhold the attacker block before an honest one to the next attacker slot and vote for it"
+}
+
+func (sandwichTemplate) Generate(p Params, duties []utils.ProposerDuty) (types.Strategy, bool) {
+	slots := attackerPattern(p, duties, true, false, true)
+	if len(slots) == 0 {
+		return types.Strategy{}, false
+	}
+	rules := slotRules{}
+	for _, slot := range slots {
+		rules.set(slot, pointset.GetPointByName("BlockAfterSign"), "storeSignedBlock")
+		rules.set(slot, pointset.GetPointByName("BlockBeforeBroadCast"), fmt.Sprintf("delayWithSecond:%d", 2*p.SecondsPerSlot))
+		rules.set(slot, pointset.GetPointByName("AttestBeforeSign"), "voteAttackerChainTip")
+		rules.set(slot, pointset.GetPointByName("AttestBeforeBroadCast"), fmt.Sprintf("delayWithSecond:%d", 2*p.SecondsPerSlot))
+		rules.set(slot+1, pointset.GetPointByName("AttestBeforeSign"), "voteAttackerChainTip")
+		rules.set(slot+1, pointset.GetPointByName("AttestBeforeBroadCast"), fmt.Sprintf("delayWithSecond:%d", p.SecondsPerSlot))
+	}
+	return epochStrategy(p, duties, rules), true
+}
+
+// balancingTemplate releases an attacker block at the attestation deadline of
+// the next honest slot, so the honest committee splits between it and the
+// honest block, the attacker votes of that slot go to the attacker block.
+type balancingTemplate struct{}
+
+func (balancingTemplate) Name() string { return "balancing" }
+
+func (balancingTemplate) Desc() string {
+	return "release the attacker block at the attestation deadline of the next honest slot to split the honest votes"
+}
+
+func (balancingTemplate) Generate(p Params, duties []utils.ProposerDuty) (types.Strategy, bool) {
+	slots := attackerPattern(p, duties, true, false)
+	if len(slots) == 0 {
+		return types.Strategy{}, false
+	}
+	deadline := fmt.Sprintf("delayToAfterNextSlot:%d", p.SecondsPerSlot/3)
+	rules := slotRules{}
+	for _, slot := range slots {
+		rules.set(slot, pointset.GetPointByName("BlockBeforeBroadCast"), deadline)
+		rules.set(slot, pointset.GetPointByName("AttestBeforeBroadCast"), deadline)
+		rules.set(slot+1, pointset.GetPointByName("AttestBeforeSign"), "voteAttackerChainTip")
+		rules.set(slot+1, pointset.GetPointByName("AttestBeforeBroadCast"), fmt.Sprintf("delayWithSecond:%d", p.SecondsPerSlot/3))
+	}
+	return epochStrategy(p, duties, rules), true
+}
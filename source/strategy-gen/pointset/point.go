@@ -1,33 +1,28 @@
 package pointset
 
-import "strings"
+import (
+	"strings"
 
-var BlockPointSet = []string{
-	"BlockDelayForReceiveBlock",
-	"BlockBeforeBroadCast",
-	"BlockAfterBroadCast",
-	"BlockBeforeSign",
-	"BlockAfterSign",
-	"BlockBeforePropose",
-	"BlockAfterPropose",
+	"github.com/tsinghua-cel/strategy-gen/catalogue"
+)
+
+// BlockPointSet returns the block points of the catalogue in use.
+func BlockPointSet() []string {
+	return catalogue.Current().PointNames(catalogue.KindBlock)
 }
 
-var AttestPointSet = []string{
-	"AttestBeforeBroadCast",
-	"AttestAfterBroadCast",
-	"AttestBeforeSign",
-	"AttestAfterSign",
-	"AttestBeforePropose",
-	"AttestAfterPropose",
+// AttestPointSet returns the attest points of the catalogue in use.
+func AttestPointSet() []string {
+	return catalogue.Current().PointNames(catalogue.KindAttest)
 }
 
 func GetPointByName(name string) string {
-	for _, p := range BlockPointSet {
+	for _, p := range BlockPointSet() {
 		if strings.ToLower(p) == strings.ToLower(name) {
 			return p
 		}
 	}
-	for _, p := range AttestPointSet {
+	for _, p := range AttestPointSet() {
 		if strings.ToLower(p) == strings.ToLower(name) {
 			return p
 		}
@@ -0,0 +1,259 @@
+package search
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	ModeGenetic = "ga"
+	ModeBandit  = "bandit"
+)
+
+// Outcome is the effect of a candidate measured on the chain.
+type Outcome struct {
+	Reorgs              int     `json:"reorgs"`
+	AttackerRewardShare float64 `json:"attacker_reward_share"`
+	AttackerStakeShare  float64 `json:"attacker_stake_share"`
+}
+
+// RelativeRewardShare is the reward share of the attacker divided by its
+// stake share, it is above 1 when the attacker earns more than its fair share.
+func (o Outcome) RelativeRewardShare() float64 {
+	if o.AttackerStakeShare == 0 {
+		return 0
+	}
+	return o.AttackerRewardShare / o.AttackerStakeShare
+}
+
+// Evaluator runs the candidate actions on the chain and returns the outcome.
+type Evaluator interface {
+	Evaluate(actions map[string]string) (Outcome, error)
+}
+
+// Candidate is a set of actions by action point and its evaluation.
+type Candidate struct {
+	Actions map[string]string `json:"actions"`
+	Outcome Outcome           `json:"outcome"`
+	Fitness float64           `json:"fitness"`
+	Round   int               `json:"round"`
+
+	evaluated bool
+}
+
+type Options struct {
+	Mode string
+	Seed int64
+	// Population is the population size of the genetic algorithm, or the
+	// number of arms of the bandit.
+	Population int
+	// Rounds is the number of generations, the bandit pulls Rounds*Population times.
+	Rounds       int
+	MutationRate float64
+	Elite        int
+	// Epsilon is the exploration rate of the bandit.
+	Epsilon      float64
+	ReorgWeight  float64
+	RewardWeight float64
+	// OnEvaluated is called after each evaluation, e.g. to keep a history.
+	OnEvaluated func(c Candidate)
+}
+
+// Searcher proposes candidates, evaluates them and evolves the population
+// towards the most damaging ones. The proposals only depend on the seed and
+// the outcomes.
+type Searcher struct {
+	opts  Options
+	space Space
+	eval  Evaluator
+	rng   *rand.Rand
+	best  *Candidate
+}
+
+func NewSearcher(opts Options, space Space, eval Evaluator) (*Searcher, error) {
+	if len(space.Points) == 0 {
+		return nil, errors.New("no action point enabled")
+	}
+	if opts.Population < 2 {
+		return nil, fmt.Errorf("population %d is less than 2", opts.Population)
+	}
+	if opts.Rounds < 1 {
+		return nil, fmt.Errorf("rounds %d is less than 1", opts.Rounds)
+	}
+	// 0 <= elite < population, the rest of the generation is bred.
+	if opts.Elite < 0 {
+		opts.Elite = 0
+	}
+	if opts.Elite >= opts.Population {
+		opts.Elite = opts.Population - 1
+	}
+	return &Searcher{
+		opts:  opts,
+		space: space,
+		eval:  eval,
+		rng:   rand.New(rand.NewSource(opts.Seed)),
+	}, nil
+}
+
+// Run searches with the configured mode and returns the best candidate.
+func (s *Searcher) Run() (Candidate, error) {
+	switch s.opts.Mode {
+	case ModeGenetic, "":
+		s.runGenetic()
+	case ModeBandit:
+		s.runBandit()
+	default:
+		return Candidate{}, fmt.Errorf("unknown search mode %s", s.opts.Mode)
+	}
+	if s.best == nil {
+		return Candidate{}, errors.New("no candidate evaluated")
+	}
+	return *s.best, nil
+}
+
+func (s *Searcher) fitness(o Outcome) float64 {
+	return s.opts.ReorgWeight*float64(o.Reorgs) + s.opts.RewardWeight*o.RelativeRewardShare()
+}
+
+// evaluate runs the candidate, a failed evaluation gets the lowest fitness.
+func (s *Searcher) evaluate(c *Candidate, round int) bool {
+	c.Round = round
+	c.evaluated = true
+	outcome, err := s.eval.Evaluate(c.Actions)
+	if err != nil {
+		log.WithError(err).WithField("round", round).Warn("evaluate candidate failed")
+		c.Fitness = math.Inf(-1)
+		return false
+	}
+	c.Outcome = outcome
+	c.Fitness = s.fitness(outcome)
+	log.WithFields(log.Fields{
+		"round":               round,
+		"actions":             c.Actions,
+		"reorgs":              outcome.Reorgs,
+		"relativeRewardShare": outcome.RelativeRewardShare(),
+		"fitness":             c.Fitness,
+	}).Info("candidate evaluated")
+	if s.opts.OnEvaluated != nil {
+		s.opts.OnEvaluated(*c)
+	}
+	if s.best == nil || c.Fitness > s.best.Fitness {
+		best := *c
+		s.best = &best
+	}
+	return true
+}
+
+func (s *Searcher) runGenetic() {
+	population := make([]Candidate, s.opts.Population)
+	for i := range population {
+		population[i] = Candidate{Actions: s.space.Random(s.rng)}
+	}
+	for round := 0; round < s.opts.Rounds; round++ {
+		for i := range population {
+			// the elites keep their fitness instead of running again.
+			if !population[i].evaluated {
+				s.evaluate(&population[i], round)
+			}
+		}
+		sort.SliceStable(population, func(i, j int) bool {
+			return population[i].Fitness > population[j].Fitness
+		})
+		if round == s.opts.Rounds-1 {
+			break
+		}
+		next := make([]Candidate, 0, len(population))
+		next = append(next, population[:s.opts.Elite]...)
+		for len(next) < len(population) {
+			child := s.crossover(s.tournament(population), s.tournament(population))
+			s.mutate(child)
+			next = append(next, Candidate{Actions: child})
+		}
+		population = next
+	}
+}
+
+// tournament returns the fitter of two random candidates.
+func (s *Searcher) tournament(population []Candidate) Candidate {
+	a := population[s.rng.Intn(len(population))]
+	b := population[s.rng.Intn(len(population))]
+	if b.Fitness > a.Fitness {
+		return b
+	}
+	return a
+}
+
+// crossover takes the action of each point from one of the parents.
+func (s *Searcher) crossover(a, b Candidate) map[string]string {
+	child := make(map[string]string)
+	for _, p := range s.space.Points {
+		if s.rng.Intn(2) == 0 {
+			child[p] = a.Actions[p]
+		} else {
+			child[p] = b.Actions[p]
+		}
+	}
+	return child
+}
+
+func (s *Searcher) mutate(actions map[string]string) {
+	for _, p := range s.space.Points {
+		if s.rng.Float64() < s.opts.MutationRate {
+			actions[p] = s.space.RandomAction(s.rng, p)
+		}
+	}
+}
+
+// runBandit is an epsilon-greedy bandit over a fixed set of random
+// candidates, the outcomes are noisy so each arm is played several times
+// and judged by its mean fitness.
+func (s *Searcher) runBandit() {
+	arms := make([]Candidate, s.opts.Population)
+	for i := range arms {
+		arms[i] = Candidate{Actions: s.space.Random(s.rng)}
+	}
+	pulls := make([]int, len(arms))
+	total := make([]float64, len(arms))
+	mean := func(i int) float64 {
+		if pulls[i] == 0 {
+			return math.Inf(1)
+		}
+		return total[i] / float64(pulls[i])
+	}
+	for round := 0; round < s.opts.Rounds*len(arms); round++ {
+		arm := 0
+		if s.rng.Float64() < s.opts.Epsilon {
+			arm = s.rng.Intn(len(arms))
+		} else {
+			for i := range arms {
+				if mean(i) > mean(arm) {
+					arm = i
+				}
+			}
+		}
+		c := Candidate{Actions: arms[arm].Actions}
+		// a failed pull counts without fitness, so a failing arm is not retried forever.
+		pulls[arm]++
+		if s.evaluate(&c, round) {
+			total[arm] += c.Fitness
+			arms[arm] = c
+		}
+	}
+	// the best arm is judged by the mean fitness, not by a lucky single pull.
+	best := -1
+	for i := range arms {
+		if arms[i].evaluated && (best < 0 || mean(i) > mean(best)) {
+			best = i
+		}
+	}
+	if best >= 0 {
+		c := arms[best]
+		c.Fitness = mean(best)
+		s.best = &c
+	}
+}
@@ -0,0 +1,130 @@
+package search
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/tsinghua-cel/strategy-gen/actionset"
+)
+
+func TestNewSearcherClampsElite(t *testing.T) {
+	space := Space{Points: []string{"BlockBeforeSign"}}
+	for _, tc := range []struct {
+		elite, want int
+	}{
+		{elite: -3, want: 0},
+		{elite: 0, want: 0},
+		{elite: 2, want: 2},
+		{elite: 4, want: 3},
+		{elite: 9, want: 3},
+	} {
+		s, err := NewSearcher(Options{Population: 4, Rounds: 1, Elite: tc.elite}, space, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s.opts.Elite != tc.want {
+			t.Errorf("elite %d clamped to %d, want %d", tc.elite, s.opts.Elite, tc.want)
+		}
+	}
+}
+
+func TestFitness(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    Options
+		outcome Outcome
+		want    float64
+	}{
+		{name: "reorgs", opts: Options{ReorgWeight: 1, RewardWeight: 10}, outcome: Outcome{Reorgs: 3}, want: 3},
+		{name: "relative reward share", opts: Options{ReorgWeight: 1, RewardWeight: 10}, outcome: Outcome{AttackerRewardShare: 0.3, AttackerStakeShare: 0.2}, want: 15},
+		{name: "both", opts: Options{ReorgWeight: 2, RewardWeight: 1}, outcome: Outcome{Reorgs: 2, AttackerRewardShare: 0.2, AttackerStakeShare: 0.2}, want: 5},
+		{name: "no attacker stake", opts: Options{ReorgWeight: 1, RewardWeight: 10}, outcome: Outcome{Reorgs: 1, AttackerRewardShare: 0.5}, want: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Searcher{opts: tt.opts}
+			if got := s.fitness(tt.outcome); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("fitness %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// actionEvaluator scores a candidate by the number of its delay actions.
+type actionEvaluator struct{}
+
+func (actionEvaluator) Evaluate(actions map[string]string) (Outcome, error) {
+	var o Outcome
+	for _, action := range actions {
+		if strings.HasPrefix(action, "delay") {
+			o.Reorgs++
+		}
+	}
+	return o, nil
+}
+
+func TestSearcherRun(t *testing.T) {
+	space := NewSpace(
+		[]string{"BlockBeforeSign", "BlockBeforeBroadCast"}, actionset.GetBlockActionSet(),
+		[]string{"AttestBeforeSign"}, actionset.GetAttestActionSet(),
+	)
+	for _, mode := range []string{ModeGenetic, ModeBandit} {
+		t.Run(mode, func(t *testing.T) {
+			run := func(eval Evaluator) (Candidate, []Candidate, error) {
+				history := make([]Candidate, 0)
+				opts := Options{
+					Mode: mode, Seed: 7, Population: 6, Rounds: 4, MutationRate: 0.3, Elite: 2, Epsilon: 0.2,
+					ReorgWeight: 1, RewardWeight: 10,
+					OnEvaluated: func(c Candidate) { history = append(history, c) },
+				}
+				s, err := NewSearcher(opts, space, eval)
+				if err != nil {
+					t.Fatal(err)
+				}
+				best, err := s.Run()
+				return best, history, err
+			}
+			best, history, err := run(actionEvaluator{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			again, _, err := run(actionEvaluator{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(best.Actions, again.Actions) || best.Fitness != again.Fitness {
+				t.Errorf("the same seed found %v and %v", best.Actions, again.Actions)
+			}
+			max := math.Inf(-1)
+			for _, c := range history {
+				max = math.Max(max, c.Fitness)
+			}
+			if mode == ModeGenetic && best.Fitness != max {
+				t.Errorf("best fitness %v, want the max %v", best.Fitness, max)
+			}
+			if best.Fitness > max {
+				t.Errorf("best fitness %v is above the max %v", best.Fitness, max)
+			}
+
+			if _, history, err := run(failingEvaluator{}); err == nil || len(history) != 0 {
+				t.Errorf("all candidates failed, got err %v and %d evaluated", err, len(history))
+			}
+		})
+	}
+	s, err := NewSearcher(Options{Mode: "annealing", Population: 2, Rounds: 1}, space, actionEvaluator{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Run(); err == nil {
+		t.Error("ran an unknown search mode")
+	}
+}
+
+type failingEvaluator struct{}
+
+func (failingEvaluator) Evaluate(actions map[string]string) (Outcome, error) {
+	return Outcome{}, errors.New("candidate failed")
+}
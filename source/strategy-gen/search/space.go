@@ -0,0 +1,81 @@
+package search
+
+import (
+	"fmt"
+	"math/rand"
+
+	"github.com/tsinghua-cel/strategy-gen/actionset"
+	"github.com/tsinghua-cel/strategy-gen/types"
+)
+
+// Space is the set of actions a candidate can choose at each action point.
+type Space struct {
+	Points  []string
+	Actions map[string][]actionset.Action
+}
+
+// NewSpace returns the space of the enabled block and attest points and the
+// enabled actions that are valid at each of them.
+func NewSpace(blockPoints []string, blockActions []actionset.Action, attPoints []string, attActions []actionset.Action) Space {
+	space := Space{
+		Points:  make([]string, 0, len(blockPoints)+len(attPoints)),
+		Actions: make(map[string][]actionset.Action),
+	}
+	add := func(points []string, actions []actionset.Action) {
+		for _, p := range points {
+			valid := actionset.ValidActions(actions, p)
+			if len(valid) == 0 {
+				continue
+			}
+			space.Points = append(space.Points, p)
+			space.Actions[p] = valid
+		}
+	}
+	add(blockPoints, blockActions)
+	add(attPoints, attActions)
+	return space
+}
+
+// Random returns the actions of a random candidate.
+func (s Space) Random(r *rand.Rand) map[string]string {
+	actions := make(map[string]string)
+	for _, p := range s.Points {
+		actions[p] = s.RandomAction(r, p)
+	}
+	return actions
+}
+
+// RandomAction returns a random action with random parameters for the point.
+func (s Space) RandomAction(r *rand.Rand, point string) string {
+	actions := s.Actions[point]
+	action := actions[r.Intn(len(actions))]
+	conf := action.GetConfig()
+	astr := action.Name()
+	for i := 0; i < conf.ParamCount; i++ {
+		param := conf.DefaultParamValue
+		if conf.MaxRandomValue > conf.MinRandomParamValue {
+			param = r.Intn(conf.MaxRandomValue-conf.MinRandomParamValue) + conf.MinRandomParamValue
+		}
+		astr = fmt.Sprintf("%s:%d", astr, param)
+	}
+	return astr
+}
+
+// Template turns the actions of a candidate into a strategy.
+type Template struct {
+	Validators []types.ValidatorStrategy
+	// Slot is the slot of the rule, a number or a slot function like attackerSlot.
+	Slot string
+}
+
+func (t Template) Strategy(actions map[string]string) types.Strategy {
+	return types.Strategy{
+		Validators: t.Validators,
+		Slots: []types.SlotStrategy{
+			{
+				Slot:    t.Slot,
+				Actions: actions,
+			},
+		},
+	}
+}
@@ -0,0 +1,289 @@
+package selector
+
+import (
+	"bufio"
+	"fmt"
+	"math/rand"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/tsinghua-cel/strategy-gen/types"
+)
+
+// Validator is a validator of the set the attackers are selected from.
+type Validator struct {
+	Index  int
+	Pubkey string
+	Stake  uint64
+}
+
+// Set is the validator set, an empty set is unknown and only allows the
+// explicit indexes.
+type Set []Validator
+
+// EqualSet returns a set of n validators with the same stake and no pubkeys.
+func EqualSet(n int) Set {
+	set := make(Set, 0, n)
+	for i := 0; i < n; i++ {
+		set = append(set, Validator{Index: i, Stake: 1})
+	}
+	return set
+}
+
+func (s Set) stake() uint64 {
+	var total uint64
+	for _, v := range s {
+		total += v.Stake
+	}
+	return total
+}
+
+const (
+	termIndexes = iota
+	termRandomCount
+	termRandomStake
+	termPubkeys
+)
+
+type term struct {
+	kind     int
+	from, to int
+	count    int
+	fraction float64
+	file     string
+}
+
+// Subset is a group of validators that are attackers in the same slots.
+type Subset struct {
+	terms []term
+	// window is false when the slots of the config are used.
+	window             bool
+	startSlot, endSlot int
+}
+
+// Selector selects the attacker validators, it is a list of subsets:
+//
+//	0-9,12;random:20%@100..200;pubkeys:client-x.txt
+//
+// A subset is a comma separated union of indexes N, ranges N-M, random
+// samples random:N by count or random:P% by stake, and pubkeys:file of
+// one pubkey per line, with an optional slot window @start..end.
+type Selector []Subset
+
+// Parse parses a selector expression.
+func Parse(expr string) (Selector, error) {
+	sel := make(Selector, 0)
+	for _, part := range strings.Split(expr, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		subset, err := parseSubset(part)
+		if err != nil {
+			return nil, err
+		}
+		sel = append(sel, subset)
+	}
+	if len(sel) == 0 {
+		return nil, fmt.Errorf("empty validator selector")
+	}
+	return sel, nil
+}
+
+func parseSubset(part string) (Subset, error) {
+	var subset Subset
+	if at := strings.LastIndex(part, "@"); at >= 0 {
+		window := part[at+1:]
+		bounds := strings.SplitN(window, "..", 2)
+		if len(bounds) != 2 {
+			return subset, fmt.Errorf("invalid slot window %s, want start..end", window)
+		}
+		start, err1 := strconv.Atoi(strings.TrimSpace(bounds[0]))
+		end, err2 := strconv.Atoi(strings.TrimSpace(bounds[1]))
+		if err1 != nil || err2 != nil || start < 0 || end < start {
+			return subset, fmt.Errorf("invalid slot window %s", window)
+		}
+		subset.window, subset.startSlot, subset.endSlot = true, start, end
+		part = part[:at]
+	}
+	for _, s := range strings.Split(part, ",") {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+		t, err := parseTerm(s)
+		if err != nil {
+			return subset, err
+		}
+		subset.terms = append(subset.terms, t)
+	}
+	if len(subset.terms) == 0 {
+		return subset, fmt.Errorf("no validators in %s", part)
+	}
+	return subset, nil
+}
+
+func parseTerm(s string) (term, error) {
+	switch {
+	case strings.HasPrefix(s, "pubkeys:"):
+		file := strings.TrimPrefix(s, "pubkeys:")
+		if file == "" {
+			return term{}, fmt.Errorf("missing pubkey file in %s", s)
+		}
+		return term{kind: termPubkeys, file: file}, nil
+	case strings.HasPrefix(s, "random:"):
+		v := strings.TrimPrefix(s, "random:")
+		if strings.HasSuffix(v, "%") {
+			p, err := strconv.ParseFloat(strings.TrimSuffix(v, "%"), 64)
+			if err != nil || p <= 0 || p > 100 {
+				return term{}, fmt.Errorf("invalid stake fraction %s", s)
+			}
+			return term{kind: termRandomStake, fraction: p / 100}, nil
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return term{}, fmt.Errorf("invalid random count %s", s)
+		}
+		return term{kind: termRandomCount, count: n}, nil
+	}
+	bounds := strings.SplitN(s, "-", 2)
+	from, err := strconv.Atoi(bounds[0])
+	if err != nil || from < 0 {
+		return term{}, fmt.Errorf("invalid validator index %s", s)
+	}
+	to := from
+	if len(bounds) == 2 {
+		if to, err = strconv.Atoi(bounds[1]); err != nil || to < from {
+			return term{}, fmt.Errorf("invalid validator range %s", s)
+		}
+	}
+	return term{kind: termIndexes, from: from, to: to}, nil
+}
+
+// Resolve returns the attacker validators of the selector in the set, the
+// subsets without a slot window are attackers from startSlot to endSlot.
+// The random samples are drawn from the validators not yet in the subset,
+// the same seed draws the same validators.
+func (sel Selector) Resolve(set Set, seed int64, startSlot, endSlot int) ([]types.ValidatorStrategy, error) {
+	rng := rand.New(rand.NewSource(seed))
+	validators := make([]types.ValidatorStrategy, 0)
+	for _, subset := range sel {
+		indexes, err := subset.resolve(set, rng)
+		if err != nil {
+			return nil, err
+		}
+		start, end := startSlot, endSlot
+		if subset.window {
+			start, end = subset.startSlot, subset.endSlot
+		}
+		for _, idx := range indexes {
+			validators = append(validators, types.ValidatorStrategy{
+				ValidatorIndex:    idx,
+				AttackerStartSlot: start,
+				AttackerEndSlot:   end,
+			})
+		}
+	}
+	return validators, nil
+}
+
+func (subset Subset) resolve(set Set, rng *rand.Rand) ([]int, error) {
+	selected := make(map[int]bool)
+	inSet := make(map[int]bool, len(set))
+	for _, v := range set {
+		inSet[v.Index] = true
+	}
+	for _, t := range subset.terms {
+		switch t.kind {
+		case termIndexes:
+			for idx := t.from; idx <= t.to; idx++ {
+				if len(set) > 0 && !inSet[idx] {
+					return nil, fmt.Errorf("validator %d is not in the validator set", idx)
+				}
+				selected[idx] = true
+			}
+		case termPubkeys:
+			indexes, err := pubkeyIndexes(set, t.file)
+			if err != nil {
+				return nil, err
+			}
+			for _, idx := range indexes {
+				selected[idx] = true
+			}
+		case termRandomCount, termRandomStake:
+			if len(set) == 0 {
+				return nil, fmt.Errorf("random selection needs the validator set")
+			}
+			candidates := make([]Validator, 0, len(set))
+			for _, v := range set {
+				if !selected[v.Index] {
+					candidates = append(candidates, v)
+				}
+			}
+			rng.Shuffle(len(candidates), func(i, j int) { candidates[i], candidates[j] = candidates[j], candidates[i] })
+			if t.kind == termRandomCount {
+				if t.count > len(candidates) {
+					return nil, fmt.Errorf("random:%d is more than the %d validators left", t.count, len(candidates))
+				}
+				for _, v := range candidates[:t.count] {
+					selected[v.Index] = true
+				}
+				continue
+			}
+			want := t.fraction * float64(set.stake())
+			var stake float64
+			for _, v := range candidates {
+				if stake >= want {
+					break
+				}
+				selected[v.Index] = true
+				stake += float64(v.Stake)
+			}
+		}
+	}
+	indexes := make([]int, 0, len(selected))
+	for idx := range selected {
+		indexes = append(indexes, idx)
+	}
+	sort.Ints(indexes)
+	return indexes, nil
+}
+
+// pubkeyIndexes reads a file of one pubkey per line, the empty lines and the
+// lines starting with # are skipped.
+func pubkeyIndexes(set Set, file string) ([]int, error) {
+	byPubkey := make(map[string]int, len(set))
+	for _, v := range set {
+		if v.Pubkey != "" {
+			byPubkey[normalizePubkey(v.Pubkey)] = v.Index
+		}
+	}
+	if len(byPubkey) == 0 {
+		return nil, fmt.Errorf("pubkeys:%s needs the validator set of the attacker service", file)
+	}
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	indexes := make([]int, 0)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		idx, ok := byPubkey[normalizePubkey(line)]
+		if !ok {
+			return nil, fmt.Errorf("pubkey %s of %s is not in the validator set", line, file)
+		}
+		indexes = append(indexes, idx)
+	}
+	return indexes, scanner.Err()
+}
+
+func normalizePubkey(p string) string {
+	return strings.TrimPrefix(strings.ToLower(p), "0x")
+}
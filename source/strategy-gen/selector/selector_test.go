@@ -0,0 +1,117 @@
+package selector
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/tsinghua-cel/strategy-gen/types"
+)
+
+func resolve(t *testing.T, expr string, set Set, seed int64) []types.ValidatorStrategy {
+	t.Helper()
+	sel, err := Parse(expr)
+	if err != nil {
+		t.Fatalf("parse %s: %v", expr, err)
+	}
+	validators, err := sel.Resolve(set, seed, 0, 100)
+	if err != nil {
+		t.Fatalf("resolve %s: %v", expr, err)
+	}
+	return validators
+}
+
+func indexes(validators []types.ValidatorStrategy) []int {
+	list := make([]int, 0, len(validators))
+	for _, v := range validators {
+		list = append(list, v.ValidatorIndex)
+	}
+	return list
+}
+
+func TestListsAndRanges(t *testing.T) {
+	validators := resolve(t, "0-2,5, 7;3@10..20", nil, 0)
+	want := []types.ValidatorStrategy{
+		{ValidatorIndex: 0, AttackerStartSlot: 0, AttackerEndSlot: 100},
+		{ValidatorIndex: 1, AttackerStartSlot: 0, AttackerEndSlot: 100},
+		{ValidatorIndex: 2, AttackerStartSlot: 0, AttackerEndSlot: 100},
+		{ValidatorIndex: 5, AttackerStartSlot: 0, AttackerEndSlot: 100},
+		{ValidatorIndex: 7, AttackerStartSlot: 0, AttackerEndSlot: 100},
+		{ValidatorIndex: 3, AttackerStartSlot: 10, AttackerEndSlot: 20},
+	}
+	if len(validators) != len(want) {
+		t.Fatalf("got %v, want %v", validators, want)
+	}
+	for i := range want {
+		if validators[i] != want[i] {
+			t.Fatalf("got %v, want %v", validators, want)
+		}
+	}
+}
+
+func TestInvalidExpressions(t *testing.T) {
+	for _, expr := range []string{"", ";", "a", "3-1", "random:0", "random:120%", "1@5", "1@9..2", "pubkeys:"} {
+		if _, err := Parse(expr); err == nil {
+			t.Errorf("expected %q to be invalid", expr)
+		}
+	}
+}
+
+func TestIndexOutsideSet(t *testing.T) {
+	sel, _ := Parse("0-10")
+	if _, err := sel.Resolve(EqualSet(8), 0, 0, 100); err == nil {
+		t.Fatal("expected validator 10 to be outside the set of 8")
+	}
+}
+
+func TestRandomCountIsSeeded(t *testing.T) {
+	a := indexes(resolve(t, "random:5", EqualSet(64), 7))
+	b := indexes(resolve(t, "random:5", EqualSet(64), 7))
+	if len(a) != 5 {
+		t.Fatalf("got %d validators, want 5", len(a))
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			t.Fatalf("the same seed selected %v and %v", a, b)
+		}
+	}
+}
+
+func TestRandomStakeFraction(t *testing.T) {
+	set := EqualSet(10)
+	// validator 0 has half of the stake.
+	set[0].Stake = 9
+	for seed := int64(0); seed < 20; seed++ {
+		var stake uint64
+		for _, idx := range indexes(resolve(t, "random:50%", set, seed)) {
+			stake += set[idx].Stake
+		}
+		if stake < 9 || stake > 17 {
+			t.Fatalf("seed %d selected stake %d of 18, want at least half", seed, stake)
+		}
+	}
+}
+
+func TestRandomSkipsSelected(t *testing.T) {
+	validators := indexes(resolve(t, "0-5,random:2", EqualSet(8), 1))
+	if len(validators) != 8 {
+		t.Fatalf("got %v, want all 8 validators", validators)
+	}
+}
+
+func TestPubkeys(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "keys.txt")
+	if err := os.WriteFile(file, []byte("# client x\n0xAA\n\ncc\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	set := Set{{Index: 4, Pubkey: "0xaa", Stake: 1}, {Index: 5, Pubkey: "0xbb", Stake: 1}, {Index: 6, Pubkey: "0xcc", Stake: 1}}
+	got := indexes(resolve(t, "pubkeys:"+file, set, 0))
+	if len(got) != 2 || got[0] != 4 || got[1] != 6 {
+		t.Fatalf("got %v, want [4 6]", got)
+	}
+
+	sel, _ := Parse("pubkeys:" + file)
+	if _, err := sel.Resolve(EqualSet(8), 0, 0, 100); err == nil {
+		t.Fatal("expected pubkeys to need the validator set of the service")
+	}
+}
@@ -0,0 +1,252 @@
+package simulate
+
+const (
+	RuleGhost  = "ghost"
+	RuleStable = "stable"
+)
+
+// proposerScoreBoost is the proposer boost in percent of the committee weight.
+const proposerScoreBoost = 40
+
+type block struct {
+	id       int
+	slot     int64
+	proposer int
+	attacker bool
+	parent   *block
+}
+
+// ancestorAt returns the block at the slot or the nearest one before it.
+func (b *block) ancestorAt(slot int64) *block {
+	for b.parent != nil && b.slot > slot {
+		b = b.parent
+	}
+	return b
+}
+
+func (b *block) descendantOf(a *block) bool {
+	return b.ancestorAt(a.slot) == a
+}
+
+type vote struct {
+	slot  int64
+	block *block
+}
+
+// view is what a group of nodes knows at a time, the honest nodes share one
+// view, the attacker has its own with the withheld blocks and votes.
+type view struct {
+	sim      *simulator
+	blocks   []*block
+	arrival  map[*block]int64
+	children map[*block][]*block
+	pending  map[*block][]*block
+	latest   map[int]vote
+	// stableVotes counts the votes received within their slot, as blocksave does.
+	stableVotes map[*block]int
+}
+
+func newView(sim *simulator, genesis *block) *view {
+	v := &view{
+		sim:         sim,
+		arrival:     make(map[*block]int64),
+		children:    make(map[*block][]*block),
+		pending:     make(map[*block][]*block),
+		latest:      make(map[int]vote),
+		stableVotes: make(map[*block]int),
+	}
+	v.blocks = append(v.blocks, genesis)
+	v.arrival[genesis] = 0
+	return v
+}
+
+func (v *view) has(b *block) bool {
+	_, ok := v.arrival[b]
+	return ok
+}
+
+// addBlock adds the block, a block whose parent is unknown waits for it.
+func (v *view) addBlock(b *block, now int64) {
+	if v.has(b) {
+		return
+	}
+	if !v.has(b.parent) {
+		v.pending[b.parent] = append(v.pending[b.parent], b)
+		return
+	}
+	v.blocks = append(v.blocks, b)
+	v.arrival[b] = now
+	v.children[b.parent] = append(v.children[b.parent], b)
+	for _, child := range v.pending[b] {
+		v.addBlock(child, now)
+	}
+	delete(v.pending, b)
+}
+
+func (v *view) addVote(validator int, vt vote, now int64) {
+	if old, ok := v.latest[validator]; !ok || vt.slot > old.slot {
+		v.latest[validator] = vt
+	}
+	if now < v.sim.slotTime(vt.slot+1) {
+		v.stableVotes[vt.block]++
+	}
+}
+
+func (v *view) stable(b *block) bool {
+	return v.stableVotes[b] >= v.sim.committeeSize()/3
+}
+
+func (v *view) finalizedSlot(now int64) int64 {
+	// the simulator does not follow the justification, a healthy chain
+	// finalizes the epoch two epochs before the current one.
+	epoch := v.sim.slotAt(now)/v.sim.conf.SlotsPerEpoch - 2
+	if epoch < 0 {
+		return 0
+	}
+	return epoch * v.sim.conf.SlotsPerEpoch
+}
+
+// head is the head the nodes vote for.
+func (v *view) head(rule string, now int64) *block {
+	if rule == RuleStable {
+		return v.longestChain(v.leaves(), now)
+	}
+	return v.ghostHead(now)
+}
+
+// proposerHead is the parent a proposer of the slot builds on.
+func (v *view) proposerHead(rule string, slot int64, now int64) *block {
+	if rule == RuleStable {
+		return v.latestHead(slot, now)
+	}
+	return v.ghostHead(now)
+}
+
+// ghostHead is LMD-GHOST with proposer boost, the votes count from the slot
+// after they are cast.
+func (v *view) ghostHead(now int64) *block {
+	current := v.sim.slotAt(now)
+	// the weights are in percent of a vote.
+	weight := make(map[*block]int)
+	for _, vt := range v.latest {
+		if vt.slot >= current {
+			continue
+		}
+		for b := vt.block; b != nil; b = b.parent {
+			weight[b] += 100
+		}
+	}
+	for _, b := range v.blocks {
+		if b.slot == current && v.arrival[b] < v.sim.slotTime(current)+v.sim.attestDelay() {
+			for a := b; a != nil; a = a.parent {
+				weight[a] += v.sim.committeeSize() * proposerScoreBoost
+			}
+		}
+	}
+	head := v.blocks[0]
+	for {
+		children := v.children[head]
+		if len(children) == 0 {
+			return head
+		}
+		best := children[0]
+		for _, c := range children[1:] {
+			if weight[c] > weight[best] || (weight[c] == weight[best] && c.id > best.id) {
+				best = c
+			}
+		}
+		head = best
+	}
+}
+
+func (v *view) leaves() []*block {
+	leaves := make([]*block, 0)
+	for _, b := range v.blocks {
+		if len(v.children[b]) == 0 {
+			leaves = append(leaves, b)
+		}
+	}
+	return leaves
+}
+
+// longestChain is GetLongestChainWithStableTransport of blocksave.
+func (v *view) longestChain(nodes []*block, now int64) *block {
+	finalized := v.finalizedSlot(now)
+	var longest *block
+	var longestLength int64
+	for _, b := range nodes {
+		length := v.lengthWithStableTransport(b, finalized)
+		if longest == nil || length > longestLength {
+			longest, longestLength = b, length
+		}
+	}
+	return longest
+}
+
+func (v *view) lengthWithStableTransport(b *block, finalized int64) int64 {
+	var stabled, unstabled int64
+	for ; b != nil && b.slot > finalized; b = b.parent {
+		if v.stable(b) {
+			stabled++
+		} else {
+			unstabled++
+		}
+	}
+	return stabled + changeUnstableToStable(unstabled)
+}
+
+// latestHead is GetLatestHead of blocksave: the stable block of the most
+// recent slot with a block, or the one with the longest chain.
+func (v *view) latestHead(slot int64, now int64) *block {
+	for i := slot; i > 0; i-- {
+		var stabled, unstabled []*block
+		for _, b := range v.blocks {
+			if b.slot != i {
+				continue
+			}
+			if v.stable(b) {
+				stabled = append(stabled, b)
+			} else {
+				unstabled = append(unstabled, b)
+			}
+		}
+		if len(stabled) > 0 {
+			return v.longestChain(stabled, now)
+		}
+		if len(unstabled) > 0 {
+			finalized := v.finalizedSlot(now)
+			longest := unstabled[0]
+			for _, b := range unstabled[1:] {
+				if chainLength(b, finalized) > chainLength(longest, finalized) {
+					longest = b
+				}
+			}
+			return longest
+		}
+	}
+	return v.blocks[0]
+}
+
+func chainLength(b *block, finalized int64) int64 {
+	var n int64
+	for ; b != nil && b.slot > finalized; b = b.parent {
+		n++
+	}
+	return n
+}
+
+// changeUnstableToStable is the table of blocksave, the number of stable
+// blocks a run of unstable blocks is worth.
+func changeUnstableToStable(unstable int64) int64 {
+	table := []int64{
+		3, 3, 4, 5, 6, 7, 8, 9, 10, 10,
+		11, 12, 13, 14, 16, 17, 18, 19, 20, 21,
+		22, 23, 24, 26, 27, 28, 29, 30, 32, 33,
+		34, 35, 37, 38, 39, 40, 42, 43, 44, 45,
+		47, 48, 49, 51, 52, 53, 55, 56, 57,
+	}
+	if unstable < 16 || unstable > 64 {
+		return 0
+	}
+	return table[unstable-16]
+}
@@ -0,0 +1,228 @@
+package simulate
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/tsinghua-cel/strategy-gen/inspect"
+	"github.com/tsinghua-cel/strategy-gen/types"
+)
+
+// the action points that hold the whole duty, the broadcast points only hold
+// the message to the other nodes, the after points do not change the timing.
+var (
+	blockHoldPoints      = []string{"BlockBeforeSign", "BlockAfterSign", "BlockBeforePropose", "BlockDelayForReceiveBlock"}
+	blockBroadcastPoints = []string{"BlockBeforeBroadCast"}
+	attestHoldPoints     = []string{"AttestBeforeSign", "AttestAfterSign", "AttestBeforePropose"}
+	attestBroadcastPoint = []string{"AttestBeforeBroadCast"}
+)
+
+// effect is the outcome of the actions of a duty.
+type effect struct {
+	// hold delays the duty, in milliseconds.
+	hold int64
+	// broadcast delays the message to the other nodes, in milliseconds.
+	broadcast int64
+	// dropped duties are not done at all, withheld messages are kept by the attacker.
+	dropped  bool
+	withheld bool
+	// vote is the vote action at AttestBeforeSign.
+	vote string
+	// voteParam is the parameter of the vote action.
+	voteParam int
+}
+
+// plan resolves the slot rules and actions of a strategy.
+type plan struct {
+	sim     *simulator
+	slots   *inspect.Slots
+	ignored map[string]bool
+}
+
+func newPlan(sim *simulator, strategy types.Strategy) (*plan, error) {
+	p := &plan{
+		sim: sim,
+		slots: inspect.NewSlots(strategy, sim.conf.SlotsPerEpoch, func(slot int64) (int, bool) {
+			return sim.proposer(slot), true
+		}),
+		ignored: make(map[string]bool),
+	}
+	for _, s := range strategy.Slots {
+		if err := inspect.CheckSlot(s.Slot); err != nil {
+			return nil, err
+		}
+		for point, action := range s.Actions {
+			if !simulatedPoint(point) {
+				p.ignored[point] = true
+			} else if name, _ := parseAction(action); !simulatedAction(name) {
+				p.ignored[name] = true
+			}
+		}
+	}
+	if len(strategy.Conditional) > 0 {
+		p.ignored["conditional"] = true
+	}
+	return p, nil
+}
+
+// Ignored returns the points and actions of the strategy the simulator does not model.
+func (p *plan) Ignored() []string {
+	ignored := make([]string, 0, len(p.ignored))
+	for k := range p.ignored {
+		ignored = append(ignored, k)
+	}
+	sort.Strings(ignored)
+	return ignored
+}
+
+func simulatedPoint(point string) bool {
+	for _, points := range [][]string{blockHoldPoints, blockBroadcastPoints, attestHoldPoints, attestBroadcastPoint} {
+		if contains(points, point) {
+			return true
+		}
+	}
+	// the after points do not change the timing of the duty.
+	return strings.Contains(point, "After")
+}
+
+func simulatedAction(name string) bool {
+	switch name {
+	case "null", "continue", "return", "skip", "abort", "exit",
+		"voteHeadOfSlot", "voteAttackerChainTip", "voteParentOfHead",
+		"delayWithSecond", "delayToNextSlot", "delayToAfterNextSlot", "delayToNextNEpochStart",
+		"delayToNextNEpochEnd", "delayToNextNEpochHalf", "delayToEpochEnd", "delayHalfEpoch":
+		return true
+	}
+	return false
+}
+
+func (p *plan) isAttacker(validator int, slot int64) bool {
+	return p.slots.IsAttacker(validator, slot)
+}
+
+// actions returns the actions of the max level rule of the slot.
+func (p *plan) actions(slot int64) map[string]string {
+	rule, ok := p.slots.Resolve(slot)
+	if !ok {
+		return nil
+	}
+	return rule.Actions
+}
+
+func (p *plan) blockEffect(slot int64) effect {
+	return p.effect(slot, 0, blockHoldPoints, blockBroadcastPoints)
+}
+
+func (p *plan) attestEffect(slot int64) effect {
+	return p.effect(slot, p.sim.attestDelay(), attestHoldPoints, attestBroadcastPoint)
+}
+
+// effect runs the actions of the duty done at offset milliseconds into the slot.
+func (p *plan) effect(slot int64, offset int64, holdPoints []string, broadcastPoints []string) effect {
+	var e effect
+	actions := p.actions(slot)
+	if actions == nil {
+		return e
+	}
+	// the points are run in the order of the duty.
+	for _, point := range append(append([]string{}, holdPoints...), broadcastPoints...) {
+		action, ok := actions[point]
+		if !ok {
+			continue
+		}
+		name, params := parseAction(action)
+		// the delays are relative to the time the point is reached.
+		elapsed := offset + e.hold + e.broadcast
+		switch name {
+		case "null", "continue":
+		case "return", "skip", "abort", "exit":
+			if contains(holdPoints, point) {
+				e.dropped = true
+			} else {
+				e.withheld = true
+			}
+		case "voteHeadOfSlot", "voteAttackerChainTip", "voteParentOfHead":
+			if point == "AttestBeforeSign" {
+				e.vote = name
+				e.voteParam = 1
+				if len(params) > 0 {
+					e.voteParam = params[0]
+				}
+			}
+		default:
+			d, ok := p.delay(name, params, slot, elapsed)
+			if !ok {
+				continue
+			}
+			if contains(holdPoints, point) {
+				e.hold += d
+			} else {
+				e.broadcast += d
+			}
+		}
+		if e.dropped || e.withheld {
+			break
+		}
+	}
+	return e
+}
+
+// delay returns the delay of a delay action in milliseconds, it follows the
+// delay actions of the attacker service.
+func (p *plan) delay(name string, params []int, slot int64, elapsed int64) (int64, bool) {
+	param := func(def int) int64 {
+		if len(params) > 0 {
+			return int64(params[0])
+		}
+		return int64(def)
+	}
+	spe := p.sim.conf.SlotsPerEpoch
+	seconds := p.sim.conf.SecondsPerSlot
+	epoch := slot / spe
+	var total int64
+	switch name {
+	case "delayWithSecond":
+		total = param(p.sim.rng.Intn(10)) * 1000
+	case "delayToNextSlot":
+		total = seconds*1000 - elapsed
+	case "delayToAfterNextSlot":
+		total = (seconds+param(p.sim.rng.Intn(10)))*1000 - elapsed
+	case "delayToNextNEpochStart":
+		total = seconds * ((epoch+param(1))*spe - slot) * 1000
+	case "delayToNextNEpochEnd":
+		total = seconds * ((epoch+param(0)+1)*spe - 1 - slot) * 1000
+	case "delayToNextNEpochHalf":
+		total = seconds * ((epoch+param(1))*spe - slot + spe/2) * 1000
+	case "delayToEpochEnd":
+		total = seconds * ((epoch+1)*spe - 1 - slot) * 1000
+	case "delayHalfEpoch":
+		total = seconds * (spe / 2) * 1000
+	default:
+		return 0, false
+	}
+	if total < 0 {
+		total = 0
+	}
+	return total, true
+}
+
+func parseAction(action string) (string, []int) {
+	parts := strings.Split(action, ":")
+	params := make([]int, 0, len(parts)-1)
+	for _, s := range parts[1:] {
+		if n, err := strconv.Atoi(s); err == nil {
+			params = append(params, n)
+		}
+	}
+	return parts[0], params
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
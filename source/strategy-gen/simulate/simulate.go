@@ -0,0 +1,325 @@
+package simulate
+
+import (
+	"container/heap"
+	"errors"
+	"fmt"
+	"math/rand"
+
+	"github.com/tsinghua-cel/strategy-gen/types"
+)
+
+// Config is the network simulated for a strategy.
+type Config struct {
+	Validators     int
+	SlotsPerEpoch  int64
+	SecondsPerSlot int64
+	Epochs         int64
+	// Latency is the network delay of a message in milliseconds, with a
+	// uniform jitter up to Jitter milliseconds.
+	Latency int64
+	Jitter  int64
+	Seed    int64
+	// Rule is the fork choice of the honest nodes, ghost or stable.
+	Rule string
+}
+
+// Result is the predicted outcome of a strategy.
+type Result struct {
+	Rule               string   `json:"rule"`
+	Slots              int64    `json:"slots"`
+	Reorgs             int      `json:"reorgs"`
+	MaxReorgDepth      int64    `json:"max_reorg_depth"`
+	HonestProposals    int      `json:"honest_proposals"`
+	HonestOrphaned     int      `json:"honest_orphaned"`
+	AttackerProposals  int      `json:"attacker_proposals"`
+	AttackerCanonical  int      `json:"attacker_canonical"`
+	AttackerBlockShare float64  `json:"attacker_block_share"`
+	AttackerStakeShare float64  `json:"attacker_stake_share"`
+	RelativeBlockShare float64  `json:"relative_block_share"`
+	Ignored            []string `json:"ignored,omitempty"`
+}
+
+type event struct {
+	at  int64
+	seq int
+	do  func()
+}
+
+type eventQueue []*event
+
+func (q eventQueue) Len() int { return len(q) }
+func (q eventQueue) Less(i, j int) bool {
+	if q[i].at != q[j].at {
+		return q[i].at < q[j].at
+	}
+	return q[i].seq < q[j].seq
+}
+func (q eventQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *eventQueue) Push(x interface{}) { *q = append(*q, x.(*event)) }
+func (q *eventQueue) Pop() interface{} {
+	old := *q
+	e := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return e
+}
+
+// simulator is a discrete-event model of the slots, the proposers, the
+// attestation committees and the network delay. The honest nodes share one
+// view of the messages, the attacker nodes share another one.
+type simulator struct {
+	conf Config
+	rng  *rand.Rand
+	plan *plan
+
+	queue eventQueue
+	seq   int
+	now   int64
+
+	proposers  map[int64]int
+	committees map[int64][]int
+	nextID     int
+
+	honest   *view
+	attacker *view
+	blocks   []*block
+	lastHead *block
+	result   Result
+}
+
+// Run simulates the strategy and returns the predicted outcome.
+func Run(conf Config, strategy types.Strategy) (Result, error) {
+	if conf.Validators < int(conf.SlotsPerEpoch) {
+		return Result{}, fmt.Errorf("validators %d is less than the slots per epoch", conf.Validators)
+	}
+	if conf.Epochs < 1 {
+		return Result{}, errors.New("epochs is less than 1")
+	}
+	if conf.Rule != RuleGhost && conf.Rule != RuleStable {
+		return Result{}, fmt.Errorf("unknown fork choice rule %s", conf.Rule)
+	}
+	s := &simulator{
+		conf:       conf,
+		rng:        rand.New(rand.NewSource(conf.Seed)),
+		proposers:  make(map[int64]int),
+		committees: make(map[int64][]int),
+	}
+	p, err := newPlan(s, strategy)
+	if err != nil {
+		return Result{}, err
+	}
+	s.plan = p
+	s.run()
+	s.result.Rule = conf.Rule
+	s.result.Ignored = p.Ignored()
+	return s.result, nil
+}
+
+func (s *simulator) slotTime(slot int64) int64 { return slot * s.conf.SecondsPerSlot * 1000 }
+
+func (s *simulator) slotAt(t int64) int64 { return t / (s.conf.SecondsPerSlot * 1000) }
+
+func (s *simulator) attestDelay() int64 { return s.conf.SecondsPerSlot * 1000 / 3 }
+
+func (s *simulator) committeeSize() int { return s.conf.Validators / int(s.conf.SlotsPerEpoch) }
+
+func (s *simulator) schedule(at int64, do func()) {
+	s.seq++
+	heap.Push(&s.queue, &event{at: at, seq: s.seq, do: do})
+}
+
+func (s *simulator) latency() int64 {
+	if s.conf.Jitter <= 0 {
+		return s.conf.Latency
+	}
+	return s.conf.Latency + s.rng.Int63n(s.conf.Jitter+1)
+}
+
+// shuffle draws the proposers and the committees of the epoch.
+func (s *simulator) shuffle(epoch int64) {
+	perm := s.rng.Perm(s.conf.Validators)
+	size := s.committeeSize()
+	for i := int64(0); i < s.conf.SlotsPerEpoch; i++ {
+		slot := epoch*s.conf.SlotsPerEpoch + i
+		s.proposers[slot] = s.rng.Intn(s.conf.Validators)
+		s.committees[slot] = perm[int(i)*size : int(i+1)*size]
+	}
+}
+
+func (s *simulator) proposer(slot int64) int {
+	return s.proposers[slot]
+}
+
+func (s *simulator) run() {
+	genesis := &block{id: 0, slot: 0, proposer: -1}
+	s.blocks = append(s.blocks, genesis)
+	s.honest = newView(s, genesis)
+	s.attacker = newView(s, genesis)
+	s.lastHead = genesis
+	for epoch := int64(0); epoch < s.conf.Epochs; epoch++ {
+		s.shuffle(epoch)
+	}
+	last := s.conf.Epochs*s.conf.SlotsPerEpoch - 1
+	for slot := int64(1); slot <= last; slot++ {
+		slot := slot
+		s.schedule(s.slotTime(slot), func() { s.propose(slot) })
+		s.schedule(s.slotTime(slot)+s.attestDelay(), func() { s.attest(slot) })
+	}
+	end := s.slotTime(last + 1)
+	for s.queue.Len() > 0 {
+		e := heap.Pop(&s.queue).(*event)
+		if e.at >= end {
+			break
+		}
+		s.now = e.at
+		e.do()
+	}
+	s.now = end
+	s.observeHead()
+	s.finish(last)
+}
+
+func (s *simulator) propose(slot int64) {
+	s.observeHead()
+	proposer := s.proposer(slot)
+	if !s.plan.isAttacker(proposer, slot) {
+		b := s.newBlock(slot, proposer, false, s.honest.proposerHead(s.conf.Rule, slot, s.now))
+		s.result.HonestProposals++
+		s.honest.addBlock(b, s.now)
+		s.deliver(b, s.attacker, s.now+s.latency())
+		return
+	}
+	s.result.AttackerProposals++
+	e := s.plan.blockEffect(slot)
+	if e.dropped {
+		return
+	}
+	b := s.newBlock(slot, proposer, true, s.attacker.proposerHead(s.conf.Rule, slot, s.now))
+	s.deliver(b, s.attacker, s.now+e.hold)
+	if !e.withheld {
+		s.deliver(b, s.honest, s.now+e.hold+e.broadcast+s.latency())
+	}
+}
+
+func (s *simulator) newBlock(slot int64, proposer int, attacker bool, parent *block) *block {
+	s.nextID++
+	b := &block{id: s.nextID, slot: slot, proposer: proposer, attacker: attacker, parent: parent}
+	s.blocks = append(s.blocks, b)
+	return b
+}
+
+func (s *simulator) deliver(b *block, v *view, at int64) {
+	if at <= s.now {
+		v.addBlock(b, s.now)
+		return
+	}
+	s.schedule(at, func() { v.addBlock(b, s.now) })
+}
+
+func (s *simulator) deliverVote(validator int, vt vote, v *view, at int64) {
+	if at <= s.now {
+		v.addVote(validator, vt, s.now)
+		return
+	}
+	s.schedule(at, func() { v.addVote(validator, vt, s.now) })
+}
+
+func (s *simulator) attest(slot int64) {
+	s.observeHead()
+	honestHead := s.honest.head(s.conf.Rule, s.now)
+	for _, validator := range s.committees[slot] {
+		if !s.plan.isAttacker(validator, slot) {
+			vt := vote{slot: slot, block: honestHead}
+			s.honest.addVote(validator, vt, s.now)
+			s.deliverVote(validator, vt, s.attacker, s.now+s.latency())
+			continue
+		}
+		e := s.plan.attestEffect(slot)
+		if e.dropped {
+			continue
+		}
+		// the attestation data is taken before the actions hold the duty.
+		validator := validator
+		vt := vote{slot: slot, block: s.attackerVote(e, slot)}
+		s.schedule(s.now+e.hold, func() {
+			s.attacker.addVote(validator, vt, s.now)
+			if !e.withheld {
+				s.deliverVote(validator, vt, s.honest, s.now+e.broadcast+s.latency())
+			}
+		})
+	}
+}
+
+// attackerVote follows the vote actions of the attacker service.
+func (s *simulator) attackerVote(e effect, slot int64) *block {
+	head := s.attacker.head(s.conf.Rule, s.now)
+	switch e.vote {
+	case "voteHeadOfSlot":
+		return head.ancestorAt(slot - int64(e.voteParam))
+	case "voteParentOfHead":
+		if head.parent != nil {
+			return head.parent
+		}
+	case "voteAttackerChainTip":
+		var tip *block
+		for _, b := range s.attacker.blocks {
+			if b.attacker && (tip == nil || b.slot > tip.slot) {
+				tip = b
+			}
+		}
+		if tip != nil {
+			return tip
+		}
+	}
+	return head
+}
+
+// observeHead counts a reorg when the honest head leaves the chain of the previous one.
+func (s *simulator) observeHead() {
+	head := s.honest.head(s.conf.Rule, s.now)
+	if !head.descendantOf(s.lastHead) {
+		common := s.lastHead
+		for !head.descendantOf(common) {
+			common = common.parent
+		}
+		depth := s.lastHead.slot - common.slot
+		s.result.Reorgs++
+		if depth > s.result.MaxReorgDepth {
+			s.result.MaxReorgDepth = depth
+		}
+	}
+	s.lastHead = head
+}
+
+func (s *simulator) finish(last int64) {
+	s.result.Slots = last
+	canonical := make(map[*block]bool)
+	for b := s.lastHead; b != nil; b = b.parent {
+		canonical[b] = true
+	}
+	var canonicalBlocks int
+	for _, b := range s.blocks[1:] {
+		if canonical[b] {
+			canonicalBlocks++
+		}
+		switch {
+		case b.attacker && canonical[b]:
+			s.result.AttackerCanonical++
+		case !b.attacker && !canonical[b]:
+			s.result.HonestOrphaned++
+		}
+	}
+	if canonicalBlocks > 0 {
+		s.result.AttackerBlockShare = float64(s.result.AttackerCanonical) / float64(canonicalBlocks)
+	}
+	attackers := 0
+	for i := 0; i < s.conf.Validators; i++ {
+		if s.plan.isAttacker(i, last) {
+			attackers++
+		}
+	}
+	s.result.AttackerStakeShare = float64(attackers) / float64(s.conf.Validators)
+	if s.result.AttackerStakeShare > 0 {
+		s.result.RelativeBlockShare = s.result.AttackerBlockShare / s.result.AttackerStakeShare
+	}
+}
@@ -0,0 +1,182 @@
+package simulate
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/tsinghua-cel/strategy-gen/types"
+)
+
+func testConfig(rule string) Config {
+	return Config{Validators: 16, SlotsPerEpoch: 4, SecondsPerSlot: 12, Epochs: 4, Seed: 1, Rule: rule}
+}
+
+func TestChangeUnstableToStable(t *testing.T) {
+	tests := []struct {
+		unstable int64
+		want     int64
+	}{
+		{unstable: 0, want: 0},
+		{unstable: 15, want: 0},
+		{unstable: 16, want: 3},
+		{unstable: 17, want: 3},
+		{unstable: 18, want: 4},
+		{unstable: 31, want: 17},
+		{unstable: 64, want: 57},
+		{unstable: 65, want: 0},
+	}
+	for _, tt := range tests {
+		if got := changeUnstableToStable(tt.unstable); got != tt.want {
+			t.Errorf("%d unstable blocks are worth %d stable ones, want %d", tt.unstable, got, tt.want)
+		}
+	}
+}
+
+func TestLengthWithStableTransport(t *testing.T) {
+	s := &simulator{conf: testConfig(RuleStable)}
+	genesis := &block{proposer: -1}
+	v := newView(s, genesis)
+	tip := genesis
+	for slot := int64(1); slot <= 20; slot++ {
+		tip = &block{id: int(slot), slot: slot, parent: tip}
+		v.addBlock(tip, 0)
+	}
+	// 20 unstable blocks are worth 6 stable ones.
+	if got := v.lengthWithStableTransport(tip, 0); got != 6 {
+		t.Errorf("length %d, want 6", got)
+	}
+	// the votes received within their slot make a block stable.
+	for i := 0; i < s.committeeSize()/3; i++ {
+		v.addVote(i, vote{slot: 20, block: tip}, s.slotTime(20))
+	}
+	if got := v.lengthWithStableTransport(tip, 0); got != 1+changeUnstableToStable(19) {
+		t.Errorf("length %d, want %d", got, 1+changeUnstableToStable(19))
+	}
+	// a run of less than 16 unstable blocks is worth nothing.
+	if got := v.lengthWithStableTransport(tip, 15); got != 1 {
+		t.Errorf("length after the finalized slot %d, want 1", got)
+	}
+}
+
+// TestReorgAccounting builds a fork by hand: the honest branch 1 <- 2 is
+// orphaned by the attacker block 3 on the genesis.
+func TestReorgAccounting(t *testing.T) {
+	s := &simulator{conf: testConfig(RuleGhost), proposers: make(map[int64]int)}
+	strategy := types.Strategy{Validators: types.GetValidatorStrategy(0, 3, 0, 100)}
+	p, err := newPlan(s, strategy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.plan = p
+	genesis := &block{proposer: -1}
+	s.blocks = []*block{genesis}
+	s.honest = newView(s, genesis)
+	s.lastHead = genesis
+	// far after the blocks, so no proposer boost applies.
+	s.now = s.slotTime(10)
+
+	a1 := s.newBlock(1, 8, false, genesis)
+	a2 := s.newBlock(2, 9, false, a1)
+	b3 := s.newBlock(3, 1, true, genesis)
+	steps := []struct {
+		add        *block
+		wantHead   *block
+		wantReorgs int
+		wantDepth  int64
+	}{
+		{add: a1, wantHead: a1},
+		{add: a2, wantHead: a2},
+		// the equal weight children are broken by the later block.
+		{add: b3, wantHead: b3, wantReorgs: 1, wantDepth: 2},
+	}
+	for _, step := range steps {
+		s.honest.addBlock(step.add, s.now)
+		s.observeHead()
+		if s.lastHead != step.wantHead {
+			t.Fatalf("head is block %d, want %d", s.lastHead.id, step.wantHead.id)
+		}
+		if s.result.Reorgs != step.wantReorgs || s.result.MaxReorgDepth != step.wantDepth {
+			t.Fatalf("reorgs %d depth %d, want %d %d", s.result.Reorgs, s.result.MaxReorgDepth, step.wantReorgs, step.wantDepth)
+		}
+	}
+
+	s.finish(15)
+	want := Result{
+		Slots:              15,
+		Reorgs:             1,
+		MaxReorgDepth:      2,
+		HonestOrphaned:     2,
+		AttackerCanonical:  1,
+		AttackerBlockShare: 1,
+		AttackerStakeShare: 0.25,
+		RelativeBlockShare: 4,
+	}
+	if !reflect.DeepEqual(s.result, want) {
+		t.Errorf("result %+v, want %+v", s.result, want)
+	}
+}
+
+func TestRun(t *testing.T) {
+	attackers := types.GetValidatorStrategy(0, 7, 0, 100)
+	withhold := types.Strategy{
+		Validators: attackers,
+		Slots:      []types.SlotStrategy{{Slot: "every", Actions: map[string]string{"BlockBeforeBroadCast": "return"}}},
+	}
+	for _, rule := range []string{RuleGhost, RuleStable} {
+		t.Run(rule, func(t *testing.T) {
+			conf := testConfig(rule)
+			honest, err := Run(conf, types.Strategy{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if honest.Reorgs != 0 || honest.HonestOrphaned != 0 || int64(honest.HonestProposals) != honest.Slots {
+				t.Errorf("honest network %+v", honest)
+			}
+
+			result, err := Run(conf, withhold)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// the honest nodes never see the withheld blocks.
+			if result.AttackerProposals == 0 || result.AttackerCanonical != 0 || result.HonestOrphaned != 0 {
+				t.Errorf("withheld blocks %+v", result)
+			}
+			if math.Abs(result.AttackerStakeShare-0.5) > 1e-9 {
+				t.Errorf("stake share %v, want 0.5", result.AttackerStakeShare)
+			}
+			if int64(result.HonestProposals+result.AttackerProposals) != result.Slots {
+				t.Errorf("%d honest and %d attacker proposals in %d slots", result.HonestProposals, result.AttackerProposals, result.Slots)
+			}
+			again, err := Run(conf, withhold)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(result, again) {
+				t.Errorf("the same seed predicted %+v and %+v", result, again)
+			}
+		})
+	}
+}
+
+func TestRunInvalidConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		conf func(*Config)
+	}{
+		{name: "few validators", conf: func(c *Config) { c.Validators = 3 }},
+		{name: "no epoch", conf: func(c *Config) { c.Epochs = 0 }},
+		{name: "unknown rule", conf: func(c *Config) { c.Rule = "longest" }},
+	}
+	for _, tt := range tests {
+		conf := testConfig(RuleGhost)
+		tt.conf(&conf)
+		if _, err := Run(conf, types.Strategy{}); err == nil {
+			t.Errorf("%s: ran the invalid config", tt.name)
+		}
+	}
+	strategy := types.Strategy{Slots: []types.SlotStrategy{{Slot: "noSuchSlot", Actions: map[string]string{}}}}
+	if _, err := Run(testConfig(RuleGhost), strategy); err == nil {
+		t.Error("ran an unknown slot function")
+	}
+}
@@ -1,30 +1,27 @@
 package types
 
 import (
-	"encoding/json"
-	"os"
-)
-
-type ValidatorStrategy struct {
-	ValidatorIndex    int `json:"validator_index"`
-	AttackerStartSlot int `json:"attacker_start_slot"`
-	AttackerEndSlot   int `json:"attacker_end_slot"`
-}
+	"fmt"
 
-type SlotStrategy struct {
-	Slot    string            `json:"slot"`
-	Level   int               `json:"level"`
-	Actions map[string]string `json:"actions"`
-}
+	schema "github.com/tsinghua-cel/strategy-schema"
+)
 
-type Strategy struct {
-	Slots      []SlotStrategy      `json:"slots"`
-	Validators []ValidatorStrategy `json:"validator"`
-}
+// The strategy types are shared with the attacker service by the strategy-schema module.
+type (
+	ValidatorStrategy   = schema.ValidatorStrategy
+	SlotStrategy        = schema.SlotStrategy
+	Trigger             = schema.Trigger
+	ConditionalStrategy = schema.ConditionalStrategy
+	Strategy            = schema.Strategy
+)
 
-func (s Strategy) ToFile(name string) error {
-	d, _ := json.MarshalIndent(s, "", "  ")
-	return os.WriteFile(name, d, 0644)
+// StrategyFromFile reads a strategy file of any supported schema version.
+func StrategyFromFile(name string) (Strategy, error) {
+	s, err := schema.FromFile(name)
+	if err != nil {
+		return s, fmt.Errorf("invalid strategy %s: %w", name, err)
+	}
+	return s, nil
 }
 
 func GetValidatorStrategy(startIndex, endIndex int, startSlot, endSlot int) []ValidatorStrategy {
@@ -5,7 +5,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/tsinghua-cel/strategy-gen/types"
-	"net/http"
 )
 
 func UpdateStrategy(url string, strategy types.Strategy) error {
@@ -14,18 +13,42 @@ func UpdateStrategy(url string, strategy types.Strategy) error {
 		return err
 	}
 
-	res, err := http.Post(fmt.Sprintf("http://%s/v1/update-strategy", url), "application/json", bytes.NewReader(d))
+	res, err := Post(fmt.Sprintf("http://%s/v1/update-strategy", url), "application/json", bytes.NewReader(d))
 	if err != nil {
 		return err
 	}
+	defer res.Body.Close()
 	if res.StatusCode != 200 {
-		return fmt.Errorf("failed to generate strategy: %s", res.Status)
+		// the service rejects the strategies it can't read, e.g. a newer schema version.
+		var body struct {
+			Error string `json:"error"`
+		}
+		if json.NewDecoder(res.Body).Decode(&body) == nil && body.Error != "" {
+			return fmt.Errorf("failed to update strategy: %s", body.Error)
+		}
+		return fmt.Errorf("failed to update strategy: %s", res.Status)
 	}
 	return nil
 }
 
+// GetStrategy returns the strategy the attacker service runs.
+func GetStrategy(url string) (types.Strategy, error) {
+	var strategy types.Strategy
+	res, err := Get(fmt.Sprintf("http://%s/v1/strategy", url))
+	if err != nil {
+		return strategy, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != 200 {
+		return strategy, fmt.Errorf("failed to get strategy: %s", res.Status)
+	}
+
+	err = json.NewDecoder(res.Body).Decode(&strategy)
+	return strategy, err
+}
+
 func GetSlot(url string) (int, error) {
-	res, err := http.Get(fmt.Sprintf("http://%s/v1/slot", url))
+	res, err := Get(fmt.Sprintf("http://%s/v1/slot", url))
 	if err != nil {
 		return 0, err
 	}
@@ -42,7 +65,7 @@ func GetSlot(url string) (int, error) {
 }
 
 func GetEpoch(url string) (int, error) {
-	res, err := http.Get(fmt.Sprintf("http://%s/v1/epoch", url))
+	res, err := Get(fmt.Sprintf("http://%s/v1/epoch", url))
 	if err != nil {
 		return 0, err
 	}
@@ -65,7 +88,7 @@ type ProposerDuty struct {
 }
 
 func GetEpochDuties(url string, epoch int64) ([]ProposerDuty, error) {
-	res, err := http.Get(fmt.Sprintf("http://%s/v1/duties/%d", url, epoch))
+	res, err := Get(fmt.Sprintf("http://%s/v1/duties/%d", url, epoch))
 	if err != nil {
 		return nil, err
 	}
@@ -80,3 +103,100 @@ func GetEpochDuties(url string, epoch int64) ([]ProposerDuty, error) {
 	}
 	return duties, nil
 }
+
+type Reorg struct {
+	Epoch int64 `json:"epoch"`
+	Slot  int64 `json:"slot"`
+	Depth int64 `json:"depth"`
+}
+
+func GetReorgs(url string) ([]Reorg, error) {
+	res, err := Get(fmt.Sprintf("http://%s/v1/reorgs", url))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != 200 {
+		return nil, fmt.Errorf("failed to get reorgs: %s", res.Status)
+	}
+
+	var reorgs []Reorg
+	err = json.NewDecoder(res.Body).Decode(&reorgs)
+	if err != nil {
+		return nil, err
+	}
+	return reorgs, nil
+}
+
+type Reward struct {
+	Epoch          int64 `json:"epoch"`
+	ValidatorIndex int   `json:"validator_index"`
+	HeadAmount     int64 `json:"head_amount"`
+	TargetAmount   int64 `json:"target_amount"`
+}
+
+func GetRewards(url string, epoch int64) ([]Reward, error) {
+	res, err := Get(fmt.Sprintf("http://%s/v1/reward/%d", url, epoch))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != 200 {
+		return nil, fmt.Errorf("failed to get rewards: %s", res.Status)
+	}
+
+	var rewards []Reward
+	err = json.NewDecoder(res.Body).Decode(&rewards)
+	if err != nil {
+		return nil, err
+	}
+	return rewards, nil
+}
+
+func GetChainSpec(url string) (ChainSpec, error) {
+	var spec ChainSpec
+	res, err := Get(fmt.Sprintf("http://%s/v1/chainspec", url))
+	if err != nil {
+		return spec, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != 200 {
+		return spec, fmt.Errorf("failed to get chain spec: %s", res.Status)
+	}
+
+	err = json.NewDecoder(res.Body).Decode(&spec)
+	if err != nil {
+		return spec, err
+	}
+	if spec.SlotsPerEpoch <= 0 || spec.SecondsPerSlot <= 0 {
+		return spec, fmt.Errorf("invalid chain spec: %+v", spec)
+	}
+	return spec, nil
+}
+
+// Validator is a validator of the head state of the attacker service, the
+// effective balance is in gwei.
+type Validator struct {
+	Index            int    `json:"index"`
+	Pubkey           string `json:"pubkey"`
+	EffectiveBalance uint64 `json:"effective_balance"`
+	Status           string `json:"status"`
+}
+
+func GetValidators(url string) ([]Validator, error) {
+	res, err := Get(fmt.Sprintf("http://%s/v1/validators", url))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != 200 {
+		return nil, fmt.Errorf("failed to get validators: %s", res.Status)
+	}
+
+	var validators []Validator
+	err = json.NewDecoder(res.Body).Decode(&validators)
+	if err != nil {
+		return nil, err
+	}
+	return validators, nil
+}
@@ -0,0 +1,81 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// ApiKeyHeader is the header of the api key of the attacker service.
+const ApiKeyHeader = "X-API-Key"
+
+// the credentials sent to the attacker service, set by SetAuth.
+var (
+	apiKey    string
+	jwtSecret []byte
+)
+
+// SetAuth sets the credentials of the requests to the attacker service, the
+// api key or a bearer token signed with the hex encoded 32 bytes secret of
+// the jwt secret file.
+func SetAuth(key string, jwtSecretFile string) error {
+	apiKey = key
+	jwtSecret = nil
+	if jwtSecretFile == "" {
+		return nil
+	}
+	data, err := os.ReadFile(jwtSecretFile)
+	if err != nil {
+		return err
+	}
+	secret, err := hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(string(data)), "0x"))
+	if err != nil || len(secret) != 32 {
+		return fmt.Errorf("invalid jwt secret %s, want 32 hex encoded bytes", jwtSecretFile)
+	}
+	jwtSecret = secret
+	return nil
+}
+
+// Get sends a GET request to the attacker service with the credentials.
+func Get(url string) (*http.Response, error) {
+	return doRequest(http.MethodGet, url, "", nil)
+}
+
+// Post sends a POST request to the attacker service with the credentials.
+func Post(url string, contentType string, body io.Reader) (*http.Response, error) {
+	return doRequest(http.MethodPost, url, contentType, body)
+}
+
+func doRequest(method string, url string, contentType string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequest(method, url, body)
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if apiKey != "" {
+		req.Header.Set(ApiKeyHeader, apiKey)
+	}
+	if len(jwtSecret) > 0 {
+		req.Header.Set("Authorization", "Bearer "+newToken(jwtSecret))
+	}
+	return http.DefaultClient.Do(req)
+}
+
+// newToken creates a HS256 token with the issued-at claim set to now, the
+// service accepts it for 60 seconds so a token is made for every request.
+func newToken(secret []byte) string {
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+	claims := base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf(`{"iat":%d}`, time.Now().Unix())))
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(header + "." + claims))
+	return header + "." + claims + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -1,5 +1,7 @@
 package utils
 
+import "time"
+
 type SlotTool struct {
 	SlotsPerEpoch int
 }
@@ -15,3 +17,28 @@ func (s SlotTool) EpochEnd(epoch int64) int64 {
 func (s SlotTool) EpochStart(epoch int64) int64 {
 	return epoch * int64(s.SlotsPerEpoch)
 }
+
+// ChainSpec is the chain timing of the attacker service.
+type ChainSpec struct {
+	SlotsPerEpoch  int   `json:"slots_per_epoch"`
+	SecondsPerSlot int   `json:"seconds_per_slot"`
+	GenesisTime    int64 `json:"genesis_time"`
+}
+
+func (c ChainSpec) SlotDuration() time.Duration {
+	return time.Duration(c.SecondsPerSlot) * time.Second
+}
+
+// SlotStartTime returns the wall clock time the slot starts at.
+func (c ChainSpec) SlotStartTime(slot int64) time.Time {
+	return time.Unix(c.GenesisTime, 0).Add(time.Duration(slot) * c.SlotDuration())
+}
+
+// CurrentSlot returns the wall clock slot.
+func (c ChainSpec) CurrentSlot() int64 {
+	elapsed := time.Since(time.Unix(c.GenesisTime, 0))
+	if elapsed < 0 {
+		return 0
+	}
+	return int64(elapsed / c.SlotDuration())
+}
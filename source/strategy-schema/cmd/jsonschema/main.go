@@ -0,0 +1,23 @@
+// Command jsonschema writes the JSON Schema of the strategy files.
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+
+	schema "github.com/tsinghua-cel/strategy-schema"
+)
+
+func main() {
+	output := flag.String("o", "strategy.schema.json", "the file to write the schema to")
+	flag.Parse()
+
+	d, err := schema.JSONSchema()
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(*output, append(d, '\n'), 0644); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,3 @@
+module github.com/tsinghua-cel/strategy-schema
+
+go 1.21
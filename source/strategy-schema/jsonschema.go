@@ -0,0 +1,89 @@
+package schema
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+//go:generate go run ./cmd/jsonschema -o strategy.schema.json
+
+// SchemaID is the $id of the JSON Schema of the strategy files.
+const SchemaID = "https://github.com/tsinghua-cel/strategy-schema/strategy.schema.json"
+
+// JSONSchema returns the JSON Schema of the strategy files, it is made from
+// the json, jsonschema and description tags of the types.
+func JSONSchema() ([]byte, error) {
+	defs := make(map[string]interface{})
+	root := objectSchema(reflect.TypeOf(Strategy{}), defs)
+	root["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	root["$id"] = SchemaID
+	root["title"] = "attacker strategy"
+	props := root["properties"].(map[string]interface{})
+	props["schema_version"].(map[string]interface{})["maximum"] = SchemaVersion
+	// the files can name the schema for the editors.
+	props["$schema"] = map[string]interface{}{"type": "string"}
+	root["$defs"] = defs
+	return json.MarshalIndent(root, "", "  ")
+}
+
+func typeSchema(t reflect.Type, defs map[string]interface{}) map[string]interface{} {
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int64, reflect.Int32:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Slice:
+		return map[string]interface{}{"type": "array", "items": typeSchema(t.Elem(), defs)}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": typeSchema(t.Elem(), defs)}
+	case reflect.Struct:
+		if _, ok := defs[t.Name()]; !ok {
+			defs[t.Name()] = nil
+			defs[t.Name()] = objectSchema(t, defs)
+		}
+		return map[string]interface{}{"$ref": "#/$defs/" + t.Name()}
+	}
+	panic("unsupported type " + t.String())
+}
+
+func objectSchema(t reflect.Type, defs map[string]interface{}) map[string]interface{} {
+	props := make(map[string]interface{})
+	required := make([]string, 0)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name := strings.Split(f.Tag.Get("json"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+		p := typeSchema(f.Type, defs)
+		if desc := f.Tag.Get("description"); desc != "" {
+			p["description"] = desc
+		}
+		for _, opt := range strings.Split(f.Tag.Get("jsonschema"), ",") {
+			key, value, _ := strings.Cut(opt, "=")
+			switch key {
+			case "required":
+				required = append(required, name)
+			case "minimum":
+				n, _ := strconv.Atoi(value)
+				p["minimum"] = n
+			case "enum":
+				p["enum"] = strings.Split(value, "|")
+			}
+		}
+		props[name] = p
+	}
+	s := map[string]interface{}{
+		"type":                 "object",
+		"properties":           props,
+		"additionalProperties": false,
+	}
+	if len(required) > 0 {
+		s["required"] = required
+	}
+	return s
+}
@@ -0,0 +1,84 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+const v1Strategy = `{
+  "slots": [{"slot": "every", "level": 0, "actions": {"BlockBeforeBroadCast": "delayWithSecond:2"}}],
+  "validator": [{"validator_index": 1, "attacker_start_slot": 0, "attacker_end_slot": 100}]
+}`
+
+func TestMigrateVersion1(t *testing.T) {
+	var s Strategy
+	if err := json.Unmarshal([]byte(v1Strategy), &s); err != nil {
+		t.Fatal(err)
+	}
+	if s.SchemaVersion != SchemaVersion {
+		t.Fatalf("got schema version %d, want %d", s.SchemaVersion, SchemaVersion)
+	}
+	if len(s.Slots) != 1 || s.Slots[0].Actions["BlockBeforeBroadCast"] != "delayWithSecond:2" {
+		t.Fatalf("slots not kept: %+v", s.Slots)
+	}
+	if !s.IsAttacker(1, 100) || s.IsAttacker(1, 101) || s.IsAttacker(2, 0) {
+		t.Fatal("wrong attacker validators")
+	}
+}
+
+func TestRejectUnsupportedVersion(t *testing.T) {
+	for _, version := range []string{"0", "3"} {
+		var s Strategy
+		err := json.Unmarshal([]byte(`{"schema_version": `+version+`, "slots": []}`), &s)
+		var verr *VersionError
+		if !errors.As(err, &verr) {
+			t.Fatalf("version %s: got %v, want a VersionError", version, err)
+		}
+	}
+	var s Strategy
+	err := json.Unmarshal([]byte(`{"schema_version": 3}`), &s)
+	if err == nil || !strings.Contains(err.Error(), "newer than the supported version") {
+		t.Fatalf("got %v", err)
+	}
+}
+
+func TestMarshalWritesVersion(t *testing.T) {
+	d, err := json.Marshal(Strategy{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"schema_version":2,"slots":[],"validator":[],"allow_slashable":false}`
+	if string(d) != want {
+		t.Fatalf("got %s, want %s", d, want)
+	}
+	var s Strategy
+	if err := json.Unmarshal(d, &s); err != nil || s.SchemaVersion != SchemaVersion {
+		t.Fatalf("round trip failed: %v %+v", err, s)
+	}
+}
+
+func TestNull(t *testing.T) {
+	var s *Strategy
+	if err := json.Unmarshal([]byte("null"), &s); err != nil || s != nil {
+		t.Fatalf("got %v %v", s, err)
+	}
+}
+
+// TestSchemaFileIsGenerated fails when the types changed without go generate.
+func TestSchemaFileIsGenerated(t *testing.T) {
+	want, err := JSONSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile("strategy.schema.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(bytes.TrimSpace(got), want) {
+		t.Fatal("strategy.schema.json is outdated, run go generate")
+	}
+}
@@ -0,0 +1,103 @@
+package schema
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+)
+
+// Trigger types of a conditional strategy.
+const (
+	// TriggerAttackerProposers holds when the next epoch has at least Value
+	// consecutive slots proposed by attacker validators.
+	TriggerAttackerProposers = "attackerProposers"
+	// TriggerFinalityLag holds when the current epoch is more than Value epochs
+	// after the finalized epoch.
+	TriggerFinalityLag = "finalityLag"
+	// TriggerReorgDepth holds when a reorg of depth at least Value was observed
+	// in the current or previous epoch.
+	TriggerReorgDepth = "reorgDepth"
+)
+
+type SlotStrategy struct {
+	Slot    string            `json:"slot" jsonschema:"required" description:"a slot number or a function slot: every, attackerSlot, period:n:k, ..."`
+	Level   int               `json:"level" description:"the rule of the highest level wins when several rules match a slot"`
+	Actions map[string]string `json:"actions" jsonschema:"required" description:"the action of each action point, name:param:..."`
+}
+
+type ValidatorStrategy struct {
+	ValidatorIndex    int `json:"validator_index" jsonschema:"required,minimum=0"`
+	AttackerStartSlot int `json:"attacker_start_slot" jsonschema:"required,minimum=0"`
+	AttackerEndSlot   int `json:"attacker_end_slot" jsonschema:"required,minimum=0"`
+}
+
+type Trigger struct {
+	Type  string `json:"type" jsonschema:"required,enum=attackerProposers|finalityLag|reorgDepth"`
+	Value int    `json:"value" jsonschema:"required"`
+}
+
+// ConditionalStrategy is a block of slot rules that is only active while it is
+// enabled. The triggers are evaluated by the service once per epoch: when all
+// Triggers hold the block is enabled for the next Epochs epochs (1 if not set),
+// when any of Until holds it is disabled again.
+type ConditionalStrategy struct {
+	Name     string         `json:"name" jsonschema:"required"`
+	Triggers []Trigger      `json:"triggers" jsonschema:"required"`
+	Until    []Trigger      `json:"until,omitempty"`
+	Epochs   int            `json:"epochs,omitempty" jsonschema:"minimum=0"`
+	Slots    []SlotStrategy `json:"slots" jsonschema:"required"`
+}
+
+// Strategy is the strategy of the attacker service. It is written with the
+// current SchemaVersion and the older versions are migrated when it is read.
+type Strategy struct {
+	SchemaVersion int                   `json:"schema_version" jsonschema:"minimum=1" description:"the version of the strategy format, 1 if not set"`
+	Slots         []SlotStrategy        `json:"slots"`
+	Validators    []ValidatorStrategy   `json:"validator" description:"the attacker validators and the slots they attack in"`
+	Conditional   []ConditionalStrategy `json:"conditional,omitempty"`
+	// AllowSlashable must be set to use the actions that make attacker
+	// validators slashable, such as double proposals and double votes.
+	AllowSlashable bool `json:"allow_slashable"`
+}
+
+// Version returns a short hash of the strategy content, the same strategy
+// always has the same version.
+func (s *Strategy) Version() string {
+	d, err := json.Marshal(s)
+	if err != nil {
+		return ""
+	}
+	h := sha256.Sum256(d)
+	return hex.EncodeToString(h[:8])
+}
+
+// IsAttacker returns true if the validator is an attacker at the slot.
+func (s *Strategy) IsAttacker(valIdx int, slot int64) bool {
+	for _, v := range s.Validators {
+		if v.ValidatorIndex == valIdx && slot >= int64(v.AttackerStartSlot) && slot <= int64(v.AttackerEndSlot) {
+			return true
+		}
+	}
+	return false
+}
+
+// FromFile reads a strategy file of any supported version.
+func FromFile(name string) (Strategy, error) {
+	var s Strategy
+	d, err := os.ReadFile(name)
+	if err != nil {
+		return s, err
+	}
+	err = json.Unmarshal(d, &s)
+	return s, err
+}
+
+// ToFile writes the strategy with the current schema version.
+func (s Strategy) ToFile(name string) error {
+	d, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(name, d, 0644)
+}
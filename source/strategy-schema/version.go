@@ -0,0 +1,90 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// SchemaVersion is the version of the strategy format written by this
+// package. Version 1 is the format before schema_version was added.
+const SchemaVersion = 2
+
+// migrations[v] upgrades the fields of a strategy of version v to v+1.
+var migrations = map[int]func(fields map[string]json.RawMessage) error{
+	// version 2 only adds schema_version to the fields of version 1.
+	1: func(map[string]json.RawMessage) error { return nil },
+}
+
+// VersionError is returned for a strategy of a version this package can't read.
+type VersionError struct {
+	Version int
+}
+
+func (e *VersionError) Error() string {
+	if e.Version > SchemaVersion {
+		return fmt.Sprintf("strategy schema version %d is newer than the supported version %d, update the tool", e.Version, SchemaVersion)
+	}
+	return fmt.Sprintf("invalid strategy schema version %d, the supported versions are 1 to %d", e.Version, SchemaVersion)
+}
+
+// Migrate upgrades the strategy json of any supported version to the
+// current one.
+func Migrate(data []byte) ([]byte, error) {
+	fields := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	version := 1
+	if raw, ok := fields["schema_version"]; ok {
+		if err := json.Unmarshal(raw, &version); err != nil {
+			return nil, fmt.Errorf("invalid strategy schema version %s", raw)
+		}
+	}
+	if version < 1 || version > SchemaVersion {
+		return nil, &VersionError{Version: version}
+	}
+	if version == SchemaVersion {
+		return data, nil
+	}
+	for v := version; v < SchemaVersion; v++ {
+		if err := migrations[v](fields); err != nil {
+			return nil, fmt.Errorf("migrate strategy from version %d: %w", v, err)
+		}
+	}
+	fields["schema_version"] = json.RawMessage(fmt.Sprint(SchemaVersion))
+	return json.Marshal(fields)
+}
+
+// plainStrategy has the fields of Strategy without its json methods.
+type plainStrategy Strategy
+
+// MarshalJSON writes the strategy with the current schema version, the
+// missing slots and validators are written as empty lists.
+func (s Strategy) MarshalJSON() ([]byte, error) {
+	s.SchemaVersion = SchemaVersion
+	if s.Slots == nil {
+		s.Slots = []SlotStrategy{}
+	}
+	if s.Validators == nil {
+		s.Validators = []ValidatorStrategy{}
+	}
+	return json.Marshal(plainStrategy(s))
+}
+
+// UnmarshalJSON migrates the strategy of an older version and rejects the
+// unsupported versions with a VersionError.
+func (s *Strategy) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	data, err := Migrate(data)
+	if err != nil {
+		return err
+	}
+	var plain plainStrategy
+	if err := json.Unmarshal(data, &plain); err != nil {
+		return err
+	}
+	*s = Strategy(plain)
+	return nil
+}